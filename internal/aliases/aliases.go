@@ -0,0 +1,255 @@
+// Package aliases expands task descriptions with synonyms so keyword search
+// finds agents described in different words.
+//
+// Entries come from three layers, each overriding the one before it: the
+// built-in defaults, an optional YAML file and rows edited at runtime through
+// the store. A Dictionary swaps in a rebuilt set atomically, so the layers can
+// be reloaded while requests are being served.
+package aliases
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Sources of an entry, from lowest to highest precedence
+const (
+	SourceBuiltin = "builtin"
+	SourceFile    = "file"
+	SourceStore   = "store"
+)
+
+// DefaultDomain is used for entries that do not name a domain
+const DefaultDomain = "general"
+
+// Entry maps a keyword to its synonyms within a domain. A task mentioning the
+// keyword gains every alias; one mentioning an alias gains the keyword.
+type Entry struct {
+	Domain  string   `json:"domain"`
+	Keyword string   `json:"keyword"`
+	Aliases []string `json:"aliases"`
+	Source  string   `json:"source"`
+}
+
+// Expansion records one entry that Expand applied
+type Expansion struct {
+	Domain  string   `json:"domain"`
+	Keyword string   `json:"keyword"`
+	Matched string   `json:"matched"` // word that triggered it, possibly added by an earlier expansion
+	Added   []string `json:"added"`
+}
+
+// Normalize lowercases and trims an entry and removes duplicate aliases.
+// Words are matched whole, so keywords and aliases may not contain spaces.
+func Normalize(e Entry) (Entry, error) {
+	e.Domain = strings.ToLower(strings.TrimSpace(e.Domain))
+	if e.Domain == "" {
+		e.Domain = DefaultDomain
+	}
+	e.Keyword = strings.ToLower(strings.TrimSpace(e.Keyword))
+	if e.Keyword == "" {
+		return e, fmt.Errorf("alias keyword is required")
+	}
+	if strings.ContainsFunc(e.Domain+e.Keyword, isSpace) {
+		return e, fmt.Errorf("alias domain and keyword must be single words: %q/%q", e.Domain, e.Keyword)
+	}
+
+	seen := map[string]bool{e.Keyword: true}
+	aliases := []string{}
+	for _, a := range e.Aliases {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || seen[a] {
+			continue
+		}
+		if strings.ContainsFunc(a, isSpace) {
+			return e, fmt.Errorf("alias %q for %q must be a single word", a, e.Keyword)
+		}
+		seen[a] = true
+		aliases = append(aliases, a)
+	}
+	e.Aliases = aliases
+	return e, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// Dictionary holds the active entries
+type Dictionary struct {
+	entries atomic.Pointer[[]Entry]
+}
+
+// New returns a dictionary holding the built-in entries
+func New() *Dictionary {
+	d := &Dictionary{}
+	d.Load()
+	return d
+}
+
+// Load replaces the active entries with the built-in ones overlaid by layers
+// in order. An entry replaces any earlier one with the same domain and
+// keyword, and an entry without aliases removes it.
+func (d *Dictionary) Load(layers ...[]Entry) {
+	type key struct{ domain, keyword string }
+	merged := map[key]Entry{}
+	for _, layer := range append([][]Entry{builtin}, layers...) {
+		for _, e := range layer {
+			k := key{e.Domain, e.Keyword}
+			if len(e.Aliases) == 0 {
+				delete(merged, k)
+				continue
+			}
+			merged[k] = e
+		}
+	}
+
+	entries := make([]Entry, 0, len(merged))
+	for _, e := range merged {
+		entries = append(entries, e)
+	}
+	// Expand walks keywords in a fixed order so its output is deterministic
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Keyword != entries[j].Keyword {
+			return entries[i].Keyword < entries[j].Keyword
+		}
+		return entries[i].Domain < entries[j].Domain
+	})
+	d.entries.Store(&entries)
+}
+
+// Entries returns the active entries ordered by keyword then domain
+func (d *Dictionary) Entries() []Entry {
+	entries := *d.entries.Load()
+	out := make([]Entry, len(entries))
+	for i, e := range entries {
+		e.Aliases = append([]string(nil), e.Aliases...)
+		out[i] = e
+	}
+	return out
+}
+
+// Expand appends synonyms to task using word boundaries and reports which
+// entries were applied. Words added by one entry can trigger later ones.
+func (d *Dictionary) Expand(task string) (string, []Expansion) {
+	wordSet := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(task)) {
+		wordSet[w] = true
+	}
+
+	expanded := task
+	expansions := []Expansion{}
+	for _, e := range *d.entries.Load() {
+		exp := Expansion{Domain: e.Domain, Keyword: e.Keyword}
+		if wordSet[e.Keyword] {
+			exp.Matched = e.Keyword
+		}
+		// An alias in the task brings in the keyword
+		for _, alias := range e.Aliases {
+			if wordSet[alias] {
+				if !wordSet[e.Keyword] {
+					expanded += " " + e.Keyword
+					wordSet[e.Keyword] = true
+					exp.Matched = alias
+					exp.Added = append(exp.Added, e.Keyword)
+				}
+				break
+			}
+		}
+		// The keyword brings in all of its aliases for better skill matching
+		if wordSet[e.Keyword] {
+			for _, alias := range e.Aliases {
+				if !wordSet[alias] {
+					expanded += " " + alias
+					wordSet[alias] = true
+					exp.Added = append(exp.Added, alias)
+				}
+			}
+		}
+		if len(exp.Added) > 0 {
+			expansions = append(expansions, exp)
+		}
+	}
+
+	return expanded, expansions
+}
+
+// Builtin returns the default entries
+func Builtin() []Entry {
+	out := make([]Entry, len(builtin))
+	for i, e := range builtin {
+		e.Aliases = append([]string(nil), e.Aliases...)
+		out[i] = e
+	}
+	return out
+}
+
+// builtin holds the defaults that apply when no file or store entry overrides them
+var builtin = func() []Entry {
+	groups := map[string]map[string][]string{
+		"code": {
+			"review":   {"code", "quality", "check", "audit", "inspect", "reviw", "reveiw"},
+			"code":     {"programming", "coding", "development", "software", "script"},
+			"bug":      {"fix", "debug", "error", "issue", "problem", "bugs", "fixing", "code-quality"},
+			"refactor": {"clean", "improve", "restructure", "optimize"},
+		},
+		"testing": {
+			"test": {"testing", "tests", "unittest", "unit", "qa", "quality"},
+			"e2e":  {"end-to-end", "integration", "functional"},
+		},
+		"security": {
+			"security": {"secure", "vulnerability", "vulnerabilities", "exploit", "attack", "pentest"},
+			"audit":    {"review", "check", "assess", "examine"},
+		},
+		"docs": {
+			"docs":  {"documentation", "document", "readme", "guide", "manual", "explain"},
+			"write": {"create", "generate", "draft", "compose"},
+		},
+		"data": {
+			"data":    {"dataset", "database", "analytics", "statistics", "metrics"},
+			"analyze": {"analysis", "examine", "study", "investigate", "explore"},
+			"charts":  {"graphs", "visualization", "visualize", "plots", "dashboard", "report"},
+		},
+		"architecture": {
+			"architecture": {"design", "structure", "system", "scalable", "microservices"},
+			"scale":        {"scalability", "scaling", "performance", "optimize", "load"},
+			"performance":  {"optimize", "speed", "fast", "slow", "bottleneck", "efficient", "optimization"},
+			"improve":      {"better", "enhance", "upgrade", "fix", "refine", "polish", "quality"},
+			"quality":      {"better", "improve", "good", "clean", "nice", "readable"},
+		},
+		"devops": {
+			"deploy": {"deployment", "release", "ship", "publish", "rollout"},
+			"devops": {"ci", "cd", "pipeline", "cicd", "ci/cd", "infrastructure", "infra"},
+			"docker": {"container", "containerize", "kubernetes", "k8s"},
+		},
+	}
+	entries, err := fromGroups(groups, SourceBuiltin)
+	if err != nil {
+		panic(err)
+	}
+	return entries
+}()
+
+// fromGroups flattens domain -> keyword -> aliases into normalized entries
+// ordered by domain and keyword
+func fromGroups(groups map[string]map[string][]string, source string) ([]Entry, error) {
+	var entries []Entry
+	for domain, keywords := range groups {
+		for keyword, aliases := range keywords {
+			e, err := Normalize(Entry{Domain: domain, Keyword: keyword, Aliases: aliases, Source: source})
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Domain != entries[j].Domain {
+			return entries[i].Domain < entries[j].Domain
+		}
+		return entries[i].Keyword < entries[j].Keyword
+	})
+	return entries, nil
+}
@@ -0,0 +1,35 @@
+package aliases
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFile reads entries from a YAML file of domains, each mapping keywords
+// to their aliases:
+//
+//	devops:
+//	  k8s: [kubernetes, kubectl, cluster]
+//	data:
+//	  etl: [pipeline, ingest]
+//
+// An empty alias list turns off a built-in entry with the same domain and keyword.
+func LoadFile(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alias file: %w", err)
+	}
+
+	var groups map[string]map[string][]string
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse alias file %s: %w", path, err)
+	}
+
+	entries, err := fromGroups(groups, SourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, nil
+}
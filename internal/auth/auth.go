@@ -0,0 +1,179 @@
+// Package auth identifies who is calling the MCP server.
+//
+// Clients present a token, either as "Authorization: Bearer <token>" or in an
+// X-API-Key header, and a Directory maps it to a named Principal holding
+// governance roles. Callers without a token act as Anonymous, which holds no
+// roles. stdio sessions carry no headers, so the server picks their principal
+// from configuration instead.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+)
+
+// Reserved principal names, which configured principals may not use
+const (
+	AnonymousName = "anonymous"
+	SystemName    = "system" // records automatic governance actions
+	APIName       = "api"    // created_by of anonymous registrations
+)
+
+// Anonymous is the principal of callers that present no token
+var Anonymous = Principal{Name: AnonymousName}
+
+// ErrUnknownToken is returned for a token that maps to no principal
+var ErrUnknownToken = errors.New("unknown token")
+
+// Principal is an authenticated caller and the governance roles it holds
+type Principal struct {
+	Name  string                  `json:"name"`
+	Roles []models.GovernanceRole `json:"roles"`
+}
+
+// HasRole reports whether p holds role
+func (p Principal) HasRole(role models.GovernanceRole) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// HasGovernanceRole reports whether p holds police, judge or executioner
+func (p Principal) HasGovernanceRole() bool {
+	return p.HasRole(models.RolePolice) || p.HasRole(models.RoleJudge) || p.HasRole(models.RoleExecutioner)
+}
+
+// Entry configures one principal and the token that identifies it
+type Entry struct {
+	Name  string
+	Token string
+	Roles []models.GovernanceRole
+}
+
+// Directory maps tokens to principals
+type Directory struct {
+	entries []Entry
+}
+
+// NewDirectory checks entries and returns a directory over them. Names and
+// tokens must be unique, and roles must be ones ValidRole accepts.
+func NewDirectory(entries []Entry) (*Directory, error) {
+	names := map[string]bool{}
+	tokens := map[string]bool{}
+	for _, e := range entries {
+		switch {
+		case e.Name == "":
+			return nil, fmt.Errorf("principal name is required")
+		case e.Name == AnonymousName || e.Name == SystemName || e.Name == APIName:
+			return nil, fmt.Errorf("principal name %q is reserved", e.Name)
+		case names[e.Name]:
+			return nil, fmt.Errorf("duplicate principal %q", e.Name)
+		case e.Token == "":
+			return nil, fmt.Errorf("principal %q has no token", e.Name)
+		case tokens[e.Token]:
+			return nil, fmt.Errorf("principal %q reuses another principal's token", e.Name)
+		}
+		for _, role := range e.Roles {
+			if !ValidRole(role) {
+				return nil, fmt.Errorf("principal %q has unknown role %q (use police, judge, executioner or admin)", e.Name, role)
+			}
+		}
+		names[e.Name] = true
+		tokens[e.Token] = true
+	}
+	return &Directory{entries: slices.Clone(entries)}, nil
+}
+
+// ValidRole reports whether role is one a principal may hold
+func ValidRole(role models.GovernanceRole) bool {
+	switch role {
+	case models.RolePolice, models.RoleJudge, models.RoleExecutioner, models.RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Len returns the number of configured principals
+func (d *Directory) Len() int {
+	return len(d.entries)
+}
+
+// Lookup returns the principal identified by token. Every entry is compared
+// in constant time so response timing does not reveal tokens.
+func (d *Directory) Lookup(token string) (Principal, error) {
+	var found *Entry
+	for i := range d.entries {
+		if subtle.ConstantTimeCompare([]byte(d.entries[i].Token), []byte(token)) == 1 {
+			found = &d.entries[i]
+		}
+	}
+	if found == nil || token == "" {
+		return Principal{}, ErrUnknownToken
+	}
+	return Principal{Name: found.Name, Roles: slices.Clone(found.Roles)}, nil
+}
+
+// Authenticate returns the principal for the token in r, or Anonymous when r
+// carries none
+func (d *Directory) Authenticate(r *http.Request) (Principal, error) {
+	token := r.Header.Get("X-API-Key")
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, value, ok := strings.Cut(h, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return Principal{}, fmt.Errorf("unsupported authorization scheme")
+		}
+		token = strings.TrimSpace(value)
+	}
+	if token == "" {
+		return Anonymous, nil
+	}
+	return d.Lookup(token)
+}
+
+// Middleware authenticates every request, rejecting unknown tokens with 401,
+// and passes the principal to handlers through the request context
+func (d *Directory) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := d.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="agentmcp"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying p
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal carried by ctx, or Anonymous
+func FromContext(ctx context.Context) Principal {
+	if p, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return p
+	}
+	return Anonymous
+}
+
+type localKey struct{}
+
+// WithLocal marks ctx as a local session: one the user started on the
+// server's own machine, such as stdio, rather than a network caller
+func WithLocal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localKey{}, true)
+}
+
+// IsLocal reports whether ctx belongs to a local session
+func IsLocal(ctx context.Context) bool {
+	local, _ := ctx.Value(localKey{}).(bool)
+	return local
+}
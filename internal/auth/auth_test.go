@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+)
+
+func testDirectory(t *testing.T) *Directory {
+	t.Helper()
+	d, err := NewDirectory([]Entry{
+		{Name: "cop", Token: "cop-token", Roles: []models.GovernanceRole{models.RolePolice}},
+		{Name: "bench", Token: "bench-token", Roles: []models.GovernanceRole{models.RoleJudge, models.RoleExecutioner}},
+	})
+	if err != nil {
+		t.Fatalf("NewDirectory failed: %v", err)
+	}
+	return d
+}
+
+func TestNewDirectory(t *testing.T) {
+	police := []models.GovernanceRole{models.RolePolice}
+	tests := []struct {
+		name    string
+		entries []Entry
+		wantErr string
+	}{
+		{"valid", []Entry{{Name: "a", Token: "x", Roles: police}, {Name: "b", Token: "y"}}, ""},
+		{"missing name", []Entry{{Token: "x"}}, "name is required"},
+		{"reserved name", []Entry{{Name: "anonymous", Token: "x"}}, "reserved"},
+		{"reserved api name", []Entry{{Name: "api", Token: "x"}}, "reserved"},
+		{"duplicate name", []Entry{{Name: "a", Token: "x"}, {Name: "a", Token: "y"}}, "duplicate principal"},
+		{"missing token", []Entry{{Name: "a"}}, "has no token"},
+		{"shared token", []Entry{{Name: "a", Token: "x"}, {Name: "b", Token: "x"}}, "reuses"},
+		{"unknown role", []Entry{{Name: "a", Token: "x", Roles: []models.GovernanceRole{"mayor"}}}, "unknown role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDirectory(tt.entries)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("NewDirectory() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("NewDirectory() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	d := testDirectory(t)
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		want     string
+		wantErr  bool
+		wantRole models.GovernanceRole
+	}{
+		{"no token", "", "", AnonymousName, false, ""},
+		{"bearer", "Authorization", "Bearer cop-token", "cop", false, models.RolePolice},
+		{"lowercase scheme", "Authorization", "bearer bench-token", "bench", false, models.RoleExecutioner},
+		{"api key header", "X-API-Key", "bench-token", "bench", false, models.RoleJudge},
+		{"unknown token", "Authorization", "Bearer nope", "", true, ""},
+		{"basic auth", "Authorization", "Basic Y29wOnB3", "", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			p, err := d.Authenticate(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if p.Name != tt.want {
+				t.Errorf("principal = %q, want %q", p.Name, tt.want)
+			}
+			if tt.wantRole != "" && !p.HasRole(tt.wantRole) {
+				t.Errorf("principal %q roles = %v, want %s", p.Name, p.Roles, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	d := testDirectory(t)
+	var got Principal
+	handler := d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer cop-token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || got.Name != "cop" {
+		t.Errorf("status = %d, principal = %q; want 200 and cop", w.Code, got.Name)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown token status = %d, want 401", w.Code)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if p := FromContext(context.Background()); p.Name != AnonymousName || len(p.Roles) != 0 {
+		t.Errorf("FromContext(empty) = %+v, want anonymous without roles", p)
+	}
+	p := Principal{Name: "cop", Roles: []models.GovernanceRole{models.RolePolice}}
+	if got := FromContext(WithPrincipal(context.Background(), p)); got.Name != "cop" {
+		t.Errorf("FromContext() = %+v, want cop", got)
+	}
+	if IsLocal(context.Background()) || !IsLocal(WithLocal(context.Background())) {
+		t.Error("IsLocal should be set only by WithLocal")
+	}
+}
@@ -0,0 +1,448 @@
+// Package config loads the v2 server configuration.
+//
+// Values are resolved in order of precedence: command-line flag, environment
+// variable, config file (config.v2.yaml, with ${ENV} interpolation), default.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aminghadersohi/agentmcp/internal/auth"
+	"github.com/aminghadersohi/agentmcp/internal/database"
+	"github.com/aminghadersohi/agentmcp/internal/embeddings"
+	"github.com/aminghadersohi/agentmcp/internal/generator"
+	"github.com/aminghadersohi/agentmcp/internal/governance"
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is loaded when no config file is named and it exists
+const DefaultPath = "config.v2.yaml"
+
+// Config mirrors config.v2.yaml.example
+type Config struct {
+	Database   DatabaseConfig   `yaml:"database"`
+	Embeddings EmbeddingsConfig `yaml:"embeddings"`
+	Generation GenerationConfig `yaml:"generation"`
+	Governance GovernanceConfig `yaml:"governance"`
+	Server     ServerConfig     `yaml:"server"`
+	Aliases    AliasesConfig    `yaml:"aliases"`
+	Auth       AuthConfig       `yaml:"auth"`
+}
+
+// DatabaseConfig configures the storage backend
+type DatabaseConfig struct {
+	Driver         string `yaml:"driver"` // postgres or memory
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port"`
+	Name           string `yaml:"name"`
+	User           string `yaml:"user"`
+	Password       string `yaml:"password"`
+	MaxConnections int    `yaml:"max_connections"`
+}
+
+// EmbeddingsConfig configures the embedding engine
+type EmbeddingsConfig struct {
+	Type         string   `yaml:"type"`          // python, http, openai, ollama or local
+	HTTPEndpoint string   `yaml:"http_endpoint"` // empty uses the type's default
+	Model        string   `yaml:"model"`
+	APIKey       string   `yaml:"api_key"`
+	AuthHeader   string   `yaml:"auth_header"`
+	BatchSize    int      `yaml:"batch_size"`
+	Dimension    int      `yaml:"dimension"`
+	Timeout      Duration `yaml:"timeout"`
+	CacheSize    int      `yaml:"cache_size"` // 0 disables the embedding cache
+	CacheDir     string   `yaml:"cache_dir"`  // optional on-disk cache layer
+}
+
+// GenerationConfig configures LLM agent generation
+type GenerationConfig struct {
+	Provider  string   `yaml:"provider"` // anthropic
+	APIKey    string   `yaml:"api_key"`
+	Model     string   `yaml:"model"`
+	MaxTokens int      `yaml:"max_tokens"`
+	Timeout   Duration `yaml:"timeout"`
+}
+
+// GovernanceConfig configures the governance engine
+type GovernanceConfig struct {
+	Enabled                 bool     `yaml:"enabled"`
+	AutoQuarantineThreshold int      `yaml:"auto_quarantine_threshold"`
+	ReputationBanThreshold  float64  `yaml:"reputation_ban_threshold"`
+	BanCoolingOff           Duration `yaml:"ban_cooling_off"` // wait between a ban ruling and its execution
+	MaxAppeals              int      `yaml:"max_appeals"`     // appeals allowed per agent
+}
+
+// ServerConfig configures the MCP transport
+type ServerConfig struct {
+	Transport string `yaml:"transport"` // stdio, sse or http
+	Port      int    `yaml:"port"`
+}
+
+// AliasesConfig configures the task alias dictionary
+type AliasesConfig struct {
+	File           string   `yaml:"file"`            // optional YAML of domain -> keyword -> aliases
+	ReloadInterval Duration `yaml:"reload_interval"` // 0 reloads only on SIGHUP or reload_aliases
+}
+
+// AuthConfig maps caller tokens to principals and their governance roles
+type AuthConfig struct {
+	Principals []PrincipalConfig `yaml:"principals"`
+	StdioToken string            `yaml:"stdio_token"` // principal for stdio sessions, which carry no headers
+}
+
+// PrincipalConfig configures one caller
+type PrincipalConfig struct {
+	Name  string   `yaml:"name"`
+	Token string   `yaml:"token"`
+	Roles []string `yaml:"roles"` // police, judge, executioner, admin
+}
+
+// Duration is a time.Duration read from strings such as "30s"; a bare
+// number is taken as seconds
+type Duration time.Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	if node.Tag == "!!int" || node.Tag == "!!float" {
+		secs, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil {
+			return err
+		}
+		*d = Duration(secs * float64(time.Second))
+		return nil
+	}
+	v, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", node.Value, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	emb := embeddings.DefaultConfig()
+	gen := generator.DefaultConfig()
+	gov := governance.DefaultConfig()
+
+	return &Config{
+		Database: DatabaseConfig{
+			Driver:         "postgres",
+			Host:           "localhost",
+			Port:           5432,
+			Name:           "mcp_serve",
+			User:           "mcp",
+			MaxConnections: 20,
+		},
+		Embeddings: EmbeddingsConfig{
+			Type:      "http",
+			Model:     emb.Model,
+			BatchSize: embeddings.DefaultBatchSize,
+			Dimension: 384,
+			Timeout:   Duration(emb.Timeout),
+			CacheSize: embeddings.DefaultCacheSize,
+		},
+		Generation: GenerationConfig{
+			Provider:  "anthropic",
+			Model:     gen.Model,
+			MaxTokens: gen.MaxTokens,
+			Timeout:   Duration(gen.Timeout),
+		},
+		Governance: GovernanceConfig{
+			Enabled:                 gov.Enabled,
+			AutoQuarantineThreshold: gov.AutoQuarantineThreshold,
+			ReputationBanThreshold:  gov.ReputationBanThreshold,
+			BanCoolingOff:           Duration(gov.BanCoolingOff),
+			MaxAppeals:              gov.MaxAppeals,
+		},
+		Server: ServerConfig{
+			Transport: "stdio",
+			Port:      8080,
+		},
+	}
+}
+
+// envRefRe matches ${VAR} and ${VAR:-fallback}
+var envRefRe = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// interpolate replaces ${VAR} references using lookup. A bare $ is left alone
+// so values such as passwords may contain it.
+func interpolate(data []byte, lookup func(string) (string, bool)) []byte {
+	return envRefRe.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := envRefRe.FindSubmatch(m)
+		if v, ok := lookup(string(sub[1])); ok && v != "" {
+			return []byte(v)
+		}
+		return sub[2]
+	})
+}
+
+// Load returns the defaults overlaid with the config file at path and then
+// with environment variables. An empty path loads DefaultPath if it exists.
+func Load(path string) (*Config, error) {
+	return load(path, os.LookupEnv)
+}
+
+func load(path string, lookup func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		dec := yaml.NewDecoder(bytes.NewReader(interpolate(data, lookup)))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+		// No config file; defaults and environment only
+	default:
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := cfg.applyEnv(lookup); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// envBinding maps an environment variable onto a config field
+type envBinding struct {
+	name string
+	set  func(c *Config, v string) error
+}
+
+func setString(field func(c *Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func setInt(field func(c *Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// envBindings lists the environment variables that override file values
+var envBindings = []envBinding{
+	{"DB_DRIVER", setString(func(c *Config) *string { return &c.Database.Driver })},
+	{"DB_HOST", setString(func(c *Config) *string { return &c.Database.Host })},
+	{"DB_PORT", setInt(func(c *Config) *int { return &c.Database.Port })},
+	{"DB_NAME", setString(func(c *Config) *string { return &c.Database.Name })},
+	{"DB_USER", setString(func(c *Config) *string { return &c.Database.User })},
+	{"DB_PASSWORD", setString(func(c *Config) *string { return &c.Database.Password })},
+	{"DB_MAX_CONNECTIONS", setInt(func(c *Config) *int { return &c.Database.MaxConnections })},
+	{"EMBEDDING_TYPE", setString(func(c *Config) *string { return &c.Embeddings.Type })},
+	{"EMBEDDING_URL", setString(func(c *Config) *string { return &c.Embeddings.HTTPEndpoint })},
+	{"EMBEDDING_MODEL", setString(func(c *Config) *string { return &c.Embeddings.Model })},
+	{"EMBEDDING_API_KEY", setString(func(c *Config) *string { return &c.Embeddings.APIKey })},
+	{"EMBEDDING_CACHE_SIZE", setInt(func(c *Config) *int { return &c.Embeddings.CacheSize })},
+	{"EMBEDDING_CACHE_DIR", setString(func(c *Config) *string { return &c.Embeddings.CacheDir })},
+	{"ANTHROPIC_API_KEY", setString(func(c *Config) *string { return &c.Generation.APIKey })},
+	{"GENERATOR_MODEL", setString(func(c *Config) *string { return &c.Generation.Model })},
+	{"MCP_TRANSPORT", setString(func(c *Config) *string { return &c.Server.Transport })},
+	{"MCP_PORT", setInt(func(c *Config) *int { return &c.Server.Port })},
+	{"TASK_ALIASES_FILE", setString(func(c *Config) *string { return &c.Aliases.File })},
+	{"MCP_TOKEN", setString(func(c *Config) *string { return &c.Auth.StdioToken })},
+}
+
+// applyEnv overrides file values with any bound environment variables that are set
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for _, b := range envBindings {
+		v, ok := lookup(b.name)
+		if !ok || v == "" {
+			continue
+		}
+		if err := b.set(c, v); err != nil {
+			return fmt.Errorf("invalid %s: %w", b.name, err)
+		}
+	}
+	return nil
+}
+
+// RegisterFlags defines command-line flags on fs that write into c.
+// Register on a Default() config so -help shows the built-in defaults.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Database.Driver, "db-driver", c.Database.Driver, "Storage backend: postgres or memory")
+	fs.StringVar(&c.Database.Host, "db-host", c.Database.Host, "Database host")
+	fs.IntVar(&c.Database.Port, "db-port", c.Database.Port, "Database port")
+	fs.StringVar(&c.Database.Name, "db-name", c.Database.Name, "Database name")
+	fs.StringVar(&c.Database.User, "db-user", c.Database.User, "Database user")
+	fs.StringVar(&c.Database.Password, "db-pass", c.Database.Password, "Database password")
+	fs.IntVar(&c.Database.MaxConnections, "db-max-conns", c.Database.MaxConnections, "Maximum database connections")
+
+	fs.StringVar(&c.Embeddings.Type, "embedding-type", c.Embeddings.Type, "Embedding type: python, http, openai, ollama or local")
+	fs.StringVar(&c.Embeddings.HTTPEndpoint, "embedding-url", c.Embeddings.HTTPEndpoint, "Embedding service URL (default depends on the embedding type)")
+
+	fs.StringVar(&c.Generation.APIKey, "anthropic-key", c.Generation.APIKey, "Anthropic API key")
+	fs.StringVar(&c.Generation.Model, "generator-model", c.Generation.Model, "Model used for agent generation")
+
+	fs.StringVar(&c.Server.Transport, "transport", c.Server.Transport, "Transport: stdio, sse or http")
+	fs.IntVar(&c.Server.Port, "port", c.Server.Port, "HTTP port")
+}
+
+// ApplyFlags copies flags explicitly set on parsed into c, so they take
+// precedence over file and environment values
+func (c *Config) ApplyFlags(parsed *flag.FlagSet) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	c.RegisterFlags(fs)
+
+	var err error
+	parsed.Visit(func(f *flag.Flag) {
+		if err == nil && fs.Lookup(f.Name) != nil {
+			err = fs.Set(f.Name, f.Value.String())
+		}
+	})
+	return err
+}
+
+// Validate reports every invalid value at once
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	oneOf := func(v string, allowed ...string) bool {
+		for _, a := range allowed {
+			if v == a {
+				return true
+			}
+		}
+		return false
+	}
+
+	check(oneOf(c.Database.Driver, "postgres", "memory"), "database.driver must be postgres or memory, got %q", c.Database.Driver)
+	if c.Database.Driver == "postgres" {
+		check(c.Database.Host != "", "database.host is required")
+		check(c.Database.Port > 0 && c.Database.Port < 65536, "database.port must be 1-65535, got %d", c.Database.Port)
+		check(c.Database.Name != "", "database.name is required")
+		check(c.Database.MaxConnections > 0, "database.max_connections must be positive, got %d", c.Database.MaxConnections)
+	}
+
+	check(oneOf(c.Embeddings.Type, "python", "http", "openai", "ollama", "local"),
+		"embeddings.type must be python, http, openai, ollama or local, got %q", c.Embeddings.Type)
+	check(c.Embeddings.BatchSize >= 0, "embeddings.batch_size must not be negative, got %d", c.Embeddings.BatchSize)
+	check(c.Embeddings.Dimension > 0, "embeddings.dimension must be positive, got %d", c.Embeddings.Dimension)
+	check(c.Embeddings.Timeout > 0, "embeddings.timeout must be positive")
+	check(c.Embeddings.CacheSize >= 0, "embeddings.cache_size must not be negative, got %d", c.Embeddings.CacheSize)
+
+	check(c.Generation.Provider == "anthropic", "generation.provider must be anthropic, got %q", c.Generation.Provider)
+	check(c.Generation.MaxTokens > 0, "generation.max_tokens must be positive, got %d", c.Generation.MaxTokens)
+	check(c.Generation.Timeout > 0, "generation.timeout must be positive")
+
+	check(c.Governance.AutoQuarantineThreshold > 0, "governance.auto_quarantine_threshold must be positive, got %d", c.Governance.AutoQuarantineThreshold)
+	check(c.Governance.ReputationBanThreshold >= 0 && c.Governance.ReputationBanThreshold <= 100,
+		"governance.reputation_ban_threshold must be between 0 and 100, got %v", c.Governance.ReputationBanThreshold)
+	check(c.Governance.BanCoolingOff >= 0, "governance.ban_cooling_off must not be negative")
+	check(c.Governance.MaxAppeals >= 0, "governance.max_appeals must not be negative, got %d", c.Governance.MaxAppeals)
+
+	check(oneOf(c.Server.Transport, "stdio", "sse", "http"), "server.transport must be stdio, sse or http, got %q", c.Server.Transport)
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port must be 1-65535, got %d", c.Server.Port)
+
+	check(c.Aliases.ReloadInterval >= 0, "aliases.reload_interval must not be negative")
+
+	if _, err := c.ForAuth(); err != nil {
+		problems = append(problems, "auth: "+err.Error())
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// ForAuth returns the caller directory and the principal stdio sessions act as
+func (c *Config) ForAuth() (*auth.Directory, error) {
+	entries := make([]auth.Entry, len(c.Auth.Principals))
+	for i, p := range c.Auth.Principals {
+		entries[i] = auth.Entry{Name: p.Name, Token: p.Token}
+		for _, role := range p.Roles {
+			entries[i].Roles = append(entries[i].Roles, models.GovernanceRole(role))
+		}
+	}
+	dir, err := auth.NewDirectory(entries)
+	if err != nil {
+		return nil, err
+	}
+	if c.Auth.StdioToken != "" {
+		if _, err := dir.Lookup(c.Auth.StdioToken); err != nil {
+			return nil, fmt.Errorf("stdio_token matches no principal")
+		}
+	}
+	return dir, nil
+}
+
+// ForDatabase returns the PostgreSQL connection settings
+func (c *Config) ForDatabase() database.Config {
+	return database.Config{
+		Host:     c.Database.Host,
+		Port:     c.Database.Port,
+		Database: c.Database.Name,
+		User:     c.Database.User,
+		Password: c.Database.Password,
+		MaxConns: c.Database.MaxConnections,
+	}
+}
+
+// ForEmbeddings returns the embedding engine settings
+func (c *Config) ForEmbeddings() embeddings.Config {
+	cfg := embeddings.DefaultConfig()
+	cfg.Type = c.Embeddings.Type
+	cfg.Model = c.Embeddings.Model
+	cfg.HTTPEndpoint = c.Embeddings.HTTPEndpoint
+	if cfg.HTTPEndpoint == "" && cfg.Type == "http" {
+		cfg.HTTPEndpoint = embeddings.DefaultHTTPEndpoint
+	}
+	cfg.APIKey = c.Embeddings.APIKey
+	cfg.AuthHeader = c.Embeddings.AuthHeader
+	cfg.BatchSize = c.Embeddings.BatchSize
+	cfg.Dimension = c.Embeddings.Dimension
+	cfg.Timeout = time.Duration(c.Embeddings.Timeout)
+	cfg.CacheSize = c.Embeddings.CacheSize
+	cfg.CacheDir = c.Embeddings.CacheDir
+	return cfg
+}
+
+// ForGenerator returns the agent generator settings
+func (c *Config) ForGenerator() generator.Config {
+	return generator.Config{
+		APIKey:    c.Generation.APIKey,
+		Model:     c.Generation.Model,
+		MaxTokens: c.Generation.MaxTokens,
+		Timeout:   time.Duration(c.Generation.Timeout),
+	}
+}
+
+// ForGovernance returns the governance engine settings
+func (c *Config) ForGovernance() governance.Config {
+	return governance.Config{
+		AutoQuarantineThreshold: c.Governance.AutoQuarantineThreshold,
+		ReputationBanThreshold:  c.Governance.ReputationBanThreshold,
+		BanCoolingOff:           time.Duration(c.Governance.BanCoolingOff),
+		MaxAppeals:              c.Governance.MaxAppeals,
+		Enabled:                 c.Governance.Enabled,
+	}
+}
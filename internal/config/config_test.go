@@ -0,0 +1,175 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.v2.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func envMap(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func TestExampleConfigIsValid(t *testing.T) {
+	cfg, err := load("../../config.v2.yaml.example", envMap(map[string]string{"DB_PASSWORD": "secret"}))
+	if err != nil {
+		t.Fatalf("load example failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("example config is invalid: %v", err)
+	}
+	if cfg.Database.Password != "secret" {
+		t.Errorf("password = %q, want interpolated secret", cfg.Database.Password)
+	}
+	if cfg.Embeddings.Timeout != Duration(30*time.Second) {
+		t.Errorf("embeddings timeout = %v, want 30s", cfg.Embeddings.Timeout)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	lookup := envMap(map[string]string{"HOST": "db.internal", "EMPTY": ""})
+	tests := []struct {
+		input, want string
+	}{
+		{"host: ${HOST}", "host: db.internal"},
+		{"host: ${MISSING}", "host: "},
+		{"host: ${MISSING:-localhost}", "host: localhost"},
+		{"host: ${EMPTY:-localhost}", "host: localhost"},
+		{"password: pa$$word", "password: pa$$word"},
+	}
+	for _, tt := range tests {
+		if got := string(interpolate([]byte(tt.input), lookup)); got != tt.want {
+			t.Errorf("interpolate(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+database:
+  host: file-host
+  port: 6543
+  max_connections: 5
+server:
+  transport: http
+`)
+
+	// env beats file
+	cfg, err := load(path, envMap(map[string]string{"DB_HOST": "env-host", "MCP_PORT": "9000"}))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	// flag beats env; unset flags do not clobber anything
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	Default().RegisterFlags(fs)
+	if err := fs.Parse([]string{"-port", "9100"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := cfg.ApplyFlags(fs); err != nil {
+		t.Fatalf("ApplyFlags failed: %v", err)
+	}
+
+	if cfg.Database.Host != "env-host" {
+		t.Errorf("host = %q, want env-host", cfg.Database.Host)
+	}
+	if cfg.Database.Port != 6543 || cfg.Database.MaxConnections != 5 {
+		t.Errorf("port/max_connections = %d/%d, want file values 6543/5", cfg.Database.Port, cfg.Database.MaxConnections)
+	}
+	if cfg.Server.Port != 9100 {
+		t.Errorf("server port = %d, want flag value 9100", cfg.Server.Port)
+	}
+	if cfg.Server.Transport != "http" {
+		t.Errorf("transport = %q, want file value http", cfg.Server.Transport)
+	}
+	if cfg.Database.Name != "mcp_serve" {
+		t.Errorf("name = %q, want default mcp_serve", cfg.Database.Name)
+	}
+	if got := cfg.ForDatabase().MaxConns; got != 5 {
+		t.Errorf("ForDatabase().MaxConns = %d, want 5", got)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := load(writeConfig(t, "database:\n  hots: typo\n"), envMap(nil)); err == nil {
+		t.Error("expected unknown field to be rejected")
+	}
+	if _, err := load(filepath.Join(t.TempDir(), "missing.yaml"), envMap(nil)); err == nil {
+		t.Error("expected an explicitly named missing file to fail")
+	}
+	if _, err := load("", envMap(map[string]string{"DB_PORT": "five"})); err == nil {
+		t.Error("expected a non-numeric DB_PORT to fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default config is invalid: %v", err)
+	}
+
+	cfg.Database.Driver = "sqlite"
+	cfg.Server.Port = 0
+	cfg.Embeddings.Dimension = 0
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, field := range []string{"database.driver", "server.port", "embeddings.dimension"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error does not mention %s: %v", field, err)
+		}
+	}
+}
+
+func TestForAuth(t *testing.T) {
+	path := writeConfig(t, `
+auth:
+  principals:
+    - name: cop
+      token: ${COP_TOKEN}
+      roles: [police]
+    - name: bench
+      token: bench-token
+      roles: [judge, executioner]
+`)
+	cfg, err := load(path, envMap(map[string]string{"COP_TOKEN": "cop-token", "MCP_TOKEN": "bench-token"}))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	dir, err := cfg.ForAuth()
+	if err != nil {
+		t.Fatalf("ForAuth failed: %v", err)
+	}
+	if p, err := dir.Lookup("cop-token"); err != nil || p.Name != "cop" || len(p.Roles) != 1 || p.Roles[0] != "police" {
+		t.Errorf("Lookup(cop-token) = %+v, %v; want cop with the police role", p, err)
+	}
+	if cfg.Auth.StdioToken != "bench-token" {
+		t.Errorf("stdio token = %q, want MCP_TOKEN", cfg.Auth.StdioToken)
+	}
+
+	cfg.Auth.StdioToken = "nobody"
+	cfg.Auth.Principals[0].Roles = []string{"sheriff"}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "auth:") {
+		t.Errorf("Validate() = %v, want an auth error", err)
+	}
+}
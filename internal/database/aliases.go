@@ -0,0 +1,61 @@
+// Package database provides PostgreSQL database operations
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+)
+
+// ============ Task Aliases ============
+
+// ListTaskAliases returns every alias override ordered by domain and keyword
+func (db *DB) ListTaskAliases(ctx context.Context) ([]models.TaskAlias, error) {
+	rows, err := db.pool.Query(ctx, `
+		SELECT domain, keyword, aliases, updated_by, updated_at
+		FROM task_aliases
+		ORDER BY domain, keyword
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := []models.TaskAlias{}
+	for rows.Next() {
+		var a models.TaskAlias
+		if err := rows.Scan(&a.Domain, &a.Keyword, &a.Aliases, &a.UpdatedBy, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, a)
+	}
+	return aliases, rows.Err()
+}
+
+// SetTaskAlias creates or replaces the override for alias's domain and keyword
+func (db *DB) SetTaskAlias(ctx context.Context, alias *models.TaskAlias) error {
+	alias.UpdatedAt = time.Now()
+	if alias.Aliases == nil {
+		alias.Aliases = []string{}
+	}
+
+	_, err := db.pool.Exec(ctx, `
+		INSERT INTO task_aliases (domain, keyword, aliases, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (domain, keyword) DO UPDATE SET
+			aliases = EXCLUDED.aliases, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, alias.Domain, alias.Keyword, alias.Aliases, alias.UpdatedBy, alias.UpdatedAt)
+	return err
+}
+
+// DeleteTaskAlias removes an override, reporting whether one existed
+func (db *DB) DeleteTaskAlias(ctx context.Context, domain, keyword string) (bool, error) {
+	tag, err := db.pool.Exec(ctx, `
+		DELETE FROM task_aliases WHERE domain = $1 AND keyword = $2
+	`, domain, keyword)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -4,6 +4,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -26,24 +27,36 @@ func (db *DB) CreateCommand(ctx context.Context, cmd *models.Command) error {
 	argumentsJSON, _ := json.Marshal(cmd.Arguments)
 	metadataJSON, _ := json.Marshal(cmd.Metadata)
 
-	_, err := db.pool.Exec(ctx, `
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
 		INSERT INTO commands (
 			id, name, version, description, prompt, arguments, metadata,
 			tags, category, embedding, reputation_score, status, is_system,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, embedding_model
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
 			$8, $9, $10, $11, $12, $13,
-			$14, $15, $16
+			$14, $15, $16, $17
 		)
 	`,
 		cmd.ID, cmd.Name, cmd.Version, cmd.Description, cmd.Prompt,
 		argumentsJSON, metadataJSON, cmd.Tags, cmd.Category, cmd.Embedding,
 		cmd.ReputationScore, cmd.Status, cmd.IsSystem,
-		cmd.CreatedBy, cmd.CreatedAt, cmd.UpdatedAt,
+		cmd.CreatedBy, cmd.CreatedAt, cmd.UpdatedAt, cmd.EmbeddingModel,
 	)
+	if err != nil {
+		return err
+	}
+	if err := insertCommandRevision(ctx, tx, cmd); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
 
-	return err
+	return tx.Commit(ctx)
 }
 
 // GetCommand retrieves a command by name
@@ -54,14 +67,15 @@ func (db *DB) GetCommand(ctx context.Context, name string) (*models.Command, err
 	err := db.pool.QueryRow(ctx, `
 		SELECT id, name, version, description, prompt, arguments, metadata,
 			   tags, category, embedding, reputation_score, usage_count, feedback_count,
-			   avg_rating, status, is_system, created_by, created_at, updated_at
+			   avg_rating, status, is_system, created_by, created_at, updated_at,
+			   embedding_model
 		FROM commands WHERE name = $1
 	`, name).Scan(
 		&cmd.ID, &cmd.Name, &cmd.Version, &cmd.Description, &cmd.Prompt,
 		&argumentsJSON, &metadataJSON, &cmd.Tags, &cmd.Category, &cmd.Embedding,
 		&cmd.ReputationScore, &cmd.UsageCount, &cmd.FeedbackCount,
 		&cmd.AvgRating, &cmd.Status, &cmd.IsSystem,
-		&cmd.CreatedBy, &cmd.CreatedAt, &cmd.UpdatedAt,
+		&cmd.CreatedBy, &cmd.CreatedAt, &cmd.UpdatedAt, &cmd.EmbeddingModel,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -84,14 +98,15 @@ func (db *DB) GetCommandByID(ctx context.Context, id uuid.UUID) (*models.Command
 	err := db.pool.QueryRow(ctx, `
 		SELECT id, name, version, description, prompt, arguments, metadata,
 			   tags, category, embedding, reputation_score, usage_count, feedback_count,
-			   avg_rating, status, is_system, created_by, created_at, updated_at
+			   avg_rating, status, is_system, created_by, created_at, updated_at,
+			   embedding_model
 		FROM commands WHERE id = $1
 	`, id).Scan(
 		&cmd.ID, &cmd.Name, &cmd.Version, &cmd.Description, &cmd.Prompt,
 		&argumentsJSON, &metadataJSON, &cmd.Tags, &cmd.Category, &cmd.Embedding,
 		&cmd.ReputationScore, &cmd.UsageCount, &cmd.FeedbackCount,
 		&cmd.AvgRating, &cmd.Status, &cmd.IsSystem,
-		&cmd.CreatedBy, &cmd.CreatedAt, &cmd.UpdatedAt,
+		&cmd.CreatedBy, &cmd.CreatedAt, &cmd.UpdatedAt, &cmd.EmbeddingModel,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -152,6 +167,54 @@ func (db *DB) ListCommands(ctx context.Context, category string, tags []string)
 	return commands, nil
 }
 
+// ListCommandsPage returns one page of commands matching opts.Filter in the requested order
+func (db *DB) ListCommandsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.CommandSummary], error) {
+	p, err := planList("commands", catalogSorts, opts)
+	if err != nil {
+		return models.Page[models.CommandSummary]{}, err
+	}
+	w := &whereBuilder{}
+	if err := catalogWhere(w, models.EmbeddingKindCommand, opts.Filter); err != nil {
+		return models.Page[models.CommandSummary]{}, err
+	}
+	p.afterSQL(w)
+
+	rows, err := db.pool.Query(ctx, fmt.Sprintf(`
+		SELECT id, name, version, description, category, tags, arguments, reputation_score, avg_rating, usage_count, status,
+			   created_at, updated_at
+		FROM commands
+		WHERE %s
+		ORDER BY %s
+		LIMIT %d
+	`, w.sql(), p.orderSQL(), p.limit+1), w.args...)
+	if err != nil {
+		return models.Page[models.CommandSummary]{}, err
+	}
+	defer rows.Close()
+
+	var commands []models.CommandSummary
+	var keys []pageKey
+	for rows.Next() {
+		var c models.CommandSummary
+		var argumentsJSON []byte
+		var k pageKey
+		err := rows.Scan(&c.ID, &c.Name, &c.Version, &c.Description, &c.Category,
+			&c.Tags, &argumentsJSON, &c.ReputationScore, &c.AvgRating, &c.UsageCount, &c.Status, &k.Created, &k.Updated)
+		if err != nil {
+			return models.Page[models.CommandSummary]{}, err
+		}
+		json.Unmarshal(argumentsJSON, &c.Arguments)
+		k.ID, k.Name, k.Reputation, k.Rating, k.Usage = c.ID, c.Name, c.ReputationScore, c.AvgRating, c.UsageCount
+		commands = append(commands, c)
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.CommandSummary]{}, err
+	}
+
+	return page(p, commands, keys), nil
+}
+
 // SearchCommands searches commands by keyword
 func (db *DB) SearchCommands(ctx context.Context, query string) ([]models.CommandSummary, error) {
 	escaped := escapeLikePattern(strings.ToLower(query))
@@ -224,6 +287,40 @@ func (db *DB) FindSimilarCommands(ctx context.Context, embedding pgvector.Vector
 	return results, nil
 }
 
+// UpdateCommand saves content changes to a command and appends cmd.Version to its history
+func (db *DB) UpdateCommand(ctx context.Context, cmd *models.Command) error {
+	argumentsJSON, _ := json.Marshal(cmd.Arguments)
+	metadataJSON, _ := json.Marshal(cmd.Metadata)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE commands SET
+			version = $1, description = $2, prompt = $3, arguments = $4, metadata = $5,
+			tags = $6, category = $7, embedding = $8, embedding_model = $9
+		WHERE id = $10
+		RETURNING updated_at
+	`,
+		cmd.Version, cmd.Description, cmd.Prompt, argumentsJSON, metadataJSON,
+		cmd.Tags, cmd.Category, cmd.Embedding, cmd.EmbeddingModel, cmd.ID,
+	).Scan(&cmd.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := insertCommandRevision(ctx, tx, cmd); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
 // IncrementCommandUsage increments the usage count
 func (db *DB) IncrementCommandUsage(ctx context.Context, commandID uuid.UUID) error {
 	_, err := db.pool.Exec(ctx, `
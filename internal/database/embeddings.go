@@ -0,0 +1,122 @@
+// Package database provides PostgreSQL database operations
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/pgvector/pgvector-go"
+)
+
+// ============ Embedding Maintenance ============
+// Every row records the model that produced its embedding, so re-indexing can
+// find rows embedded by another model (or not at all) and resume where it
+// stopped: a row drops out of the stale set as soon as it is rewritten.
+
+// embeddingTables maps each kind to its table and the column holding the
+// content that is embedded alongside name and description
+var embeddingTables = map[models.EmbeddingKind]struct{ table, content string }{
+	models.EmbeddingKindAgent:   {"agents", "''"},
+	models.EmbeddingKindSkill:   {"skills", "content"},
+	models.EmbeddingKindCommand: {"commands", "prompt"},
+}
+
+func embeddingTable(kind models.EmbeddingKind) (string, string, error) {
+	t, ok := embeddingTables[kind]
+	if !ok {
+		return "", "", fmt.Errorf("unknown embedding kind: %s", kind)
+	}
+	return t.table, t.content, nil
+}
+
+// EmbeddingDimension returns the declared dimension of a kind's embedding
+// column, or 0 if the column is unconstrained
+func (db *DB) EmbeddingDimension(ctx context.Context, kind models.EmbeddingKind) (int, error) {
+	table, _, err := embeddingTable(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	// pgvector stores the dimension as the column's type modifier
+	var typmod int
+	err = db.pool.QueryRow(ctx, `
+		SELECT atttypmod FROM pg_attribute
+		WHERE attrelid = $1::regclass AND attname = 'embedding' AND NOT attisdropped
+	`, table).Scan(&typmod)
+	if err != nil {
+		return 0, err
+	}
+	if typmod < 0 {
+		return 0, nil
+	}
+	return typmod, nil
+}
+
+// CountEmbeddings counts a kind's rows and those embedded with model
+func (db *DB) CountEmbeddings(ctx context.Context, kind models.EmbeddingKind, model string) (models.EmbeddingCounts, error) {
+	var counts models.EmbeddingCounts
+	table, _, err := embeddingTable(kind)
+	if err != nil {
+		return counts, err
+	}
+
+	err = db.pool.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COUNT(*),
+			   COUNT(*) FILTER (WHERE embedding IS NOT NULL AND embedding_model = $1)
+		FROM %s
+	`, table), model).Scan(&counts.Total, &counts.Current)
+	return counts, err
+}
+
+// ListStaleEmbeddings returns up to limit rows of a kind that have no
+// embedding or were embedded with a model other than model, in id order
+func (db *DB) ListStaleEmbeddings(ctx context.Context, kind models.EmbeddingKind, model string, limit int) ([]models.EmbeddingSource, error) {
+	table, content, err := embeddingTable(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	skills := "NULL::text[]"
+	if kind == models.EmbeddingKindAgent {
+		skills = "skills"
+	}
+
+	rows, err := db.pool.Query(ctx, fmt.Sprintf(`
+		SELECT id, name, description, %s, %s, updated_at
+		FROM %s
+		WHERE embedding IS NULL OR embedding_model <> $1
+		ORDER BY id
+		LIMIT $2
+	`, content, skills, table), model, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := []models.EmbeddingSource{}
+	for rows.Next() {
+		src := models.EmbeddingSource{Kind: kind}
+		if err := rows.Scan(&src.ID, &src.Name, &src.Description, &src.Content, &src.Skills, &src.UpdatedAt); err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	return sources, rows.Err()
+}
+
+// SetEmbedding stores an embedding and its model for the row src was read
+// from. Rows changed since then are left alone; they are picked up again on
+// the next pass if still stale.
+func (db *DB) SetEmbedding(ctx context.Context, src models.EmbeddingSource, embedding pgvector.Vector, model string) error {
+	table, _, err := embeddingTable(src.Kind)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.pool.Exec(ctx, fmt.Sprintf(`
+		UPDATE %s SET embedding = $1, embedding_model = $2
+		WHERE id = $3 AND updated_at = $4
+	`, table), embedding, model, src.ID, src.UpdatedAt)
+	return err
+}
@@ -0,0 +1,2156 @@
+// Package database provides PostgreSQL database operations
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aminghadersohi/agentmcp/internal/embeddings"
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/aminghadersohi/agentmcp/internal/trigram"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+)
+
+// MemoryStore is an embedded, in-process Store backend.
+// It mirrors the PostgreSQL semantics closely enough to run the full v2 tool
+// set in development and CI without a database server. Data is not persisted.
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	agents      map[uuid.UUID]*models.Agent
+	agentNames  map[string]uuid.UUID
+	feedback    []models.Feedback
+	reports     []*models.Report
+	actions     []models.GovernanceAction
+	appeals     []*models.Appeal
+	skillCache  map[string]*skillRequest
+	skills      map[uuid.UUID]*models.Skill
+	skillNames  map[string]uuid.UUID
+	skillFb     []models.SkillFeedback
+	commands    map[uuid.UUID]*models.Command
+	commandName map[string]uuid.UUID
+	commandFb   []models.CommandFeedback
+
+	// Append-only revision history, oldest first
+	agentRevs   map[uuid.UUID][]*models.AgentRevision
+	skillRevs   map[uuid.UUID][]*models.SkillRevision
+	commandRevs map[uuid.UUID][]*models.CommandRevision
+
+	taskAliases map[taskAliasKey]models.TaskAlias
+}
+
+// taskAliasKey identifies a task alias override
+type taskAliasKey struct{ domain, keyword string }
+
+// skillRequest is a row of the skill request cache
+type skillRequest struct {
+	skills        []string
+	agentID       uuid.UUID
+	requestCount  int
+	lastRequested time.Time
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		agents:      make(map[uuid.UUID]*models.Agent),
+		agentNames:  make(map[string]uuid.UUID),
+		skillCache:  make(map[string]*skillRequest),
+		skills:      make(map[uuid.UUID]*models.Skill),
+		skillNames:  make(map[string]uuid.UUID),
+		commands:    make(map[uuid.UUID]*models.Command),
+		commandName: make(map[string]uuid.UUID),
+		agentRevs:   make(map[uuid.UUID][]*models.AgentRevision),
+		skillRevs:   make(map[uuid.UUID][]*models.SkillRevision),
+		commandRevs: make(map[uuid.UUID][]*models.CommandRevision),
+		taskAliases: make(map[taskAliasKey]models.TaskAlias),
+	}
+}
+
+// Close is a no-op for the in-process store
+func (m *MemoryStore) Close() {}
+
+// ============ Helpers ============
+
+// cloneStrings copies a string slice so callers cannot mutate stored data
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
+
+// cloneMetadata makes a shallow copy of a metadata map
+func cloneMetadata(md map[string]any) map[string]any {
+	if md == nil {
+		return nil
+	}
+	out := make(map[string]any, len(md))
+	for k, v := range md {
+		out[k] = v
+	}
+	return out
+}
+
+// cloneVector copies an optional embedding
+func cloneVector(v *pgvector.Vector) *pgvector.Vector {
+	if v == nil {
+		return nil
+	}
+	c := pgvector.NewVector(append([]float32{}, v.Slice()...))
+	return &c
+}
+
+func cloneAgent(a *models.Agent) *models.Agent {
+	c := *a
+	c.Tools = cloneStrings(a.Tools)
+	c.Skills = cloneStrings(a.Skills)
+	c.Metadata = cloneMetadata(a.Metadata)
+	c.Embedding = cloneVector(a.Embedding)
+	return &c
+}
+
+func cloneSkill(s *models.Skill) *models.Skill {
+	c := *s
+	c.Tags = cloneStrings(s.Tags)
+	c.Examples = append([]models.Example(nil), s.Examples...)
+	c.Metadata = cloneMetadata(s.Metadata)
+	c.Embedding = cloneVector(s.Embedding)
+	return &c
+}
+
+func cloneCommand(cmd *models.Command) *models.Command {
+	c := *cmd
+	c.Tags = cloneStrings(cmd.Tags)
+	c.Arguments = append([]models.Argument(nil), cmd.Arguments...)
+	c.Metadata = cloneMetadata(cmd.Metadata)
+	c.Embedding = cloneVector(cmd.Embedding)
+	return &c
+}
+
+// metadataTags extracts metadata["tags"] whether stored as []string or decoded JSON
+func metadataTags(md map[string]any) []string {
+	switch tags := md["tags"].(type) {
+	case []string:
+		return tags
+	case []any:
+		out := make([]string, 0, len(tags))
+		for _, t := range tags {
+			if s, ok := t.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// containsFold reports whether s contains the already-lowercased substring q
+func containsFold(s, q string) bool {
+	return strings.Contains(strings.ToLower(s), q)
+}
+
+// overlaps reports whether any element of a is present in b (SQL &&)
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contains reports whether v is an element of list (SQL = ANY)
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ============ Agent Operations ============
+
+// CreateAgent inserts a new agent
+func (m *MemoryStore) CreateAgent(ctx context.Context, agent *models.Agent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.agentNames[agent.Name]; exists {
+		return fmt.Errorf("agent %q already exists", agent.Name)
+	}
+	if agent.ID == uuid.Nil {
+		agent.ID = uuid.New()
+	}
+	agent.CreatedAt = time.Now()
+	agent.UpdatedAt = time.Now()
+	agent.Revision = 1
+
+	m.agents[agent.ID] = cloneAgent(agent)
+	m.agentNames[agent.Name] = agent.ID
+	m.recordAgentRevision(agent)
+	return nil
+}
+
+// GetAgent retrieves an agent by name
+func (m *MemoryStore) GetAgent(ctx context.Context, name string) (*models.Agent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, ok := m.agentNames[name]
+	if !ok {
+		return nil, nil
+	}
+	return cloneAgent(m.agents[id]), nil
+}
+
+// GetAgentByID retrieves an agent by ID
+func (m *MemoryStore) GetAgentByID(ctx context.Context, id uuid.UUID) (*models.Agent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	agent, ok := m.agents[id]
+	if !ok {
+		return nil, nil
+	}
+	return cloneAgent(agent), nil
+}
+
+// activeAgents returns active agents matching filter, ordered like the SQL queries
+func (m *MemoryStore) activeAgents(filter func(*models.Agent) bool) []*models.Agent {
+	var out []*models.Agent
+	for _, a := range m.agents {
+		if a.Status != models.StatusActive {
+			continue
+		}
+		if filter != nil && !filter(a) {
+			continue
+		}
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ReputationScore != out[j].ReputationScore {
+			return out[i].ReputationScore > out[j].ReputationScore
+		}
+		if out[i].UsageCount != out[j].UsageCount {
+			return out[i].UsageCount > out[j].UsageCount
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func agentSummaries(agents []*models.Agent) []models.AgentSummary {
+	summaries := []models.AgentSummary{}
+	for _, a := range agents {
+		s := a.ToSummary()
+		s.Skills = cloneStrings(s.Skills)
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+// ListAgents returns all active agents
+func (m *MemoryStore) ListAgents(ctx context.Context, tags []string) ([]models.AgentSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return agentSummaries(m.activeAgents(func(a *models.Agent) bool {
+		if len(tags) == 0 {
+			return true
+		}
+		return overlaps(a.Skills, tags) || overlaps(metadataTags(a.Metadata), tags)
+	})), nil
+}
+
+// ListAgentsPage returns one page of agents matching opts.Filter in the requested order
+func (m *MemoryStore) ListAgentsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.AgentSummary], error) {
+	p, err := planList("agents", catalogSorts, opts)
+	if err != nil {
+		return models.Page[models.AgentSummary]{}, err
+	}
+	if err := catalogFilter(models.EmbeddingKindAgent, opts.Filter); err != nil {
+		return models.Page[models.AgentSummary]{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var agents []*models.Agent
+	var keys []pageKey
+	for _, a := range m.agents {
+		if agentMatches(a, opts.Filter) {
+			agents = append(agents, a)
+			keys = append(keys, agentKey(a))
+		}
+	}
+	result := sortedPage(p, agents, keys)
+	return models.Page[models.AgentSummary]{Items: agentSummaries(result.Items), NextCursor: result.NextCursor}, nil
+}
+
+// SearchAgents searches agents by keyword
+func (m *MemoryStore) SearchAgents(ctx context.Context, query string) ([]models.AgentSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	q := strings.ToLower(query)
+	return agentSummaries(m.activeAgents(func(a *models.Agent) bool {
+		if containsFold(a.Name, q) || containsFold(a.Description, q) || contains(a.Skills, q) {
+			return true
+		}
+		for _, tag := range metadataTags(a.Metadata) {
+			if containsFold(tag, q) {
+				return true
+			}
+		}
+		return false
+	})), nil
+}
+
+// FindSimilarAgents finds agents by embedding similarity
+func (m *MemoryStore) FindSimilarAgents(ctx context.Context, embedding pgvector.Vector, limit int, threshold float64) ([]models.SimilarAgent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := []models.SimilarAgent{}
+	for _, a := range m.agents {
+		if a.Status != models.StatusActive || a.Embedding == nil {
+			continue
+		}
+		results = append(results, models.SimilarAgent{
+			Agent:      agentSummaries([]*models.Agent{a})[0],
+			Similarity: embeddings.CosineSimilarity(*a.Embedding, embedding),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Similarity > results[j].Similarity })
+
+	// Match SQL semantics: LIMIT first, then apply the threshold
+	if limit >= 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	filtered := results[:0]
+	for _, r := range results {
+		if r.Similarity >= threshold {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
+
+// UpdateAgent saves content changes to an agent if its revision still matches
+// expectedRevision. On success agent.Revision and agent.UpdatedAt are refreshed.
+func (m *MemoryStore) UpdateAgent(ctx context.Context, agent *models.Agent, expectedRevision int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.agents[agent.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Revision != expectedRevision {
+		return fmt.Errorf("%w: current revision is %d", ErrRevisionConflict, stored.Revision)
+	}
+	if m.hasAgentVersion(agent.ID, agent.Version) {
+		return fmt.Errorf("agent %q already has version %s", stored.Name, agent.Version)
+	}
+	if agent.Name != stored.Name {
+		if _, taken := m.agentNames[agent.Name]; taken {
+			return fmt.Errorf("agent %q already exists", agent.Name)
+		}
+		delete(m.agentNames, stored.Name)
+		m.agentNames[agent.Name] = agent.ID
+	}
+
+	agent.Revision = stored.Revision + 1
+	agent.UpdatedAt = time.Now()
+
+	updated := cloneAgent(stored)
+	updated.Name = agent.Name
+	updated.Version = agent.Version
+	updated.Description = agent.Description
+	updated.Model = agent.Model
+	updated.Tools = cloneStrings(agent.Tools)
+	updated.Metadata = cloneMetadata(agent.Metadata)
+	updated.Prompt = agent.Prompt
+	updated.Embedding = cloneVector(agent.Embedding)
+	updated.EmbeddingModel = agent.EmbeddingModel
+	updated.Skills = cloneStrings(agent.Skills)
+	updated.Revision = agent.Revision
+	updated.UpdatedAt = agent.UpdatedAt
+	m.agents[agent.ID] = updated
+	m.recordAgentRevision(updated)
+	return nil
+}
+
+// ArchiveAgent soft-deletes an agent if its revision still matches expectedRevision
+func (m *MemoryStore) ArchiveAgent(ctx context.Context, agentID uuid.UUID, expectedRevision int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.agents[agentID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Revision != expectedRevision {
+		return fmt.Errorf("%w: current revision is %d", ErrRevisionConflict, stored.Revision)
+	}
+
+	stored.Status = models.StatusArchived
+	stored.Revision++
+	stored.UpdatedAt = time.Now()
+
+	// Cached skill lookups must not resolve to an archived agent
+	for hash, entry := range m.skillCache {
+		if entry.agentID == agentID {
+			delete(m.skillCache, hash)
+		}
+	}
+	return nil
+}
+
+// UpdateAgentStatus updates an agent's status
+func (m *MemoryStore) UpdateAgentStatus(ctx context.Context, agentID uuid.UUID, status models.AgentStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if a, ok := m.agents[agentID]; ok {
+		a.Status = status
+		a.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// UpdateAgentReputation updates reputation metrics
+func (m *MemoryStore) UpdateAgentReputation(ctx context.Context, agentID uuid.UUID, score float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if a, ok := m.agents[agentID]; ok {
+		a.ReputationScore = score
+		a.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// IncrementUsage increments the usage count
+func (m *MemoryStore) IncrementUsage(ctx context.Context, agentID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if a, ok := m.agents[agentID]; ok {
+		a.UsageCount++
+		a.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// GetTopAgents returns the highest-rated agents
+func (m *MemoryStore) GetTopAgents(ctx context.Context, limit int, category string) ([]models.AgentSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	agents := m.activeAgents(func(a *models.Agent) bool {
+		return category == "" || contains(a.Skills, category) || contains(metadataTags(a.Metadata), category)
+	})
+	sort.SliceStable(agents, func(i, j int) bool {
+		if agents[i].ReputationScore != agents[j].ReputationScore {
+			return agents[i].ReputationScore > agents[j].ReputationScore
+		}
+		return agents[i].AvgRating > agents[j].AvgRating
+	})
+	if limit >= 0 && len(agents) > limit {
+		agents = agents[:limit]
+	}
+	return agentSummaries(agents), nil
+}
+
+// ============ Skill Request Cache ============
+
+// GetCachedAgentBySkills checks if we have a cached agent for these skills
+func (m *MemoryStore) GetCachedAgentBySkills(ctx context.Context, skills []string) (*models.Agent, error) {
+	m.mu.Lock()
+	entry, ok := m.skillCache[HashSkills(skills)]
+	if !ok {
+		m.mu.Unlock()
+		return nil, nil
+	}
+	entry.requestCount++
+	entry.lastRequested = time.Now()
+	agentID := entry.agentID
+	m.mu.Unlock()
+
+	return m.GetAgentByID(ctx, agentID)
+}
+
+// CacheSkillRequest caches a skill->agent mapping
+func (m *MemoryStore) CacheSkillRequest(ctx context.Context, skills []string, agentID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := HashSkills(skills)
+	if entry, ok := m.skillCache[hash]; ok {
+		entry.requestCount++
+		entry.lastRequested = time.Now()
+		return nil
+	}
+
+	normalized := make([]string, len(skills))
+	for i, s := range skills {
+		normalized[i] = strings.ToLower(strings.TrimSpace(s))
+	}
+	m.skillCache[hash] = &skillRequest{
+		skills:        normalized,
+		agentID:       agentID,
+		requestCount:  1,
+		lastRequested: time.Now(),
+	}
+	return nil
+}
+
+// ============ Feedback Operations ============
+
+// SubmitFeedback records feedback for an agent
+func (m *MemoryStore) SubmitFeedback(ctx context.Context, feedback *models.Feedback) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, ok := m.agents[feedback.AgentID]
+	if !ok {
+		return fmt.Errorf("agent not found: %s", feedback.AgentID)
+	}
+
+	feedback.ID = uuid.New()
+	feedback.CreatedAt = time.Now()
+	m.feedback = append(m.feedback, *feedback)
+
+	// Update agent statistics
+	var sum, count int
+	for _, f := range m.feedback {
+		if f.AgentID == agent.ID {
+			sum += f.Rating
+			count++
+		}
+	}
+	agent.FeedbackCount++
+	agent.AvgRating = float64(sum) / float64(count)
+	agent.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetAgentReputation calculates reputation details
+func (m *MemoryStore) GetAgentReputation(ctx context.Context, agentID uuid.UUID) (*models.AgentReputation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	agent, ok := m.agents[agentID]
+	if !ok {
+		return nil, nil
+	}
+
+	rep := &models.AgentReputation{
+		AgentID:            agent.ID,
+		AgentName:          agent.Name,
+		ReputationScore:    agent.ReputationScore,
+		UsageCount:         agent.UsageCount,
+		FeedbackCount:      agent.FeedbackCount,
+		AvgRating:          agent.AvgRating,
+		RatingDistribution: make(map[int]int),
+		TaskTypeBreakdown:  make(map[string]int),
+	}
+
+	successCount := 0
+	for _, f := range m.feedback {
+		if f.AgentID != agentID {
+			continue
+		}
+		rep.RatingDistribution[f.Rating]++
+		if f.TaskSuccess {
+			successCount++
+		}
+		if f.TaskType != "" {
+			rep.TaskTypeBreakdown[f.TaskType]++
+		}
+	}
+	if rep.FeedbackCount > 0 {
+		rep.SuccessRate = float64(successCount) / float64(rep.FeedbackCount)
+	}
+
+	return rep, nil
+}
+
+// ============ Governance Operations ============
+
+// CreateReport creates a new report
+func (m *MemoryStore) CreateReport(ctx context.Context, report *models.Report) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.agents[report.AgentID]; !ok {
+		return fmt.Errorf("agent not found: %s", report.AgentID)
+	}
+
+	report.ID = uuid.New()
+	report.Status = models.ReportStatusPending
+	report.CreatedAt = time.Now()
+
+	stored := *report
+	m.reports = append(m.reports, &stored)
+	return nil
+}
+
+// severityRank orders reports the same way as the SQL CASE expression
+func severityRank(s models.Severity) int {
+	switch s {
+	case models.SeverityCritical:
+		return 1
+	case models.SeverityHigh:
+		return 2
+	case models.SeverityMedium:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// GetPendingReports returns reports awaiting review
+func (m *MemoryStore) GetPendingReports(ctx context.Context) ([]models.Report, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	reports := []models.Report{}
+	for _, r := range m.reports {
+		if r.Status != models.ReportStatusPending && r.Status != models.ReportStatusReviewing {
+			continue
+		}
+		agent, ok := m.agents[r.AgentID]
+		if !ok {
+			continue
+		}
+		c := *r
+		c.AgentName = agent.Name
+		reports = append(reports, c)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		ri, rj := severityRank(reports[i].Severity), severityRank(reports[j].Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		return reports[i].CreatedAt.Before(reports[j].CreatedAt)
+	})
+	return reports, nil
+}
+
+// ListReports returns one page of reports matching opts.Filter in the requested order.
+// With no status filter it lists reports awaiting review.
+func (m *MemoryStore) ListReports(ctx context.Context, opts models.ListOptions) (models.Page[models.Report], error) {
+	p, err := planList("reports", reportSorts, opts)
+	if err != nil {
+		return models.Page[models.Report]{}, err
+	}
+	if err := checkFilter("reports", opts.Filter, "tags", "category", "model", "is_generated"); err != nil {
+		return models.Page[models.Report]{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var reports []models.Report
+	var keys []pageKey
+	for _, r := range m.reports {
+		agent, ok := m.agents[r.AgentID]
+		if !ok || !reportMatches(r, opts.Filter) {
+			continue
+		}
+		c := *r
+		c.AgentName = agent.Name
+		reports = append(reports, c)
+		keys = append(keys, reportKey(r))
+	}
+	return sortedPage(p, reports, keys), nil
+}
+
+// findReport returns the stored report with the given ID
+func (m *MemoryStore) findReport(id uuid.UUID) *models.Report {
+	for _, r := range m.reports {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// GetReport retrieves a report by ID
+func (m *MemoryStore) GetReport(ctx context.Context, reportID uuid.UUID) (*models.Report, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	r := m.findReport(reportID)
+	if r == nil {
+		return nil, nil
+	}
+	c := *r
+	if agent, ok := m.agents[r.AgentID]; ok {
+		c.AgentName = agent.Name
+	}
+	return &c, nil
+}
+
+// reportConflict explains why r cannot be claimed or resolved by reviewer
+func reportConflict(r *models.Report, reviewer string) error {
+	switch {
+	case r.Status == models.ReportStatusResolved:
+		return fmt.Errorf("%w: report is already resolved", ErrReportConflict)
+	case r.Status == models.ReportStatusReviewing && r.ReviewedBy != nil && *r.ReviewedBy != reviewer:
+		return fmt.Errorf("%w: report is claimed by %s", ErrReportConflict, *r.ReviewedBy)
+	case r.Status == models.ReportStatusPending:
+		return fmt.Errorf("%w: report has not been claimed", ErrReportConflict)
+	}
+	return nil
+}
+
+// ClaimReport moves a pending report to reviewing by reviewer
+func (m *MemoryStore) ClaimReport(ctx context.Context, reportID uuid.UUID, reviewer string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.findReport(reportID)
+	if r == nil {
+		return fmt.Errorf("report %s: %w", reportID, ErrNotFound)
+	}
+	if r.Status != models.ReportStatusPending {
+		if err := reportConflict(r, reviewer); err != nil {
+			return err
+		}
+	}
+	r.Status = models.ReportStatusReviewing
+	r.ReviewedBy = &reviewer
+	return nil
+}
+
+// UpdateReportStatus updates a report's status
+func (m *MemoryStore) UpdateReportStatus(ctx context.Context, reportID uuid.UUID, status models.ReportStatus, reviewedBy string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r := m.findReport(reportID); r != nil {
+		r.Status = status
+		r.ReviewedBy = &reviewedBy
+	}
+	return nil
+}
+
+// ResolveReport resolves a report
+func (m *MemoryStore) ResolveReport(ctx context.Context, reportID uuid.UUID, resolution models.Resolution, note string, resolvedBy string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.findReport(reportID)
+	if r == nil {
+		return fmt.Errorf("report %s: %w", reportID, ErrNotFound)
+	}
+	if err := reportConflict(r, resolvedBy); err != nil {
+		return err
+	}
+	now := time.Now()
+	r.Status = models.ReportStatusResolved
+	r.Resolution = &resolution
+	r.ResolutionNote = &note
+	r.ReviewedBy = &resolvedBy
+	r.ResolvedAt = &now
+	return nil
+}
+
+// RecordGovernanceAction records an action taken on an agent
+func (m *MemoryStore) RecordGovernanceAction(ctx context.Context, action *models.GovernanceAction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	action.ID = uuid.New()
+	action.CreatedAt = time.Now()
+	m.actions = append(m.actions, *action)
+	return nil
+}
+
+// ApplyGovernanceAction records action and changes its agent's status together
+func (m *MemoryStore) ApplyGovernanceAction(ctx context.Context, action *models.GovernanceAction, status models.AgentStatus) error {
+	if action.PreviousStatus == nil {
+		return fmt.Errorf("action has no previous status")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.applyAction(action, status)
+}
+
+// applyAction records action and, unless status is empty, moves its agent
+// from action.PreviousStatus to status. Nothing changes if it fails. Callers
+// hold m.mu.
+func (m *MemoryStore) applyAction(action *models.GovernanceAction, status models.AgentStatus) error {
+	if status != "" {
+		if action.PreviousStatus == nil {
+			return fmt.Errorf("action has no previous status")
+		}
+		a, ok := m.agents[action.AgentID]
+		if !ok {
+			return ErrNotFound
+		}
+		if a.Status != *action.PreviousStatus {
+			return fmt.Errorf("%w: agent status is %s", ErrRevisionConflict, a.Status)
+		}
+		a.Status = status
+		a.UpdatedAt = time.Now()
+	}
+
+	action.ID = uuid.New()
+	action.CreatedAt = time.Now()
+	m.actions = append(m.actions, *action)
+	return nil
+}
+
+// ApplyReputationAction records action and changes its agent's reputation
+// together
+func (m *MemoryStore) ApplyReputationAction(ctx context.Context, action *models.GovernanceAction, score float64) error {
+	if action.PreviousReputation == nil {
+		return fmt.Errorf("action has no previous reputation")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, ok := m.agents[action.AgentID]
+	if !ok {
+		return ErrNotFound
+	}
+	if a.ReputationScore != *action.PreviousReputation {
+		return fmt.Errorf("%w: agent reputation is %v", ErrRevisionConflict, a.ReputationScore)
+	}
+
+	a.ReputationScore = score
+	a.UpdatedAt = time.Now()
+	action.ID = uuid.New()
+	action.CreatedAt = time.Now()
+	m.actions = append(m.actions, *action)
+	return nil
+}
+
+// RuleOnReport resolves a report and records the ruling's action together
+func (m *MemoryStore) RuleOnReport(ctx context.Context, reportID uuid.UUID, resolution models.Resolution, note string, resolvedBy string, action *models.GovernanceAction, status models.AgentStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.findReport(reportID)
+	if r == nil {
+		return fmt.Errorf("report %s: %w", reportID, ErrNotFound)
+	}
+	if err := reportConflict(r, resolvedBy); err != nil {
+		return err
+	}
+	if action != nil {
+		if err := m.applyAction(action, status); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	r.Status = models.ReportStatusResolved
+	r.Resolution = &resolution
+	r.ResolutionNote = &note
+	r.ReviewedBy = &resolvedBy
+	r.ResolvedAt = &now
+	return nil
+}
+
+// GetGovernanceAction returns an action by ID
+func (m *MemoryStore) GetGovernanceAction(ctx context.Context, actionID uuid.UUID) (*models.GovernanceAction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, a := range m.actions {
+		if a.ID == actionID {
+			if agent, ok := m.agents[a.AgentID]; ok {
+				a.AgentName = agent.Name
+			}
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListGovernanceActions returns the actions taken on an agent, oldest first
+func (m *MemoryStore) ListGovernanceActions(ctx context.Context, agentID uuid.UUID) ([]models.GovernanceAction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []models.GovernanceAction
+	for _, a := range m.actions {
+		if a.AgentID == agentID {
+			if agent, ok := m.agents[a.AgentID]; ok {
+				a.AgentName = agent.Name
+			}
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// GetGovernanceStats returns governance statistics
+func (m *MemoryStore) GetGovernanceStats(ctx context.Context) (*models.GovernanceStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &models.GovernanceStats{}
+	for _, r := range m.reports {
+		switch r.Status {
+		case models.ReportStatusPending:
+			stats.PendingReports++
+		case models.ReportStatusReviewing:
+			stats.ReviewingReports++
+		}
+	}
+	for _, a := range m.appeals {
+		if a.Status == models.AppealStatusPending {
+			stats.PendingAppeals++
+		}
+	}
+	for _, a := range m.agents {
+		switch a.Status {
+		case models.StatusQuarantined:
+			stats.QuarantinedAgents++
+		case models.StatusBanned:
+			stats.BannedAgents++
+		}
+	}
+	now := time.Now()
+	for _, a := range m.actions {
+		if a.CreatedAt.After(now.Add(-24 * time.Hour)) {
+			stats.ActionsToday++
+		}
+		if a.CreatedAt.After(now.Add(-7 * 24 * time.Hour)) {
+			stats.ActionsThisWeek++
+		}
+	}
+	return stats, nil
+}
+
+// CountPendingReportsForAgent counts pending reports for an agent
+func (m *MemoryStore) CountPendingReportsForAgent(ctx context.Context, agentID uuid.UUID) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, r := range m.reports {
+		if r.AgentID == agentID && r.Status == models.ReportStatusPending {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ============ Appeal Operations ============
+
+// CreateAppeal files a pending appeal, one per agent at a time and at most
+// maxAppeals per agent
+func (m *MemoryStore) CreateAppeal(ctx context.Context, appeal *models.Appeal, maxAppeals int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.agents[appeal.AgentID]; !ok {
+		return fmt.Errorf("agent not found: %s", appeal.AgentID)
+	}
+	count := 0
+	for _, a := range m.appeals {
+		if a.AgentID != appeal.AgentID {
+			continue
+		}
+		if a.Status == models.AppealStatusPending {
+			return fmt.Errorf("%w: agent already has a pending appeal", ErrAppealConflict)
+		}
+		count++
+	}
+	if count >= maxAppeals {
+		return fmt.Errorf("%w: agent has used all %d of its appeals", ErrAppealConflict, maxAppeals)
+	}
+
+	appeal.ID = uuid.New()
+	appeal.Status = models.AppealStatusPending
+	appeal.CreatedAt = time.Now()
+
+	stored := *appeal
+	m.appeals = append(m.appeals, &stored)
+	return nil
+}
+
+// GetAppeal retrieves an appeal by ID
+func (m *MemoryStore) GetAppeal(ctx context.Context, appealID uuid.UUID) (*models.Appeal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, a := range m.appeals {
+		if a.ID == appealID {
+			c := *a
+			if agent, ok := m.agents[a.AgentID]; ok {
+				c.AgentName = agent.Name
+			}
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetPendingAppeals returns appeals awaiting a decision, oldest first
+func (m *MemoryStore) GetPendingAppeals(ctx context.Context) ([]models.Appeal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var appeals []models.Appeal
+	for _, a := range m.appeals {
+		agent, ok := m.agents[a.AgentID]
+		if !ok || a.Status != models.AppealStatusPending {
+			continue
+		}
+		c := *a
+		c.AgentName = agent.Name
+		appeals = append(appeals, c)
+	}
+	return appeals, nil
+}
+
+// DecideAppeal grants or denies a pending appeal and applies its status
+// changes together
+func (m *MemoryStore) DecideAppeal(ctx context.Context, appealID uuid.UUID, status models.AppealStatus, note string, decidedBy string, changes []StatusChange) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, a := range m.appeals {
+		if a.ID != appealID {
+			continue
+		}
+		if a.Status != models.AppealStatusPending {
+			return fmt.Errorf("%w: appeal was already %s", ErrAppealConflict, a.Status)
+		}
+
+		// Check the whole chain first so a conflict changes nothing
+		current := map[uuid.UUID]models.AgentStatus{}
+		for _, c := range changes {
+			if c.Action.PreviousStatus == nil {
+				return fmt.Errorf("action has no previous status")
+			}
+			if _, ok := current[c.Action.AgentID]; !ok {
+				agent, ok := m.agents[c.Action.AgentID]
+				if !ok {
+					return ErrNotFound
+				}
+				current[c.Action.AgentID] = agent.Status
+			}
+			if st := current[c.Action.AgentID]; st != *c.Action.PreviousStatus {
+				return fmt.Errorf("%w: agent status is %s", ErrRevisionConflict, st)
+			}
+			current[c.Action.AgentID] = c.Status
+		}
+		for _, c := range changes {
+			if err := m.applyAction(c.Action, c.Status); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		a.Status = status
+		a.DecidedBy = &decidedBy
+		a.DecisionNote = &note
+		a.DecidedAt = &now
+		return nil
+	}
+	return fmt.Errorf("appeal %s: %w", appealID, ErrNotFound)
+}
+
+// ============ Skill Operations ============
+
+// CreateSkill inserts a new skill
+func (m *MemoryStore) CreateSkill(ctx context.Context, skill *models.Skill) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.skillNames[skill.Name]; exists {
+		return fmt.Errorf("skill %q already exists", skill.Name)
+	}
+	if skill.ID == uuid.Nil {
+		skill.ID = uuid.New()
+	}
+	skill.CreatedAt = time.Now()
+	skill.UpdatedAt = time.Now()
+
+	m.skills[skill.ID] = cloneSkill(skill)
+	m.skillNames[skill.Name] = skill.ID
+	m.recordSkillRevision(skill)
+	return nil
+}
+
+// GetSkill retrieves a skill by name
+func (m *MemoryStore) GetSkill(ctx context.Context, name string) (*models.Skill, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, ok := m.skillNames[name]
+	if !ok {
+		return nil, nil
+	}
+	return cloneSkill(m.skills[id]), nil
+}
+
+// GetSkillByID retrieves a skill by ID
+func (m *MemoryStore) GetSkillByID(ctx context.Context, id uuid.UUID) (*models.Skill, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	skill, ok := m.skills[id]
+	if !ok {
+		return nil, nil
+	}
+	return cloneSkill(skill), nil
+}
+
+// activeSkills returns active skills matching filter, ordered like the SQL queries
+func (m *MemoryStore) activeSkills(filter func(*models.Skill) bool) []models.SkillSummary {
+	var matched []*models.Skill
+	for _, s := range m.skills {
+		if s.Status != models.SkillStatusActive {
+			continue
+		}
+		if filter != nil && !filter(s) {
+			continue
+		}
+		matched = append(matched, s)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].ReputationScore != matched[j].ReputationScore {
+			return matched[i].ReputationScore > matched[j].ReputationScore
+		}
+		if matched[i].UsageCount != matched[j].UsageCount {
+			return matched[i].UsageCount > matched[j].UsageCount
+		}
+		return matched[i].Name < matched[j].Name
+	})
+
+	skills := []models.SkillSummary{}
+	for _, s := range matched {
+		summary := s.ToSummary()
+		summary.Tags = cloneStrings(summary.Tags)
+		skills = append(skills, summary)
+	}
+	return skills
+}
+
+// ListSkills returns all active skills
+func (m *MemoryStore) ListSkills(ctx context.Context, category string, tags []string) ([]models.SkillSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.activeSkills(func(s *models.Skill) bool {
+		if category != "" && s.Category != category {
+			return false
+		}
+		return len(tags) == 0 || overlaps(s.Tags, tags)
+	}), nil
+}
+
+// ListSkillsPage returns one page of skills matching opts.Filter in the requested order
+func (m *MemoryStore) ListSkillsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.SkillSummary], error) {
+	p, err := planList("skills", catalogSorts, opts)
+	if err != nil {
+		return models.Page[models.SkillSummary]{}, err
+	}
+	if err := catalogFilter(models.EmbeddingKindSkill, opts.Filter); err != nil {
+		return models.Page[models.SkillSummary]{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var skills []models.SkillSummary
+	var keys []pageKey
+	for _, s := range m.skills {
+		if skillMatches(s, opts.Filter) {
+			summary := s.ToSummary()
+			summary.Tags = cloneStrings(summary.Tags)
+			skills = append(skills, summary)
+			keys = append(keys, skillKey(s))
+		}
+	}
+	return sortedPage(p, skills, keys), nil
+}
+
+// SearchSkills searches skills by keyword
+func (m *MemoryStore) SearchSkills(ctx context.Context, query string) ([]models.SkillSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	q := strings.ToLower(query)
+	return m.activeSkills(func(s *models.Skill) bool {
+		return containsFold(s.Name, q) || containsFold(s.Description, q) ||
+			containsFold(s.Content, q) || contains(s.Tags, q)
+	}), nil
+}
+
+// FindSimilarSkills finds skills by embedding similarity
+func (m *MemoryStore) FindSimilarSkills(ctx context.Context, embedding pgvector.Vector, limit int, threshold float64) ([]models.SimilarSkill, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := []models.SimilarSkill{}
+	for _, s := range m.skills {
+		if s.Status != models.SkillStatusActive || s.Embedding == nil {
+			continue
+		}
+		summary := s.ToSummary()
+		summary.Tags = cloneStrings(summary.Tags)
+		results = append(results, models.SimilarSkill{
+			Skill:      summary,
+			Similarity: embeddings.CosineSimilarity(*s.Embedding, embedding),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Similarity > results[j].Similarity })
+
+	if limit >= 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	filtered := results[:0]
+	for _, r := range results {
+		if r.Similarity >= threshold {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
+
+// UpdateSkill saves content changes to a skill and appends skill.Version to its history
+func (m *MemoryStore) UpdateSkill(ctx context.Context, skill *models.Skill) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.skills[skill.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, rev := range m.skillRevs[skill.ID] {
+		if rev.Version == skill.Version {
+			return fmt.Errorf("skill %q already has version %s", stored.Name, skill.Version)
+		}
+	}
+
+	skill.UpdatedAt = time.Now()
+	updated := cloneSkill(stored)
+	updated.Version = skill.Version
+	updated.Description = skill.Description
+	updated.Category = skill.Category
+	updated.Content = skill.Content
+	updated.Examples = append([]models.Example(nil), skill.Examples...)
+	updated.Metadata = cloneMetadata(skill.Metadata)
+	updated.Tags = cloneStrings(skill.Tags)
+	updated.Embedding = cloneVector(skill.Embedding)
+	updated.EmbeddingModel = skill.EmbeddingModel
+	updated.UpdatedAt = skill.UpdatedAt
+	m.skills[skill.ID] = updated
+	m.recordSkillRevision(updated)
+	return nil
+}
+
+// IncrementSkillUsage increments the usage count
+func (m *MemoryStore) IncrementSkillUsage(ctx context.Context, skillID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.skills[skillID]; ok {
+		s.UsageCount++
+		s.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// SubmitSkillFeedback records feedback for a skill
+func (m *MemoryStore) SubmitSkillFeedback(ctx context.Context, feedback *models.SkillFeedback) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	skill, ok := m.skills[feedback.SkillID]
+	if !ok {
+		return fmt.Errorf("skill not found: %s", feedback.SkillID)
+	}
+
+	feedback.ID = uuid.New()
+	feedback.CreatedAt = time.Now()
+	m.skillFb = append(m.skillFb, *feedback)
+
+	var sum, count int
+	for _, f := range m.skillFb {
+		if f.SkillID == skill.ID {
+			sum += f.Rating
+			count++
+		}
+	}
+	skill.FeedbackCount++
+	skill.AvgRating = float64(sum) / float64(count)
+	skill.UpdatedAt = time.Now()
+	return nil
+}
+
+// ============ Command Operations ============
+
+// CreateCommand inserts a new command
+func (m *MemoryStore) CreateCommand(ctx context.Context, cmd *models.Command) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.commandName[cmd.Name]; exists {
+		return fmt.Errorf("command %q already exists", cmd.Name)
+	}
+	if cmd.ID == uuid.Nil {
+		cmd.ID = uuid.New()
+	}
+	cmd.CreatedAt = time.Now()
+	cmd.UpdatedAt = time.Now()
+
+	m.commands[cmd.ID] = cloneCommand(cmd)
+	m.commandName[cmd.Name] = cmd.ID
+	m.recordCommandRevision(cmd)
+	return nil
+}
+
+// GetCommand retrieves a command by name
+func (m *MemoryStore) GetCommand(ctx context.Context, name string) (*models.Command, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, ok := m.commandName[name]
+	if !ok {
+		return nil, nil
+	}
+	return cloneCommand(m.commands[id]), nil
+}
+
+// GetCommandByID retrieves a command by ID
+func (m *MemoryStore) GetCommandByID(ctx context.Context, id uuid.UUID) (*models.Command, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cmd, ok := m.commands[id]
+	if !ok {
+		return nil, nil
+	}
+	return cloneCommand(cmd), nil
+}
+
+// commandSummary converts a stored command without sharing slices
+func commandSummary(c *models.Command) models.CommandSummary {
+	summary := c.ToSummary()
+	summary.Tags = cloneStrings(summary.Tags)
+	summary.Arguments = append([]models.Argument(nil), summary.Arguments...)
+	return summary
+}
+
+// activeCommands returns active commands matching filter, ordered like the SQL queries
+func (m *MemoryStore) activeCommands(filter func(*models.Command) bool) []models.CommandSummary {
+	var matched []*models.Command
+	for _, c := range m.commands {
+		if c.Status != models.CommandStatusActive {
+			continue
+		}
+		if filter != nil && !filter(c) {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].ReputationScore != matched[j].ReputationScore {
+			return matched[i].ReputationScore > matched[j].ReputationScore
+		}
+		if matched[i].UsageCount != matched[j].UsageCount {
+			return matched[i].UsageCount > matched[j].UsageCount
+		}
+		return matched[i].Name < matched[j].Name
+	})
+
+	commands := []models.CommandSummary{}
+	for _, c := range matched {
+		commands = append(commands, commandSummary(c))
+	}
+	return commands
+}
+
+// ListCommands returns all active commands
+func (m *MemoryStore) ListCommands(ctx context.Context, category string, tags []string) ([]models.CommandSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.activeCommands(func(c *models.Command) bool {
+		if category != "" && c.Category != category {
+			return false
+		}
+		return len(tags) == 0 || overlaps(c.Tags, tags)
+	}), nil
+}
+
+// ListCommandsPage returns one page of commands matching opts.Filter in the requested order
+func (m *MemoryStore) ListCommandsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.CommandSummary], error) {
+	p, err := planList("commands", catalogSorts, opts)
+	if err != nil {
+		return models.Page[models.CommandSummary]{}, err
+	}
+	if err := catalogFilter(models.EmbeddingKindCommand, opts.Filter); err != nil {
+		return models.Page[models.CommandSummary]{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var commands []models.CommandSummary
+	var keys []pageKey
+	for _, c := range m.commands {
+		if commandMatches(c, opts.Filter) {
+			commands = append(commands, commandSummary(c))
+			keys = append(keys, commandKey(c))
+		}
+	}
+	return sortedPage(p, commands, keys), nil
+}
+
+// SearchCommands searches commands by keyword
+func (m *MemoryStore) SearchCommands(ctx context.Context, query string) ([]models.CommandSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	q := strings.ToLower(query)
+	return m.activeCommands(func(c *models.Command) bool {
+		return containsFold(c.Name, q) || containsFold(c.Description, q) ||
+			containsFold(c.Prompt, q) || contains(c.Tags, q)
+	}), nil
+}
+
+// FindSimilarCommands finds commands by embedding similarity
+func (m *MemoryStore) FindSimilarCommands(ctx context.Context, embedding pgvector.Vector, limit int, threshold float64) ([]models.SimilarCommand, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := []models.SimilarCommand{}
+	for _, c := range m.commands {
+		if c.Status != models.CommandStatusActive || c.Embedding == nil {
+			continue
+		}
+		results = append(results, models.SimilarCommand{
+			Command:    commandSummary(c),
+			Similarity: embeddings.CosineSimilarity(*c.Embedding, embedding),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Similarity > results[j].Similarity })
+
+	if limit >= 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	filtered := results[:0]
+	for _, r := range results {
+		if r.Similarity >= threshold {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
+
+// UpdateCommand saves content changes to a command and appends cmd.Version to its history
+func (m *MemoryStore) UpdateCommand(ctx context.Context, cmd *models.Command) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.commands[cmd.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, rev := range m.commandRevs[cmd.ID] {
+		if rev.Version == cmd.Version {
+			return fmt.Errorf("command %q already has version %s", stored.Name, cmd.Version)
+		}
+	}
+
+	cmd.UpdatedAt = time.Now()
+	updated := cloneCommand(stored)
+	updated.Version = cmd.Version
+	updated.Description = cmd.Description
+	updated.Prompt = cmd.Prompt
+	updated.Arguments = append([]models.Argument(nil), cmd.Arguments...)
+	updated.Metadata = cloneMetadata(cmd.Metadata)
+	updated.Tags = cloneStrings(cmd.Tags)
+	updated.Category = cmd.Category
+	updated.Embedding = cloneVector(cmd.Embedding)
+	updated.EmbeddingModel = cmd.EmbeddingModel
+	updated.UpdatedAt = cmd.UpdatedAt
+	m.commands[cmd.ID] = updated
+	m.recordCommandRevision(updated)
+	return nil
+}
+
+// IncrementCommandUsage increments the usage count
+func (m *MemoryStore) IncrementCommandUsage(ctx context.Context, commandID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.commands[commandID]; ok {
+		c.UsageCount++
+		c.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// SubmitCommandFeedback records feedback for a command
+func (m *MemoryStore) SubmitCommandFeedback(ctx context.Context, feedback *models.CommandFeedback) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cmd, ok := m.commands[feedback.CommandID]
+	if !ok {
+		return fmt.Errorf("command not found: %s", feedback.CommandID)
+	}
+
+	feedback.ID = uuid.New()
+	feedback.CreatedAt = time.Now()
+	m.commandFb = append(m.commandFb, *feedback)
+
+	var sum, count int
+	for _, f := range m.commandFb {
+		if f.CommandID == cmd.ID {
+			sum += f.Rating
+			count++
+		}
+	}
+	cmd.FeedbackCount++
+	cmd.AvgRating = float64(sum) / float64(count)
+	cmd.UpdatedAt = time.Now()
+	return nil
+}
+
+// ============ Revision History ============
+// record*Revision must be called with m.mu held for writing.
+
+func (m *MemoryStore) hasAgentVersion(agentID uuid.UUID, version string) bool {
+	for _, rev := range m.agentRevs[agentID] {
+		if rev.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryStore) recordAgentRevision(agent *models.Agent) {
+	rev := models.NewAgentRevision(cloneAgent(agent))
+	rev.ID = uuid.New()
+	rev.CreatedAt = time.Now()
+	m.agentRevs[agent.ID] = append(m.agentRevs[agent.ID], rev)
+}
+
+func (m *MemoryStore) recordSkillRevision(skill *models.Skill) {
+	rev := models.NewSkillRevision(cloneSkill(skill))
+	rev.ID = uuid.New()
+	rev.CreatedAt = time.Now()
+	m.skillRevs[skill.ID] = append(m.skillRevs[skill.ID], rev)
+}
+
+func (m *MemoryStore) recordCommandRevision(cmd *models.Command) {
+	rev := models.NewCommandRevision(cloneCommand(cmd))
+	rev.ID = uuid.New()
+	rev.CreatedAt = time.Now()
+	m.commandRevs[cmd.ID] = append(m.commandRevs[cmd.ID], rev)
+}
+
+// ListAgentRevisions returns an agent's history, newest first
+func (m *MemoryStore) ListAgentRevisions(ctx context.Context, agentID uuid.UUID) ([]models.RevisionInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	revs := m.agentRevs[agentID]
+	out := []models.RevisionInfo{}
+	for i := len(revs) - 1; i >= 0; i-- {
+		out = append(out, models.RevisionInfo{Version: revs[i].Version, CreatedAt: revs[i].CreatedAt})
+	}
+	return out, nil
+}
+
+// GetAgentRevision retrieves one version of an agent
+func (m *MemoryStore) GetAgentRevision(ctx context.Context, agentID uuid.UUID, version string) (*models.AgentRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rev := range m.agentRevs[agentID] {
+		if rev.Version == version {
+			c := *rev
+			c.Tools = cloneStrings(rev.Tools)
+			c.Skills = cloneStrings(rev.Skills)
+			c.Metadata = cloneMetadata(rev.Metadata)
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListSkillRevisions returns a skill's history, newest first
+func (m *MemoryStore) ListSkillRevisions(ctx context.Context, skillID uuid.UUID) ([]models.RevisionInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	revs := m.skillRevs[skillID]
+	out := []models.RevisionInfo{}
+	for i := len(revs) - 1; i >= 0; i-- {
+		out = append(out, models.RevisionInfo{Version: revs[i].Version, CreatedAt: revs[i].CreatedAt})
+	}
+	return out, nil
+}
+
+// GetSkillRevision retrieves one version of a skill
+func (m *MemoryStore) GetSkillRevision(ctx context.Context, skillID uuid.UUID, version string) (*models.SkillRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rev := range m.skillRevs[skillID] {
+		if rev.Version == version {
+			c := *rev
+			c.Tags = cloneStrings(rev.Tags)
+			c.Examples = append([]models.Example(nil), rev.Examples...)
+			c.Metadata = cloneMetadata(rev.Metadata)
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListCommandRevisions returns a command's history, newest first
+func (m *MemoryStore) ListCommandRevisions(ctx context.Context, commandID uuid.UUID) ([]models.RevisionInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	revs := m.commandRevs[commandID]
+	out := []models.RevisionInfo{}
+	for i := len(revs) - 1; i >= 0; i-- {
+		out = append(out, models.RevisionInfo{Version: revs[i].Version, CreatedAt: revs[i].CreatedAt})
+	}
+	return out, nil
+}
+
+// GetCommandRevision retrieves one version of a command
+func (m *MemoryStore) GetCommandRevision(ctx context.Context, commandID uuid.UUID, version string) (*models.CommandRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rev := range m.commandRevs[commandID] {
+		if rev.Version == version {
+			c := *rev
+			c.Tags = cloneStrings(rev.Tags)
+			c.Arguments = append([]models.Argument(nil), rev.Arguments...)
+			c.Metadata = cloneMetadata(rev.Metadata)
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// ============ Embedding Maintenance ============
+
+// embeddingRow is the part of an agent, skill or command that re-indexing reads and writes
+type embeddingRow struct {
+	src       models.EmbeddingSource
+	embedding **pgvector.Vector
+	model     *string
+}
+
+// embeddingRows returns the rows of a kind in id order; callers must hold m.mu
+func (m *MemoryStore) embeddingRows(kind models.EmbeddingKind) ([]embeddingRow, error) {
+	var rows []embeddingRow
+	switch kind {
+	case models.EmbeddingKindAgent:
+		for _, a := range m.agents {
+			rows = append(rows, embeddingRow{
+				src: models.EmbeddingSource{Kind: kind, ID: a.ID, Name: a.Name, Description: a.Description,
+					Skills: cloneStrings(a.Skills), UpdatedAt: a.UpdatedAt},
+				embedding: &a.Embedding, model: &a.EmbeddingModel,
+			})
+		}
+	case models.EmbeddingKindSkill:
+		for _, s := range m.skills {
+			rows = append(rows, embeddingRow{
+				src: models.EmbeddingSource{Kind: kind, ID: s.ID, Name: s.Name, Description: s.Description,
+					Content: s.Content, UpdatedAt: s.UpdatedAt},
+				embedding: &s.Embedding, model: &s.EmbeddingModel,
+			})
+		}
+	case models.EmbeddingKindCommand:
+		for _, c := range m.commands {
+			rows = append(rows, embeddingRow{
+				src: models.EmbeddingSource{Kind: kind, ID: c.ID, Name: c.Name, Description: c.Description,
+					Content: c.Prompt, UpdatedAt: c.UpdatedAt},
+				embedding: &c.Embedding, model: &c.EmbeddingModel,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown embedding kind: %s", kind)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].src.ID.String() < rows[j].src.ID.String() })
+	return rows, nil
+}
+
+// EmbeddingDimension returns 0: the in-process store accepts any dimension
+func (m *MemoryStore) EmbeddingDimension(ctx context.Context, kind models.EmbeddingKind) (int, error) {
+	if _, ok := embeddingTables[kind]; !ok {
+		return 0, fmt.Errorf("unknown embedding kind: %s", kind)
+	}
+	return 0, nil
+}
+
+// CountEmbeddings counts a kind's rows and those embedded with model
+func (m *MemoryStore) CountEmbeddings(ctx context.Context, kind models.EmbeddingKind, model string) (models.EmbeddingCounts, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var counts models.EmbeddingCounts
+	rows, err := m.embeddingRows(kind)
+	if err != nil {
+		return counts, err
+	}
+	for _, r := range rows {
+		counts.Total++
+		if *r.embedding != nil && *r.model == model {
+			counts.Current++
+		}
+	}
+	return counts, nil
+}
+
+// ListStaleEmbeddings returns up to limit rows of a kind that have no
+// embedding or were embedded with a model other than model, in id order
+func (m *MemoryStore) ListStaleEmbeddings(ctx context.Context, kind models.EmbeddingKind, model string, limit int) ([]models.EmbeddingSource, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rows, err := m.embeddingRows(kind)
+	if err != nil {
+		return nil, err
+	}
+	sources := []models.EmbeddingSource{}
+	for _, r := range rows {
+		if len(sources) == limit {
+			break
+		}
+		if *r.embedding == nil || *r.model != model {
+			sources = append(sources, r.src)
+		}
+	}
+	return sources, nil
+}
+
+// SetEmbedding stores an embedding and its model unless the row changed since src was read
+func (m *MemoryStore) SetEmbedding(ctx context.Context, src models.EmbeddingSource, embedding pgvector.Vector, model string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows, err := m.embeddingRows(src.Kind)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if r.src.ID == src.ID && r.src.UpdatedAt.Equal(src.UpdatedAt) {
+			*r.embedding = cloneVector(&embedding)
+			*r.model = model
+		}
+	}
+	return nil
+}
+
+// ============ Task Aliases ============
+
+// ListTaskAliases returns every alias override ordered by domain and keyword
+func (m *MemoryStore) ListTaskAliases(ctx context.Context) ([]models.TaskAlias, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	aliases := make([]models.TaskAlias, 0, len(m.taskAliases))
+	for _, a := range m.taskAliases {
+		a.Aliases = cloneStrings(a.Aliases)
+		aliases = append(aliases, a)
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		if aliases[i].Domain != aliases[j].Domain {
+			return aliases[i].Domain < aliases[j].Domain
+		}
+		return aliases[i].Keyword < aliases[j].Keyword
+	})
+	return aliases, nil
+}
+
+// SetTaskAlias creates or replaces the override for alias's domain and keyword
+func (m *MemoryStore) SetTaskAlias(ctx context.Context, alias *models.TaskAlias) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alias.UpdatedAt = time.Now()
+	if alias.Aliases == nil {
+		alias.Aliases = []string{}
+	}
+	stored := *alias
+	stored.Aliases = cloneStrings(alias.Aliases)
+	m.taskAliases[taskAliasKey{alias.Domain, alias.Keyword}] = stored
+	return nil
+}
+
+// DeleteTaskAlias removes an override, reporting whether one existed
+func (m *MemoryStore) DeleteTaskAlias(ctx context.Context, domain, keyword string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := taskAliasKey{domain, keyword}
+	if _, ok := m.taskAliases[k]; !ok {
+		return false, nil
+	}
+	delete(m.taskAliases, k)
+	return true, nil
+}
+
+// ============ Hybrid Search ============
+
+// searchCandidate is one active row's signals during an in-process hybrid search
+type searchCandidate struct {
+	index      int // position in the caller's rows
+	id         uuid.UUID
+	name       string
+	reputation float64
+	text       float64
+	hits       []string
+	fuzzy      float64
+	fuzzyHits  []string
+	similarity float64
+	embedded   bool
+	scores     models.SearchScores
+}
+
+// newSearchCandidate scores one row's text and embedding against q.
+// fuzzy is the row's fuzzyText.
+func newSearchCandidate(q models.SearchQuery, terms []string, index int, id uuid.UUID, name, description, body, fuzzy string,
+	reputation float64, embedding *pgvector.Vector) searchCandidate {
+	c := searchCandidate{
+		index:      index,
+		id:         id,
+		name:       name,
+		reputation: reputation,
+	}
+	c.text, c.hits = textScore(terms, name, description, body)
+	c.fuzzy, c.fuzzyHits = fuzzyScore(terms, c.hits, fuzzy)
+	if q.Embedding != nil && embedding != nil {
+		c.embedded = true
+		c.similarity = embeddings.CosineSimilarity(*embedding, *q.Embedding)
+	}
+	return c
+}
+
+// textScore stands in for ts_rank_cd using the weights migration 009 gives
+// each field: name 1.0, description 0.4 and prompt or content 0.2 per
+// matching term. A term matches any word it prefixes, approximating stemming.
+// It also returns the terms that matched anywhere.
+func textScore(terms []string, name, description, body string) (float64, []string) {
+	fields := []struct {
+		text   string
+		weight float64
+	}{{name, 1}, {description, 0.4}, {body, 0.2}}
+
+	score := 0.0
+	matched := map[string]bool{}
+	for _, field := range fields {
+		words := searchTerms(field.text)
+		for _, term := range terms {
+			for _, word := range words {
+				if strings.HasPrefix(word, term) {
+					score += field.weight
+					matched[term] = true
+					break
+				}
+			}
+		}
+	}
+
+	var hits []string
+	for _, term := range terms {
+		if matched[term] {
+			hits = append(hits, term)
+		}
+	}
+	return score, hits
+}
+
+// fuzzyText mirrors the fuzzy_text column of migration 012: the lowercased
+// name, description and tags
+func fuzzyText(name, description string, tags ...[]string) string {
+	parts := []string{name, description}
+	for _, t := range tags {
+		parts = append(parts, t...)
+	}
+	return strings.ToLower(strings.Join(parts, " "))
+}
+
+// fuzzyScore stands in for the fuzzy_hits ranking: each term without a
+// keyword hit scores its trigram word similarity to text when that reaches
+// pg_trgm's default threshold. It also returns the terms that scored.
+func fuzzyScore(terms, hits []string, text string) (float64, []string) {
+	score := 0.0
+	var matched []string
+	for _, term := range terms {
+		if slices.Contains(hits, term) {
+			continue
+		}
+		if sim := trigram.WordSimilarity(term, text); sim >= trigram.WordThreshold {
+			score += sim
+			matched = append(matched, term)
+		}
+	}
+	return score, matched
+}
+
+// hybridRank mirrors hybridSearchSQL: candidates are ranked by text score and
+// by similarity, the rankings are fused with RRF, and reputation then name
+// break ties
+func hybridRank(cands []searchCandidate, q models.SearchQuery) []searchCandidate {
+	limit := searchLimit(q.Limit)
+	offset := max(q.Offset, 0)
+	pool := searchCandidates(limit + offset)
+
+	rank := func(keep func(*searchCandidate) bool, value func(*searchCandidate) float64) []*searchCandidate {
+		var list []*searchCandidate
+		for i := range cands {
+			if keep(&cands[i]) {
+				list = append(list, &cands[i])
+			}
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if value(list[i]) != value(list[j]) {
+				return value(list[i]) > value(list[j])
+			}
+			return list[i].id.String() < list[j].id.String()
+		})
+		if len(list) > pool {
+			list = list[:pool]
+		}
+		return list
+	}
+
+	for i, c := range rank(
+		func(c *searchCandidate) bool { return c.text > 0 },
+		func(c *searchCandidate) float64 { return c.text },
+	) {
+		c.scores.TextRank = i + 1
+		c.scores.TextScore = c.text
+	}
+
+	for i, c := range rank(
+		func(c *searchCandidate) bool { return c.fuzzy > 0 },
+		func(c *searchCandidate) float64 { return c.fuzzy },
+	) {
+		c.scores.FuzzyRank = i + 1
+		c.scores.FuzzyScore = c.fuzzy
+		c.scores.FuzzyHits = c.fuzzyHits
+	}
+
+	// Like the SQL, the nearest neighbours are taken first and then filtered
+	vectorRank := 0
+	for _, c := range rank(
+		func(c *searchCandidate) bool { return c.embedded },
+		func(c *searchCandidate) float64 { return c.similarity },
+	) {
+		if c.similarity < q.MinSimilarity {
+			continue
+		}
+		vectorRank++
+		c.scores.VectorRank = vectorRank
+	}
+
+	var fused []searchCandidate
+	for _, c := range cands {
+		c.scores.KeywordHits = c.hits
+		if c.embedded {
+			c.scores.Similarity = c.similarity
+		}
+		if c.scores.TextRank > 0 {
+			c.scores.Score += 1.0 / float64(rrfK+c.scores.TextRank)
+		}
+		if c.scores.FuzzyRank > 0 {
+			c.scores.Score += 1.0 / float64(rrfK+c.scores.FuzzyRank)
+		}
+		if c.scores.VectorRank > 0 {
+			c.scores.Score += 1.0 / float64(rrfK+c.scores.VectorRank)
+		}
+		if c.scores.Score > 0 {
+			fused = append(fused, c)
+		}
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].scores.Score != fused[j].scores.Score {
+			return fused[i].scores.Score > fused[j].scores.Score
+		}
+		if fused[i].reputation != fused[j].reputation {
+			return fused[i].reputation > fused[j].reputation
+		}
+		return fused[i].name < fused[j].name
+	})
+	fused = fused[min(offset, len(fused)):]
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+	return fused
+}
+
+// HybridSearchAgents finds agents by keyword and embedding similarity
+func (m *MemoryStore) HybridSearchAgents(ctx context.Context, q models.SearchQuery) ([]models.AgentSearchResult, error) {
+	if err := catalogFilter(models.EmbeddingKindAgent, q.Filter); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	terms := searchTerms(q.Text)
+	var agents []*models.Agent
+	for _, a := range m.agents {
+		if agentMatches(a, q.Filter) {
+			agents = append(agents, a)
+		}
+	}
+	cands := make([]searchCandidate, len(agents))
+	for i, a := range agents {
+		cands[i] = newSearchCandidate(q, terms, i, a.ID, a.Name, a.Description, a.Prompt,
+			fuzzyText(a.Name, a.Description, metadataTags(a.Metadata), a.Skills), a.ReputationScore, a.Embedding)
+	}
+
+	results := []models.AgentSearchResult{}
+	for _, c := range hybridRank(cands, q) {
+		results = append(results, models.AgentSearchResult{
+			Agent:  agentSummaries([]*models.Agent{agents[c.index]})[0],
+			Scores: c.scores,
+		})
+	}
+	return results, nil
+}
+
+// HybridSearchSkills finds skills by keyword and embedding similarity
+func (m *MemoryStore) HybridSearchSkills(ctx context.Context, q models.SearchQuery) ([]models.SkillSearchResult, error) {
+	if err := catalogFilter(models.EmbeddingKindSkill, q.Filter); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	terms := searchTerms(q.Text)
+	var skills []*models.Skill
+	var cands []searchCandidate
+	for _, s := range m.skills {
+		if !skillMatches(s, q.Filter) {
+			continue
+		}
+		cands = append(cands, newSearchCandidate(q, terms, len(skills), s.ID, s.Name, s.Description, s.Content,
+			fuzzyText(s.Name, s.Description, s.Tags), s.ReputationScore, s.Embedding))
+		skills = append(skills, s)
+	}
+
+	results := []models.SkillSearchResult{}
+	for _, c := range hybridRank(cands, q) {
+		summary := skills[c.index].ToSummary()
+		summary.Tags = cloneStrings(summary.Tags)
+		results = append(results, models.SkillSearchResult{Skill: summary, Scores: c.scores})
+	}
+	return results, nil
+}
+
+// HybridSearchCommands finds commands by keyword and embedding similarity
+func (m *MemoryStore) HybridSearchCommands(ctx context.Context, q models.SearchQuery) ([]models.CommandSearchResult, error) {
+	if err := catalogFilter(models.EmbeddingKindCommand, q.Filter); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	terms := searchTerms(q.Text)
+	var commands []*models.Command
+	var cands []searchCandidate
+	for _, c := range m.commands {
+		if !commandMatches(c, q.Filter) {
+			continue
+		}
+		cands = append(cands, newSearchCandidate(q, terms, len(commands), c.ID, c.Name, c.Description, c.Prompt,
+			fuzzyText(c.Name, c.Description, c.Tags), c.ReputationScore, c.Embedding))
+		commands = append(commands, c)
+	}
+
+	results := []models.CommandSearchResult{}
+	for _, c := range hybridRank(cands, q) {
+		results = append(results, models.CommandSearchResult{Command: commandSummary(commands[c.index]), Scores: c.scores})
+	}
+	return results, nil
+}
+
+// SuggestTerms proposes catalog words for the terms of text that no row of
+// kind matching filter contains, for "did you mean" hints
+func (m *MemoryStore) SuggestTerms(ctx context.Context, kind models.EmbeddingKind, text string, filter models.ListFilter) ([]models.Suggestion, error) {
+	if err := catalogFilter(kind, filter); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	var texts []string
+	switch kind {
+	case models.EmbeddingKindAgent:
+		for _, a := range m.agents {
+			if agentMatches(a, filter) {
+				texts = append(texts, fuzzyText(a.Name, a.Description, metadataTags(a.Metadata), a.Skills))
+			}
+		}
+	case models.EmbeddingKindSkill:
+		for _, s := range m.skills {
+			if skillMatches(s, filter) {
+				texts = append(texts, fuzzyText(s.Name, s.Description, s.Tags))
+			}
+		}
+	case models.EmbeddingKindCommand:
+		for _, c := range m.commands {
+			if commandMatches(c, filter) {
+				texts = append(texts, fuzzyText(c.Name, c.Description, c.Tags))
+			}
+		}
+	default:
+		m.mu.RUnlock()
+		return nil, fmt.Errorf("unknown embedding kind: %s", kind)
+	}
+	m.mu.RUnlock()
+
+	seen := map[string]bool{}
+	var vocabulary []string
+	for _, t := range texts {
+		for _, word := range trigram.Words(t) {
+			if utf8.RuneCountInString(word) >= 3 && !seen[word] {
+				seen[word] = true
+				vocabulary = append(vocabulary, word)
+			}
+		}
+	}
+
+	suggestions := []models.Suggestion{}
+	for _, term := range searchTerms(text) {
+		if seen[term] {
+			continue
+		}
+		if word, sim := trigram.Closest(term, vocabulary); word != "" {
+			suggestions = append(suggestions, models.Suggestion{Term: term, Suggestion: word, Similarity: sim})
+		}
+	}
+	return suggestions, nil
+}
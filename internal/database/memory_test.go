@@ -0,0 +1,744 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+)
+
+func newTestAgent(name string, reputation float64, skills ...string) *models.Agent {
+	return &models.Agent{
+		Name:            name,
+		Version:         "1.0.0",
+		Description:     "Agent " + name,
+		Model:           "sonnet",
+		Prompt:          "You are " + name,
+		Skills:          skills,
+		Status:          models.StatusActive,
+		ReputationScore: reputation,
+		Metadata:        map[string]any{"tags": []string{"test"}},
+	}
+}
+
+func TestMemoryStoreAgentCRUD(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("code-reviewer", 50, "review")
+	if err := store.CreateAgent(ctx, agent); err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+	if err := store.CreateAgent(ctx, newTestAgent("code-reviewer", 50)); err == nil {
+		t.Error("expected duplicate name to fail")
+	}
+
+	got, err := store.GetAgent(ctx, "code-reviewer")
+	if err != nil || got == nil {
+		t.Fatalf("GetAgent failed: %v", err)
+	}
+	if got.ID != agent.ID {
+		t.Errorf("GetAgent ID = %v, want %v", got.ID, agent.ID)
+	}
+
+	// Returned agents must not alias stored state
+	got.Skills[0] = "mutated"
+	again, _ := store.GetAgentByID(ctx, agent.ID)
+	if again.Skills[0] != "review" {
+		t.Errorf("stored agent was mutated through returned copy: %v", again.Skills)
+	}
+
+	missing, err := store.GetAgent(ctx, "missing")
+	if err != nil || missing != nil {
+		t.Errorf("GetAgent(missing) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestMemoryStoreUpdateAndArchiveAgent(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("editable", 50, "docs")
+	store.CreateAgent(ctx, agent)
+	store.CreateAgent(ctx, newTestAgent("taken", 50))
+	store.CacheSkillRequest(ctx, []string{"docs"}, agent.ID)
+
+	agent.Name = "renamed"
+	agent.Version = "1.0.1"
+	if err := store.UpdateAgent(ctx, agent, 1); err != nil {
+		t.Fatalf("UpdateAgent failed: %v", err)
+	}
+	if agent.Revision != 2 {
+		t.Errorf("Revision = %d, want 2", agent.Revision)
+	}
+	if got, _ := store.GetAgent(ctx, "editable"); got != nil {
+		t.Error("old name should no longer resolve")
+	}
+
+	if err := store.UpdateAgent(ctx, agent, 1); !errors.Is(err, ErrRevisionConflict) {
+		t.Errorf("stale UpdateAgent error = %v, want ErrRevisionConflict", err)
+	}
+	agent.Name = "taken"
+	agent.Version = "1.0.2"
+	if err := store.UpdateAgent(ctx, agent, 2); err == nil {
+		t.Error("expected rename onto an existing name to fail")
+	}
+
+	if err := store.ArchiveAgent(ctx, agent.ID, 2); err != nil {
+		t.Fatalf("ArchiveAgent failed: %v", err)
+	}
+	got, _ := store.GetAgent(ctx, "renamed")
+	if got.Status != models.StatusArchived || got.Revision != 3 {
+		t.Errorf("got status=%s revision=%d, want archived and 3", got.Status, got.Revision)
+	}
+	if cached, _ := store.GetCachedAgentBySkills(ctx, []string{"docs"}); cached != nil {
+		t.Error("archived agent should be evicted from the skill request cache")
+	}
+}
+
+func TestMemoryStoreRevisionHistory(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("versioned", 50)
+	store.CreateAgent(ctx, agent)
+
+	agent.Version = "1.1.0"
+	agent.Prompt = "You are improved"
+	if err := store.UpdateAgent(ctx, agent, agent.Revision); err != nil {
+		t.Fatalf("UpdateAgent failed: %v", err)
+	}
+
+	// Reusing a version would rewrite history
+	agent.Prompt = "sneaky"
+	if err := store.UpdateAgent(ctx, agent, agent.Revision); err == nil {
+		t.Error("expected duplicate version to fail")
+	}
+
+	revisions, _ := store.ListAgentRevisions(ctx, agent.ID)
+	if len(revisions) != 2 || revisions[0].Version != "1.1.0" {
+		t.Fatalf("ListAgentRevisions = %v, want [1.1.0 1.0.0]", revisions)
+	}
+
+	original, err := store.GetAgentRevision(ctx, agent.ID, "1.0.0")
+	if err != nil || original == nil {
+		t.Fatalf("GetAgentRevision failed: %v", err)
+	}
+	if original.Prompt != "You are versioned" {
+		t.Errorf("revision 1.0.0 prompt = %q", original.Prompt)
+	}
+	if missing, _ := store.GetAgentRevision(ctx, agent.ID, "9.9.9"); missing != nil {
+		t.Error("expected nil for unknown version")
+	}
+
+	cmd := &models.Command{Name: "deploy", Version: "1.0.0", Description: "Deploy", Prompt: "v1", Status: models.CommandStatusActive}
+	store.CreateCommand(ctx, cmd)
+	cmd.Version = "1.0.1"
+	cmd.Prompt = "v2"
+	if err := store.UpdateCommand(ctx, cmd); err != nil {
+		t.Fatalf("UpdateCommand failed: %v", err)
+	}
+	got, _ := store.GetCommand(ctx, "deploy")
+	if got.Prompt != "v2" {
+		t.Errorf("command prompt = %q, want v2", got.Prompt)
+	}
+	cmdRevs, _ := store.ListCommandRevisions(ctx, cmd.ID)
+	if len(cmdRevs) != 2 {
+		t.Errorf("ListCommandRevisions = %v, want 2 entries", cmdRevs)
+	}
+}
+
+func TestMemoryStoreListAndSearch(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	store.CreateAgent(ctx, newTestAgent("low", 10, "docs"))
+	store.CreateAgent(ctx, newTestAgent("high", 90, "security"))
+	banned := newTestAgent("banned", 99, "security")
+	store.CreateAgent(ctx, banned)
+	store.UpdateAgentStatus(ctx, banned.ID, models.StatusBanned)
+
+	agents, _ := store.ListAgents(ctx, nil)
+	if len(agents) != 2 {
+		t.Fatalf("ListAgents returned %d agents, want 2", len(agents))
+	}
+	if agents[0].Name != "high" {
+		t.Errorf("ListAgents should order by reputation, got %s first", agents[0].Name)
+	}
+
+	tagged, _ := store.ListAgents(ctx, []string{"security"})
+	if len(tagged) != 1 || tagged[0].Name != "high" {
+		t.Errorf("ListAgents(security) = %v, want [high]", tagged)
+	}
+
+	results, _ := store.SearchAgents(ctx, "LOW")
+	if len(results) != 1 || results[0].Name != "low" {
+		t.Errorf("SearchAgents(LOW) = %v, want [low]", results)
+	}
+}
+
+func TestMemoryStoreListAgentsPage(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	// Equal reputations exercise the usage and id tie-breakers across pages
+	for i, rep := range []float64{70, 50, 50, 50, 30, 10, 90} {
+		a := newTestAgent(string(rune('a'+i)), rep)
+		a.IsGenerated = i%2 == 0
+		if i == 6 {
+			a.Model = "opus"
+		}
+		store.CreateAgent(ctx, a)
+	}
+
+	all, _ := store.ListAgentsPage(ctx, models.ListOptions{Limit: MaxPageSize})
+	if len(all.Items) != 7 || all.NextCursor != "" {
+		t.Fatalf("single page = %d agents, cursor %q; want 7 and no cursor", len(all.Items), all.NextCursor)
+	}
+
+	var walked []string
+	opts := models.ListOptions{Limit: 3}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("paging did not terminate")
+		}
+		page, err := store.ListAgentsPage(ctx, opts)
+		if err != nil {
+			t.Fatalf("ListAgentsPage failed: %v", err)
+		}
+		for _, a := range page.Items {
+			walked = append(walked, a.Name)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	var want []string
+	for _, a := range all.Items {
+		want = append(want, a.Name)
+	}
+	if strings.Join(walked, ",") != strings.Join(want, ",") {
+		t.Errorf("paged order = %v, want %v", walked, want)
+	}
+
+	byName, _ := store.ListAgentsPage(ctx, models.ListOptions{SortBy: "name", Order: models.SortDesc, Limit: 2})
+	if len(byName.Items) != 2 || byName.Items[0].Name != "g" || byName.Items[1].Name != "f" {
+		t.Errorf("name desc = %v, want g, f", byName.Items)
+	}
+
+	generated := true
+	filtered, _ := store.ListAgentsPage(ctx, models.ListOptions{Filter: models.ListFilter{Model: "sonnet", IsGenerated: &generated}})
+	if len(filtered.Items) != 3 {
+		t.Errorf("generated sonnet agents = %d, want 3 (a, c, e)", len(filtered.Items))
+	}
+
+	// A cursor is only valid for the ordering and filters that produced it
+	first, _ := store.ListAgentsPage(ctx, models.ListOptions{Limit: 2})
+	if _, err := store.ListAgentsPage(ctx, models.ListOptions{Limit: 2, SortBy: "name", Cursor: first.NextCursor}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor reused with another sort: err = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := store.ListAgentsPage(ctx, models.ListOptions{Cursor: "not-a-cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("garbage cursor: err = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := store.ListAgentsPage(ctx, models.ListOptions{SortBy: "popularity"}); err == nil {
+		t.Error("expected unknown sort to fail")
+	}
+	if _, err := store.ListAgentsPage(ctx, models.ListOptions{Filter: models.ListFilter{Category: "devops"}}); err == nil {
+		t.Error("expected category filter on agents to fail")
+	}
+}
+
+func TestMemoryStoreFindSimilarAgents(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	near := newTestAgent("near", 50)
+	nearVec := pgvector.NewVector([]float32{1, 0, 0})
+	near.Embedding = &nearVec
+	far := newTestAgent("far", 50)
+	farVec := pgvector.NewVector([]float32{0, 1, 0})
+	far.Embedding = &farVec
+	store.CreateAgent(ctx, near)
+	store.CreateAgent(ctx, far)
+	store.CreateAgent(ctx, newTestAgent("no-embedding", 50))
+
+	query := pgvector.NewVector([]float32{0.9, 0.1, 0})
+	similar, err := store.FindSimilarAgents(ctx, query, 5, 0.5)
+	if err != nil {
+		t.Fatalf("FindSimilarAgents failed: %v", err)
+	}
+	if len(similar) != 1 || similar[0].Agent.Name != "near" {
+		t.Errorf("FindSimilarAgents = %v, want [near]", similar)
+	}
+}
+
+func TestMemoryStoreHybridSearchAgents(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	withVector := func(a *models.Agent, v ...float32) *models.Agent {
+		vec := pgvector.NewVector(v)
+		a.Embedding = &vec
+		return a
+	}
+	// alpha is the best keyword hit but semantically far, beta is the
+	// nearest vector with no keyword hit, gamma is second on both
+	alpha := withVector(newTestAgent("terraform-alpha", 80), 0, 1, 0)
+	beta := withVector(newTestAgent("beta", 50), 1, 0, 0)
+	gamma := withVector(newTestAgent("gamma", 10), 0.7, 0.7, 0)
+	gamma.Description = "Writes Terraform modules"
+	for _, a := range []*models.Agent{alpha, beta, gamma, newTestAgent("unrelated", 90)} {
+		store.CreateAgent(ctx, a)
+	}
+
+	query := pgvector.NewVector([]float32{1, 0, 0})
+	results, err := store.HybridSearchAgents(ctx, models.SearchQuery{
+		Text: "terraform", Embedding: &query, MinSimilarity: 0.5, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("HybridSearchAgents failed: %v", err)
+	}
+
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Agent.Name)
+	}
+	// gamma appears in both rankings; alpha and beta tie on fused score
+	// and reputation puts alpha first
+	if strings.Join(names, ",") != "gamma,terraform-alpha,beta" {
+		t.Fatalf("results = %v, want [gamma terraform-alpha beta]", names)
+	}
+	if sc := results[0].Scores; sc.TextRank != 2 || sc.VectorRank != 2 || sc.Similarity < 0.7 {
+		t.Errorf("gamma scores = %+v, want text #2 and vector #2", sc)
+	}
+	if sc := results[1].Scores; sc.TextRank != 1 || sc.VectorRank != 0 || sc.Similarity != 0 {
+		t.Errorf("alpha scores = %+v, want a keyword-only match below the similarity floor", sc)
+	}
+
+	// Without an embedding only keywords count, and a name match outranks
+	// a description match
+	results, _ = store.HybridSearchAgents(ctx, models.SearchQuery{Text: "terraform", Limit: 1})
+	if len(results) != 1 || results[0].Agent.Name != "terraform-alpha" || results[0].Scores.VectorRank != 0 {
+		t.Errorf("keyword-only search = %+v, want terraform-alpha alone", results)
+	}
+}
+
+func TestMemoryStoreFuzzySearch(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	k8s := newTestAgent("cluster-operator", 50)
+	k8s.Description = "Operates Kubernetes clusters"
+	k8s.Metadata = map[string]any{"tags": []string{"helm"}}
+	store.CreateAgent(ctx, k8s)
+	store.CreateAgent(ctx, newTestAgent("copywriter", 90))
+
+	// A misspelled term still finds the agent through the fuzzy signal, as
+	// does a tag, which full-text search does not index
+	results, err := store.HybridSearchAgents(ctx, models.SearchQuery{Text: "kubernets helm operates", Limit: 10})
+	if err != nil {
+		t.Fatalf("HybridSearchAgents failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Agent.Name != "cluster-operator" {
+		t.Fatalf("results = %+v, want cluster-operator alone", results)
+	}
+	sc := results[0].Scores
+	if sc.TextRank != 1 || sc.FuzzyRank != 1 || strings.Join(sc.FuzzyHits, ",") != "kubernets,helm" {
+		t.Errorf("scores = %+v, want a keyword hit on operates and fuzzy hits on kubernets and helm", sc)
+	}
+
+	suggestions, err := store.SuggestTerms(ctx, models.EmbeddingKindAgent, "kubernets helm clustr", models.ListFilter{})
+	if err != nil {
+		t.Fatalf("SuggestTerms failed: %v", err)
+	}
+	var got []string
+	for _, s := range suggestions {
+		got = append(got, s.Term+"->"+s.Suggestion)
+	}
+	// helm is a tag, so only the misspellings get suggestions
+	if strings.Join(got, ",") != "kubernets->kubernetes,clustr->cluster" {
+		t.Errorf("suggestions = %v, want kubernets->kubernetes and clustr->cluster", got)
+	}
+
+	if _, err := store.SuggestTerms(ctx, models.EmbeddingKindAgent, "x", models.ListFilter{Category: "ops"}); err == nil {
+		t.Error("SuggestTerms accepted a category filter for agents")
+	}
+}
+
+func TestMemoryStoreFeedbackAndReputation(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("rated", 50)
+	store.CreateAgent(ctx, agent)
+
+	store.SubmitFeedback(ctx, &models.Feedback{AgentID: agent.ID, Rating: 5, TaskSuccess: true, TaskType: "review"})
+	store.SubmitFeedback(ctx, &models.Feedback{AgentID: agent.ID, Rating: 3, TaskSuccess: false})
+
+	rep, err := store.GetAgentReputation(ctx, agent.ID)
+	if err != nil || rep == nil {
+		t.Fatalf("GetAgentReputation failed: %v", err)
+	}
+	if rep.FeedbackCount != 2 || rep.AvgRating != 4 {
+		t.Errorf("got feedback_count=%d avg_rating=%v, want 2 and 4", rep.FeedbackCount, rep.AvgRating)
+	}
+	if rep.SuccessRate != 0.5 {
+		t.Errorf("SuccessRate = %v, want 0.5", rep.SuccessRate)
+	}
+	if rep.TaskTypeBreakdown["review"] != 1 {
+		t.Errorf("TaskTypeBreakdown = %v", rep.TaskTypeBreakdown)
+	}
+}
+
+func TestMemoryStoreReports(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("reported", 50)
+	store.CreateAgent(ctx, agent)
+
+	low := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeSpam, Severity: models.SeverityLow, Description: "spam"}
+	critical := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeHarmful, Severity: models.SeverityCritical, Description: "harm"}
+	store.CreateReport(ctx, low)
+	store.CreateReport(ctx, critical)
+
+	pending, _ := store.GetPendingReports(ctx)
+	if len(pending) != 2 || pending[0].ID != critical.ID {
+		t.Fatalf("GetPendingReports should order critical first, got %v", pending)
+	}
+	if pending[0].AgentName != "reported" {
+		t.Errorf("AgentName = %q, want reported", pending[0].AgentName)
+	}
+
+	// Only the judge holding a claim may resolve it
+	if err := store.ResolveReport(ctx, low.ID, models.ResolutionDismissed, "not spam", "judge"); !errors.Is(err, ErrReportConflict) {
+		t.Errorf("ResolveReport before claim = %v, want ErrReportConflict", err)
+	}
+	if err := store.ClaimReport(ctx, low.ID, "judge"); err != nil {
+		t.Fatalf("ClaimReport failed: %v", err)
+	}
+	if err := store.ClaimReport(ctx, low.ID, "judge"); err != nil {
+		t.Errorf("reclaiming own report = %v, want nil", err)
+	}
+	if err := store.ClaimReport(ctx, low.ID, "other"); !errors.Is(err, ErrReportConflict) || !strings.Contains(err.Error(), "claimed by judge") {
+		t.Errorf("ClaimReport by another judge = %v, want ErrReportConflict naming the holder", err)
+	}
+	if err := store.ResolveReport(ctx, low.ID, models.ResolutionDismissed, "not spam", "other"); !errors.Is(err, ErrReportConflict) {
+		t.Errorf("ResolveReport by another judge = %v, want ErrReportConflict", err)
+	}
+	if err := store.ResolveReport(ctx, low.ID, models.ResolutionDismissed, "not spam", "judge"); err != nil {
+		t.Fatalf("ResolveReport failed: %v", err)
+	}
+	if err := store.ClaimReport(ctx, low.ID, "judge"); !errors.Is(err, ErrReportConflict) {
+		t.Errorf("ClaimReport on a resolved report = %v, want ErrReportConflict", err)
+	}
+	if got, _ := store.GetReport(ctx, low.ID); got == nil || got.Status != models.ReportStatusResolved || got.AgentName != "reported" {
+		t.Errorf("GetReport = %+v, want the resolved report", got)
+	}
+	if err := store.ClaimReport(ctx, uuid.New(), "judge"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ClaimReport(missing) = %v, want ErrNotFound", err)
+	}
+
+	count, _ := store.CountPendingReportsForAgent(ctx, agent.ID)
+	if count != 1 {
+		t.Errorf("CountPendingReportsForAgent = %d, want 1", count)
+	}
+
+	store.RecordGovernanceAction(ctx, &models.GovernanceAction{AgentID: agent.ID, ActionType: models.ActionWarn, ActionBy: models.RoleJudge, Reason: "test"})
+	stats, _ := store.GetGovernanceStats(ctx)
+	if stats.PendingReports != 1 || stats.ActionsToday != 1 {
+		t.Errorf("GetGovernanceStats = %+v", stats)
+	}
+}
+
+func TestMemoryStoreApplyGovernanceAction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("governed", 50)
+	store.CreateAgent(ctx, agent)
+
+	active := models.StatusActive
+	action := &models.GovernanceAction{AgentID: agent.ID, ActionType: models.ActionQuarantine, ActionBy: models.RolePolice, Actor: "cop", PreviousStatus: &active}
+	if err := store.ApplyGovernanceAction(ctx, action, models.StatusQuarantined); err != nil {
+		t.Fatalf("ApplyGovernanceAction failed: %v", err)
+	}
+	if action.ID == uuid.Nil {
+		t.Error("action was not assigned an ID")
+	}
+
+	// A stale previous status changes nothing
+	stale := &models.GovernanceAction{AgentID: agent.ID, ActionType: models.ActionQuarantine, ActionBy: models.RolePolice, Actor: "cop", PreviousStatus: &active}
+	if err := store.ApplyGovernanceAction(ctx, stale, models.StatusBanned); !errors.Is(err, ErrRevisionConflict) {
+		t.Errorf("stale ApplyGovernanceAction = %v, want ErrRevisionConflict", err)
+	}
+	got, _ := store.GetAgent(ctx, "governed")
+	if got.Status != models.StatusQuarantined {
+		t.Errorf("status = %s, want quarantined", got.Status)
+	}
+	if stats, _ := store.GetGovernanceStats(ctx); stats.ActionsToday != 1 {
+		t.Errorf("ActionsToday = %d, want only the applied action", stats.ActionsToday)
+	}
+
+	actions, _ := store.ListGovernanceActions(ctx, agent.ID)
+	if len(actions) != 1 || actions[0].ID != action.ID || actions[0].AgentName != "governed" {
+		t.Errorf("ListGovernanceActions = %+v, want the applied action", actions)
+	}
+	if got, _ := store.GetGovernanceAction(ctx, action.ID); got == nil || got.Actor != "cop" {
+		t.Errorf("GetGovernanceAction = %+v, want the applied action", got)
+	}
+	if got, err := store.GetGovernanceAction(ctx, uuid.New()); got != nil || err != nil {
+		t.Errorf("GetGovernanceAction(unknown) = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestMemoryStoreApplyReputationAction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("rated", 50)
+	store.CreateAgent(ctx, agent)
+
+	previous := 50.0
+	action := &models.GovernanceAction{AgentID: agent.ID, ActionType: models.ActionPromote, ActionBy: models.RoleJudge, Actor: "judge", PreviousReputation: &previous}
+	if err := store.ApplyReputationAction(ctx, action, 60); err != nil {
+		t.Fatalf("ApplyReputationAction failed: %v", err)
+	}
+
+	// A stale previous reputation records nothing
+	stale := &models.GovernanceAction{AgentID: agent.ID, ActionType: models.ActionDemote, ActionBy: models.RoleJudge, Actor: "judge", PreviousReputation: &previous}
+	if err := store.ApplyReputationAction(ctx, stale, 40); !errors.Is(err, ErrRevisionConflict) {
+		t.Errorf("stale ApplyReputationAction = %v, want ErrRevisionConflict", err)
+	}
+	if got, _ := store.GetAgent(ctx, "rated"); got.ReputationScore != 60 {
+		t.Errorf("reputation = %v, want 60", got.ReputationScore)
+	}
+	if actions, _ := store.ListGovernanceActions(ctx, agent.ID); len(actions) != 1 {
+		t.Errorf("actions = %d, want only the applied one", len(actions))
+	}
+}
+
+func TestMemoryStoreRuleOnReport(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("ruled", 50)
+	store.CreateAgent(ctx, agent)
+	report := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeSpam, Severity: models.SeverityLow}
+	store.CreateReport(ctx, report)
+
+	active := models.StatusActive
+	quarantine := func() *models.GovernanceAction {
+		return &models.GovernanceAction{AgentID: agent.ID, ReportID: &report.ID, ActionType: models.ActionQuarantine, ActionBy: models.RoleJudge, Actor: "judge", PreviousStatus: &active}
+	}
+
+	// An unclaimed report changes nothing, not even the agent
+	if err := store.RuleOnReport(ctx, report.ID, models.ResolutionQuarantine, "n", "judge", quarantine(), models.StatusQuarantined); !errors.Is(err, ErrReportConflict) {
+		t.Errorf("RuleOnReport(unclaimed) = %v, want ErrReportConflict", err)
+	}
+	if got, _ := store.GetAgent(ctx, "ruled"); got.Status != models.StatusActive {
+		t.Errorf("status = %s after a failed ruling, want active", got.Status)
+	}
+	if actions, _ := store.ListGovernanceActions(ctx, agent.ID); len(actions) != 0 {
+		t.Errorf("actions = %+v after a failed ruling, want none", actions)
+	}
+
+	store.ClaimReport(ctx, report.ID, "judge")
+	if err := store.RuleOnReport(ctx, report.ID, models.ResolutionQuarantine, "n", "judge", quarantine(), models.StatusQuarantined); err != nil {
+		t.Fatalf("RuleOnReport failed: %v", err)
+	}
+	got, _ := store.GetReport(ctx, report.ID)
+	if got.Status != models.ReportStatusResolved {
+		t.Errorf("report status = %s, want resolved", got.Status)
+	}
+	if agent, _ := store.GetAgent(ctx, "ruled"); agent.Status != models.StatusQuarantined {
+		t.Errorf("status = %s, want quarantined", agent.Status)
+	}
+
+	// A second ruling cannot record another action
+	if err := store.RuleOnReport(ctx, report.ID, models.ResolutionQuarantine, "n", "judge", quarantine(), models.StatusQuarantined); !errors.Is(err, ErrReportConflict) {
+		t.Errorf("second RuleOnReport = %v, want ErrReportConflict", err)
+	}
+	if actions, _ := store.ListGovernanceActions(ctx, agent.ID); len(actions) != 1 {
+		t.Errorf("actions = %d, want one", len(actions))
+	}
+}
+
+func TestMemoryStoreAppeals(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("appealed", 50)
+	store.CreateAgent(ctx, agent)
+
+	appeal := &models.Appeal{AgentID: agent.ID, FiledBy: "owner", Statement: "fixed"}
+	if err := store.CreateAppeal(ctx, appeal, 2); err != nil {
+		t.Fatalf("CreateAppeal failed: %v", err)
+	}
+	if err := store.CreateAppeal(ctx, &models.Appeal{AgentID: agent.ID, FiledBy: "owner"}, 2); !errors.Is(err, ErrAppealConflict) {
+		t.Errorf("second pending CreateAppeal = %v, want ErrAppealConflict", err)
+	}
+
+	pending, _ := store.GetPendingAppeals(ctx)
+	if len(pending) != 1 || pending[0].AgentName != "appealed" {
+		t.Errorf("GetPendingAppeals = %+v, want the filed appeal", pending)
+	}
+
+	if err := store.DecideAppeal(ctx, appeal.ID, models.AppealStatusDenied, "no", "judge", nil); err != nil {
+		t.Fatalf("DecideAppeal failed: %v", err)
+	}
+	if err := store.DecideAppeal(ctx, appeal.ID, models.AppealStatusGranted, "yes", "judge", nil); !errors.Is(err, ErrAppealConflict) {
+		t.Errorf("second DecideAppeal = %v, want ErrAppealConflict", err)
+	}
+	if err := store.DecideAppeal(ctx, uuid.New(), models.AppealStatusGranted, "yes", "judge", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DecideAppeal(unknown) = %v, want ErrNotFound", err)
+	}
+	got, _ := store.GetAppeal(ctx, appeal.ID)
+	if got.Status != models.AppealStatusDenied || *got.DecidedBy != "judge" || got.DecidedAt == nil {
+		t.Errorf("decided appeal = %+v, want denied by judge", got)
+	}
+
+	// A decided appeal makes room for another, and every appeal counts
+	second := &models.Appeal{AgentID: agent.ID, FiledBy: "owner"}
+	if err := store.CreateAppeal(ctx, second, 2); err != nil {
+		t.Fatalf("CreateAppeal after decision failed: %v", err)
+	}
+
+	// A stale status change leaves both the agent and the appeal untouched
+	quarantined := models.StatusQuarantined
+	lift := StatusChange{
+		Action: &models.GovernanceAction{AgentID: agent.ID, ActionType: models.ActionUnquarantine, PreviousStatus: &quarantined},
+		Status: models.StatusActive,
+	}
+	if err := store.DecideAppeal(ctx, second.ID, models.AppealStatusGranted, "yes", "judge", []StatusChange{lift}); !errors.Is(err, ErrRevisionConflict) {
+		t.Errorf("DecideAppeal(stale change) = %v, want ErrRevisionConflict", err)
+	}
+	if got, _ := store.GetAppeal(ctx, second.ID); got.Status != models.AppealStatusPending {
+		t.Errorf("appeal status = %s, want pending after a failed decision", got.Status)
+	}
+	if got, _ := store.GetAgentByID(ctx, agent.ID); got.Status != models.StatusActive {
+		t.Errorf("agent status = %s, want it unchanged", got.Status)
+	}
+	store.DecideAppeal(ctx, second.ID, models.AppealStatusDenied, "no", "judge", nil)
+	if err := store.CreateAppeal(ctx, &models.Appeal{AgentID: agent.ID, FiledBy: "owner"}, 2); !errors.Is(err, ErrAppealConflict) || !strings.Contains(err.Error(), "all 2") {
+		t.Errorf("CreateAppeal over the limit = %v, want ErrAppealConflict", err)
+	}
+}
+
+func TestMemoryStoreListReports(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("reported", 50)
+	store.CreateAgent(ctx, agent)
+
+	severities := []models.Severity{models.SeverityLow, models.SeverityCritical, models.SeverityMedium, models.SeverityCritical}
+	reports := make([]*models.Report, len(severities))
+	for i, sev := range severities {
+		reports[i] = &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeSpam, Severity: sev, Description: "r"}
+		store.CreateReport(ctx, reports[i])
+	}
+	store.ClaimReport(ctx, reports[2].ID, "judge")
+	store.ResolveReport(ctx, reports[2].ID, models.ResolutionDismissed, "", "judge")
+
+	first, err := store.ListReports(ctx, models.ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListReports failed: %v", err)
+	}
+	// Critical first and, within a severity, oldest first
+	if len(first.Items) != 2 || first.Items[0].ID != reports[1].ID || first.Items[1].ID != reports[3].ID {
+		t.Fatalf("first page = %v, want both critical reports oldest first", first.Items)
+	}
+	rest, _ := store.ListReports(ctx, models.ListOptions{Limit: 2, Cursor: first.NextCursor})
+	if len(rest.Items) != 1 || rest.Items[0].ID != reports[0].ID || rest.NextCursor != "" {
+		t.Errorf("second page = %v (cursor %q), want only the low report", rest.Items, rest.NextCursor)
+	}
+
+	all, _ := store.ListReports(ctx, models.ListOptions{Filter: models.ListFilter{Status: models.StatusAll}})
+	if len(all.Items) != 4 {
+		t.Errorf("status all = %d reports, want 4", len(all.Items))
+	}
+}
+
+func TestMemoryStoreSkillRequestCache(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	agent := newTestAgent("cached", 50)
+	store.CreateAgent(ctx, agent)
+
+	if cached, _ := store.GetCachedAgentBySkills(ctx, []string{"Go", "SQL"}); cached != nil {
+		t.Fatal("expected empty cache")
+	}
+	store.CacheSkillRequest(ctx, []string{"Go", "SQL"}, agent.ID)
+
+	cached, err := store.GetCachedAgentBySkills(ctx, []string{"sql", " go "})
+	if err != nil || cached == nil || cached.ID != agent.ID {
+		t.Errorf("GetCachedAgentBySkills = %v, %v; want %s", cached, err, agent.Name)
+	}
+}
+
+func TestMemoryStoreTaskAliases(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	store.SetTaskAlias(ctx, &models.TaskAlias{Domain: "devops", Keyword: "k8s", Aliases: []string{"kubernetes"}})
+	store.SetTaskAlias(ctx, &models.TaskAlias{Domain: "code", Keyword: "lint"})
+	store.SetTaskAlias(ctx, &models.TaskAlias{Domain: "devops", Keyword: "k8s", Aliases: []string{"kubectl"}})
+
+	aliases, err := store.ListTaskAliases(ctx)
+	if err != nil || len(aliases) != 2 {
+		t.Fatalf("ListTaskAliases = %v, %v; want 2 rows", aliases, err)
+	}
+	if aliases[0].Keyword != "lint" || aliases[0].Aliases == nil {
+		t.Errorf("first alias = %+v, want lint with an empty alias list", aliases[0])
+	}
+	if got := aliases[1].Aliases; len(got) != 1 || got[0] != "kubectl" {
+		t.Errorf("k8s aliases = %v, want the replacement [kubectl]", got)
+	}
+
+	if deleted, _ := store.DeleteTaskAlias(ctx, "devops", "k8s"); !deleted {
+		t.Error("DeleteTaskAlias did not find k8s")
+	}
+	if deleted, _ := store.DeleteTaskAlias(ctx, "devops", "k8s"); deleted {
+		t.Error("DeleteTaskAlias deleted k8s twice")
+	}
+}
+
+func TestMemoryStoreSkillsAndCommands(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	skill := &models.Skill{Name: "kubectl", Description: "Kubernetes CLI", Category: "devops", Content: "kubectl get pods", Tags: []string{"k8s"}, Status: models.SkillStatusActive}
+	if err := store.CreateSkill(ctx, skill); err != nil {
+		t.Fatalf("CreateSkill failed: %v", err)
+	}
+	skills, _ := store.ListSkills(ctx, "devops", []string{"k8s"})
+	if len(skills) != 1 {
+		t.Errorf("ListSkills = %v, want [kubectl]", skills)
+	}
+	found, _ := store.SearchSkills(ctx, "get pods")
+	if len(found) != 1 {
+		t.Errorf("SearchSkills should match content, got %v", found)
+	}
+
+	cmd := &models.Command{Name: "review-pr", Description: "Review a PR", Prompt: "Review $ARGUMENTS", Category: "git", Status: models.CommandStatusActive,
+		Arguments: []models.Argument{{Name: "pr", Required: true}}}
+	if err := store.CreateCommand(ctx, cmd); err != nil {
+		t.Fatalf("CreateCommand failed: %v", err)
+	}
+	commands, _ := store.ListCommands(ctx, "git", nil)
+	if len(commands) != 1 || len(commands[0].Arguments) != 1 {
+		t.Errorf("ListCommands = %v", commands)
+	}
+	store.IncrementCommandUsage(ctx, cmd.ID)
+	got, _ := store.GetCommand(ctx, "review-pr")
+	if got.UsageCount != 1 {
+		t.Errorf("UsageCount = %d, want 1", got.UsageCount)
+	}
+}
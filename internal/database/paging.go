@@ -0,0 +1,574 @@
+// Package database provides PostgreSQL database operations
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/google/uuid"
+)
+
+// ============ Pagination ============
+// Lists page with keyset cursors: a cursor records the sort keys of the last
+// row returned and the next page starts strictly after it. Unlike OFFSET, a
+// page stays consistent while rows are added or removed, and deep pages cost
+// no more than the first. Cursors are opaque to callers and are only valid
+// for the sort, order and filters that produced them.
+
+const (
+	// DefaultPageSize is used when ListOptions.Limit is not positive
+	DefaultPageSize = 50
+	// MaxPageSize caps ListOptions.Limit
+	MaxPageSize = 200
+)
+
+// ErrInvalidCursor is returned for a cursor that is malformed or was issued
+// for a different sort, order or filter
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageKey holds the sortable fields of a row
+type pageKey struct {
+	Reputation float64   `json:"r,omitempty"`
+	Rating     float64   `json:"a,omitempty"`
+	Usage      int       `json:"u,omitempty"`
+	Severity   int       `json:"v,omitempty"`
+	Created    time.Time `json:"c"`
+	Updated    time.Time `json:"t"`
+	Name       string    `json:"n,omitempty"`
+	ID         uuid.UUID `json:"i"`
+}
+
+// sortField is one column of an ordering
+type sortField struct {
+	column string // SQL expression
+	cast   string // SQL type of the cursor parameter
+	desc   bool
+	value  func(k *pageKey) any
+}
+
+func (f sortField) reversed() sortField {
+	f.desc = !f.desc
+	return f
+}
+
+var (
+	sortReputation = sortField{"reputation_score", "float8", true, func(k *pageKey) any { return k.Reputation }}
+	sortRating     = sortField{"avg_rating", "float8", true, func(k *pageKey) any { return k.Rating }}
+	sortUsage      = sortField{"usage_count", "int", true, func(k *pageKey) any { return k.Usage }}
+	sortCreated    = sortField{"created_at", "timestamptz", true, func(k *pageKey) any { return k.Created }}
+	sortUpdated    = sortField{"updated_at", "timestamptz", true, func(k *pageKey) any { return k.Updated }}
+	sortName       = sortField{"name", "text", false, func(k *pageKey) any { return k.Name }}
+	sortID         = sortField{"id", "uuid", false, func(k *pageKey) any { return k.ID }}
+
+	// sortSeverity ranks critical reports highest
+	sortSeverity = sortField{`CASE r.severity
+		WHEN 'critical' THEN 4
+		WHEN 'high' THEN 3
+		WHEN 'medium' THEN 2
+		ELSE 1
+	END`, "int", true, func(k *pageKey) any { return k.Severity }}
+	sortReportCreated = sortField{"r.created_at", "timestamptz", true, func(k *pageKey) any { return k.Created }}
+	sortReportID      = sortField{"r.id", "uuid", false, func(k *pageKey) any { return k.ID }}
+)
+
+// sortSpec lists the orderings a kind of list offers, each in its default direction
+type sortSpec struct {
+	def   string
+	sorts map[string][]sortField
+	id    sortField
+}
+
+// catalogSorts orders agents, skills and commands
+var catalogSorts = sortSpec{
+	def: "reputation",
+	sorts: map[string][]sortField{
+		"reputation": {sortReputation, sortUsage},
+		"rating":     {sortRating, sortUsage},
+		"usage":      {sortUsage, sortReputation},
+		"created":    {sortCreated},
+		"updated":    {sortUpdated},
+		"name":       {sortName},
+	},
+	id: sortID,
+}
+
+// reportSorts orders reports: by default the most severe first and, within a
+// severity, the longest waiting first
+var reportSorts = sortSpec{
+	def: "severity",
+	sorts: map[string][]sortField{
+		"severity": {sortSeverity, sortReportCreated.reversed()},
+		"created":  {sortReportCreated},
+	},
+	id: sortReportID,
+}
+
+// CatalogSorts lists the sort_by values accepted for agents, skills and commands
+func CatalogSorts() []string { return catalogSorts.names() }
+
+// ReportSorts lists the sort_by values accepted for reports
+func ReportSorts() []string { return reportSorts.names() }
+
+func (s sortSpec) names() []string {
+	names := make([]string, 0, len(s.sorts))
+	for name := range s.sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultOrder is the direction a sort runs in unless reversed
+func (s sortSpec) defaultOrder(sortBy string) string {
+	if s.sorts[sortBy][0].desc {
+		return models.SortDesc
+	}
+	return models.SortAsc
+}
+
+func agentKey(a *models.Agent) pageKey {
+	return pageKey{Reputation: a.ReputationScore, Rating: a.AvgRating, Usage: a.UsageCount,
+		Created: a.CreatedAt, Updated: a.UpdatedAt, Name: a.Name, ID: a.ID}
+}
+
+func skillKey(s *models.Skill) pageKey {
+	return pageKey{Reputation: s.ReputationScore, Rating: s.AvgRating, Usage: s.UsageCount,
+		Created: s.CreatedAt, Updated: s.UpdatedAt, Name: s.Name, ID: s.ID}
+}
+
+func commandKey(c *models.Command) pageKey {
+	return pageKey{Reputation: c.ReputationScore, Rating: c.AvgRating, Usage: c.UsageCount,
+		Created: c.CreatedAt, Updated: c.UpdatedAt, Name: c.Name, ID: c.ID}
+}
+
+// reportKey weights severity like sortSeverity, from severityRank's 1 for critical
+func reportKey(r *models.Report) pageKey {
+	return pageKey{Severity: 5 - severityRank(r.Severity), Created: r.CreatedAt, ID: r.ID}
+}
+
+// cursor is the decoded form of an opaque page cursor. Lists resume after
+// After; searches, which rank by relevance, resume at Offset.
+type cursor struct {
+	Sort   string   `json:"s,omitempty"`
+	Order  string   `json:"o,omitempty"`
+	Query  uint64   `json:"q"`
+	After  *pageKey `json:"k,omitempty"`
+	Offset int      `json:"n,omitempty"`
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// fingerprint identifies a query so a cursor cannot be replayed against another
+func fingerprint(parts ...any) uint64 {
+	h := fnv.New64a()
+	for _, p := range parts {
+		data, _ := json.Marshal(p)
+		h.Write(data)
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// pageSize clamps a requested page size
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(limit, MaxPageSize)
+}
+
+// listPlan is a resolved ListOptions: the ordering to apply, the page size
+// and where the page starts
+type listPlan struct {
+	sortBy string
+	order  string
+	fields []sortField
+	limit  int
+	query  uint64
+	after  *pageKey
+}
+
+// planList validates opts for a list of kind ordered by spec
+func planList(kind string, spec sortSpec, opts models.ListOptions) (*listPlan, error) {
+	p := &listPlan{sortBy: opts.SortBy, order: strings.ToLower(opts.Order), limit: pageSize(opts.Limit)}
+	if p.sortBy == "" {
+		p.sortBy = spec.def
+	}
+	fields, ok := spec.sorts[p.sortBy]
+	if !ok {
+		return nil, fmt.Errorf("cannot sort %s by %q (use one of: %s)", kind, p.sortBy, strings.Join(spec.names(), ", "))
+	}
+	def := spec.defaultOrder(p.sortBy)
+	switch p.order {
+	case "":
+		p.order = def
+	case models.SortAsc, models.SortDesc:
+	default:
+		return nil, fmt.Errorf("order must be %q or %q", models.SortAsc, models.SortDesc)
+	}
+
+	p.fields = append(append([]sortField(nil), fields...), spec.id)
+	if p.order != def {
+		for i := range p.fields {
+			p.fields[i] = p.fields[i].reversed()
+		}
+	}
+
+	p.query = fingerprint(kind, opts.Filter)
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.After == nil || c.Sort != p.sortBy || c.Order != p.order || c.Query != p.query {
+			return nil, ErrInvalidCursor
+		}
+		p.after = c.After
+	}
+	return p, nil
+}
+
+// orderSQL returns the ORDER BY list for the plan
+func (p *listPlan) orderSQL() string {
+	parts := make([]string, len(p.fields))
+	for i, f := range p.fields {
+		dir := "ASC"
+		if f.desc {
+			dir = "DESC"
+		}
+		parts[i] = f.column + " " + dir
+	}
+	return strings.Join(parts, ", ")
+}
+
+// afterSQL returns a condition selecting rows that sort after the cursor,
+// expanded lexicographically: (a > x) OR (a = x AND b > y) OR ...
+func (p *listPlan) afterSQL(w *whereBuilder) {
+	if p.after == nil {
+		return
+	}
+	params := make([]string, len(p.fields))
+	for i, f := range p.fields {
+		w.args = append(w.args, f.value(p.after))
+		params[i] = fmt.Sprintf("$%d::%s", len(w.args), f.cast)
+	}
+
+	var alternatives []string
+	for i, f := range p.fields {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, p.fields[j].column+" = "+params[j])
+		}
+		op := ">"
+		if f.desc {
+			op = "<"
+		}
+		terms = append(terms, f.column+" "+op+" "+params[i])
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	w.conds = append(w.conds, "("+strings.Join(alternatives, " OR ")+")")
+}
+
+// compare orders two rows the way orderSQL does
+func (p *listPlan) compare(a, b *pageKey) int {
+	for _, f := range p.fields {
+		c := compareValues(f.value(a), f.value(b))
+		if f.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case float64:
+		return cmpOrdered(a, b.(float64))
+	case int:
+		return cmpOrdered(a, b.(int))
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case uuid.UUID:
+		bb := b.(uuid.UUID)
+		return bytes.Compare(a[:], bb[:])
+	}
+	return 0
+}
+
+func cmpOrdered[T float64 | int](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// page cuts one page from rows that are already in plan order and start
+// after the cursor; keys holds each row's sort fields. The query asks for
+// one row more than the limit so a following page can be detected.
+func page[T any](p *listPlan, rows []T, keys []pageKey) models.Page[T] {
+	if rows == nil {
+		rows = []T{}
+	}
+	if len(rows) <= p.limit {
+		return models.Page[T]{Items: rows}
+	}
+	last := keys[p.limit-1]
+	next := cursor{Sort: p.sortBy, Order: p.order, Query: p.query, After: &last}
+	return models.Page[T]{Items: rows[:p.limit], NextCursor: next.encode()}
+}
+
+// sortedPage orders rows by plan, skips those up to the cursor and cuts a page.
+// It is the in-memory equivalent of afterSQL, orderSQL and a LIMIT.
+func sortedPage[T any](p *listPlan, rows []T, keys []pageKey) models.Page[T] {
+	idx := make([]int, len(rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return p.compare(&keys[idx[i]], &keys[idx[j]]) < 0 })
+
+	var out []T
+	var outKeys []pageKey
+	for _, i := range idx {
+		if p.after != nil && p.compare(&keys[i], p.after) <= 0 {
+			continue
+		}
+		out = append(out, rows[i])
+		outKeys = append(outKeys, keys[i])
+		if len(out) > p.limit {
+			break
+		}
+	}
+	return page(p, out, outKeys)
+}
+
+// ============ Search Cursors ============
+// Search results are ranked by relevance rather than a stable key, so their
+// cursors carry an offset into the ranking instead.
+
+// SearchOffset decodes a search cursor issued by NextSearchCursor for the
+// same scope, text and filter
+func SearchOffset(scope string, q models.SearchQuery, cursorText string) (int, error) {
+	if cursorText == "" {
+		return 0, nil
+	}
+	c, err := decodeCursor(cursorText)
+	if err != nil {
+		return 0, err
+	}
+	if c.After != nil || c.Offset <= 0 || c.Query != searchFingerprint(scope, q) {
+		return 0, ErrInvalidCursor
+	}
+	return c.Offset, nil
+}
+
+// NextSearchCursor returns the cursor for the page after one that returned
+// n results for q, or "" when that page was not full
+func NextSearchCursor(scope string, q models.SearchQuery, n int) string {
+	if n < searchLimit(q.Limit) {
+		return ""
+	}
+	return cursor{Query: searchFingerprint(scope, q), Offset: q.Offset + n}.encode()
+}
+
+func searchFingerprint(scope string, q models.SearchQuery) uint64 {
+	return fingerprint(scope, q.Text, q.Limit, q.Filter)
+}
+
+// ============ Filters ============
+
+// whereBuilder collects AND-ed conditions and their numbered parameters
+type whereBuilder struct {
+	conds []string
+	args  []any
+}
+
+// add appends cond, whose %d is replaced by the number of the new parameter arg
+func (w *whereBuilder) add(cond string, arg any) {
+	w.args = append(w.args, arg)
+	w.conds = append(w.conds, fmt.Sprintf(cond, len(w.args)))
+}
+
+func (w *whereBuilder) sql() string {
+	if len(w.conds) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(w.conds, " AND ")
+}
+
+// checkFilter rejects filter fields that do not apply to kind
+func checkFilter(kind string, f models.ListFilter, unsupported ...string) error {
+	set := map[string]bool{
+		"tags":         len(f.Tags) > 0,
+		"category":     f.Category != "",
+		"model":        f.Model != "",
+		"is_generated": f.IsGenerated != nil,
+	}
+	for _, name := range unsupported {
+		if set[name] {
+			return fmt.Errorf("%s cannot be filtered by %s", kind, name)
+		}
+	}
+	return nil
+}
+
+// catalogFilter validates f for agents, skills or commands
+func catalogFilter(kind models.EmbeddingKind, f models.ListFilter) error {
+	if kind == models.EmbeddingKindAgent {
+		return checkFilter("agents", f, "category")
+	}
+	return checkFilter(string(kind)+"s", f, "model", "is_generated")
+}
+
+// catalogWhere adds conditions for f on an agents, skills or commands table.
+// An empty status means active rows only.
+func catalogWhere(w *whereBuilder, kind models.EmbeddingKind, f models.ListFilter) error {
+	if err := catalogFilter(kind, f); err != nil {
+		return err
+	}
+	switch f.Status {
+	case "":
+		w.conds = append(w.conds, "status = 'active'")
+	case models.StatusAll:
+	default:
+		w.add("status = $%d", f.Status)
+	}
+	if len(f.Tags) > 0 {
+		if kind == models.EmbeddingKindAgent {
+			// Check both skills array and metadata->tags JSON array
+			w.add("(skills && $%[1]d OR metadata->'tags' ?| $%[1]d)", f.Tags)
+		} else {
+			w.add("tags && $%d", f.Tags)
+		}
+	}
+	if f.Category != "" {
+		w.add("category = $%d", f.Category)
+	}
+	if f.Model != "" {
+		w.add("model = $%d", f.Model)
+	}
+	if f.IsGenerated != nil {
+		w.add("is_generated = $%d", *f.IsGenerated)
+	}
+	createdWhere(w, "created_at", f)
+	return nil
+}
+
+// reportWhere adds conditions for f on reports aliased r. An empty status
+// means reports still awaiting a ruling.
+func reportWhere(w *whereBuilder, f models.ListFilter) error {
+	if err := checkFilter("reports", f, "tags", "category", "model", "is_generated"); err != nil {
+		return err
+	}
+	switch f.Status {
+	case "":
+		w.conds = append(w.conds, "r.status IN ('pending', 'reviewing')")
+	case models.StatusAll:
+	default:
+		w.add("r.status = $%d", f.Status)
+	}
+	createdWhere(w, "r.created_at", f)
+	return nil
+}
+
+func createdWhere(w *whereBuilder, column string, f models.ListFilter) {
+	if f.CreatedAfter != nil {
+		w.add(column+" >= $%d", *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		w.add(column+" < $%d", *f.CreatedBefore)
+	}
+}
+
+// statusMatches applies a ListFilter status in memory; defaults are the
+// statuses an empty filter selects
+func statusMatches(filter, status string, defaults ...string) bool {
+	switch filter {
+	case "":
+		return contains(defaults, status)
+	case models.StatusAll:
+		return true
+	default:
+		return filter == status
+	}
+}
+
+// createdMatches applies a ListFilter creation range in memory
+func createdMatches(f models.ListFilter, created time.Time) bool {
+	if f.CreatedAfter != nil && created.Before(*f.CreatedAfter) {
+		return false
+	}
+	return f.CreatedBefore == nil || created.Before(*f.CreatedBefore)
+}
+
+// agentMatches applies f to an agent the way catalogWhere does
+func agentMatches(a *models.Agent, f models.ListFilter) bool {
+	if !statusMatches(f.Status, string(a.Status), string(models.StatusActive)) || !createdMatches(f, a.CreatedAt) {
+		return false
+	}
+	if len(f.Tags) > 0 && !overlaps(a.Skills, f.Tags) && !overlaps(metadataTags(a.Metadata), f.Tags) {
+		return false
+	}
+	if f.Model != "" && a.Model != f.Model {
+		return false
+	}
+	return f.IsGenerated == nil || a.IsGenerated == *f.IsGenerated
+}
+
+// skillMatches applies f to a skill the way catalogWhere does
+func skillMatches(s *models.Skill, f models.ListFilter) bool {
+	if !statusMatches(f.Status, string(s.Status), string(models.SkillStatusActive)) || !createdMatches(f, s.CreatedAt) {
+		return false
+	}
+	if len(f.Tags) > 0 && !overlaps(s.Tags, f.Tags) {
+		return false
+	}
+	return f.Category == "" || s.Category == f.Category
+}
+
+// commandMatches applies f to a command the way catalogWhere does
+func commandMatches(c *models.Command, f models.ListFilter) bool {
+	if !statusMatches(f.Status, string(c.Status), string(models.CommandStatusActive)) || !createdMatches(f, c.CreatedAt) {
+		return false
+	}
+	if len(f.Tags) > 0 && !overlaps(c.Tags, f.Tags) {
+		return false
+	}
+	return f.Category == "" || c.Category == f.Category
+}
+
+// reportMatches applies f to a report the way reportWhere does
+func reportMatches(r *models.Report, f models.ListFilter) bool {
+	return statusMatches(f.Status, string(r.Status), string(models.ReportStatusPending), string(models.ReportStatusReviewing)) &&
+		createdMatches(f, r.CreatedAt)
+}
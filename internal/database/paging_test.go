@@ -0,0 +1,57 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+)
+
+func TestListPlanKeysetSQL(t *testing.T) {
+	p, err := planList("agents", catalogSorts, models.ListOptions{SortBy: "rating", Order: models.SortAsc})
+	if err != nil {
+		t.Fatalf("planList failed: %v", err)
+	}
+	if got, want := p.orderSQL(), "avg_rating ASC, usage_count ASC, id DESC"; got != want {
+		t.Errorf("orderSQL = %q, want %q", got, want)
+	}
+
+	p.after = &pageKey{Rating: 4.5, Usage: 7}
+	w := &whereBuilder{args: []any{"existing"}}
+	p.afterSQL(w)
+	want := "((avg_rating > $2::float8) OR (avg_rating = $2::float8 AND usage_count > $3::int) OR " +
+		"(avg_rating = $2::float8 AND usage_count = $3::int AND id < $4::uuid))"
+	if got := w.sql(); got != want {
+		t.Errorf("afterSQL =\n%s\nwant\n%s", got, want)
+	}
+	if len(w.args) != 4 || w.args[1] != 4.5 || w.args[2] != 7 {
+		t.Errorf("args = %v", w.args)
+	}
+}
+
+func TestSearchCursor(t *testing.T) {
+	q := models.SearchQuery{Text: "kubernetes", Limit: 10}
+
+	if next := NextSearchCursor("agents", q, 9); next != "" {
+		t.Errorf("short page returned cursor %q", next)
+	}
+	next := NextSearchCursor("agents", q, 10)
+	offset, err := SearchOffset("agents", q, next)
+	if err != nil || offset != 10 {
+		t.Fatalf("SearchOffset = %d, %v; want 10", offset, err)
+	}
+
+	q.Offset = offset
+	offset, _ = SearchOffset("agents", q, NextSearchCursor("agents", q, 10))
+	if offset != 20 {
+		t.Errorf("second offset = %d, want 20", offset)
+	}
+
+	other := models.SearchQuery{Text: "terraform", Limit: 10}
+	if _, err := SearchOffset("agents", other, next); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor for another query: err = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := SearchOffset("skills", q, next); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor for another kind: err = %v, want ErrInvalidCursor", err)
+	}
+}
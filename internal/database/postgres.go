@@ -14,6 +14,7 @@ import (
 	"github.com/aminghadersohi/agentmcp/internal/models"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pgvector/pgvector-go"
 )
@@ -76,24 +77,41 @@ func (db *DB) CreateAgent(ctx context.Context, agent *models.Agent) error {
 	toolsJSON, _ := json.Marshal(agent.Tools)
 	metadataJSON, _ := json.Marshal(agent.Metadata)
 
-	_, err := db.pool.Exec(ctx, `
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
 		INSERT INTO agents (
 			id, name, version, description, model, tools, metadata, prompt,
 			embedding, skills, reputation_score, status, is_system, is_generated,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, embedding_model
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8,
 			$9, $10, $11, $12, $13, $14,
-			$15, $16, $17
+			$15, $16, $17, $18
 		)
 	`,
 		agent.ID, agent.Name, agent.Version, agent.Description, agent.Model,
 		toolsJSON, metadataJSON, agent.Prompt,
 		agent.Embedding, agent.Skills, agent.ReputationScore, agent.Status,
 		agent.IsSystem, agent.IsGenerated, agent.CreatedBy, agent.CreatedAt, agent.UpdatedAt,
+		agent.EmbeddingModel,
 	)
+	if err != nil {
+		return err
+	}
+	if err := insertAgentRevision(ctx, tx, agent); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
 
-	return err
+	agent.Revision = 1
+	return nil
 }
 
 // GetAgent retrieves an agent by name
@@ -104,7 +122,8 @@ func (db *DB) GetAgent(ctx context.Context, name string) (*models.Agent, error)
 	err := db.pool.QueryRow(ctx, `
 		SELECT id, name, version, description, model, tools, metadata, prompt,
 			   embedding, skills, reputation_score, usage_count, feedback_count,
-			   avg_rating, status, is_system, is_generated, created_by, created_at, updated_at
+			   avg_rating, status, is_system, is_generated, created_by, created_at, updated_at,
+			   revision, embedding_model
 		FROM agents WHERE name = $1
 	`, name).Scan(
 		&agent.ID, &agent.Name, &agent.Version, &agent.Description, &agent.Model,
@@ -112,6 +131,7 @@ func (db *DB) GetAgent(ctx context.Context, name string) (*models.Agent, error)
 		&agent.Embedding, &agent.Skills, &agent.ReputationScore, &agent.UsageCount,
 		&agent.FeedbackCount, &agent.AvgRating, &agent.Status, &agent.IsSystem,
 		&agent.IsGenerated, &agent.CreatedBy, &agent.CreatedAt, &agent.UpdatedAt,
+		&agent.Revision, &agent.EmbeddingModel,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -134,7 +154,8 @@ func (db *DB) GetAgentByID(ctx context.Context, id uuid.UUID) (*models.Agent, er
 	err := db.pool.QueryRow(ctx, `
 		SELECT id, name, version, description, model, tools, metadata, prompt,
 			   embedding, skills, reputation_score, usage_count, feedback_count,
-			   avg_rating, status, is_system, is_generated, created_by, created_at, updated_at
+			   avg_rating, status, is_system, is_generated, created_by, created_at, updated_at,
+			   revision, embedding_model
 		FROM agents WHERE id = $1
 	`, id).Scan(
 		&agent.ID, &agent.Name, &agent.Version, &agent.Description, &agent.Model,
@@ -142,6 +163,7 @@ func (db *DB) GetAgentByID(ctx context.Context, id uuid.UUID) (*models.Agent, er
 		&agent.Embedding, &agent.Skills, &agent.ReputationScore, &agent.UsageCount,
 		&agent.FeedbackCount, &agent.AvgRating, &agent.Status, &agent.IsSystem,
 		&agent.IsGenerated, &agent.CreatedBy, &agent.CreatedAt, &agent.UpdatedAt,
+		&agent.Revision, &agent.EmbeddingModel,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -196,6 +218,52 @@ func (db *DB) ListAgents(ctx context.Context, tags []string) ([]models.AgentSumm
 	return agents, nil
 }
 
+// ListAgentsPage returns one page of agents matching opts.Filter in the requested order
+func (db *DB) ListAgentsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.AgentSummary], error) {
+	p, err := planList("agents", catalogSorts, opts)
+	if err != nil {
+		return models.Page[models.AgentSummary]{}, err
+	}
+	w := &whereBuilder{}
+	if err := catalogWhere(w, models.EmbeddingKindAgent, opts.Filter); err != nil {
+		return models.Page[models.AgentSummary]{}, err
+	}
+	p.afterSQL(w)
+
+	rows, err := db.pool.Query(ctx, fmt.Sprintf(`
+		SELECT id, name, version, description, skills, reputation_score, avg_rating, usage_count, status,
+			   created_at, updated_at
+		FROM agents
+		WHERE %s
+		ORDER BY %s
+		LIMIT %d
+	`, w.sql(), p.orderSQL(), p.limit+1), w.args...)
+	if err != nil {
+		return models.Page[models.AgentSummary]{}, err
+	}
+	defer rows.Close()
+
+	var agents []models.AgentSummary
+	var keys []pageKey
+	for rows.Next() {
+		var a models.AgentSummary
+		var k pageKey
+		err := rows.Scan(&a.ID, &a.Name, &a.Version, &a.Description, &a.Skills,
+			&a.ReputationScore, &a.AvgRating, &a.UsageCount, &a.Status, &k.Created, &k.Updated)
+		if err != nil {
+			return models.Page[models.AgentSummary]{}, err
+		}
+		k.ID, k.Name, k.Reputation, k.Rating, k.Usage = a.ID, a.Name, a.ReputationScore, a.AvgRating, a.UsageCount
+		agents = append(agents, a)
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.AgentSummary]{}, err
+	}
+
+	return page(p, agents, keys), nil
+}
+
 // escapeLikePattern escapes SQL LIKE pattern special characters
 func escapeLikePattern(s string) string {
 	s = strings.ReplaceAll(s, "\\", "\\\\")
@@ -276,6 +344,85 @@ func (db *DB) FindSimilarAgents(ctx context.Context, embedding pgvector.Vector,
 	return results, nil
 }
 
+// UpdateAgent saves content changes to an agent if its revision still matches
+// expectedRevision, and appends agent.Version to its history.
+// On success agent.Revision and agent.UpdatedAt are refreshed.
+func (db *DB) UpdateAgent(ctx context.Context, agent *models.Agent, expectedRevision int) error {
+	toolsJSON, _ := json.Marshal(agent.Tools)
+	metadataJSON, _ := json.Marshal(agent.Metadata)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE agents SET
+			name = $1, version = $2, description = $3, model = $4, tools = $5,
+			metadata = $6, prompt = $7, embedding = $8, skills = $9,
+			embedding_model = $10, revision = revision + 1, updated_at = NOW()
+		WHERE id = $11 AND revision = $12
+		RETURNING revision, updated_at
+	`,
+		agent.Name, agent.Version, agent.Description, agent.Model, toolsJSON,
+		metadataJSON, agent.Prompt, agent.Embedding, agent.Skills,
+		agent.EmbeddingModel, agent.ID, expectedRevision,
+	).Scan(&agent.Revision, &agent.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return db.revisionError(ctx, agent.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := insertAgentRevision(ctx, tx, agent); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ArchiveAgent soft-deletes an agent if its revision still matches expectedRevision.
+// Archived agents keep their history but no longer appear in listings or searches.
+func (db *DB) ArchiveAgent(ctx context.Context, agentID uuid.UUID, expectedRevision int) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE agents SET status = 'archived', revision = revision + 1, updated_at = NOW()
+		WHERE id = $1 AND revision = $2
+	`, agentID, expectedRevision)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return db.revisionError(ctx, agentID)
+	}
+
+	// Cached skill lookups must not resolve to an archived agent
+	if _, err := tx.Exec(ctx, `DELETE FROM skill_requests WHERE agent_id = $1`, agentID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// revisionError explains why a revision-guarded update matched no rows
+func (db *DB) revisionError(ctx context.Context, agentID uuid.UUID) error {
+	var current int
+	err := db.pool.QueryRow(ctx, `SELECT revision FROM agents WHERE id = $1`, agentID).Scan(&current)
+	if err == pgx.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: current revision is %d", ErrRevisionConflict, current)
+}
+
 // UpdateAgentStatus updates an agent's status
 func (db *DB) UpdateAgentStatus(ctx context.Context, agentID uuid.UUID, status models.AgentStatus) error {
 	_, err := db.pool.Exec(ctx, `
@@ -553,6 +700,117 @@ func (db *DB) GetPendingReports(ctx context.Context) ([]models.Report, error) {
 	return reports, nil
 }
 
+// ListReports returns one page of reports matching opts.Filter in the requested order.
+// With no status filter it lists reports awaiting review.
+func (db *DB) ListReports(ctx context.Context, opts models.ListOptions) (models.Page[models.Report], error) {
+	p, err := planList("reports", reportSorts, opts)
+	if err != nil {
+		return models.Page[models.Report]{}, err
+	}
+	w := &whereBuilder{}
+	if err := reportWhere(w, opts.Filter); err != nil {
+		return models.Page[models.Report]{}, err
+	}
+	p.afterSQL(w)
+
+	rows, err := db.pool.Query(ctx, fmt.Sprintf(`
+		SELECT r.id, r.agent_id, a.name, r.reported_by, r.report_type, r.severity,
+			   r.description, r.evidence, r.status, r.reviewed_by, r.resolution,
+			   r.resolution_note, r.created_at, r.resolved_at
+		FROM reports r
+		JOIN agents a ON r.agent_id = a.id
+		WHERE %s
+		ORDER BY %s
+		LIMIT %d
+	`, w.sql(), p.orderSQL(), p.limit+1), w.args...)
+	if err != nil {
+		return models.Page[models.Report]{}, err
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	var keys []pageKey
+	for rows.Next() {
+		var r models.Report
+		var evidenceJSON []byte
+		err := rows.Scan(&r.ID, &r.AgentID, &r.AgentName, &r.ReportedBy, &r.ReportType,
+			&r.Severity, &r.Description, &evidenceJSON, &r.Status, &r.ReviewedBy,
+			&r.Resolution, &r.ResolutionNote, &r.CreatedAt, &r.ResolvedAt)
+		if err != nil {
+			return models.Page[models.Report]{}, err
+		}
+		json.Unmarshal(evidenceJSON, &r.Evidence)
+		reports = append(reports, r)
+		keys = append(keys, reportKey(&r))
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.Report]{}, err
+	}
+
+	return page(p, reports, keys), nil
+}
+
+// GetReport retrieves a report by ID
+func (db *DB) GetReport(ctx context.Context, reportID uuid.UUID) (*models.Report, error) {
+	var r models.Report
+	var evidenceJSON []byte
+	err := db.pool.QueryRow(ctx, `
+		SELECT r.id, r.agent_id, a.name, r.reported_by, r.report_type, r.severity,
+			   r.description, r.evidence, r.status, r.reviewed_by, r.resolution,
+			   r.resolution_note, r.created_at, r.resolved_at
+		FROM reports r
+		JOIN agents a ON r.agent_id = a.id
+		WHERE r.id = $1
+	`, reportID).Scan(&r.ID, &r.AgentID, &r.AgentName, &r.ReportedBy, &r.ReportType,
+		&r.Severity, &r.Description, &evidenceJSON, &r.Status, &r.ReviewedBy,
+		&r.Resolution, &r.ResolutionNote, &r.CreatedAt, &r.ResolvedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(evidenceJSON, &r.Evidence)
+	return &r, nil
+}
+
+// reportConflict explains why a conditional report update matched no row
+func (db *DB) reportConflict(ctx context.Context, reportID uuid.UUID, reviewer string) error {
+	var status models.ReportStatus
+	var reviewedBy *string
+	err := db.pool.QueryRow(ctx, `SELECT status, reviewed_by FROM reports WHERE id = $1`, reportID).Scan(&status, &reviewedBy)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("report %s: %w", reportID, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case status == models.ReportStatusResolved:
+		return fmt.Errorf("%w: report is already resolved", ErrReportConflict)
+	case status == models.ReportStatusPending:
+		return fmt.Errorf("%w: report has not been claimed", ErrReportConflict)
+	case reviewedBy != nil && *reviewedBy != reviewer:
+		return fmt.Errorf("%w: report is claimed by %s", ErrReportConflict, *reviewedBy)
+	}
+	return fmt.Errorf("%w: report changed concurrently", ErrReportConflict)
+}
+
+// ClaimReport moves a pending report to reviewing by reviewer
+func (db *DB) ClaimReport(ctx context.Context, reportID uuid.UUID, reviewer string) error {
+	tag, err := db.pool.Exec(ctx, `
+		UPDATE reports SET status = 'reviewing', reviewed_by = $1
+		WHERE id = $2 AND (status = 'pending' OR (status = 'reviewing' AND reviewed_by = $1))
+	`, reviewer, reportID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return db.reportConflict(ctx, reportID, reviewer)
+	}
+	return nil
+}
+
 // UpdateReportStatus updates a report's status
 func (db *DB) UpdateReportStatus(ctx context.Context, reportID uuid.UUID, status models.ReportStatus, reviewedBy string) error {
 	_, err := db.pool.Exec(ctx, `
@@ -564,36 +822,223 @@ func (db *DB) UpdateReportStatus(ctx context.Context, reportID uuid.UUID, status
 // ResolveReport resolves a report
 func (db *DB) ResolveReport(ctx context.Context, reportID uuid.UUID, resolution models.Resolution, note string, resolvedBy string) error {
 	now := time.Now()
-	_, err := db.pool.Exec(ctx, `
+	tag, err := db.pool.Exec(ctx, `
 		UPDATE reports SET status = 'resolved', resolution = $1, resolution_note = $2,
 						   reviewed_by = $3, resolved_at = $4
-		WHERE id = $5
+		WHERE id = $5 AND status = 'reviewing' AND reviewed_by = $3
 	`, resolution, note, resolvedBy, now, reportID)
-	return err
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return db.reportConflict(ctx, reportID, resolvedBy)
+	}
+	return nil
 }
 
 // RecordGovernanceAction records an action taken on an agent
 func (db *DB) RecordGovernanceAction(ctx context.Context, action *models.GovernanceAction) error {
+	return insertGovernanceAction(ctx, db.pool, action)
+}
+
+// execer runs a statement on the pool or within a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// insertGovernanceAction assigns action an ID and timestamp and stores it
+func insertGovernanceAction(ctx context.Context, db execer, action *models.GovernanceAction) error {
 	action.ID = uuid.New()
 	action.CreatedAt = time.Now()
 
-	_, err := db.pool.Exec(ctx, `
-		INSERT INTO governance_actions (id, agent_id, report_id, action_type, action_by, reason,
-										previous_status, previous_reputation, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	_, err := db.Exec(ctx, `
+		INSERT INTO governance_actions (id, agent_id, report_id, action_type, action_by, actor, reason,
+										previous_status, previous_reputation, reverts_action_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`,
-		action.ID, action.AgentID, action.ReportID, action.ActionType, action.ActionBy,
-		action.Reason, action.PreviousStatus, action.PreviousReputation, action.CreatedAt,
+		action.ID, action.AgentID, action.ReportID, action.ActionType, action.ActionBy, action.Actor,
+		action.Reason, action.PreviousStatus, action.PreviousReputation, action.RevertsActionID, action.CreatedAt,
 	)
 	return err
 }
 
+// ApplyGovernanceAction records action and changes its agent's status in one
+// transaction
+func (db *DB) ApplyGovernanceAction(ctx context.Context, action *models.GovernanceAction, status models.AgentStatus) error {
+	if action.PreviousStatus == nil {
+		return fmt.Errorf("action has no previous status")
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := applyAction(ctx, tx, action, status); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// applyAction records action within tx and, unless status is empty, moves
+// its agent from action.PreviousStatus to status
+func applyAction(ctx context.Context, tx pgx.Tx, action *models.GovernanceAction, status models.AgentStatus) error {
+	if status != "" {
+		if action.PreviousStatus == nil {
+			return fmt.Errorf("action has no previous status")
+		}
+		tag, err := tx.Exec(ctx, `
+			UPDATE agents SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3
+		`, status, action.AgentID, *action.PreviousStatus)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			var current models.AgentStatus
+			err := tx.QueryRow(ctx, `SELECT status FROM agents WHERE id = $1`, action.AgentID).Scan(&current)
+			if err == pgx.ErrNoRows {
+				return ErrNotFound
+			}
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: agent status is %s", ErrRevisionConflict, current)
+		}
+	}
+
+	if err := insertGovernanceAction(ctx, tx, action); err != nil {
+		return fmt.Errorf("failed to record action: %w", err)
+	}
+	return nil
+}
+
+// ApplyReputationAction records action and changes its agent's reputation in
+// one transaction
+func (db *DB) ApplyReputationAction(ctx context.Context, action *models.GovernanceAction, score float64) error {
+	if action.PreviousReputation == nil {
+		return fmt.Errorf("action has no previous reputation")
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE agents SET reputation_score = $1, updated_at = NOW() WHERE id = $2 AND reputation_score = $3
+	`, score, action.AgentID, *action.PreviousReputation)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		var current float64
+		err := tx.QueryRow(ctx, `SELECT reputation_score FROM agents WHERE id = $1`, action.AgentID).Scan(&current)
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: agent reputation is %v", ErrRevisionConflict, current)
+	}
+
+	if err := insertGovernanceAction(ctx, tx, action); err != nil {
+		return fmt.Errorf("failed to record action: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// RuleOnReport resolves a report and records the ruling's action in one
+// transaction
+func (db *DB) RuleOnReport(ctx context.Context, reportID uuid.UUID, resolution models.Resolution, note string, resolvedBy string, action *models.GovernanceAction, status models.AgentStatus) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE reports SET status = 'resolved', resolution = $1, resolution_note = $2,
+						   reviewed_by = $3, resolved_at = $4
+		WHERE id = $5 AND status = 'reviewing' AND reviewed_by = $3
+	`, resolution, note, resolvedBy, time.Now(), reportID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return db.reportConflict(ctx, reportID, resolvedBy)
+	}
+	if action != nil {
+		if err := applyAction(ctx, tx, action, status); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// governanceActionColumns are the columns scanned by scanGovernanceAction
+const governanceActionColumns = `g.id, g.agent_id, a.name, g.report_id, g.action_type, g.action_by, g.actor,
+	   g.reason, g.previous_status, g.previous_reputation, g.reverts_action_id, g.created_at`
+
+// scanGovernanceAction scans a row selected with governanceActionColumns
+func scanGovernanceAction(row pgx.Row) (*models.GovernanceAction, error) {
+	var g models.GovernanceAction
+	err := row.Scan(&g.ID, &g.AgentID, &g.AgentName, &g.ReportID, &g.ActionType, &g.ActionBy, &g.Actor,
+		&g.Reason, &g.PreviousStatus, &g.PreviousReputation, &g.RevertsActionID, &g.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// GetGovernanceAction returns an action by ID
+func (db *DB) GetGovernanceAction(ctx context.Context, actionID uuid.UUID) (*models.GovernanceAction, error) {
+	g, err := scanGovernanceAction(db.pool.QueryRow(ctx, `
+		SELECT `+governanceActionColumns+`
+		FROM governance_actions g
+		JOIN agents a ON g.agent_id = a.id
+		WHERE g.id = $1
+	`, actionID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return g, err
+}
+
+// ListGovernanceActions returns the actions taken on an agent, oldest first
+func (db *DB) ListGovernanceActions(ctx context.Context, agentID uuid.UUID) ([]models.GovernanceAction, error) {
+	rows, err := db.pool.Query(ctx, `
+		SELECT `+governanceActionColumns+`
+		FROM governance_actions g
+		JOIN agents a ON g.agent_id = a.id
+		WHERE g.agent_id = $1
+		ORDER BY g.created_at, g.id
+	`, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []models.GovernanceAction
+	for rows.Next() {
+		g, err := scanGovernanceAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, *g)
+	}
+	return actions, rows.Err()
+}
+
 // GetGovernanceStats returns governance statistics
 func (db *DB) GetGovernanceStats(ctx context.Context) (*models.GovernanceStats, error) {
 	stats := &models.GovernanceStats{}
 
 	db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM reports WHERE status = 'pending'`).Scan(&stats.PendingReports)
 	db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM reports WHERE status = 'reviewing'`).Scan(&stats.ReviewingReports)
+	db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM appeals WHERE status = 'pending'`).Scan(&stats.PendingAppeals)
 	db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM agents WHERE status = 'quarantined'`).Scan(&stats.QuarantinedAgents)
 	db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM agents WHERE status = 'banned'`).Scan(&stats.BannedAgents)
 	db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM governance_actions WHERE created_at > NOW() - INTERVAL '1 day'`).Scan(&stats.ActionsToday)
@@ -610,3 +1055,137 @@ func (db *DB) CountPendingReportsForAgent(ctx context.Context, agentID uuid.UUID
 	`, agentID).Scan(&count)
 	return count, err
 }
+
+// ============ Appeal Operations ============
+
+// CreateAppeal files a pending appeal, one per agent at a time and at most
+// maxAppeals per agent. The agent row is locked so concurrent filings are
+// counted one after another.
+func (db *DB) CreateAppeal(ctx context.Context, appeal *models.Appeal, maxAppeals int) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT id FROM agents WHERE id = $1 FOR UPDATE`, appeal.AgentID).Scan(&locked)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("agent not found: %s", appeal.AgentID)
+	}
+	if err != nil {
+		return err
+	}
+
+	var total, pending int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE status = 'pending') FROM appeals WHERE agent_id = $1
+	`, appeal.AgentID).Scan(&total, &pending)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: agent already has a pending appeal", ErrAppealConflict)
+	}
+	if total >= maxAppeals {
+		return fmt.Errorf("%w: agent has used all %d of its appeals", ErrAppealConflict, maxAppeals)
+	}
+
+	appeal.ID = uuid.New()
+	appeal.Status = models.AppealStatusPending
+	appeal.CreatedAt = time.Now()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO appeals (id, agent_id, action_id, filed_by, statement, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		appeal.ID, appeal.AgentID, appeal.ActionID, appeal.FiledBy, appeal.Statement,
+		appeal.Status, appeal.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetAppeal retrieves an appeal by ID
+func (db *DB) GetAppeal(ctx context.Context, appealID uuid.UUID) (*models.Appeal, error) {
+	var a models.Appeal
+	err := db.pool.QueryRow(ctx, `
+		SELECT p.id, p.agent_id, a.name, p.action_id, p.filed_by, p.statement, p.status,
+			   p.decided_by, p.decision_note, p.created_at, p.decided_at
+		FROM appeals p
+		JOIN agents a ON p.agent_id = a.id
+		WHERE p.id = $1
+	`, appealID).Scan(&a.ID, &a.AgentID, &a.AgentName, &a.ActionID, &a.FiledBy, &a.Statement,
+		&a.Status, &a.DecidedBy, &a.DecisionNote, &a.CreatedAt, &a.DecidedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetPendingAppeals returns appeals awaiting a decision, oldest first
+func (db *DB) GetPendingAppeals(ctx context.Context) ([]models.Appeal, error) {
+	rows, err := db.pool.Query(ctx, `
+		SELECT p.id, p.agent_id, a.name, p.action_id, p.filed_by, p.statement, p.status,
+			   p.decided_by, p.decision_note, p.created_at, p.decided_at
+		FROM appeals p
+		JOIN agents a ON p.agent_id = a.id
+		WHERE p.status = 'pending'
+		ORDER BY p.created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appeals []models.Appeal
+	for rows.Next() {
+		var a models.Appeal
+		if err := rows.Scan(&a.ID, &a.AgentID, &a.AgentName, &a.ActionID, &a.FiledBy, &a.Statement,
+			&a.Status, &a.DecidedBy, &a.DecisionNote, &a.CreatedAt, &a.DecidedAt); err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, a)
+	}
+	return appeals, rows.Err()
+}
+
+// DecideAppeal grants or denies a pending appeal and applies its status
+// changes in one transaction
+func (db *DB) DecideAppeal(ctx context.Context, appealID uuid.UUID, status models.AppealStatus, note string, decidedBy string, changes []StatusChange) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE appeals SET status = $1, decision_note = $2, decided_by = $3, decided_at = $4
+		WHERE id = $5 AND status = 'pending'
+	`, status, note, decidedBy, time.Now(), appealID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		var current models.AppealStatus
+		err := tx.QueryRow(ctx, `SELECT status FROM appeals WHERE id = $1`, appealID).Scan(&current)
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("appeal %s: %w", appealID, ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: appeal was already %s", ErrAppealConflict, current)
+	}
+	for _, c := range changes {
+		if err := applyAction(ctx, tx, c.Action, c.Status); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,185 @@
+// Package database provides PostgreSQL database operations
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ============ Revision History ============
+// Revisions are written inside the same transaction as the change they record,
+// so the history can never miss a version that was saved.
+
+// insertAgentRevision appends a snapshot of agent to its history
+func insertAgentRevision(ctx context.Context, tx pgx.Tx, agent *models.Agent) error {
+	rev := models.NewAgentRevision(agent)
+	toolsJSON, _ := json.Marshal(rev.Tools)
+	metadataJSON, _ := json.Marshal(rev.Metadata)
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO agent_revisions (
+			id, agent_id, version, name, description, model, tools, metadata, prompt, skills, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		uuid.New(), rev.AgentID, rev.Version, rev.Name, rev.Description, rev.Model,
+		toolsJSON, metadataJSON, rev.Prompt, rev.Skills, time.Now(),
+	)
+	return err
+}
+
+// insertSkillRevision appends a snapshot of skill to its history
+func insertSkillRevision(ctx context.Context, tx pgx.Tx, skill *models.Skill) error {
+	rev := models.NewSkillRevision(skill)
+	examplesJSON, _ := json.Marshal(rev.Examples)
+	metadataJSON, _ := json.Marshal(rev.Metadata)
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO skill_revisions (
+			id, skill_id, version, name, description, category, content, examples, metadata, tags, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		uuid.New(), rev.SkillID, rev.Version, rev.Name, rev.Description, rev.Category,
+		rev.Content, examplesJSON, metadataJSON, rev.Tags, time.Now(),
+	)
+	return err
+}
+
+// insertCommandRevision appends a snapshot of cmd to its history
+func insertCommandRevision(ctx context.Context, tx pgx.Tx, cmd *models.Command) error {
+	rev := models.NewCommandRevision(cmd)
+	argumentsJSON, _ := json.Marshal(rev.Arguments)
+	metadataJSON, _ := json.Marshal(rev.Metadata)
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO command_revisions (
+			id, command_id, version, name, description, prompt, arguments, metadata, tags, category, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		uuid.New(), rev.CommandID, rev.Version, rev.Name, rev.Description, rev.Prompt,
+		argumentsJSON, metadataJSON, rev.Tags, rev.Category, time.Now(),
+	)
+	return err
+}
+
+// ListAgentRevisions returns an agent's history, newest first
+func (db *DB) ListAgentRevisions(ctx context.Context, agentID uuid.UUID) ([]models.RevisionInfo, error) {
+	return db.listRevisions(ctx, `
+		SELECT version, created_at FROM agent_revisions
+		WHERE agent_id = $1 ORDER BY created_at DESC
+	`, agentID)
+}
+
+// ListSkillRevisions returns a skill's history, newest first
+func (db *DB) ListSkillRevisions(ctx context.Context, skillID uuid.UUID) ([]models.RevisionInfo, error) {
+	return db.listRevisions(ctx, `
+		SELECT version, created_at FROM skill_revisions
+		WHERE skill_id = $1 ORDER BY created_at DESC
+	`, skillID)
+}
+
+// ListCommandRevisions returns a command's history, newest first
+func (db *DB) ListCommandRevisions(ctx context.Context, commandID uuid.UUID) ([]models.RevisionInfo, error) {
+	return db.listRevisions(ctx, `
+		SELECT version, created_at FROM command_revisions
+		WHERE command_id = $1 ORDER BY created_at DESC
+	`, commandID)
+}
+
+func (db *DB) listRevisions(ctx context.Context, query string, id uuid.UUID) ([]models.RevisionInfo, error) {
+	rows, err := db.pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.RevisionInfo{}
+	for rows.Next() {
+		var r models.RevisionInfo
+		if err := rows.Scan(&r.Version, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+
+	return revisions, nil
+}
+
+// GetAgentRevision retrieves one version of an agent
+func (db *DB) GetAgentRevision(ctx context.Context, agentID uuid.UUID, version string) (*models.AgentRevision, error) {
+	var rev models.AgentRevision
+	var toolsJSON, metadataJSON []byte
+
+	err := db.pool.QueryRow(ctx, `
+		SELECT id, agent_id, version, name, description, model, tools, metadata, prompt, skills, created_at
+		FROM agent_revisions WHERE agent_id = $1 AND version = $2
+	`, agentID, version).Scan(
+		&rev.ID, &rev.AgentID, &rev.Version, &rev.Name, &rev.Description, &rev.Model,
+		&toolsJSON, &metadataJSON, &rev.Prompt, &rev.Skills, &rev.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(toolsJSON, &rev.Tools)
+	json.Unmarshal(metadataJSON, &rev.Metadata)
+
+	return &rev, nil
+}
+
+// GetSkillRevision retrieves one version of a skill
+func (db *DB) GetSkillRevision(ctx context.Context, skillID uuid.UUID, version string) (*models.SkillRevision, error) {
+	var rev models.SkillRevision
+	var examplesJSON, metadataJSON []byte
+
+	err := db.pool.QueryRow(ctx, `
+		SELECT id, skill_id, version, name, description, category, content, examples, metadata, tags, created_at
+		FROM skill_revisions WHERE skill_id = $1 AND version = $2
+	`, skillID, version).Scan(
+		&rev.ID, &rev.SkillID, &rev.Version, &rev.Name, &rev.Description, &rev.Category,
+		&rev.Content, &examplesJSON, &metadataJSON, &rev.Tags, &rev.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(examplesJSON, &rev.Examples)
+	json.Unmarshal(metadataJSON, &rev.Metadata)
+
+	return &rev, nil
+}
+
+// GetCommandRevision retrieves one version of a command
+func (db *DB) GetCommandRevision(ctx context.Context, commandID uuid.UUID, version string) (*models.CommandRevision, error) {
+	var rev models.CommandRevision
+	var argumentsJSON, metadataJSON []byte
+
+	err := db.pool.QueryRow(ctx, `
+		SELECT id, command_id, version, name, description, prompt, arguments, metadata, tags, category, created_at
+		FROM command_revisions WHERE command_id = $1 AND version = $2
+	`, commandID, version).Scan(
+		&rev.ID, &rev.CommandID, &rev.Version, &rev.Name, &rev.Description, &rev.Prompt,
+		&argumentsJSON, &metadataJSON, &rev.Tags, &rev.Category, &rev.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(argumentsJSON, &rev.Arguments)
+	json.Unmarshal(metadataJSON, &rev.Metadata)
+
+	return &rev, nil
+}
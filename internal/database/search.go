@@ -0,0 +1,302 @@
+// Package database provides PostgreSQL database operations
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+)
+
+// ============ Hybrid Search ============
+// Keyword and vector matches are ranked separately and fused with reciprocal
+// rank fusion: each signal contributes 1/(rrfK + rank), so an item near the
+// top of either list scores well and one near the top of both scores best.
+// Reputation only breaks ties.
+
+const (
+	// rrfK damps the weight of top ranks; 60 is the value from the RRF paper
+	rrfK = 60
+	// minSearchCandidates is the least number of items taken from each signal before fusing
+	minSearchCandidates = 50
+)
+
+// stopWords are common words that would match nearly everything
+var stopWords = map[string]bool{
+	"and": true, "are": true, "but": true, "can": true, "for": true, "from": true,
+	"has": true, "have": true, "how": true, "into": true, "not": true, "that": true,
+	"the": true, "this": true, "was": true, "what": true, "with": true, "you": true,
+	"your": true,
+}
+
+// searchTerms splits text into distinct lowercase words worth matching,
+// skipping short words and stop words
+func searchTerms(text string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) < 3 || stopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// tsQuery builds a to_tsquery expression matching any of the terms in text,
+// so a task description needs only some words in common with a row.
+// Terms are letters and digits only, which keeps the expression valid.
+func tsQuery(text string) string {
+	return strings.Join(searchTerms(text), " | ")
+}
+
+// searchCandidates is how many items each signal contributes before fusing
+func searchCandidates(limit int) int {
+	return max(limit*5, minSearchCandidates)
+}
+
+// searchLimit defaults a non-positive limit
+func searchLimit(limit int) int {
+	if limit <= 0 {
+		return 10
+	}
+	return limit
+}
+
+// hybridSearchSQL ranks table by full-text score, by trigram similarity of
+// the terms that found no keyword match, and by embedding distance, fuses the
+// three rankings and returns columns (from alias r) followed by the
+// per-signal scores. Similarity is reported even for rows the vector ranking
+// left out; keyword_hits and fuzzy_hits list the query terms each row
+// matched exactly and approximately.
+// Parameters: $1 tsquery, $2 query vector or NULL, $3 minimum similarity,
+// $4 candidates per signal, $5 rrf k, $6 limit, $7 offset, then those of
+// where, which selects the rows eligible for any signal.
+func hybridSearchSQL(table, columns, where string) string {
+	return fmt.Sprintf(`
+		WITH text_hits AS (
+			SELECT id, ts_rank_cd(search_vector, query)::float8 AS text_score,
+				   ROW_NUMBER() OVER (ORDER BY ts_rank_cd(search_vector, query) DESC, id) AS text_rank
+			FROM %[1]s, to_tsquery('english', $1) AS query
+			WHERE %[3]s AND $1 <> '' AND search_vector @@ query
+			ORDER BY text_rank
+			LIMIT $4
+		),
+		fuzzy_hits AS (
+			SELECT id, fuzzy_score, ROW_NUMBER() OVER (ORDER BY fuzzy_score DESC, id) AS fuzzy_rank
+			FROM (
+				SELECT id, SUM(word_similarity(term, fuzzy_text))::float8 AS fuzzy_score
+				FROM %[1]s, unnest(string_to_array($1, ' | ')) AS term
+				WHERE %[3]s AND $1 <> '' AND fuzzy_text %%> term
+				  AND NOT search_vector @@ to_tsquery('english', term)
+				GROUP BY id
+			) scored
+			ORDER BY fuzzy_rank
+			LIMIT $4
+		),
+		nearest AS (
+			SELECT id, (1 - (embedding <=> $2::vector))::float8 AS similarity
+			FROM %[1]s
+			WHERE %[3]s AND embedding IS NOT NULL AND $2::vector IS NOT NULL
+			ORDER BY embedding <=> $2::vector
+			LIMIT $4
+		),
+		vector_hits AS (
+			SELECT id, similarity, ROW_NUMBER() OVER (ORDER BY similarity DESC, id) AS vector_rank
+			FROM nearest
+			WHERE similarity >= $3
+		),
+		fused AS (
+			SELECT COALESCE(t.id, z.id, v.id) AS id,
+				   COALESCE(t.text_rank, 0) AS text_rank,
+				   COALESCE(t.text_score, 0) AS text_score,
+				   COALESCE(z.fuzzy_rank, 0) AS fuzzy_rank,
+				   COALESCE(z.fuzzy_score, 0) AS fuzzy_score,
+				   COALESCE(v.vector_rank, 0) AS vector_rank,
+				   (COALESCE(1.0 / ($5 + t.text_rank), 0) + COALESCE(1.0 / ($5 + z.fuzzy_rank), 0) +
+				    COALESCE(1.0 / ($5 + v.vector_rank), 0))::float8 AS score
+			FROM text_hits t
+			FULL OUTER JOIN fuzzy_hits z ON z.id = t.id
+			FULL OUTER JOIN vector_hits v ON v.id = COALESCE(t.id, z.id)
+		)
+		SELECT %[2]s, f.score, f.text_rank, f.text_score, f.fuzzy_rank, f.fuzzy_score, f.vector_rank,
+			   COALESCE((1 - (r.embedding <=> $2::vector))::float8, 0) AS similarity,
+			   ARRAY(
+				   SELECT term FROM unnest(string_to_array($1, ' | ')) AS term
+				   WHERE r.search_vector @@ to_tsquery('english', term)
+			   ) AS keyword_hits,
+			   ARRAY(
+				   SELECT term FROM unnest(string_to_array($1, ' | ')) AS term
+				   WHERE f.fuzzy_rank > 0 AND r.fuzzy_text %%> term
+					 AND NOT r.search_vector @@ to_tsquery('english', term)
+			   ) AS fuzzy_hits
+		FROM fused f JOIN %[1]s r ON r.id = f.id
+		ORDER BY f.score DESC, r.reputation_score DESC, r.name
+		LIMIT $6 OFFSET $7
+	`, table, columns, where)
+}
+
+// hybridSearch builds the query and parameters for a hybrid search of kind
+func hybridSearch(kind models.EmbeddingKind, table, columns string, q models.SearchQuery) (string, []any, error) {
+	limit := searchLimit(q.Limit)
+	offset := max(q.Offset, 0)
+	w := &whereBuilder{args: []any{
+		tsQuery(q.Text), q.Embedding, q.MinSimilarity, searchCandidates(limit + offset), rrfK, limit, offset,
+	}}
+	if err := catalogWhere(w, kind, q.Filter); err != nil {
+		return "", nil, err
+	}
+	return hybridSearchSQL(table, columns, w.sql()), w.args, nil
+}
+
+// HybridSearchAgents finds agents by keyword and embedding similarity
+func (db *DB) HybridSearchAgents(ctx context.Context, q models.SearchQuery) ([]models.AgentSearchResult, error) {
+	query, args, err := hybridSearch(models.EmbeddingKindAgent, "agents",
+		"r.id, r.name, r.version, r.description, r.skills, r.reputation_score, r.avg_rating, r.usage_count, r.status", q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.AgentSearchResult{}
+	for rows.Next() {
+		var r models.AgentSearchResult
+		a, sc := &r.Agent, &r.Scores
+		err := rows.Scan(&a.ID, &a.Name, &a.Version, &a.Description, &a.Skills,
+			&a.ReputationScore, &a.AvgRating, &a.UsageCount, &a.Status,
+			&sc.Score, &sc.TextRank, &sc.TextScore, &sc.FuzzyRank, &sc.FuzzyScore, &sc.VectorRank,
+			&sc.Similarity, &sc.KeywordHits, &sc.FuzzyHits)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// HybridSearchSkills finds skills by keyword and embedding similarity
+func (db *DB) HybridSearchSkills(ctx context.Context, q models.SearchQuery) ([]models.SkillSearchResult, error) {
+	query, args, err := hybridSearch(models.EmbeddingKindSkill, "skills",
+		"r.id, r.name, r.version, r.description, r.category, r.tags, r.reputation_score, r.avg_rating, r.usage_count, r.status", q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.SkillSearchResult{}
+	for rows.Next() {
+		var r models.SkillSearchResult
+		s, sc := &r.Skill, &r.Scores
+		err := rows.Scan(&s.ID, &s.Name, &s.Version, &s.Description, &s.Category,
+			&s.Tags, &s.ReputationScore, &s.AvgRating, &s.UsageCount, &s.Status,
+			&sc.Score, &sc.TextRank, &sc.TextScore, &sc.FuzzyRank, &sc.FuzzyScore, &sc.VectorRank,
+			&sc.Similarity, &sc.KeywordHits, &sc.FuzzyHits)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// HybridSearchCommands finds commands by keyword and embedding similarity
+func (db *DB) HybridSearchCommands(ctx context.Context, q models.SearchQuery) ([]models.CommandSearchResult, error) {
+	query, args, err := hybridSearch(models.EmbeddingKindCommand, "commands",
+		"r.id, r.name, r.version, r.description, r.category, r.tags, r.arguments, r.reputation_score, r.avg_rating, r.usage_count, r.status", q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.CommandSearchResult{}
+	for rows.Next() {
+		var r models.CommandSearchResult
+		var argumentsJSON []byte
+		c, sc := &r.Command, &r.Scores
+		err := rows.Scan(&c.ID, &c.Name, &c.Version, &c.Description, &c.Category,
+			&c.Tags, &argumentsJSON, &c.ReputationScore, &c.AvgRating, &c.UsageCount, &c.Status,
+			&sc.Score, &sc.TextRank, &sc.TextScore, &sc.FuzzyRank, &sc.FuzzyScore, &sc.VectorRank,
+			&sc.Similarity, &sc.KeywordHits, &sc.FuzzyHits)
+		if err != nil {
+			return nil, err
+		}
+		json.Unmarshal(argumentsJSON, &c.Arguments)
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// suggestSQL finds, for each term that is not a word of the eligible rows of
+// table, the most similar word that is, by pg_trgm similarity at its default
+// threshold. Parameters: $1 terms, then those of where.
+func suggestSQL(table, where string) string {
+	return fmt.Sprintf(`
+		WITH vocabulary AS (
+			SELECT DISTINCT word
+			FROM %[1]s, regexp_split_to_table(fuzzy_text, '[^[:alnum:]]+') AS word
+			WHERE %[2]s AND length(word) >= 3
+		)
+		SELECT t.term, best.word, best.similarity
+		FROM unnest($1::text[]) WITH ORDINALITY AS t(term, pos)
+		CROSS JOIN LATERAL (
+			SELECT word, similarity(t.term, word)::float8 AS similarity
+			FROM vocabulary
+			WHERE word %% t.term
+			ORDER BY similarity DESC, word
+			LIMIT 1
+		) best
+		WHERE NOT EXISTS (SELECT 1 FROM vocabulary WHERE word = t.term)
+		ORDER BY t.pos
+	`, table, where)
+}
+
+// SuggestTerms proposes catalog words for the terms of text that no row of
+// kind matching filter contains, for "did you mean" hints
+func (db *DB) SuggestTerms(ctx context.Context, kind models.EmbeddingKind, text string, filter models.ListFilter) ([]models.Suggestion, error) {
+	table, _, err := embeddingTable(kind)
+	if err != nil {
+		return nil, err
+	}
+	terms := searchTerms(text)
+	if len(terms) == 0 {
+		return []models.Suggestion{}, nil
+	}
+	w := &whereBuilder{args: []any{terms}}
+	if err := catalogWhere(w, kind, filter); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.pool.Query(ctx, suggestSQL(table, w.sql()), w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []models.Suggestion{}
+	for rows.Next() {
+		var s models.Suggestion
+		if err := rows.Scan(&s.Term, &s.Suggestion, &s.Similarity); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
@@ -0,0 +1,24 @@
+package database
+
+import "testing"
+
+func TestTsQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"words joined with or", "review Go code", "review | code"},
+		{"stop words and duplicates dropped", "the review of the review", "review"},
+		{"operators stripped", "k8s & (pods | !nodes):*", "k8s | pods | nodes"},
+		{"punctuation splits words", "kubectl's get-pods", "kubectl | get | pods"},
+		{"nothing left", "a to of", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tsQuery(tt.input); got != tt.want {
+				t.Errorf("tsQuery(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
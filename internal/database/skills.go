@@ -4,6 +4,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -26,24 +27,36 @@ func (db *DB) CreateSkill(ctx context.Context, skill *models.Skill) error {
 	examplesJSON, _ := json.Marshal(skill.Examples)
 	metadataJSON, _ := json.Marshal(skill.Metadata)
 
-	_, err := db.pool.Exec(ctx, `
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
 		INSERT INTO skills (
 			id, name, version, description, category, content, examples,
 			metadata, tags, embedding, reputation_score, status, is_system,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, embedding_model
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
 			$8, $9, $10, $11, $12, $13,
-			$14, $15, $16
+			$14, $15, $16, $17
 		)
 	`,
 		skill.ID, skill.Name, skill.Version, skill.Description, skill.Category,
 		skill.Content, examplesJSON, metadataJSON, skill.Tags, skill.Embedding,
 		skill.ReputationScore, skill.Status, skill.IsSystem,
-		skill.CreatedBy, skill.CreatedAt, skill.UpdatedAt,
+		skill.CreatedBy, skill.CreatedAt, skill.UpdatedAt, skill.EmbeddingModel,
 	)
+	if err != nil {
+		return err
+	}
+	if err := insertSkillRevision(ctx, tx, skill); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
 
-	return err
+	return tx.Commit(ctx)
 }
 
 // GetSkill retrieves a skill by name
@@ -54,14 +67,15 @@ func (db *DB) GetSkill(ctx context.Context, name string) (*models.Skill, error)
 	err := db.pool.QueryRow(ctx, `
 		SELECT id, name, version, description, category, content, examples,
 			   metadata, tags, embedding, reputation_score, usage_count, feedback_count,
-			   avg_rating, status, is_system, created_by, created_at, updated_at
+			   avg_rating, status, is_system, created_by, created_at, updated_at,
+			   embedding_model
 		FROM skills WHERE name = $1
 	`, name).Scan(
 		&skill.ID, &skill.Name, &skill.Version, &skill.Description, &skill.Category,
 		&skill.Content, &examplesJSON, &metadataJSON, &skill.Tags, &skill.Embedding,
 		&skill.ReputationScore, &skill.UsageCount, &skill.FeedbackCount,
 		&skill.AvgRating, &skill.Status, &skill.IsSystem,
-		&skill.CreatedBy, &skill.CreatedAt, &skill.UpdatedAt,
+		&skill.CreatedBy, &skill.CreatedAt, &skill.UpdatedAt, &skill.EmbeddingModel,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -84,14 +98,15 @@ func (db *DB) GetSkillByID(ctx context.Context, id uuid.UUID) (*models.Skill, er
 	err := db.pool.QueryRow(ctx, `
 		SELECT id, name, version, description, category, content, examples,
 			   metadata, tags, embedding, reputation_score, usage_count, feedback_count,
-			   avg_rating, status, is_system, created_by, created_at, updated_at
+			   avg_rating, status, is_system, created_by, created_at, updated_at,
+			   embedding_model
 		FROM skills WHERE id = $1
 	`, id).Scan(
 		&skill.ID, &skill.Name, &skill.Version, &skill.Description, &skill.Category,
 		&skill.Content, &examplesJSON, &metadataJSON, &skill.Tags, &skill.Embedding,
 		&skill.ReputationScore, &skill.UsageCount, &skill.FeedbackCount,
 		&skill.AvgRating, &skill.Status, &skill.IsSystem,
-		&skill.CreatedBy, &skill.CreatedAt, &skill.UpdatedAt,
+		&skill.CreatedBy, &skill.CreatedAt, &skill.UpdatedAt, &skill.EmbeddingModel,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -150,6 +165,52 @@ func (db *DB) ListSkills(ctx context.Context, category string, tags []string) ([
 	return skills, nil
 }
 
+// ListSkillsPage returns one page of skills matching opts.Filter in the requested order
+func (db *DB) ListSkillsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.SkillSummary], error) {
+	p, err := planList("skills", catalogSorts, opts)
+	if err != nil {
+		return models.Page[models.SkillSummary]{}, err
+	}
+	w := &whereBuilder{}
+	if err := catalogWhere(w, models.EmbeddingKindSkill, opts.Filter); err != nil {
+		return models.Page[models.SkillSummary]{}, err
+	}
+	p.afterSQL(w)
+
+	rows, err := db.pool.Query(ctx, fmt.Sprintf(`
+		SELECT id, name, version, description, category, tags, reputation_score, avg_rating, usage_count, status,
+			   created_at, updated_at
+		FROM skills
+		WHERE %s
+		ORDER BY %s
+		LIMIT %d
+	`, w.sql(), p.orderSQL(), p.limit+1), w.args...)
+	if err != nil {
+		return models.Page[models.SkillSummary]{}, err
+	}
+	defer rows.Close()
+
+	var skills []models.SkillSummary
+	var keys []pageKey
+	for rows.Next() {
+		var s models.SkillSummary
+		var k pageKey
+		err := rows.Scan(&s.ID, &s.Name, &s.Version, &s.Description, &s.Category,
+			&s.Tags, &s.ReputationScore, &s.AvgRating, &s.UsageCount, &s.Status, &k.Created, &k.Updated)
+		if err != nil {
+			return models.Page[models.SkillSummary]{}, err
+		}
+		k.ID, k.Name, k.Reputation, k.Rating, k.Usage = s.ID, s.Name, s.ReputationScore, s.AvgRating, s.UsageCount
+		skills = append(skills, s)
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.SkillSummary]{}, err
+	}
+
+	return page(p, skills, keys), nil
+}
+
 // SearchSkills searches skills by keyword
 func (db *DB) SearchSkills(ctx context.Context, query string) ([]models.SkillSummary, error) {
 	escaped := escapeLikePattern(strings.ToLower(query))
@@ -218,6 +279,40 @@ func (db *DB) FindSimilarSkills(ctx context.Context, embedding pgvector.Vector,
 	return results, nil
 }
 
+// UpdateSkill saves content changes to a skill and appends skill.Version to its history
+func (db *DB) UpdateSkill(ctx context.Context, skill *models.Skill) error {
+	examplesJSON, _ := json.Marshal(skill.Examples)
+	metadataJSON, _ := json.Marshal(skill.Metadata)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE skills SET
+			version = $1, description = $2, category = $3, content = $4, examples = $5,
+			metadata = $6, tags = $7, embedding = $8, embedding_model = $9
+		WHERE id = $10
+		RETURNING updated_at
+	`,
+		skill.Version, skill.Description, skill.Category, skill.Content, examplesJSON,
+		metadataJSON, skill.Tags, skill.Embedding, skill.EmbeddingModel, skill.ID,
+	).Scan(&skill.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := insertSkillRevision(ctx, tx, skill); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
 // IncrementSkillUsage increments the usage count
 func (db *DB) IncrementSkillUsage(ctx context.Context, skillID uuid.UUID) error {
 	_, err := db.pool.Exec(ctx, `
@@ -0,0 +1,157 @@
+// Package database provides PostgreSQL database operations
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+)
+
+// Errors shared by all Store backends
+var (
+	// ErrNotFound is returned when an update targets a row that does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrRevisionConflict is returned when an optimistic-concurrency precondition fails
+	ErrRevisionConflict = errors.New("revision conflict")
+	// ErrReportConflict is returned when a report is claimed or resolved out of turn
+	ErrReportConflict = errors.New("report state conflict")
+	// ErrAppealConflict is returned when an appeal is filed or decided out of turn
+	ErrAppealConflict = errors.New("appeal state conflict")
+)
+
+// StatusChange is a governance action and the status it moves its agent to
+type StatusChange struct {
+	Action *models.GovernanceAction
+	Status models.AgentStatus
+}
+
+// Store is the persistence interface used by the v2 server and governance engine.
+// DB (PostgreSQL + pgvector) and MemoryStore (embedded, in-process) implement it.
+type Store interface {
+	// Close releases any resources held by the store
+	Close()
+
+	// Agents
+	CreateAgent(ctx context.Context, agent *models.Agent) error
+	GetAgent(ctx context.Context, name string) (*models.Agent, error)
+	GetAgentByID(ctx context.Context, id uuid.UUID) (*models.Agent, error)
+	ListAgents(ctx context.Context, tags []string) ([]models.AgentSummary, error)
+	ListAgentsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.AgentSummary], error)
+	SearchAgents(ctx context.Context, query string) ([]models.AgentSummary, error)
+	FindSimilarAgents(ctx context.Context, embedding pgvector.Vector, limit int, threshold float64) ([]models.SimilarAgent, error)
+	HybridSearchAgents(ctx context.Context, q models.SearchQuery) ([]models.AgentSearchResult, error)
+	UpdateAgent(ctx context.Context, agent *models.Agent, expectedRevision int) error
+	ArchiveAgent(ctx context.Context, agentID uuid.UUID, expectedRevision int) error
+	UpdateAgentStatus(ctx context.Context, agentID uuid.UUID, status models.AgentStatus) error
+	UpdateAgentReputation(ctx context.Context, agentID uuid.UUID, score float64) error
+	IncrementUsage(ctx context.Context, agentID uuid.UUID) error
+	GetTopAgents(ctx context.Context, limit int, category string) ([]models.AgentSummary, error)
+	ListAgentRevisions(ctx context.Context, agentID uuid.UUID) ([]models.RevisionInfo, error)
+	GetAgentRevision(ctx context.Context, agentID uuid.UUID, version string) (*models.AgentRevision, error)
+
+	// Skill request cache
+	GetCachedAgentBySkills(ctx context.Context, skills []string) (*models.Agent, error)
+	CacheSkillRequest(ctx context.Context, skills []string, agentID uuid.UUID) error
+
+	// Feedback
+	SubmitFeedback(ctx context.Context, feedback *models.Feedback) error
+	GetAgentReputation(ctx context.Context, agentID uuid.UUID) (*models.AgentReputation, error)
+
+	// Reports and governance actions
+	CreateReport(ctx context.Context, report *models.Report) error
+	GetPendingReports(ctx context.Context) ([]models.Report, error)
+	ListReports(ctx context.Context, opts models.ListOptions) (models.Page[models.Report], error)
+	GetReport(ctx context.Context, reportID uuid.UUID) (*models.Report, error)
+	UpdateReportStatus(ctx context.Context, reportID uuid.UUID, status models.ReportStatus, reviewedBy string) error
+	// ClaimReport moves a pending report to reviewing by reviewer. Claiming a
+	// report the reviewer already holds succeeds; any other claim fails with
+	// ErrReportConflict.
+	ClaimReport(ctx context.Context, reportID uuid.UUID, reviewer string) error
+	// ResolveReport resolves a report under review by resolvedBy, failing with
+	// ErrReportConflict otherwise
+	ResolveReport(ctx context.Context, reportID uuid.UUID, resolution models.Resolution, note string, resolvedBy string) error
+	RecordGovernanceAction(ctx context.Context, action *models.GovernanceAction) error
+	// ApplyGovernanceAction records action and moves its agent from
+	// action.PreviousStatus to status in one transaction, failing with
+	// ErrRevisionConflict if the status changed since it was read
+	ApplyGovernanceAction(ctx context.Context, action *models.GovernanceAction, status models.AgentStatus) error
+	// ApplyReputationAction records action and moves its agent's reputation
+	// from action.PreviousReputation to score in one transaction, failing with
+	// ErrRevisionConflict if the reputation changed since it was read
+	ApplyReputationAction(ctx context.Context, action *models.GovernanceAction, score float64) error
+	// RuleOnReport resolves a report under review by resolvedBy and, when
+	// action is not nil, records it in the same transaction. A non-empty status
+	// also moves the agent as ApplyGovernanceAction does.
+	RuleOnReport(ctx context.Context, reportID uuid.UUID, resolution models.Resolution, note string, resolvedBy string, action *models.GovernanceAction, status models.AgentStatus) error
+	GetGovernanceAction(ctx context.Context, actionID uuid.UUID) (*models.GovernanceAction, error)
+	// ListGovernanceActions returns the actions taken on an agent, oldest first
+	ListGovernanceActions(ctx context.Context, agentID uuid.UUID) ([]models.GovernanceAction, error)
+	GetGovernanceStats(ctx context.Context) (*models.GovernanceStats, error)
+	CountPendingReportsForAgent(ctx context.Context, agentID uuid.UUID) (int, error)
+
+	// Appeals
+	// CreateAppeal files a pending appeal, failing with ErrAppealConflict if
+	// the agent already has one pending or has filed maxAppeals in total. The
+	// checks and the insert are atomic.
+	CreateAppeal(ctx context.Context, appeal *models.Appeal, maxAppeals int) error
+	GetAppeal(ctx context.Context, appealID uuid.UUID) (*models.Appeal, error)
+	// GetPendingAppeals returns appeals awaiting a decision, oldest first
+	GetPendingAppeals(ctx context.Context) ([]models.Appeal, error)
+	// DecideAppeal grants or denies a pending appeal and applies changes in
+	// order, each as ApplyGovernanceAction does, in one transaction. It fails
+	// with ErrAppealConflict if the appeal was already decided.
+	DecideAppeal(ctx context.Context, appealID uuid.UUID, status models.AppealStatus, note string, decidedBy string, changes []StatusChange) error
+
+	// Skills
+	CreateSkill(ctx context.Context, skill *models.Skill) error
+	GetSkill(ctx context.Context, name string) (*models.Skill, error)
+	GetSkillByID(ctx context.Context, id uuid.UUID) (*models.Skill, error)
+	ListSkills(ctx context.Context, category string, tags []string) ([]models.SkillSummary, error)
+	ListSkillsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.SkillSummary], error)
+	SearchSkills(ctx context.Context, query string) ([]models.SkillSummary, error)
+	FindSimilarSkills(ctx context.Context, embedding pgvector.Vector, limit int, threshold float64) ([]models.SimilarSkill, error)
+	HybridSearchSkills(ctx context.Context, q models.SearchQuery) ([]models.SkillSearchResult, error)
+	UpdateSkill(ctx context.Context, skill *models.Skill) error
+	ListSkillRevisions(ctx context.Context, skillID uuid.UUID) ([]models.RevisionInfo, error)
+	GetSkillRevision(ctx context.Context, skillID uuid.UUID, version string) (*models.SkillRevision, error)
+	IncrementSkillUsage(ctx context.Context, skillID uuid.UUID) error
+	SubmitSkillFeedback(ctx context.Context, feedback *models.SkillFeedback) error
+
+	// Commands
+	CreateCommand(ctx context.Context, cmd *models.Command) error
+	GetCommand(ctx context.Context, name string) (*models.Command, error)
+	GetCommandByID(ctx context.Context, id uuid.UUID) (*models.Command, error)
+	ListCommands(ctx context.Context, category string, tags []string) ([]models.CommandSummary, error)
+	ListCommandsPage(ctx context.Context, opts models.ListOptions) (models.Page[models.CommandSummary], error)
+	SearchCommands(ctx context.Context, query string) ([]models.CommandSummary, error)
+	FindSimilarCommands(ctx context.Context, embedding pgvector.Vector, limit int, threshold float64) ([]models.SimilarCommand, error)
+	HybridSearchCommands(ctx context.Context, q models.SearchQuery) ([]models.CommandSearchResult, error)
+	UpdateCommand(ctx context.Context, cmd *models.Command) error
+	ListCommandRevisions(ctx context.Context, commandID uuid.UUID) ([]models.RevisionInfo, error)
+	GetCommandRevision(ctx context.Context, commandID uuid.UUID, version string) (*models.CommandRevision, error)
+	IncrementCommandUsage(ctx context.Context, commandID uuid.UUID) error
+	SubmitCommandFeedback(ctx context.Context, feedback *models.CommandFeedback) error
+
+	// "Did you mean" suggestions for searches of any kind
+	SuggestTerms(ctx context.Context, kind models.EmbeddingKind, text string, filter models.ListFilter) ([]models.Suggestion, error)
+
+	// Embedding maintenance
+	EmbeddingDimension(ctx context.Context, kind models.EmbeddingKind) (int, error)
+	CountEmbeddings(ctx context.Context, kind models.EmbeddingKind, model string) (models.EmbeddingCounts, error)
+	ListStaleEmbeddings(ctx context.Context, kind models.EmbeddingKind, model string, limit int) ([]models.EmbeddingSource, error)
+	SetEmbedding(ctx context.Context, src models.EmbeddingSource, embedding pgvector.Vector, model string) error
+
+	// Task aliases
+	ListTaskAliases(ctx context.Context) ([]models.TaskAlias, error)
+	SetTaskAlias(ctx context.Context, alias *models.TaskAlias) error
+	DeleteTaskAlias(ctx context.Context, domain, keyword string) (bool, error)
+}
+
+// Compile-time checks that both backends satisfy Store
+var (
+	_ Store = (*DB)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
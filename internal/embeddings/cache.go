@@ -0,0 +1,302 @@
+package embeddings
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// ============ Caching Engine ============
+
+// DefaultCacheSize is the number of embeddings kept in memory by default
+const DefaultCacheSize = 10000
+
+// CacheStore is an optional second-level cache behind the in-memory LRU,
+// such as DiskCache. Get reports a miss with ok=false.
+type CacheStore interface {
+	Get(key string) (vec []float32, ok bool, err error)
+	Put(key string, vec []float32) error
+}
+
+// CacheStats reports cache effectiveness
+type CacheStats struct {
+	Model       string `json:"model"`
+	Size        int    `json:"size"`
+	Capacity    int    `json:"capacity"`
+	Hits        uint64 `json:"hits"`
+	StoreHits   uint64 `json:"store_hits"`
+	Misses      uint64 `json:"misses"`
+	StoreErrors uint64 `json:"store_errors"`
+}
+
+// CachedEngine decorates an Engine with an LRU cache keyed by model name
+// and text hash, so repeated texts (hot use_agent tasks, unchanged content
+// on re-registration) are embedded once
+type CachedEngine struct {
+	inner Engine
+	model string
+	store CacheStore
+
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+
+	hits        atomic.Uint64
+	storeHits   atomic.Uint64
+	misses      atomic.Uint64
+	storeErrors atomic.Uint64
+}
+
+type cacheEntry struct {
+	key string
+	vec []float32
+}
+
+// NewCachedEngine wraps inner with an LRU of capacity entries. Keys include
+// inner.Model() so switching models never serves stale vectors. store may be
+// nil.
+func NewCachedEngine(inner Engine, capacity int, store CacheStore) *CachedEngine {
+	if capacity <= 0 {
+		capacity = DefaultCacheSize
+	}
+	return &CachedEngine{
+		inner:    inner,
+		model:    inner.Model(),
+		store:    store,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Embed generates an embedding for a single text
+func (e *CachedEngine) Embed(ctx context.Context, text string) (pgvector.Vector, error) {
+	vectors, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return pgvector.Vector{}, err
+	}
+	return vectors[0], nil
+}
+
+// EmbedBatch returns cached embeddings and sends only the misses, each
+// distinct text once, to the wrapped engine
+func (e *CachedEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	vectors := make([]pgvector.Vector, len(texts))
+
+	// Distinct missing texts and the positions each one fills
+	var missTexts []string
+	missAt := make(map[string][]int)
+
+	for i, text := range texts {
+		key := e.key(text)
+		if positions, ok := missAt[key]; ok {
+			missAt[key] = append(positions, i)
+			continue
+		}
+		if vec, ok := e.lookup(key); ok {
+			vectors[i] = pgvector.NewVector(vec)
+			continue
+		}
+		missTexts = append(missTexts, text)
+		missAt[key] = []int{i}
+	}
+
+	if len(missTexts) == 0 {
+		return vectors, nil
+	}
+
+	e.misses.Add(uint64(len(missTexts)))
+	embedded, err := e.inner.EmbedBatch(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missTexts) {
+		return nil, fmt.Errorf("embedding engine returned %d embeddings for %d texts", len(embedded), len(missTexts))
+	}
+
+	for j, text := range missTexts {
+		key := e.key(text)
+		vec := embedded[j].Slice()
+		e.add(key, vec)
+		if e.store != nil {
+			if err := e.store.Put(key, vec); err != nil {
+				e.storeErrors.Add(1)
+			}
+		}
+		for _, i := range missAt[key] {
+			vectors[i] = embedded[j]
+		}
+	}
+
+	return vectors, nil
+}
+
+// Dimension returns the embedding dimension
+func (e *CachedEngine) Dimension() int {
+	return e.inner.Dimension()
+}
+
+// Model identifies the vectors the engine produces
+func (e *CachedEngine) Model() string {
+	return e.model
+}
+
+// Close closes the wrapped engine if it holds resources
+func (e *CachedEngine) Close() error {
+	if closer, ok := e.inner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the cache counters
+func (e *CachedEngine) Stats() CacheStats {
+	e.mu.Lock()
+	size := e.order.Len()
+	e.mu.Unlock()
+
+	return CacheStats{
+		Model:       e.model,
+		Size:        size,
+		Capacity:    e.capacity,
+		Hits:        e.hits.Load(),
+		StoreHits:   e.storeHits.Load(),
+		Misses:      e.misses.Load(),
+		StoreErrors: e.storeErrors.Load(),
+	}
+}
+
+// key hashes the model name and text
+func (e *CachedEngine) key(text string) string {
+	h := sha256.New()
+	h.Write([]byte(e.model))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lookup checks the LRU and then the store, promoting store hits
+func (e *CachedEngine) lookup(key string) ([]float32, bool) {
+	e.mu.Lock()
+	if el, ok := e.entries[key]; ok {
+		e.order.MoveToFront(el)
+		vec := el.Value.(*cacheEntry).vec
+		e.mu.Unlock()
+		e.hits.Add(1)
+		return vec, true
+	}
+	e.mu.Unlock()
+
+	if e.store == nil {
+		return nil, false
+	}
+	vec, ok, err := e.store.Get(key)
+	if err != nil {
+		e.storeErrors.Add(1)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	e.add(key, vec)
+	e.storeHits.Add(1)
+	return vec, true
+}
+
+// add inserts or refreshes an entry, evicting the least recently used
+func (e *CachedEngine) add(key string, vec []float32) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if el, ok := e.entries[key]; ok {
+		el.Value.(*cacheEntry).vec = vec
+		e.order.MoveToFront(el)
+		return
+	}
+	e.entries[key] = e.order.PushFront(&cacheEntry{key: key, vec: vec})
+	for e.order.Len() > e.capacity {
+		oldest := e.order.Back()
+		e.order.Remove(oldest)
+		delete(e.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// ============ Disk Cache Store ============
+
+// DiskCache stores embeddings as little-endian float32 files under a
+// directory, so the cache survives restarts
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates dir if needed and returns a store rooted there
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create embedding cache directory: %w", err)
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get reads the embedding stored for key
+func (c *DiskCache) Get(key string) ([]float32, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data)%4 != 0 {
+		return nil, false, fmt.Errorf("corrupt embedding cache entry %s", key)
+	}
+	vec := make([]float32, len(data)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vec, true, nil
+}
+
+// Put writes the embedding for key, replacing it atomically
+func (c *DiskCache) Put(key string, vec []float32) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data := make([]byte, len(vec)*4)
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// path shards entries by the first two hex characters of the key
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
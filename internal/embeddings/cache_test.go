@@ -0,0 +1,157 @@
+package embeddings
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// countingEngine embeds each text to its length and records what it was asked
+type countingEngine struct {
+	model string
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (e *countingEngine) Embed(ctx context.Context, text string) (pgvector.Vector, error) {
+	vectors, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return pgvector.Vector{}, err
+	}
+	return vectors[0], nil
+}
+
+func (e *countingEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, append([]string(nil), texts...))
+	e.mu.Unlock()
+
+	vectors := make([]pgvector.Vector, len(texts))
+	for i, text := range texts {
+		vectors[i] = pgvector.NewVector([]float32{float32(len(text))})
+	}
+	return vectors, nil
+}
+
+func (e *countingEngine) Dimension() int { return 1 }
+
+func (e *countingEngine) Model() string { return e.model }
+
+func (e *countingEngine) embedded() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := 0
+	for _, call := range e.calls {
+		n += len(call)
+	}
+	return n
+}
+
+func TestCachedEngineHitsAndDedup(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingEngine{model: "test-model"}
+	e := NewCachedEngine(inner, 10, nil)
+
+	vectors, err := e.EmbedBatch(ctx, []string{"a", "bb", "a", "ccc"})
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	for i, want := range []float32{1, 2, 1, 3} {
+		if got := vectors[i].Slice()[0]; got != want {
+			t.Errorf("vector %d = %v, want %v", i, got, want)
+		}
+	}
+	if n := inner.embedded(); n != 3 {
+		t.Errorf("inner engine embedded %d texts, want 3 distinct", n)
+	}
+
+	if _, err := e.Embed(ctx, "bb"); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if n := inner.embedded(); n != 3 {
+		t.Errorf("cached text was embedded again (%d texts)", n)
+	}
+
+	stats := e.Stats()
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Size != 3 {
+		t.Errorf("stats = %+v, want 1 hit, 3 misses, size 3", stats)
+	}
+}
+
+func TestCachedEngineEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingEngine{model: "test-model"}
+	e := NewCachedEngine(inner, 2, nil)
+
+	e.Embed(ctx, "a")
+	e.Embed(ctx, "b")
+	e.Embed(ctx, "a") // a is now more recent than b
+	e.Embed(ctx, "c") // evicts b
+
+	before := inner.embedded()
+	e.Embed(ctx, "a")
+	if inner.embedded() != before {
+		t.Error("recently used entry was evicted")
+	}
+	e.Embed(ctx, "b")
+	if inner.embedded() != before+1 {
+		t.Error("least recently used entry was not evicted")
+	}
+	if size := e.Stats().Size; size != 2 {
+		t.Errorf("size = %d, want capacity 2", size)
+	}
+}
+
+func TestCachedEngineDiskStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	store, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+	first := NewCachedEngine(&countingEngine{model: "test-model"}, 10, store)
+	if _, err := first.Embed(ctx, "persist me"); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	// A new process with an empty LRU reads the vector back from disk
+	inner := &countingEngine{model: "test-model"}
+	second := NewCachedEngine(inner, 10, store)
+	v, err := second.Embed(ctx, "persist me")
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if v.Slice()[0] != 10 {
+		t.Errorf("vector = %v, want 10", v.Slice())
+	}
+	if inner.embedded() != 0 {
+		t.Error("disk hit still called the inner engine")
+	}
+	if stats := second.Stats(); stats.StoreHits != 1 || stats.Misses != 0 {
+		t.Errorf("stats = %+v, want one store hit", stats)
+	}
+
+	// Another model must not see the first model's vectors
+	other := &countingEngine{model: "other-model"}
+	NewCachedEngine(other, 10, store).Embed(ctx, "persist me")
+	if other.embedded() != 1 {
+		t.Error("cache entries leaked across models")
+	}
+}
+
+func TestNewEngineWrapsCache(t *testing.T) {
+	engine, err := NewEngine(Config{Type: "local", CacheSize: 5})
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	cached, ok := engine.(*CachedEngine)
+	if !ok {
+		t.Fatalf("engine = %T, want *CachedEngine", engine)
+	}
+	if stats := cached.Stats(); stats.Model != LocalModel || stats.Capacity != 5 {
+		t.Errorf("stats = %+v, want model %s and capacity 5", stats, LocalModel)
+	}
+}
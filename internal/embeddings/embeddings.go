@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"math"
 	"net/http"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -18,6 +17,8 @@ import (
 const (
 	// DefaultDimension is the embedding dimension for all-MiniLM-L6-v2
 	DefaultDimension = 384
+	// DefaultHTTPEndpoint is where scripts/embedding_server.py listens
+	DefaultHTTPEndpoint = "http://localhost:8081"
 )
 
 // Engine defines the interface for generating embeddings
@@ -28,20 +29,38 @@ type Engine interface {
 	EmbedBatch(ctx context.Context, texts []string) ([]pgvector.Vector, error)
 	// Dimension returns the embedding dimension
 	Dimension() int
+	// Model identifies the vectors the engine produces; vectors from
+	// different models must not be compared
+	Model() string
 }
 
 // Config holds embedding engine configuration
 type Config struct {
-	// Type: "python" or "http"
+	// Type: "python", "http", "openai", "ollama" or "local"
 	Type string
-	// Model name for sentence-transformers
+	// Model name for sentence-transformers, OpenAI or Ollama
 	Model string
-	// HTTPEndpoint for HTTP-based embedding service
+	// HTTPEndpoint for HTTP-based embedding service, or the base URL of an
+	// OpenAI-compatible or Ollama server
 	HTTPEndpoint string
+	// APIKey authenticates OpenAI-compatible and Ollama requests
+	APIKey string
+	// AuthHeader names the header carrying APIKey; empty means
+	// "Authorization: Bearer <key>"
+	AuthHeader string
+	// BatchSize caps the texts sent per OpenAI or Ollama request
+	BatchSize int
+	// Dimension the model must produce (default 384)
+	Dimension int
 	// PythonPath for Python executable
 	PythonPath string
 	// Timeout for embedding operations
 	Timeout time.Duration
+	// CacheSize is the number of embeddings kept in an in-memory LRU
+	// (0 disables caching)
+	CacheSize int
+	// CacheDir optionally persists cached embeddings on disk
+	CacheDir string
 }
 
 // DefaultConfig returns a default configuration
@@ -56,98 +75,70 @@ func DefaultConfig() Config {
 
 // NewEngine creates an embedding engine based on config
 func NewEngine(cfg Config) (Engine, error) {
+	// Return a nil interface (not a typed nil pointer) on error so callers
+	// can rely on embedder != nil
+	var (
+		engine Engine
+		err    error
+	)
 	switch cfg.Type {
 	case "python":
-		return NewPythonEngine(cfg)
+		engine, err = NewPythonEngine(cfg)
 	case "http":
-		return NewHTTPEngine(cfg)
+		engine, err = NewHTTPEngine(cfg)
+	case "openai":
+		engine, err = NewOpenAIEngine(cfg)
+	case "ollama":
+		engine, err = NewOllamaEngine(cfg)
+	case "local":
+		engine, err = NewLocalEngine(cfg)
 	default:
 		return nil, fmt.Errorf("unknown embedding engine type: %s", cfg.Type)
 	}
-}
-
-// ============ Python-based Engine ============
-
-// PythonEngine uses sentence-transformers via Python subprocess
-type PythonEngine struct {
-	model      string
-	pythonPath string
-	timeout    time.Duration
-}
-
-// NewPythonEngine creates a new Python-based embedding engine
-func NewPythonEngine(cfg Config) (*PythonEngine, error) {
-	return &PythonEngine{
-		model:      cfg.Model,
-		pythonPath: cfg.PythonPath,
-		timeout:    cfg.Timeout,
-	}, nil
-}
-
-// Embed generates an embedding for a single text
-func (e *PythonEngine) Embed(ctx context.Context, text string) (pgvector.Vector, error) {
-	vectors, err := e.EmbedBatch(ctx, []string{text})
 	if err != nil {
-		return pgvector.Vector{}, err
-	}
-	return vectors[0], nil
-}
-
-// EmbedBatch generates embeddings for multiple texts
-func (e *PythonEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
-	// Create Python script for embedding
-	script := fmt.Sprintf(`
-import sys
-import json
-from sentence_transformers import SentenceTransformer
-
-model = SentenceTransformer('%s')
-texts = json.loads(sys.argv[1])
-embeddings = model.encode(texts)
-print(json.dumps(embeddings.tolist()))
-`, e.model)
-
-	textsJSON, err := json.Marshal(texts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal texts: %w", err)
-	}
-
-	ctx, cancel := context.WithTimeout(ctx, e.timeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, e.pythonPath, "-c", script, string(textsJSON))
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("embedding failed: %s: %w", stderr.String(), err)
+		return nil, err
 	}
 
-	var embeddings [][]float32
-	if err := json.Unmarshal(stdout.Bytes(), &embeddings); err != nil {
-		return nil, fmt.Errorf("failed to parse embeddings: %w", err)
+	if cfg.CacheSize > 0 {
+		var store CacheStore
+		if cfg.CacheDir != "" {
+			disk, err := NewDiskCache(cfg.CacheDir)
+			if err != nil {
+				return nil, err
+			}
+			store = disk
+		}
+		engine = NewCachedEngine(engine, cfg.CacheSize, store)
 	}
+	return engine, nil
+}
 
-	vectors := make([]pgvector.Vector, len(embeddings))
-	for i, emb := range embeddings {
-		vectors[i] = pgvector.NewVector(emb)
+// dimensionOrDefault returns the configured dimension or DefaultDimension
+func dimensionOrDefault(cfg Config) int {
+	if cfg.Dimension > 0 {
+		return cfg.Dimension
 	}
-
-	return vectors, nil
+	return DefaultDimension
 }
 
-// Dimension returns the embedding dimension
-func (e *PythonEngine) Dimension() int {
-	return DefaultDimension
+// checkDimensions rejects vectors that would not fit the embedding columns
+func checkDimensions(embeddings [][]float32, dimension int) error {
+	for _, emb := range embeddings {
+		if len(emb) != dimension {
+			return fmt.Errorf("embedding service returned dimension %d, expected %d", len(emb), dimension)
+		}
+	}
+	return nil
 }
 
 // ============ HTTP-based Engine ============
 
 // HTTPEngine uses an HTTP endpoint for embeddings
 type HTTPEngine struct {
-	endpoint string
-	client   *http.Client
+	endpoint  string
+	model     string
+	dimension int
+	client    *http.Client
 }
 
 // NewHTTPEngine creates a new HTTP-based embedding engine
@@ -157,7 +148,9 @@ func NewHTTPEngine(cfg Config) (*HTTPEngine, error) {
 	}
 
 	return &HTTPEngine{
-		endpoint: strings.TrimSuffix(cfg.HTTPEndpoint, "/"),
+		endpoint:  strings.TrimSuffix(cfg.HTTPEndpoint, "/"),
+		model:     cfg.Model,
+		dimension: dimensionOrDefault(cfg),
 		client: &http.Client{
 			Timeout: cfg.Timeout,
 		},
@@ -208,6 +201,9 @@ func (e *HTTPEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	if err := checkDimensions(result.Embeddings, e.dimension); err != nil {
+		return nil, err
+	}
 
 	vectors := make([]pgvector.Vector, len(result.Embeddings))
 	for i, emb := range result.Embeddings {
@@ -219,20 +215,28 @@ func (e *HTTPEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector
 
 // Dimension returns the embedding dimension
 func (e *HTTPEngine) Dimension() int {
-	return DefaultDimension
+	return e.dimension
+}
+
+// Model identifies the vectors the engine produces
+func (e *HTTPEngine) Model() string {
+	return "http:" + e.model
 }
 
 // ============ Utility Functions ============
 
 // CreateAgentEmbedding creates an embedding from agent data
 func CreateAgentEmbedding(engine Engine, ctx context.Context, name, description string, skills []string) (pgvector.Vector, error) {
-	// Combine agent info into a single text for embedding
-	text := fmt.Sprintf("%s. %s. Skills: %s",
+	return engine.Embed(ctx, AgentText(name, description, skills))
+}
+
+// AgentText combines agent info into the single text that is embedded
+func AgentText(name, description string, skills []string) string {
+	return fmt.Sprintf("%s. %s. Skills: %s",
 		name,
 		description,
 		strings.Join(skills, ", "),
 	)
-	return engine.Embed(ctx, text)
 }
 
 // CosineSimilarity calculates cosine similarity between two vectors
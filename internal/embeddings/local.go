@@ -0,0 +1,153 @@
+package embeddings
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// ============ Local (in-process) Engine ============
+
+// LocalModel identifies vectors produced by LocalEngine
+const LocalModel = "hashed-ngram-v1"
+
+// Feature weights: whole words dominate, word pairs add phrase context and
+// character trigrams let inflections and typos still overlap
+const (
+	wordWeight    = 1.0
+	bigramWeight  = 0.5
+	trigramWeight = 0.25
+)
+
+// stopWords carry no topical signal and would otherwise dominate short texts
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"i": true, "in": true, "is": true, "it": true, "its": true, "me": true,
+	"my": true, "of": true, "on": true, "or": true, "please": true, "so": true,
+	"that": true, "the": true, "this": true, "to": true, "was": true, "we": true,
+	"will": true, "with": true, "you": true, "your": true,
+}
+
+// LocalEngine embeds text in-process with signed feature hashing over words,
+// word bigrams and character trigrams, so semantic search works without
+// Python or an embedding service. Vectors are L2-normalised and have
+// DefaultDimension entries to fit the existing vector(384) columns. They
+// are not comparable with sentence-transformer vectors, so switching engines
+// requires re-embedding stored rows.
+type LocalEngine struct{}
+
+// NewLocalEngine creates an in-process embedding engine
+func NewLocalEngine(cfg Config) (*LocalEngine, error) {
+	return &LocalEngine{}, nil
+}
+
+// Embed generates an embedding for a single text
+func (e *LocalEngine) Embed(ctx context.Context, text string) (pgvector.Vector, error) {
+	return pgvector.NewVector(localVector(text)), nil
+}
+
+// EmbedBatch generates embeddings for multiple texts
+func (e *LocalEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	vectors := make([]pgvector.Vector, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = pgvector.NewVector(localVector(text))
+	}
+	return vectors, nil
+}
+
+// Dimension returns the embedding dimension
+func (e *LocalEngine) Dimension() int {
+	return DefaultDimension
+}
+
+// Model identifies the vectors the engine produces
+func (e *LocalEngine) Model() string {
+	return LocalModel
+}
+
+// localVector builds the hashed feature vector for text
+func localVector(text string) []float32 {
+	counts := make(map[string]int)
+
+	words := tokenize(text)
+	for i, w := range words {
+		counts["w:"+w]++
+		if i > 0 {
+			counts["b:"+words[i-1]+" "+w]++
+		}
+		padded := []rune("<" + w + ">")
+		for j := 0; j+3 <= len(padded); j++ {
+			counts["c:"+string(padded[j:j+3])]++
+		}
+	}
+
+	// Text made only of stop words or punctuation still gets a stable vector
+	if len(counts) == 0 {
+		counts["t:"+strings.ToLower(strings.TrimSpace(text))] = 1
+	}
+
+	vec := make([]float64, DefaultDimension)
+	for feature, n := range counts {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+
+		// Sublinear term frequency; the sign bit halves collision bias
+		v := featureWeight(feature) * (1 + math.Log(float64(n)))
+		if sum>>63 == 1 {
+			v = -v
+		}
+		vec[sum%DefaultDimension] += v
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	out := make([]float32, DefaultDimension)
+	if norm == 0 {
+		// Features cancelled out; a zero vector would make cosine distance NaN
+		out[0] = 1
+		return out
+	}
+	for i, v := range vec {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+// featureWeight returns the weight of a feature by its kind prefix
+func featureWeight(feature string) float64 {
+	switch feature[0] {
+	case 'b':
+		return bigramWeight
+	case 'c':
+		return trigramWeight
+	default:
+		return wordWeight
+	}
+}
+
+// tokenize lowercases text and splits it into words, dropping stop words
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, f := range fields {
+		if !stopWords[f] {
+			words = append(words, f)
+		}
+	}
+	return words
+}
@@ -0,0 +1,71 @@
+package embeddings
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestLocalEngineVectors(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(Config{Type: "local"})
+	if err != nil {
+		t.Fatalf("NewEngine(local) failed: %v", err)
+	}
+
+	texts := []string{"Fix the security vulnerability in the login handler", "", "the and of"}
+	vectors, err := engine.EmbedBatch(ctx, texts)
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+
+	for i, v := range vectors {
+		values := v.Slice()
+		if len(values) != DefaultDimension {
+			t.Fatalf("text %d: dimension = %d, want %d", i, len(values), DefaultDimension)
+		}
+		var norm float64
+		for _, x := range values {
+			norm += float64(x) * float64(x)
+		}
+		if math.Abs(norm-1) > 1e-5 {
+			t.Errorf("text %d: squared norm = %v, want 1", i, norm)
+		}
+	}
+
+	again, _ := engine.Embed(ctx, texts[0])
+	if CosineSimilarity(again, vectors[0]) < 0.9999 {
+		t.Error("embedding is not deterministic")
+	}
+}
+
+func TestLocalEngineSimilarity(t *testing.T) {
+	ctx := context.Background()
+	engine, _ := NewLocalEngine(Config{})
+
+	tests := []struct {
+		query, related, unrelated string
+	}{
+		{"audit code for security vulnerabilities", "Security auditor that finds vulnerabilities in code", "Writes marketing copy for landing pages"},
+		{"kubernetes pod keeps crashing", "Debug Kubernetes pods and deployments with kubectl", "Design a relational database schema"},
+		{"reviw my pull request", "Review pull requests for code quality", "Translate documents into French"},
+	}
+	for _, tt := range tests {
+		q, _ := engine.Embed(ctx, tt.query)
+		r, _ := engine.Embed(ctx, tt.related)
+		u, _ := engine.Embed(ctx, tt.unrelated)
+		if rs, us := CosineSimilarity(q, r), CosineSimilarity(q, u); rs <= us {
+			t.Errorf("%q: related similarity %.3f <= unrelated %.3f", tt.query, rs, us)
+		}
+	}
+}
+
+func TestNewEngineErrorReturnsNilInterface(t *testing.T) {
+	engine, err := NewEngine(Config{Type: "http"})
+	if err == nil {
+		t.Fatal("expected an error for a missing HTTP endpoint")
+	}
+	if engine != nil {
+		t.Errorf("engine = %#v, want a nil interface", engine)
+	}
+}
@@ -0,0 +1,295 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// ============ Hosted API Engines ============
+
+// Default endpoints and batch size for the hosted API engines
+const (
+	DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+	DefaultOllamaEndpoint = "http://localhost:11434"
+	DefaultBatchSize      = 64
+)
+
+// apiClient holds what the OpenAI and Ollama engines share: where to send
+// requests, how to authenticate and how to split and check batches
+type apiClient struct {
+	url        string
+	model      string
+	authHeader string
+	authValue  string
+	batchSize  int
+	dimension  int
+	client     *http.Client
+}
+
+// newAPIClient builds a client for baseURL+path. With an APIKey and no
+// AuthHeader the key is sent as "Authorization: Bearer <key>"; with an
+// AuthHeader (such as "api-key") the key is sent as that header's value.
+func newAPIClient(cfg Config, defaultEndpoint, path string) (*apiClient, error) {
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("embedding model is required")
+	}
+
+	base := strings.TrimSuffix(cfg.HTTPEndpoint, "/")
+	if base == "" {
+		base = defaultEndpoint
+	}
+
+	c := &apiClient{
+		url:       base + path,
+		model:     cfg.Model,
+		batchSize: cfg.BatchSize,
+		dimension: dimensionOrDefault(cfg),
+		client:    &http.Client{Timeout: cfg.Timeout},
+	}
+	if c.batchSize <= 0 {
+		c.batchSize = DefaultBatchSize
+	}
+	if cfg.APIKey != "" {
+		if cfg.AuthHeader == "" || strings.EqualFold(cfg.AuthHeader, "Authorization") {
+			c.authHeader, c.authValue = "Authorization", "Bearer "+cfg.APIKey
+		} else {
+			c.authHeader, c.authValue = cfg.AuthHeader, cfg.APIKey
+		}
+	}
+	return c, nil
+}
+
+// embedInBatches splits texts into batchSize chunks, embeds each with fn and
+// checks the results have the configured dimension
+func (c *apiClient) embedInBatches(ctx context.Context, texts []string, fn func(context.Context, []string) ([][]float32, error)) ([]pgvector.Vector, error) {
+	vectors := make([]pgvector.Vector, 0, len(texts))
+	for start := 0; start < len(texts); start += c.batchSize {
+		end := min(start+c.batchSize, len(texts))
+		batch := texts[start:end]
+
+		embeddings, err := fn(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(embeddings) != len(batch) {
+			return nil, fmt.Errorf("embedding service returned %d embeddings for %d texts", len(embeddings), len(batch))
+		}
+		if err := checkDimensions(embeddings, c.dimension); err != nil {
+			return nil, err
+		}
+		for _, emb := range embeddings {
+			vectors = append(vectors, pgvector.NewVector(emb))
+		}
+	}
+	return vectors, nil
+}
+
+// post sends body as JSON and decodes a 200 response into out. errorMessage
+// extracts the service's own message from a failed response body.
+func (c *apiClient) post(ctx context.Context, body, out any, errorMessage func([]byte) string) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.authHeader != "" {
+		req.Header.Set(c.authHeader, c.authValue)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if msg := errorMessage(data); msg != "" {
+			return fmt.Errorf("embedding service returned status %d: %s", resp.StatusCode, msg)
+		}
+		return fmt.Errorf("embedding service returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// ============ OpenAI-compatible Engine ============
+
+// OpenAIEngine calls an OpenAI-compatible /embeddings endpoint (OpenAI,
+// Azure OpenAI, vLLM, LiteLLM and similar). HTTPEndpoint is the API base
+// URL including /v1.
+type OpenAIEngine struct {
+	api *apiClient
+	// dimensions is sent with each request when configured, so models that
+	// support shortening (text-embedding-3-*) fit the vector column
+	dimensions int
+}
+
+// NewOpenAIEngine creates an OpenAI-compatible embedding engine
+func NewOpenAIEngine(cfg Config) (*OpenAIEngine, error) {
+	api, err := newAPIClient(cfg, DefaultOpenAIEndpoint, "/embeddings")
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAIEngine{api: api, dimensions: cfg.Dimension}, nil
+}
+
+type openAIRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	EncodingFormat string   `json:"encoding_format"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+}
+
+type openAIResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed generates an embedding for a single text
+func (e *OpenAIEngine) Embed(ctx context.Context, text string) (pgvector.Vector, error) {
+	vectors, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return pgvector.Vector{}, err
+	}
+	return vectors[0], nil
+}
+
+// EmbedBatch generates embeddings for multiple texts
+func (e *OpenAIEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	return e.api.embedInBatches(ctx, texts, e.embed)
+}
+
+// Dimension returns the embedding dimension
+func (e *OpenAIEngine) Dimension() int {
+	return e.api.dimension
+}
+
+// Model identifies the vectors the engine produces
+func (e *OpenAIEngine) Model() string {
+	return "openai:" + e.api.model
+}
+
+// embed sends one batch
+func (e *OpenAIEngine) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var result openAIResponse
+	err := e.api.post(ctx, openAIRequest{
+		Model:          e.api.model,
+		Input:          texts,
+		EncodingFormat: "float",
+		Dimensions:     e.dimensions,
+	}, &result, openAIErrorMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items carry their input position; don't rely on response order
+	sort.Slice(result.Data, func(i, j int) bool { return result.Data[i].Index < result.Data[j].Index })
+	embeddings := make([][]float32, len(result.Data))
+	for i, item := range result.Data {
+		embeddings[i] = item.Embedding
+	}
+	return embeddings, nil
+}
+
+// openAIErrorMessage reads {"error": {"message": "..."}}
+func openAIErrorMessage(body []byte) string {
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	return resp.Error.Message
+}
+
+// ============ Ollama Engine ============
+
+// OllamaEngine calls Ollama's /api/embed endpoint. HTTPEndpoint is the
+// Ollama server URL.
+type OllamaEngine struct {
+	api *apiClient
+}
+
+// NewOllamaEngine creates an Ollama embedding engine
+func NewOllamaEngine(cfg Config) (*OllamaEngine, error) {
+	api, err := newAPIClient(cfg, DefaultOllamaEndpoint, "/api/embed")
+	if err != nil {
+		return nil, err
+	}
+	return &OllamaEngine{api: api}, nil
+}
+
+type ollamaRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed generates an embedding for a single text
+func (e *OllamaEngine) Embed(ctx context.Context, text string) (pgvector.Vector, error) {
+	vectors, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return pgvector.Vector{}, err
+	}
+	return vectors[0], nil
+}
+
+// EmbedBatch generates embeddings for multiple texts
+func (e *OllamaEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	return e.api.embedInBatches(ctx, texts, e.embed)
+}
+
+// Dimension returns the embedding dimension
+func (e *OllamaEngine) Dimension() int {
+	return e.api.dimension
+}
+
+// Model identifies the vectors the engine produces
+func (e *OllamaEngine) Model() string {
+	return "ollama:" + e.api.model
+}
+
+// embed sends one batch
+func (e *OllamaEngine) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var result ollamaResponse
+	err := e.api.post(ctx, ollamaRequest{Model: e.api.model, Input: texts}, &result, ollamaErrorMessage)
+	if err != nil {
+		return nil, err
+	}
+	return result.Embeddings, nil
+}
+
+// ollamaErrorMessage reads {"error": "..."}
+func ollamaErrorMessage(body []byte) string {
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	return resp.Error
+}
@@ -0,0 +1,151 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// vectorFor returns a dim-sized vector whose first entry is len(text)
+func vectorFor(text string, dim int) []float32 {
+	v := make([]float32, dim)
+	v[0] = float32(len(text))
+	return v
+}
+
+func TestOpenAIEngine(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "bad key"}}`))
+			return
+		}
+
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		if req.Model != "text-embedding-3-small" || req.EncodingFormat != "float" {
+			t.Errorf("request = %+v", req)
+		}
+		mu.Lock()
+		batches = append(batches, req.Input)
+		first := len(batches) == 1
+		mu.Unlock()
+		if first && req.Dimensions != 8 {
+			t.Errorf("dimensions = %d, want the configured 8", req.Dimensions)
+		}
+
+		// Answer in reverse order; the engine must reorder by index
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []item
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, item{Index: i, Embedding: vectorFor(req.Input[i], 8)})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"object": "list", "data": data})
+	}))
+	defer srv.Close()
+
+	cfg := Config{Type: "openai", HTTPEndpoint: srv.URL + "/v1/", Model: "text-embedding-3-small", APIKey: "sk-test", BatchSize: 2, Dimension: 8}
+	engine, err := NewEngine(cfg)
+	if err != nil {
+		t.Fatalf("NewEngine(openai) failed: %v", err)
+	}
+	if engine.Dimension() != 8 {
+		t.Errorf("Dimension() = %d, want 8", engine.Dimension())
+	}
+
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+	vectors, err := engine.EmbedBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	for i, v := range vectors {
+		if got := v.Slice()[0]; got != float32(i+1) {
+			t.Errorf("vector %d = %v, want %d", i, got, i+1)
+		}
+	}
+	if len(batches) != 3 || len(batches[2]) != 1 {
+		t.Errorf("batches = %v, want 3 requests of at most 2 texts", batches)
+	}
+
+	// The service's own error message is surfaced
+	cfg.APIKey = "wrong"
+	engine, _ = NewEngine(cfg)
+	if _, err := engine.Embed(context.Background(), "x"); err == nil || !strings.Contains(err.Error(), "bad key") {
+		t.Errorf("error = %v, want it to include the service message", err)
+	}
+
+	// Vectors that don't fit the configured dimension are rejected
+	cfg.APIKey = "sk-test"
+	cfg.Dimension = 0
+	engine, _ = NewEngine(cfg)
+	if _, err := engine.Embed(context.Background(), "x"); err == nil || !strings.Contains(err.Error(), "dimension 8") {
+		t.Errorf("error = %v, want a dimension mismatch", err)
+	}
+}
+
+func TestOllamaEngine(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("X-Api-Key") != "secret" || r.Header.Get("Authorization") != "" {
+			t.Errorf("auth headers = %v", r.Header)
+		}
+
+		var req ollamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "model \"missing\" not found, try pulling it first"}`))
+			return
+		}
+
+		embeddings := make([][]float32, len(req.Input))
+		for i, text := range req.Input {
+			embeddings[i] = vectorFor(text, DefaultDimension)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"model": req.Model, "embeddings": embeddings})
+	}))
+	defer srv.Close()
+
+	cfg := Config{Type: "ollama", HTTPEndpoint: srv.URL, Model: "all-minilm", APIKey: "secret", AuthHeader: "X-Api-Key"}
+	engine, err := NewEngine(cfg)
+	if err != nil {
+		t.Fatalf("NewEngine(ollama) failed: %v", err)
+	}
+
+	v, err := engine.Embed(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if got := v.Slice(); len(got) != DefaultDimension || got[0] != 5 {
+		t.Errorf("vector has dimension %d and first entry %v", len(got), got[0])
+	}
+
+	cfg.Model = "missing"
+	engine, _ = NewEngine(cfg)
+	if _, err := engine.Embed(context.Background(), "hello"); err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Errorf("error = %v, want the Ollama message", err)
+	}
+
+	if _, err := NewEngine(Config{Type: "ollama"}); err == nil {
+		t.Error("expected an error without a model")
+	}
+}
@@ -0,0 +1,413 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// ============ Python-based Engine ============
+
+// workerScript loads the model once and then answers line-delimited JSON
+// requests ({"id": n, "texts": [...]}) on stdin with one response line per
+// request ({"id": n, "embeddings": [...]} or {"id": n, "error": "..."}).
+// The first line it writes is {"ready": true} once the model has loaded.
+const workerScript = `
+import json
+import sys
+
+from sentence_transformers import SentenceTransformer
+
+model = SentenceTransformer(sys.argv[1])
+out = sys.stdout
+out.write(json.dumps({"ready": True}) + "\n")
+out.flush()
+
+for line in sys.stdin:
+    line = line.strip()
+    if not line:
+        continue
+    req = {}
+    try:
+        req = json.loads(line)
+        embeddings = model.encode(req["texts"])
+        resp = {"id": req["id"], "embeddings": embeddings.tolist()}
+    except Exception as exc:
+        resp = {"id": req.get("id"), "error": str(exc)}
+    out.write(json.dumps(resp) + "\n")
+    out.flush()
+`
+
+const (
+	// workerStartTimeout bounds how long a request waits for the model to
+	// load; it is separate from Config.Timeout because the first load can
+	// involve downloading the model
+	workerStartTimeout = 2 * time.Minute
+	// workerStopTimeout is how long Close waits for the worker to exit
+	// after its stdin is closed before killing it
+	workerStopTimeout = 5 * time.Second
+	// stderrTailSize is how much worker stderr is kept for error messages
+	stderrTailSize = 4096
+)
+
+// errEngineClosed is returned by requests made after Close
+var errEngineClosed = errors.New("python embedding engine is closed")
+
+// PythonEngine uses sentence-transformers in a long-lived Python worker
+// process. The worker is started on first use, serves concurrent requests
+// matched by ID, and is restarted on the next request if it crashes.
+type PythonEngine struct {
+	model      string
+	pythonPath string
+	timeout    time.Duration
+	dimension  int
+
+	// command builds the worker process; tests replace it with a fake
+	command func() *exec.Cmd
+
+	mu     sync.Mutex
+	worker *pythonWorker
+	nextID uint64
+	closed bool
+}
+
+// NewPythonEngine creates a new Python-based embedding engine
+func NewPythonEngine(cfg Config) (*PythonEngine, error) {
+	e := &PythonEngine{
+		model:      cfg.Model,
+		pythonPath: cfg.PythonPath,
+		timeout:    cfg.Timeout,
+		dimension:  dimensionOrDefault(cfg),
+	}
+	e.command = func() *exec.Cmd {
+		return exec.Command(e.pythonPath, "-c", workerScript, e.model)
+	}
+	return e, nil
+}
+
+// Embed generates an embedding for a single text
+func (e *PythonEngine) Embed(ctx context.Context, text string) (pgvector.Vector, error) {
+	vectors, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return pgvector.Vector{}, err
+	}
+	return vectors[0], nil
+}
+
+// EmbedBatch generates embeddings for multiple texts
+func (e *PythonEngine) EmbedBatch(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	w, id, err := e.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.waitReady(ctx); err != nil {
+		return nil, err
+	}
+
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	resp, err := w.call(ctx, workerRequest{ID: id, Texts: texts})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("embedding failed: %s", resp.Error)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding worker returned %d embeddings for %d texts", len(resp.Embeddings), len(texts))
+	}
+	if err := checkDimensions(resp.Embeddings, e.dimension); err != nil {
+		return nil, err
+	}
+
+	vectors := make([]pgvector.Vector, len(resp.Embeddings))
+	for i, emb := range resp.Embeddings {
+		vectors[i] = pgvector.NewVector(emb)
+	}
+
+	return vectors, nil
+}
+
+// Dimension returns the embedding dimension
+func (e *PythonEngine) Dimension() int {
+	return e.dimension
+}
+
+// Model identifies the vectors the engine produces
+func (e *PythonEngine) Model() string {
+	return "python:" + e.model
+}
+
+// Close stops the worker process. In-flight requests fail and later
+// requests return an error.
+func (e *PythonEngine) Close() error {
+	e.mu.Lock()
+	w := e.worker
+	e.worker = nil
+	e.closed = true
+	e.mu.Unlock()
+
+	if w == nil {
+		return nil
+	}
+	return w.stop()
+}
+
+// acquire returns a running worker, starting a new one if there is none
+// or the previous one exited, along with a fresh request ID
+func (e *PythonEngine) acquire() (*pythonWorker, uint64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil, 0, errEngineClosed
+	}
+	if e.worker == nil || e.worker.exited() {
+		w, err := startWorker(e.command())
+		if err != nil {
+			return nil, 0, err
+		}
+		e.worker = w
+	}
+	e.nextID++
+	return e.worker, e.nextID, nil
+}
+
+// ============ Worker Process ============
+
+type workerRequest struct {
+	ID    uint64   `json:"id"`
+	Texts []string `json:"texts"`
+}
+
+type workerResponse struct {
+	ID         *uint64     `json:"id"`
+	Ready      bool        `json:"ready,omitempty"`
+	Embeddings [][]float32 `json:"embeddings,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// pythonWorker is one running worker process
+type pythonWorker struct {
+	cmd    *exec.Cmd
+	stderr *tailBuffer
+
+	writeMu sync.Mutex
+	stdin   io.WriteCloser
+
+	mu      sync.Mutex
+	pending map[uint64]chan workerResponse
+
+	ready   chan struct{} // closed when the worker reports it is ready
+	done    chan struct{} // closed when the process has exited
+	exitErr error         // set before done is closed
+}
+
+// startWorker launches cmd and starts reading its responses
+func startWorker(cmd *exec.Cmd) (*pythonWorker, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &pythonWorker{
+		cmd:     cmd,
+		stderr:  &tailBuffer{max: stderrTailSize},
+		stdin:   stdin,
+		pending: make(map[uint64]chan workerResponse),
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	cmd.Stderr = w.stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start embedding worker: %w", err)
+	}
+
+	go w.readLoop(stdout)
+	return w, nil
+}
+
+// readLoop dispatches responses to waiting callers until the worker exits
+func (w *pythonWorker) readLoop(stdout io.Reader) {
+	dec := json.NewDecoder(stdout)
+	readyClosed := false
+	var readErr error
+	for {
+		var resp workerResponse
+		if err := dec.Decode(&resp); err != nil {
+			if err != io.EOF {
+				readErr = fmt.Errorf("invalid worker output: %w", err)
+			}
+			break
+		}
+		if resp.Ready {
+			if !readyClosed {
+				close(w.ready)
+				readyClosed = true
+			}
+			continue
+		}
+		if resp.ID == nil {
+			continue
+		}
+
+		w.mu.Lock()
+		ch, ok := w.pending[*resp.ID]
+		delete(w.pending, *resp.ID)
+		w.mu.Unlock()
+
+		// Responses for requests that already timed out are dropped
+		if ok {
+			ch <- resp
+		}
+	}
+
+	// Make sure the process is gone even if only its stdout broke
+	if readErr != nil {
+		_ = w.cmd.Process.Kill()
+	}
+	waitErr := w.cmd.Wait()
+
+	err := readErr
+	if err == nil {
+		err = waitErr
+	}
+	if err == nil {
+		err = errors.New("worker exited")
+	}
+	if tail := strings.TrimSpace(w.stderr.String()); tail != "" {
+		err = fmt.Errorf("%w: %s", err, tail)
+	}
+	w.exitErr = fmt.Errorf("embedding worker stopped: %w", err)
+	close(w.done)
+}
+
+// waitReady blocks until the model has loaded
+func (w *pythonWorker) waitReady(ctx context.Context) error {
+	timer := time.NewTimer(workerStartTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-w.done:
+		return w.exitErr
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return fmt.Errorf("embedding worker did not become ready within %s", workerStartTimeout)
+	}
+}
+
+// call sends one request and waits for its response
+func (w *pythonWorker) call(ctx context.Context, req workerRequest) (workerResponse, error) {
+	line, err := json.Marshal(req)
+	if err != nil {
+		return workerResponse{}, fmt.Errorf("failed to marshal texts: %w", err)
+	}
+
+	ch := make(chan workerResponse, 1)
+	w.mu.Lock()
+	w.pending[req.ID] = ch
+	w.mu.Unlock()
+
+	w.writeMu.Lock()
+	_, err = w.stdin.Write(append(line, '\n'))
+	w.writeMu.Unlock()
+	if err != nil {
+		w.forget(req.ID)
+		select {
+		case <-w.done:
+			return workerResponse{}, w.exitErr
+		default:
+			return workerResponse{}, fmt.Errorf("failed to send embedding request: %w", err)
+		}
+	}
+
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-w.done:
+		return workerResponse{}, w.exitErr
+	case <-ctx.Done():
+		w.forget(req.ID)
+		return workerResponse{}, fmt.Errorf("embedding request timed out: %w", ctx.Err())
+	}
+}
+
+// forget drops a pending request so a late response is discarded
+func (w *pythonWorker) forget(id uint64) {
+	w.mu.Lock()
+	delete(w.pending, id)
+	w.mu.Unlock()
+}
+
+// exited reports whether the process has exited
+func (w *pythonWorker) exited() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop closes stdin so the worker exits on EOF, killing it if it does not
+func (w *pythonWorker) stop() error {
+	_ = w.stdin.Close()
+
+	timer := time.NewTimer(workerStopTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-timer.C:
+		if err := w.cmd.Process.Kill(); err != nil {
+			return fmt.Errorf("failed to kill embedding worker: %w", err)
+		}
+		<-w.done
+		return nil
+	}
+}
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
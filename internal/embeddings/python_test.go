@@ -0,0 +1,195 @@
+package embeddings
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const fakeWorkerEnv = "EMBEDDINGS_FAKE_WORKER"
+
+// TestFakeWorker is not a real test: when re-executed by newFakeEngine it
+// speaks the worker protocol. Each text embeds to its length; "slow" waits
+// before answering, "fail" returns an error and "crash" kills the process.
+func TestFakeWorker(t *testing.T) {
+	if os.Getenv(fakeWorkerEnv) != "1" {
+		return
+	}
+
+	var mu sync.Mutex
+	enc := json.NewEncoder(os.Stdout)
+	write := func(v any) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(v)
+	}
+	write(map[string]any{"ready": true})
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req workerRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			continue
+		}
+		go func() {
+			embeddings := make([][]float32, len(req.Texts))
+			for i, text := range req.Texts {
+				switch text {
+				case "slow":
+					time.Sleep(500 * time.Millisecond)
+				case "fail":
+					write(map[string]any{"id": req.ID, "error": "model exploded"})
+					return
+				case "crash":
+					fmt.Fprintln(os.Stderr, "segfault in fake worker")
+					os.Exit(3)
+				}
+				embeddings[i] = []float32{float32(len(text))}
+			}
+			write(map[string]any{"id": req.ID, "embeddings": embeddings})
+		}()
+	}
+	os.Exit(0)
+}
+
+func newFakeEngine(t *testing.T, timeout time.Duration) *PythonEngine {
+	t.Helper()
+	e, err := NewPythonEngine(Config{Timeout: timeout, Dimension: 1})
+	if err != nil {
+		t.Fatalf("NewPythonEngine failed: %v", err)
+	}
+	e.command = func() *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestFakeWorker$")
+		cmd.Env = append(os.Environ(), fakeWorkerEnv+"=1")
+		return cmd
+	}
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+func TestPythonEngineConcurrentRequests(t *testing.T) {
+	ctx := context.Background()
+	e := newFakeEngine(t, 5*time.Second)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 21)
+
+	// A slow request must not hold up the others or receive their responses
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := e.Embed(ctx, "slow"); err != nil {
+			errs <- err
+		}
+	}()
+
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			text := strings.Repeat("x", n)
+			v, err := e.Embed(ctx, text)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if got := v.Slice()[0]; got != float32(n) {
+				errs <- fmt.Errorf("text of length %d got embedding %v", n, got)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	vectors, err := e.EmbedBatch(ctx, []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	for i, v := range vectors {
+		if got := v.Slice()[0]; got != float32(i+1) {
+			t.Errorf("batch item %d = %v, want %d", i, got, i+1)
+		}
+	}
+}
+
+func TestPythonEngineTimeoutAndErrors(t *testing.T) {
+	ctx := context.Background()
+	e := newFakeEngine(t, 100*time.Millisecond)
+
+	if _, err := e.Embed(ctx, "slow"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow request error = %v, want a timeout", err)
+	}
+	if _, err := e.Embed(ctx, "fail"); err == nil || !strings.Contains(err.Error(), "model exploded") {
+		t.Errorf("failing request error = %v, want the worker error", err)
+	}
+
+	// Neither a timeout nor a per-request error takes the worker down
+	first := e.worker
+	if _, err := e.Embed(ctx, "ok"); err != nil {
+		t.Fatalf("Embed after errors failed: %v", err)
+	}
+	if e.worker != first {
+		t.Error("worker was restarted after a recoverable error")
+	}
+}
+
+func TestPythonEngineRestartsAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	e := newFakeEngine(t, 5*time.Second)
+
+	if _, err := e.Embed(ctx, "warm"); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	first := e.worker
+
+	_, err := e.Embed(ctx, "crash")
+	if err == nil || !strings.Contains(err.Error(), "segfault in fake worker") {
+		t.Fatalf("crash error = %v, want it to include the worker stderr", err)
+	}
+
+	v, err := e.Embed(ctx, "back")
+	if err != nil {
+		t.Fatalf("Embed after crash failed: %v", err)
+	}
+	if v.Slice()[0] != 4 {
+		t.Errorf("embedding = %v, want 4", v.Slice()[0])
+	}
+	if e.worker == first {
+		t.Error("crashed worker was not replaced")
+	}
+}
+
+func TestPythonEngineClose(t *testing.T) {
+	ctx := context.Background()
+	e := newFakeEngine(t, 5*time.Second)
+
+	if _, err := e.Embed(ctx, "warm"); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	w := e.worker
+
+	if err := e.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !w.exited() {
+		t.Error("worker still running after Close")
+	}
+	if _, err := e.Embed(ctx, "late"); !errors.Is(err, errEngineClosed) {
+		t.Errorf("Embed after Close error = %v, want errEngineClosed", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
+	"time"
 
 	"github.com/aminghadersohi/agentmcp/internal/database"
 	"github.com/aminghadersohi/agentmcp/internal/models"
@@ -17,6 +19,10 @@ type Config struct {
 	AutoQuarantineThreshold int
 	// ReputationBanThreshold: reputation below this triggers review
 	ReputationBanThreshold float64
+	// BanCoolingOff: minimum time between a judge's ban ruling and its execution
+	BanCoolingOff time.Duration
+	// MaxAppeals: appeals an agent's creator may file over its lifetime
+	MaxAppeals int
 	// Enabled controls whether governance is active
 	Enabled bool
 }
@@ -26,24 +32,52 @@ func DefaultConfig() Config {
 	return Config{
 		AutoQuarantineThreshold: 3,
 		ReputationBanThreshold:  10.0,
+		BanCoolingOff:           24 * time.Hour,
+		MaxAppeals:              3,
 		Enabled:                 true,
 	}
 }
 
 // Engine manages governance operations
 type Engine struct {
-	db     *database.DB
+	db     database.Store
 	config Config
 }
 
 // New creates a new governance engine
-func New(db *database.DB, cfg Config) *Engine {
+func New(db database.Store, cfg Config) *Engine {
 	return &Engine{
 		db:     db,
 		config: cfg,
 	}
 }
 
+// Actor identifies who takes a governance action and the role they act in.
+// Name is recorded alongside the role so the audit trail shows the caller.
+type Actor struct {
+	Name string
+	Role models.GovernanceRole
+}
+
+// SystemActor takes automatic actions such as auto-quarantine
+var SystemActor = Actor{Name: "system", Role: models.RolePolice}
+
+// authorize checks that actor may perform action
+func authorize(actor Actor, action models.GovernanceActionType) error {
+	if actor.Name == "" {
+		return fmt.Errorf("governance actions require a named actor")
+	}
+	return ValidateGovernanceAction(actor.Role, action)
+}
+
+// requireJudge checks that actor may review and rule on reports
+func requireJudge(actor Actor) error {
+	if actor.Name == "" || actor.Role != models.RoleJudge {
+		return fmt.Errorf("only a judge can review reports")
+	}
+	return nil
+}
+
 // ============ Police Operations ============
 
 // CreateReport creates a new report (Police action)
@@ -65,6 +99,9 @@ func (e *Engine) CreateReport(ctx context.Context, input models.ReportInput, rep
 	if agent.IsSystem {
 		return nil, fmt.Errorf("system agents cannot be reported")
 	}
+	if agent.Status == models.StatusArchived {
+		return nil, fmt.Errorf("archived agents cannot be reported")
+	}
 
 	report := &models.Report{
 		AgentID:     agent.ID,
@@ -83,7 +120,7 @@ func (e *Engine) CreateReport(ctx context.Context, input models.ReportInput, rep
 	pendingCount, _ := e.db.CountPendingReportsForAgent(ctx, agent.ID)
 	if pendingCount >= e.config.AutoQuarantineThreshold && agent.Status == models.StatusActive {
 		// Auto-quarantine
-		if err := e.Quarantine(ctx, agent.ID, models.RolePolice, "Auto-quarantine: exceeded report threshold", nil); err != nil {
+		if err := e.Quarantine(ctx, agent.ID, SystemActor, "Auto-quarantine: exceeded report threshold", nil); err != nil {
 			// Log but don't fail the report creation
 			fmt.Printf("Warning: auto-quarantine failed: %v\n", err)
 		}
@@ -93,78 +130,186 @@ func (e *Engine) CreateReport(ctx context.Context, input models.ReportInput, rep
 }
 
 // Quarantine temporarily disables an agent (Police action)
-func (e *Engine) Quarantine(ctx context.Context, agentID uuid.UUID, role models.GovernanceRole, reason string, reportID *uuid.UUID) error {
+func (e *Engine) Quarantine(ctx context.Context, agentID uuid.UUID, actor Actor, reason string, reportID *uuid.UUID) error {
 	if !e.config.Enabled {
 		return fmt.Errorf("governance is disabled")
 	}
+	_, err := e.quarantine(ctx, agentID, actor, reason, reportID)
+	return err
+}
+
+// quarantine records and applies a quarantine, returning the action
+func (e *Engine) quarantine(ctx context.Context, agentID uuid.UUID, actor Actor, reason string, reportID *uuid.UUID) (*models.GovernanceAction, error) {
+	action, err := e.quarantineAction(ctx, agentID, actor, reason, reportID)
+	if err != nil {
+		return nil, err
+	}
 
+	// Record the action and update agent status together
+	if err := e.db.ApplyGovernanceAction(ctx, action, models.StatusQuarantined); err != nil {
+		return nil, fmt.Errorf("failed to quarantine agent: %w", err)
+	}
+	return action, nil
+}
+
+// quarantineAction checks that actor may quarantine the agent and returns the
+// action to record, without applying it
+func (e *Engine) quarantineAction(ctx context.Context, agentID uuid.UUID, actor Actor, reason string, reportID *uuid.UUID) (*models.GovernanceAction, error) {
 	// Only Police and Judge can quarantine
-	if role != models.RolePolice && role != models.RoleJudge {
-		return fmt.Errorf("only Police or Judge can quarantine agents")
+	if err := authorize(actor, models.ActionQuarantine); err != nil {
+		return nil, err
 	}
 
 	agent, err := e.db.GetAgentByID(ctx, agentID)
 	if err != nil || agent == nil {
-		return fmt.Errorf("agent not found")
+		return nil, fmt.Errorf("agent not found")
 	}
 
 	if agent.IsSystem {
-		return fmt.Errorf("system agents cannot be quarantined")
+		return nil, fmt.Errorf("system agents cannot be quarantined")
 	}
 
 	if agent.Status == models.StatusBanned {
-		return fmt.Errorf("agent is already banned")
+		return nil, fmt.Errorf("agent is already banned")
+	}
+	// Quarantining would record archived as the status to restore, and
+	// lifting it would undo the delete
+	if agent.Status == models.StatusArchived {
+		return nil, fmt.Errorf("archived agents cannot be quarantined")
 	}
 
-	// Record action
-	action := &models.GovernanceAction{
+	return &models.GovernanceAction{
 		AgentID:        agentID,
 		ReportID:       reportID,
 		ActionType:     models.ActionQuarantine,
-		ActionBy:       role,
+		ActionBy:       actor.Role,
+		Actor:          actor.Name,
 		Reason:         reason,
 		PreviousStatus: &agent.Status,
+	}, nil
+}
+
+// warnAction checks that actor may warn the agent and returns the action to
+// record
+func (e *Engine) warnAction(ctx context.Context, agentID uuid.UUID, actor Actor, reason string, reportID *uuid.UUID) (*models.GovernanceAction, error) {
+	if err := authorize(actor, models.ActionWarn); err != nil {
+		return nil, err
 	}
 
-	if err := e.db.RecordGovernanceAction(ctx, action); err != nil {
-		return fmt.Errorf("failed to record action: %w", err)
+	agent, err := e.db.GetAgentByID(ctx, agentID)
+	if err != nil || agent == nil {
+		return nil, fmt.Errorf("agent not found")
 	}
 
-	// Update agent status
-	return e.db.UpdateAgentStatus(ctx, agentID, models.StatusQuarantined)
+	return &models.GovernanceAction{
+		AgentID:        agentID,
+		ReportID:       reportID,
+		ActionType:     models.ActionWarn,
+		ActionBy:       actor.Role,
+		Actor:          actor.Name,
+		Reason:         reason,
+		PreviousStatus: &agent.Status,
+	}, nil
 }
 
 // ============ Judge Operations ============
 
-// ReviewReport marks a report as under review (Judge action)
-func (e *Engine) ReviewReport(ctx context.Context, reportID uuid.UUID, reviewedBy string) error {
+// ReviewReport claims a pending report for review by a judge (Judge action).
+// Only the judge holding a report may rule on it.
+func (e *Engine) ReviewReport(ctx context.Context, reportID uuid.UUID, actor Actor) (*models.Report, error) {
 	if !e.config.Enabled {
-		return fmt.Errorf("governance is disabled")
+		return nil, fmt.Errorf("governance is disabled")
+	}
+	if err := requireJudge(actor); err != nil {
+		return nil, err
 	}
 
-	return e.db.UpdateReportStatus(ctx, reportID, models.ReportStatusReviewing, reviewedBy)
+	if err := e.db.ClaimReport(ctx, reportID, actor.Name); err != nil {
+		return nil, fmt.Errorf("failed to claim report: %w", err)
+	}
+	return e.db.GetReport(ctx, reportID)
+}
+
+// Ruling is the outcome of MakeRuling
+type Ruling struct {
+	Report *models.Report           `json:"report"`
+	Action *models.GovernanceAction `json:"action,omitempty"` // nil for dismissals
 }
 
-// MakeRuling resolves a report with a decision (Judge action)
-func (e *Engine) MakeRuling(ctx context.Context, reportID uuid.UUID, resolution models.Resolution, note string, judgeID string) error {
+// MakeRuling resolves a report the judge has claimed and applies the
+// resolution to the agent (Judge action). A warning records a warn action and
+// a quarantine ruling quarantines the agent. A ban ruling quarantines the
+// agent until the executioner carries it out. Agents already quarantined are
+// left as they are. Actions link to the report, and the report is resolved in
+// the same transaction as its action.
+func (e *Engine) MakeRuling(ctx context.Context, reportID uuid.UUID, resolution models.Resolution, note string, actor Actor) (*Ruling, error) {
 	if !e.config.Enabled {
-		return fmt.Errorf("governance is disabled")
+		return nil, fmt.Errorf("governance is disabled")
+	}
+	if err := requireJudge(actor); err != nil {
+		return nil, err
 	}
 
-	// Resolve the report
-	if err := e.db.ResolveReport(ctx, reportID, resolution, note, judgeID); err != nil {
-		return fmt.Errorf("failed to resolve report: %w", err)
+	report, err := e.db.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	if report == nil {
+		return nil, fmt.Errorf("report not found: %s", reportID)
+	}
+	if report.Status != models.ReportStatusReviewing || report.ReviewedBy == nil || *report.ReviewedBy != actor.Name {
+		return nil, fmt.Errorf("claim the report before ruling on it")
 	}
 
-	return nil
+	reason := fmt.Sprintf("Ruling on report %s: %s", reportID, note)
+	var action *models.GovernanceAction
+	var status models.AgentStatus
+	switch resolution {
+	case models.ResolutionDismissed:
+	case models.ResolutionWarning:
+		action, err = e.warnAction(ctx, report.AgentID, actor, reason, &reportID)
+	case models.ResolutionQuarantine, models.ResolutionBan:
+		if resolution == models.ResolutionBan {
+			reason = "Awaiting executioner: " + reason
+		}
+		action, err = e.quarantineAction(ctx, report.AgentID, actor, reason, &reportID)
+		status = models.StatusQuarantined
+		if action != nil && *action.PreviousStatus == models.StatusQuarantined {
+			action, status = nil, ""
+		}
+	default:
+		return nil, fmt.Errorf("invalid resolution: %s (use dismissed, warning, quarantine or ban)", resolution)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s ruling: %w", resolution, err)
+	}
+
+	if err := e.db.RuleOnReport(ctx, reportID, resolution, note, actor.Name, action, status); err != nil {
+		return nil, fmt.Errorf("failed to resolve report: %w", err)
+	}
+
+	report, err = e.db.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	return &Ruling{Report: report, Action: action}, nil
 }
 
 // AdjustReputation adjusts an agent's reputation (Judge action)
-func (e *Engine) AdjustReputation(ctx context.Context, agentID uuid.UUID, delta float64, reason string) error {
+func (e *Engine) AdjustReputation(ctx context.Context, agentID uuid.UUID, actor Actor, delta float64, reason string) error {
 	if !e.config.Enabled {
 		return fmt.Errorf("governance is disabled")
 	}
 
+	// Record action
+	actionType := models.ActionDemote
+	if delta > 0 {
+		actionType = models.ActionPromote
+	}
+	if err := authorize(actor, actionType); err != nil {
+		return err
+	}
+
 	agent, err := e.db.GetAgentByID(ctx, agentID)
 	if err != nil || agent == nil {
 		return fmt.Errorf("agent not found")
@@ -182,90 +327,469 @@ func (e *Engine) AdjustReputation(ctx context.Context, agentID uuid.UUID, delta
 		newScore = 100
 	}
 
-	// Record action
-	actionType := models.ActionDemote
-	if delta > 0 {
-		actionType = models.ActionPromote
-	}
-
 	action := &models.GovernanceAction{
 		AgentID:            agentID,
 		ActionType:         actionType,
-		ActionBy:           models.RoleJudge,
+		ActionBy:           actor.Role,
+		Actor:              actor.Name,
 		Reason:             reason,
 		PreviousReputation: &agent.ReputationScore,
 	}
 
-	if err := e.db.RecordGovernanceAction(ctx, action); err != nil {
-		return fmt.Errorf("failed to record action: %w", err)
+	// Record the action and update the reputation together
+	if err := e.db.ApplyReputationAction(ctx, action, newScore); err != nil {
+		return fmt.Errorf("failed to adjust reputation: %w", err)
 	}
-
-	return e.db.UpdateAgentReputation(ctx, agentID, newScore)
+	return nil
 }
 
 // Unquarantine restores an agent to active status (Judge action)
-func (e *Engine) Unquarantine(ctx context.Context, agentID uuid.UUID, reason string) error {
+func (e *Engine) Unquarantine(ctx context.Context, agentID uuid.UUID, actor Actor, reason string) error {
 	if !e.config.Enabled {
 		return fmt.Errorf("governance is disabled")
 	}
+	_, err := e.unquarantine(ctx, agentID, actor, reason)
+	return err
+}
+
+// unquarantine records and applies an unquarantine, returning the action
+func (e *Engine) unquarantine(ctx context.Context, agentID uuid.UUID, actor Actor, reason string) (*models.GovernanceAction, error) {
+	if err := authorize(actor, models.ActionUnquarantine); err != nil {
+		return nil, err
+	}
 
 	agent, err := e.db.GetAgentByID(ctx, agentID)
 	if err != nil || agent == nil {
-		return fmt.Errorf("agent not found")
+		return nil, fmt.Errorf("agent not found")
 	}
 
 	if agent.Status != models.StatusQuarantined {
-		return fmt.Errorf("agent is not quarantined")
+		return nil, fmt.Errorf("agent is not quarantined")
 	}
 
-	// Record action
-	action := &models.GovernanceAction{
+	action := unquarantineAction(agentID, actor, reason)
+	if err := e.db.ApplyGovernanceAction(ctx, action, models.StatusActive); err != nil {
+		return nil, fmt.Errorf("failed to unquarantine agent: %w", err)
+	}
+	return action, nil
+}
+
+// unquarantineAction returns the action that lifts a quarantine on agentID
+func unquarantineAction(agentID uuid.UUID, actor Actor, reason string) *models.GovernanceAction {
+	previous := models.StatusQuarantined
+	return &models.GovernanceAction{
 		AgentID:        agentID,
 		ActionType:     models.ActionUnquarantine,
-		ActionBy:       models.RoleJudge,
+		ActionBy:       actor.Role,
+		Actor:          actor.Name,
 		Reason:         reason,
-		PreviousStatus: &agent.Status,
+		PreviousStatus: &previous,
 	}
+}
+
+// compensations maps each action that Revert can undo to the action recorded
+// when undoing it, and the agent status the original left behind. Reputation
+// changes leave no status.
+var compensations = map[models.GovernanceActionType]struct {
+	action models.GovernanceActionType
+	status models.AgentStatus
+}{
+	models.ActionQuarantine:   {models.ActionUnquarantine, models.StatusQuarantined},
+	models.ActionUnquarantine: {models.ActionQuarantine, models.StatusActive},
+	models.ActionBan:          {models.ActionUnban, models.StatusBanned},
+	models.ActionPromote:      {models.ActionDemote, ""},
+	models.ActionDemote:       {models.ActionPromote, ""},
+}
 
-	if err := e.db.RecordGovernanceAction(ctx, action); err != nil {
-		return fmt.Errorf("failed to record action: %w", err)
+// stateOf names the agent state an action of type t changes, or "" for
+// warnings, which change none
+func stateOf(t models.GovernanceActionType) string {
+	switch t {
+	case models.ActionQuarantine, models.ActionUnquarantine, models.ActionBan, models.ActionUnban:
+		return "status"
+	case models.ActionPromote, models.ActionDemote:
+		return "reputation"
 	}
+	return ""
+}
 
-	return e.db.UpdateAgentStatus(ctx, agentID, models.StatusActive)
+// Revert undoes a governance action by restoring the status or reputation it
+// recorded as previous, and records the compensating action (Judge action).
+// It refuses while a later, unreverted action changed the same state, since
+// undoing the earlier one would silently discard it; revert those first.
+// Reverts themselves cannot be reverted.
+func (e *Engine) Revert(ctx context.Context, actionID uuid.UUID, actor Actor, reason string) (*models.GovernanceAction, error) {
+	if !e.config.Enabled {
+		return nil, fmt.Errorf("governance is disabled")
+	}
+
+	action, original, err := e.revertAction(ctx, actionID, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+	if action.PreviousReputation != nil {
+		if err := e.db.ApplyReputationAction(ctx, action, *original.PreviousReputation); err != nil {
+			return nil, fmt.Errorf("failed to restore reputation: %w", err)
+		}
+		return action, nil
+	}
+	if err := e.db.ApplyGovernanceAction(ctx, action, *original.PreviousStatus); err != nil {
+		return nil, fmt.Errorf("failed to revert action: %w", err)
+	}
+	return action, nil
 }
 
-// ============ Executioner Operations ============
+// revertAction checks that actor may revert the action and returns the
+// compensating action together with the original, whose previous status or
+// reputation is what the revert restores. Nothing is applied.
+func (e *Engine) revertAction(ctx context.Context, actionID uuid.UUID, actor Actor, reason string) (*models.GovernanceAction, *models.GovernanceAction, error) {
+	if actor.Role != models.RoleJudge {
+		return nil, nil, fmt.Errorf("only a judge can revert governance actions")
+	}
+
+	original, err := e.db.GetGovernanceAction(ctx, actionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get action: %w", err)
+	}
+	if original == nil {
+		return nil, nil, fmt.Errorf("governance action not found: %s", actionID)
+	}
+	if original.RevertsActionID != nil {
+		return nil, nil, fmt.Errorf("action %s is itself a revert; take a new action instead", actionID)
+	}
+	comp, ok := compensations[original.ActionType]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s actions change no state and cannot be reverted", original.ActionType)
+	}
+	if err := authorize(actor, comp.action); err != nil {
+		return nil, nil, err
+	}
+
+	// Later actions on the same state depend on this one, unless they have
+	// been reverted in turn
+	history, err := e.db.ListGovernanceActions(ctx, original.AgentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list actions: %w", err)
+	}
+	var later []models.GovernanceAction
+	reverted := map[uuid.UUID]bool{}
+	for i, a := range history {
+		if a.ID == original.ID {
+			later = history[i+1:]
+			break
+		}
+	}
+	for _, a := range later {
+		if a.RevertsActionID != nil {
+			reverted[*a.RevertsActionID] = true
+		}
+	}
+	if reverted[original.ID] {
+		return nil, nil, fmt.Errorf("action %s was already reverted", actionID)
+	}
+	for _, a := range later {
+		if stateOf(a.ActionType) == stateOf(original.ActionType) && a.RevertsActionID == nil && !reverted[a.ID] {
+			return nil, nil, fmt.Errorf("later %s action %s depends on action %s; revert it first", a.ActionType, a.ID, actionID)
+		}
+	}
+
+	agent, err := e.db.GetAgentByID(ctx, original.AgentID)
+	if err != nil || agent == nil {
+		return nil, nil, fmt.Errorf("agent not found")
+	}
+
+	action := &models.GovernanceAction{
+		AgentID:         agent.ID,
+		AgentName:       agent.Name,
+		ReportID:        original.ReportID,
+		ActionType:      comp.action,
+		ActionBy:        actor.Role,
+		Actor:           actor.Name,
+		Reason:          reason,
+		RevertsActionID: &original.ID,
+	}
+
+	if comp.status == "" {
+		if original.PreviousReputation == nil {
+			return nil, nil, fmt.Errorf("action %s recorded no previous reputation", actionID)
+		}
+		action.PreviousReputation = &agent.ReputationScore
+		return action, original, nil
+	}
+
+	if original.PreviousStatus == nil {
+		return nil, nil, fmt.Errorf("action %s recorded no previous status", actionID)
+	}
+	if agent.Status != comp.status {
+		return nil, nil, fmt.Errorf("agent is %s, not %s; its status changed outside governance", agent.Status, comp.status)
+	}
+	action.PreviousStatus = &agent.Status
+	return action, original, nil
+}
 
-// ExecuteBan permanently bans an agent (Executioner action, requires Judge ruling)
-func (e *Engine) ExecuteBan(ctx context.Context, agentID uuid.UUID, reportID uuid.UUID, reason string) error {
+// Unban reverts the ban on a banned agent, restoring the status it had before
+// the executioner acted (Judge action)
+func (e *Engine) Unban(ctx context.Context, agentID uuid.UUID, actor Actor, reason string) (*models.GovernanceAction, error) {
 	if !e.config.Enabled {
-		return fmt.Errorf("governance is disabled")
+		return nil, fmt.Errorf("governance is disabled")
 	}
 
 	agent, err := e.db.GetAgentByID(ctx, agentID)
 	if err != nil || agent == nil {
-		return fmt.Errorf("agent not found")
+		return nil, fmt.Errorf("agent not found")
+	}
+	if agent.Status != models.StatusBanned {
+		return nil, fmt.Errorf("agent is not banned")
+	}
+
+	ban, err := e.latestBan(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	return e.Revert(ctx, ban.ID, actor, reason)
+}
+
+// latestBan returns the most recent ban action on an agent
+func (e *Engine) latestBan(ctx context.Context, agentID uuid.UUID) (*models.GovernanceAction, error) {
+	history, err := e.db.ListGovernanceActions(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list actions: %w", err)
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].ActionType == models.ActionBan {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no recorded ban to revert")
+}
+
+// ListActions returns the governance actions taken on an agent, oldest first
+func (e *Engine) ListActions(ctx context.Context, agentID uuid.UUID) ([]models.GovernanceAction, error) {
+	return e.db.ListGovernanceActions(ctx, agentID)
+}
+
+// ============ Appeals ============
+
+// FileAppeal asks a judge to lift an agent's quarantine or ban. Only the
+// agent's creator may appeal, one appeal at a time and at most MaxAppeals
+// over the agent's lifetime. The appeal names the action that left the agent
+// in its current status.
+func (e *Engine) FileAppeal(ctx context.Context, agentID uuid.UUID, filedBy, statement string) (*models.Appeal, error) {
+	if !e.config.Enabled {
+		return nil, fmt.Errorf("governance is disabled")
+	}
+
+	agent, err := e.db.GetAgentByID(ctx, agentID)
+	if err != nil || agent == nil {
+		return nil, fmt.Errorf("agent not found")
+	}
+	if filedBy == "" || agent.CreatedBy == nil || *agent.CreatedBy != filedBy {
+		return nil, fmt.Errorf("only the agent's creator can appeal")
+	}
+
+	var appealed models.GovernanceActionType
+	switch agent.Status {
+	case models.StatusQuarantined:
+		appealed = models.ActionQuarantine
+	case models.StatusBanned:
+		appealed = models.ActionBan
+	default:
+		return nil, fmt.Errorf("agent is %s; only quarantines and bans can be appealed", agent.Status)
+	}
+
+	appeal := &models.Appeal{
+		AgentID:   agentID,
+		AgentName: agent.Name,
+		FiledBy:   filedBy,
+		Statement: statement,
+	}
+
+	// Name the latest unreverted action that set the current status
+	history, err := e.db.ListGovernanceActions(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list actions: %w", err)
+	}
+	reverted := map[uuid.UUID]bool{}
+	for i := len(history) - 1; i >= 0; i-- {
+		a := history[i]
+		if a.RevertsActionID != nil {
+			reverted[*a.RevertsActionID] = true
+		}
+		if a.ActionType == appealed && !reverted[a.ID] {
+			appeal.ActionID = &a.ID
+			break
+		}
+	}
+
+	// The store enforces the limits together with the insert
+	if err := e.db.CreateAppeal(ctx, appeal, e.config.MaxAppeals); err != nil {
+		return nil, fmt.Errorf("failed to file appeal: %w", err)
+	}
+	return appeal, nil
+}
+
+// AppealDecision is the outcome of DecideAppeal
+type AppealDecision struct {
+	Appeal  *models.Appeal            `json:"appeal"`
+	Actions []models.GovernanceAction `json:"actions"` // empty when denied
+}
+
+// DecideAppeal grants or denies a pending appeal (Judge action). Granting it
+// restores the agent to active: a ban is reverted, then any quarantine is
+// lifted. The decision and the actions are stored in one transaction, so a
+// failure, or another judge deciding first, changes nothing.
+func (e *Engine) DecideAppeal(ctx context.Context, appealID uuid.UUID, grant bool, note string, actor Actor) (*AppealDecision, error) {
+	if !e.config.Enabled {
+		return nil, fmt.Errorf("governance is disabled")
+	}
+	if actor.Name == "" || actor.Role != models.RoleJudge {
+		return nil, fmt.Errorf("only a judge can decide appeals")
+	}
+
+	appeal, err := e.db.GetAppeal(ctx, appealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appeal: %w", err)
+	}
+	if appeal == nil {
+		return nil, fmt.Errorf("appeal not found: %s", appealID)
+	}
+	if appeal.Status != models.AppealStatusPending {
+		return nil, fmt.Errorf("appeal was already %s", appeal.Status)
+	}
+
+	// Plan the actions that lift the sanctions; the store applies them
+	var changes []database.StatusChange
+	status := models.AppealStatusDenied
+	if grant {
+		status = models.AppealStatusGranted
+		reason := fmt.Sprintf("Appeal %s granted: %s", appealID, note)
+
+		agent, err := e.db.GetAgentByID(ctx, appeal.AgentID)
+		if err != nil || agent == nil {
+			return nil, fmt.Errorf("agent not found")
+		}
+		current := agent.Status
+		if current == models.StatusBanned {
+			ban, err := e.latestBan(ctx, agent.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to lift ban: %w", err)
+			}
+			action, original, err := e.revertAction(ctx, ban.ID, actor, reason)
+			if err != nil {
+				return nil, fmt.Errorf("failed to lift ban: %w", err)
+			}
+			current = *original.PreviousStatus
+			changes = append(changes, database.StatusChange{Action: action, Status: current})
+		}
+		if current == models.StatusQuarantined {
+			if err := authorize(actor, models.ActionUnquarantine); err != nil {
+				return nil, fmt.Errorf("failed to lift quarantine: %w", err)
+			}
+			changes = append(changes, database.StatusChange{Action: unquarantineAction(agent.ID, actor, reason), Status: models.StatusActive})
+		}
+	}
+
+	if err := e.db.DecideAppeal(ctx, appealID, status, note, actor.Name, changes); err != nil {
+		return nil, fmt.Errorf("failed to decide appeal: %w", err)
+	}
+
+	decision := &AppealDecision{Actions: []models.GovernanceAction{}}
+	for _, c := range changes {
+		decision.Actions = append(decision.Actions, *c.Action)
+	}
+
+	decision.Appeal, err = e.db.GetAppeal(ctx, appealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appeal: %w", err)
+	}
+	return decision, nil
+}
+
+// GetPendingAppeals returns appeals awaiting a decision
+func (e *Engine) GetPendingAppeals(ctx context.Context) ([]models.Appeal, error) {
+	return e.db.GetPendingAppeals(ctx)
+}
+
+// ============ Executioner Operations ============
+
+// ExecuteBan permanently bans the agent named in a report (Executioner
+// action). The report must carry a judge's ban ruling made at least the
+// cooling-off period ago, and no judge or granted appeal may have lifted the
+// agent's sanctions since. The status change and its audit row are written in
+// one transaction.
+func (e *Engine) ExecuteBan(ctx context.Context, reportID uuid.UUID, actor Actor, reason string) (*models.GovernanceAction, error) {
+	if !e.config.Enabled {
+		return nil, fmt.Errorf("governance is disabled")
+	}
+	if err := authorize(actor, models.ActionBan); err != nil {
+		return nil, err
+	}
+
+	report, err := e.db.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	if report == nil {
+		return nil, fmt.Errorf("report not found: %s", reportID)
+	}
+	if report.Status != models.ReportStatusResolved || report.Resolution == nil || *report.Resolution != models.ResolutionBan {
+		return nil, fmt.Errorf("report %s has no ban ruling from a judge", reportID)
+	}
+	if report.ResolvedAt == nil {
+		return nil, fmt.Errorf("ban ruling on report %s has no resolution time; it cannot be executed", reportID)
+	}
+	if ready := report.ResolvedAt.Add(e.config.BanCoolingOff); time.Now().Before(ready) {
+		return nil, fmt.Errorf("ban ruling is cooling off until %s", ready.UTC().Format(time.RFC3339))
+	}
+
+	agent, err := e.db.GetAgentByID(ctx, report.AgentID)
+	if err != nil || agent == nil {
+		return nil, fmt.Errorf("agent not found")
 	}
 
 	if agent.IsSystem {
-		return fmt.Errorf("system agents cannot be banned")
+		return nil, fmt.Errorf("system agents cannot be banned")
+	}
+	if agent.Status == models.StatusBanned {
+		return nil, fmt.Errorf("agent is already banned")
+	}
+	if agent.Status == models.StatusArchived {
+		return nil, fmt.Errorf("archived agents cannot be banned")
+	}
+
+	// A ruling is carried out once; unbans and granted appeals must stick
+	history, err := e.db.ListGovernanceActions(ctx, agent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list actions: %w", err)
+	}
+	for _, a := range history {
+		if a.ActionType == models.ActionBan && a.ReportID != nil && *a.ReportID == reportID {
+			return nil, fmt.Errorf("ban ruling on report %s was already executed by action %s", reportID, a.ID)
+		}
+	}
+	// Lifting the sanctions after the ruling, by a judge or a granted appeal,
+	// overrides it
+	for _, a := range history {
+		if (a.ActionType == models.ActionUnquarantine || a.ActionType == models.ActionUnban) && a.CreatedAt.After(*report.ResolvedAt) {
+			return nil, fmt.Errorf("ban ruling on report %s was overridden by %s action %s", reportID, a.ActionType, a.ID)
+		}
 	}
 
-	// Record action
 	action := &models.GovernanceAction{
-		AgentID:        agentID,
+		AgentID:        agent.ID,
+		AgentName:      agent.Name,
 		ReportID:       &reportID,
 		ActionType:     models.ActionBan,
-		ActionBy:       models.RoleExecutioner,
+		ActionBy:       actor.Role,
+		Actor:          actor.Name,
 		Reason:         reason,
 		PreviousStatus: &agent.Status,
 	}
 
-	if err := e.db.RecordGovernanceAction(ctx, action); err != nil {
-		return fmt.Errorf("failed to record action: %w", err)
+	if err := e.db.ApplyGovernanceAction(ctx, action, models.StatusBanned); err != nil {
+		return nil, fmt.Errorf("failed to ban agent: %w", err)
 	}
-
-	return e.db.UpdateAgentStatus(ctx, agentID, models.StatusBanned)
+	return action, nil
 }
 
 // ============ Reputation Calculation ============
@@ -328,7 +852,7 @@ func CanPoliceAct(action models.GovernanceActionType) bool {
 // CanJudgeAct checks if an action is allowed for Judge role
 func CanJudgeAct(action models.GovernanceActionType) bool {
 	switch action {
-	case models.ActionQuarantine, models.ActionUnquarantine, models.ActionWarn, models.ActionPromote, models.ActionDemote:
+	case models.ActionQuarantine, models.ActionUnquarantine, models.ActionUnban, models.ActionWarn, models.ActionPromote, models.ActionDemote:
 		return true
 	default:
 		return false
@@ -365,6 +889,24 @@ func ValidateGovernanceAction(role models.GovernanceRole, action models.Governan
 	return nil
 }
 
+// RoleFor returns the first of roles allowed to perform action, so a caller
+// holding several roles acts in one the audit trail can record
+func RoleFor(roles []models.GovernanceRole, action models.GovernanceActionType) (models.GovernanceRole, error) {
+	for _, role := range roles {
+		if ValidateGovernanceAction(role, action) == nil {
+			return role, nil
+		}
+	}
+
+	var allowed []string
+	for _, role := range []models.GovernanceRole{models.RolePolice, models.RoleJudge, models.RoleExecutioner} {
+		if ValidateGovernanceAction(role, action) == nil {
+			allowed = append(allowed, string(role))
+		}
+	}
+	return "", fmt.Errorf("%s action requires the %s role", action, strings.Join(allowed, " or "))
+}
+
 // ============ Auto-Maintenance ============
 
 // RunMaintenance performs periodic governance maintenance
@@ -388,3 +930,8 @@ func (e *Engine) GetStats(ctx context.Context) (*models.GovernanceStats, error)
 func (e *Engine) GetPendingReports(ctx context.Context) ([]models.Report, error) {
 	return e.db.GetPendingReports(ctx)
 }
+
+// ListReports returns one page of reports, by default those awaiting review
+func (e *Engine) ListReports(ctx context.Context, opts models.ListOptions) (models.Page[models.Report], error) {
+	return e.db.ListReports(ctx, opts)
+}
@@ -1,10 +1,15 @@
 package governance
 
 import (
+	"context"
+	"errors"
 	"math"
+	"strings"
 	"testing"
 
+	"github.com/aminghadersohi/agentmcp/internal/database"
 	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/google/uuid"
 )
 
 func TestLogBase10(t *testing.T) {
@@ -58,6 +63,10 @@ func TestDefaultConfig(t *testing.T) {
 		t.Error("ReputationBanThreshold should be non-negative")
 	}
 
+	if cfg.MaxAppeals <= 0 {
+		t.Error("MaxAppeals should allow appeals by default")
+	}
+
 	if !cfg.Enabled {
 		t.Error("Governance should be enabled by default")
 	}
@@ -189,6 +198,7 @@ func TestCanJudgeAct(t *testing.T) {
 	}{
 		{models.ActionQuarantine, true},
 		{models.ActionUnquarantine, true},
+		{models.ActionUnban, true},
 		{models.ActionWarn, true},
 		{models.ActionPromote, true},
 		{models.ActionDemote, true},
@@ -228,6 +238,518 @@ func TestCanExecutionerAct(t *testing.T) {
 	}
 }
 
+func TestRoleFor(t *testing.T) {
+	both := []models.GovernanceRole{models.RoleExecutioner, models.RoleJudge}
+	tests := []struct {
+		roles   []models.GovernanceRole
+		action  models.GovernanceActionType
+		want    models.GovernanceRole
+		wantErr string
+	}{
+		{both, models.ActionBan, models.RoleExecutioner, ""},
+		{both, models.ActionQuarantine, models.RoleJudge, ""},
+		{[]models.GovernanceRole{models.RolePolice}, models.ActionUnquarantine, "", "requires the judge role"},
+		{nil, models.ActionQuarantine, "", "requires the police or judge role"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			got, err := RoleFor(tt.roles, tt.action)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("RoleFor(%v, %s) error = %v, want %q", tt.roles, tt.action, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("RoleFor(%v, %s) = %s, %v; want %s", tt.roles, tt.action, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineChecksActorRole(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	defer store.Close()
+	engine := New(store, DefaultConfig())
+
+	agent := &models.Agent{Name: "rogue", Version: "1.0.0", Status: models.StatusActive, ReputationScore: 50}
+	if err := store.CreateAgent(ctx, agent); err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+
+	police := Actor{Name: "cop", Role: models.RolePolice}
+	judge := Actor{Name: "alice", Role: models.RoleJudge}
+
+	if err := engine.Quarantine(ctx, agent.ID, Actor{Role: models.RolePolice}, "no name", nil); err == nil {
+		t.Error("Quarantine accepted an actor without a name")
+	}
+	if err := engine.Quarantine(ctx, agent.ID, police, "urgent", nil); err != nil {
+		t.Fatalf("Quarantine as police failed: %v", err)
+	}
+	if err := engine.Unquarantine(ctx, agent.ID, police, "cleared"); err == nil {
+		t.Error("Unquarantine accepted the police role")
+	}
+	if err := engine.Unquarantine(ctx, agent.ID, judge, "cleared"); err != nil {
+		t.Fatalf("Unquarantine as judge failed: %v", err)
+	}
+	if err := engine.AdjustReputation(ctx, agent.ID, police, 5, "good"); err == nil {
+		t.Error("AdjustReputation accepted the police role")
+	}
+	if _, err := engine.ExecuteBan(ctx, uuid.New(), judge, "bad"); err == nil {
+		t.Error("ExecuteBan accepted the judge role")
+	}
+
+	got, _ := store.GetAgent(ctx, "rogue")
+	if got.Status != models.StatusActive {
+		t.Errorf("status = %s, want active", got.Status)
+	}
+}
+
+func TestExecuteBan(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	defer store.Close()
+
+	judge := Actor{Name: "alice", Role: models.RoleJudge}
+	executioner := Actor{Name: "bob", Role: models.RoleExecutioner}
+
+	// rule files a report against a new agent and resolves it as resolution
+	rule := func(engine *Engine, name string, system bool, resolution models.Resolution) uuid.UUID {
+		t.Helper()
+		owner := "owner"
+		agent := &models.Agent{Name: name, Version: "1.0.0", Status: models.StatusActive, IsSystem: system, CreatedBy: &owner}
+		if err := store.CreateAgent(ctx, agent); err != nil {
+			t.Fatalf("CreateAgent failed: %v", err)
+		}
+		report := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeHarmful, Severity: models.SeverityHigh, Description: "harm"}
+		if err := store.CreateReport(ctx, report); err != nil {
+			t.Fatalf("CreateReport failed: %v", err)
+		}
+		if _, err := engine.ReviewReport(ctx, report.ID, judge); err != nil {
+			t.Fatalf("ReviewReport failed: %v", err)
+		}
+		if system {
+			// Rulings cannot touch system agents, so resolve the report directly
+			if err := store.ResolveReport(ctx, report.ID, resolution, "ruled", judge.Name); err != nil {
+				t.Fatalf("ResolveReport failed: %v", err)
+			}
+		} else if _, err := engine.MakeRuling(ctx, report.ID, resolution, "ruled", judge); err != nil {
+			t.Fatalf("MakeRuling failed: %v", err)
+		}
+		return report.ID
+	}
+
+	cfg := DefaultConfig()
+	cfg.BanCoolingOff = 0
+	engine := New(store, cfg)
+
+	tests := []struct {
+		name       string
+		engine     *Engine
+		agent      string
+		system     bool
+		resolution models.Resolution
+		actor      Actor
+		wantErr    string
+	}{
+		{"judge cannot execute", engine, "a1", false, models.ResolutionBan, judge, "judge cannot perform ban"},
+		{"quarantine ruling", engine, "a2", false, models.ResolutionQuarantine, executioner, "no ban ruling"},
+		{"cooling off", New(store, DefaultConfig()), "a3", false, models.ResolutionBan, executioner, "cooling off until"},
+		{"system agent", engine, "a4", true, models.ResolutionBan, executioner, "system agents cannot be banned"},
+		{"ban ruling", engine, "a5", false, models.ResolutionBan, executioner, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reportID := rule(tt.engine, tt.agent, tt.system, tt.resolution)
+			action, err := tt.engine.ExecuteBan(ctx, reportID, tt.actor, "carried out")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ExecuteBan() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteBan failed: %v", err)
+			}
+			if action.ActionType != models.ActionBan || action.Actor != "bob" || *action.ReportID != reportID || *action.PreviousStatus != models.StatusQuarantined {
+				t.Errorf("action = %+v, want a ban by bob linked to the report", action)
+			}
+			if agent, _ := store.GetAgent(ctx, tt.agent); agent.Status != models.StatusBanned {
+				t.Errorf("status = %s, want banned", agent.Status)
+			}
+			if _, err := tt.engine.ExecuteBan(ctx, reportID, tt.actor, "again"); err == nil || !strings.Contains(err.Error(), "already banned") {
+				t.Errorf("second ExecuteBan error = %v, want already banned", err)
+			}
+
+			// After an unban the same ruling cannot ban the agent again
+			if _, err := tt.engine.Unban(ctx, action.AgentID, judge, "overturned"); err != nil {
+				t.Fatalf("Unban failed: %v", err)
+			}
+			if _, err := tt.engine.ExecuteBan(ctx, reportID, tt.actor, "again"); err == nil || !strings.Contains(err.Error(), "already executed") {
+				t.Errorf("ExecuteBan after unban error = %v, want already executed", err)
+			}
+		})
+	}
+
+	t.Run("granted appeal", func(t *testing.T) {
+		// The ruling quarantines the agent, and a granted appeal lifts it
+		reportID := rule(engine, "a7", false, models.ResolutionBan)
+		agent, _ := store.GetAgent(ctx, "a7")
+		appeal, err := engine.FileAppeal(ctx, agent.ID, "owner", "not harmful")
+		if err != nil {
+			t.Fatalf("FileAppeal failed: %v", err)
+		}
+		if _, err := engine.DecideAppeal(ctx, appeal.ID, true, "agreed", judge); err != nil {
+			t.Fatalf("DecideAppeal failed: %v", err)
+		}
+		if _, err := engine.ExecuteBan(ctx, reportID, executioner, "carried out"); err == nil || !strings.Contains(err.Error(), "overridden by unquarantine") {
+			t.Errorf("ExecuteBan after a granted appeal error = %v, want overridden", err)
+		}
+		if agent, _ := store.GetAgent(ctx, "a7"); agent.Status != models.StatusActive {
+			t.Errorf("status = %s, want active", agent.Status)
+		}
+	})
+
+	t.Run("no resolution time", func(t *testing.T) {
+		reportID := rule(engine, "a6", false, models.ResolutionBan)
+		unresolved := New(noResolvedAt{store}, cfg)
+		if _, err := unresolved.ExecuteBan(ctx, reportID, executioner, "carried out"); err == nil || !strings.Contains(err.Error(), "no resolution time") {
+			t.Errorf("ExecuteBan() error = %v, want no resolution time", err)
+		}
+	})
+}
+
+// noResolvedAt hides when reports were resolved
+type noResolvedAt struct {
+	*database.MemoryStore
+}
+
+func (s noResolvedAt) GetReport(ctx context.Context, reportID uuid.UUID) (*models.Report, error) {
+	report, err := s.MemoryStore.GetReport(ctx, reportID)
+	if report != nil {
+		report.ResolvedAt = nil
+	}
+	return report, err
+}
+
+func TestMakeRulingOnQuarantinedAgent(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	defer store.Close()
+
+	engine := New(store, DefaultConfig())
+	judge := Actor{Name: "alice", Role: models.RoleJudge}
+
+	agent := &models.Agent{Name: "held", Version: "1.0.0", Status: models.StatusActive}
+	if err := store.CreateAgent(ctx, agent); err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+	if err := engine.Quarantine(ctx, agent.ID, SystemActor, "auto", nil); err != nil {
+		t.Fatalf("Quarantine failed: %v", err)
+	}
+	report := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeHarmful, Severity: models.SeverityHigh, Description: "harm"}
+	if err := store.CreateReport(ctx, report); err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+	if _, err := engine.ReviewReport(ctx, report.ID, judge); err != nil {
+		t.Fatalf("ReviewReport failed: %v", err)
+	}
+
+	// The ban ruling stands, but the agent is not quarantined twice
+	ruling, err := engine.MakeRuling(ctx, report.ID, models.ResolutionBan, "harmful", judge)
+	if err != nil {
+		t.Fatalf("MakeRuling failed: %v", err)
+	}
+	if ruling.Action != nil || ruling.Report.Status != models.ReportStatusResolved {
+		t.Errorf("ruling = %+v, want a resolved report without a new action", ruling)
+	}
+	if actions, _ := engine.ListActions(ctx, agent.ID); len(actions) != 1 {
+		t.Errorf("actions = %d, want only the original quarantine", len(actions))
+	}
+}
+
+func TestArchivedAgentsAreLeftAlone(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	defer store.Close()
+
+	cfg := DefaultConfig()
+	cfg.BanCoolingOff = 0
+	engine := New(store, cfg)
+	judge := Actor{Name: "alice", Role: models.RoleJudge}
+	executioner := Actor{Name: "bob", Role: models.RoleExecutioner}
+
+	agent := &models.Agent{Name: "retired", Version: "1.0.0", Status: models.StatusActive}
+	if err := store.CreateAgent(ctx, agent); err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+	report := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeHarmful, Severity: models.SeverityHigh, Description: "harm"}
+	if err := store.CreateReport(ctx, report); err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+	if _, err := engine.ReviewReport(ctx, report.ID, judge); err != nil {
+		t.Fatalf("ReviewReport failed: %v", err)
+	}
+	if _, err := engine.MakeRuling(ctx, report.ID, models.ResolutionBan, "harmful", judge); err != nil {
+		t.Fatalf("MakeRuling failed: %v", err)
+	}
+
+	// Deleting the agent before the ban is carried out takes it out of reach
+	if err := store.UpdateAgentStatus(ctx, agent.ID, models.StatusArchived); err != nil {
+		t.Fatalf("UpdateAgentStatus failed: %v", err)
+	}
+	if _, err := engine.CreateReport(ctx, models.ReportInput{AgentName: "retired", ReportType: models.ReportTypeHarmful, Severity: models.SeverityHigh, Description: "still harmful"}, "cop"); err == nil || !strings.Contains(err.Error(), "archived agents cannot be reported") {
+		t.Errorf("CreateReport error = %v, want archived refused", err)
+	}
+	if err := engine.Quarantine(ctx, agent.ID, judge, "again", nil); err == nil || !strings.Contains(err.Error(), "archived agents cannot be quarantined") {
+		t.Errorf("Quarantine error = %v, want archived refused", err)
+	}
+	if _, err := engine.ExecuteBan(ctx, report.ID, executioner, "carried out"); err == nil || !strings.Contains(err.Error(), "archived agents cannot be banned") {
+		t.Errorf("ExecuteBan error = %v, want archived refused", err)
+	}
+	if a, _ := store.GetAgentByID(ctx, agent.ID); a.Status != models.StatusArchived {
+		t.Errorf("status = %s, want archived", a.Status)
+	}
+}
+
+func TestRevert(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	defer store.Close()
+
+	cfg := DefaultConfig()
+	cfg.BanCoolingOff = 0
+	engine := New(store, cfg)
+	cop := Actor{Name: "carol", Role: models.RolePolice}
+	judge := Actor{Name: "alice", Role: models.RoleJudge}
+	executioner := Actor{Name: "bob", Role: models.RoleExecutioner}
+
+	agent := &models.Agent{Name: "reverted", Version: "1.0.0", Status: models.StatusActive, ReputationScore: 50}
+	if err := store.CreateAgent(ctx, agent); err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+	history := func() []models.GovernanceAction {
+		t.Helper()
+		actions, err := engine.ListActions(ctx, agent.ID)
+		if err != nil {
+			t.Fatalf("ListActions failed: %v", err)
+		}
+		return actions
+	}
+	current := func() *models.Agent {
+		t.Helper()
+		a, _ := store.GetAgentByID(ctx, agent.ID)
+		return a
+	}
+
+	// Quarantine, then demote: the demotion does not block reverting the quarantine
+	if err := engine.Quarantine(ctx, agent.ID, cop, "suspicious", nil); err != nil {
+		t.Fatalf("Quarantine failed: %v", err)
+	}
+	if err := engine.AdjustReputation(ctx, agent.ID, judge, -20, "sloppy"); err != nil {
+		t.Fatalf("AdjustReputation failed: %v", err)
+	}
+	quarantine, demote := history()[0], history()[1]
+
+	if _, err := engine.Revert(ctx, quarantine.ID, cop, "mistake"); err == nil || !strings.Contains(err.Error(), "only a judge") {
+		t.Errorf("police Revert error = %v, want only a judge", err)
+	}
+	undo, err := engine.Revert(ctx, quarantine.ID, judge, "mistake")
+	if err != nil {
+		t.Fatalf("Revert(quarantine) failed: %v", err)
+	}
+	if undo.ActionType != models.ActionUnquarantine || *undo.RevertsActionID != quarantine.ID || undo.Actor != "alice" {
+		t.Errorf("compensating action = %+v, want an unquarantine by alice reverting %s", undo, quarantine.ID)
+	}
+	if a := current(); a.Status != models.StatusActive {
+		t.Errorf("status = %s, want active", a.Status)
+	}
+	if _, err := engine.Revert(ctx, quarantine.ID, judge, "again"); err == nil || !strings.Contains(err.Error(), "already reverted") {
+		t.Errorf("second Revert error = %v, want already reverted", err)
+	}
+	if _, err := engine.Revert(ctx, undo.ID, judge, "undo the undo"); err == nil || !strings.Contains(err.Error(), "itself a revert") {
+		t.Errorf("Revert(revert) error = %v, want itself a revert", err)
+	}
+
+	// A later reputation change blocks reverting the earlier one
+	if err := engine.AdjustReputation(ctx, agent.ID, judge, 5, "improving"); err != nil {
+		t.Fatalf("AdjustReputation failed: %v", err)
+	}
+	promote := history()[len(history())-1]
+	if _, err := engine.Revert(ctx, demote.ID, judge, "too harsh"); err == nil || !strings.Contains(err.Error(), "depends on") {
+		t.Errorf("Revert(demote) error = %v, want a dependency refusal", err)
+	}
+	if _, err := engine.Revert(ctx, promote.ID, judge, "premature"); err != nil {
+		t.Fatalf("Revert(promote) failed: %v", err)
+	}
+	if _, err := engine.Revert(ctx, demote.ID, judge, "too harsh"); err != nil {
+		t.Fatalf("Revert(demote) after reverting the promotion failed: %v", err)
+	}
+	if a := current(); a.ReputationScore != 50 {
+		t.Errorf("reputation = %v, want the original 50", a.ReputationScore)
+	}
+
+	// A warning ruling records a warn action and leaves the status alone,
+	// so it has nothing to revert
+	warned := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeIneffective, Severity: models.SeverityLow, Description: "sloppy"}
+	if err := store.CreateReport(ctx, warned); err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+	if _, err := engine.ReviewReport(ctx, warned.ID, judge); err != nil {
+		t.Fatalf("ReviewReport failed: %v", err)
+	}
+	ruling, err := engine.MakeRuling(ctx, warned.ID, models.ResolutionWarning, "careful", judge)
+	if err != nil {
+		t.Fatalf("MakeRuling(warning) failed: %v", err)
+	}
+	if ruling.Action == nil || ruling.Action.ActionType != models.ActionWarn || *ruling.Action.ReportID != warned.ID {
+		t.Errorf("warning action = %+v, want a warn linked to the report", ruling.Action)
+	}
+	if a := current(); a.Status != models.StatusActive {
+		t.Errorf("status = %s, want active after a warning", a.Status)
+	}
+	if _, err := engine.Revert(ctx, ruling.Action.ID, judge, "undo"); err == nil || !strings.Contains(err.Error(), "cannot be reverted") {
+		t.Errorf("Revert(warn) error = %v, want cannot be reverted", err)
+	}
+
+	// Unban restores the quarantine left by the ban ruling
+	report := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeHarmful, Severity: models.SeverityHigh, Description: "harm"}
+	if err := store.CreateReport(ctx, report); err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+	if _, err := engine.ReviewReport(ctx, report.ID, judge); err != nil {
+		t.Fatalf("ReviewReport failed: %v", err)
+	}
+	if _, err := engine.MakeRuling(ctx, report.ID, models.ResolutionBan, "harmful", judge); err != nil {
+		t.Fatalf("MakeRuling failed: %v", err)
+	}
+	if _, err := engine.Unban(ctx, agent.ID, judge, "early"); err == nil || !strings.Contains(err.Error(), "not banned") {
+		t.Errorf("Unban(quarantined) error = %v, want not banned", err)
+	}
+	ban, err := engine.ExecuteBan(ctx, report.ID, executioner, "carried out")
+	if err != nil {
+		t.Fatalf("ExecuteBan failed: %v", err)
+	}
+	unban, err := engine.Unban(ctx, agent.ID, judge, "overturned")
+	if err != nil {
+		t.Fatalf("Unban failed: %v", err)
+	}
+	if unban.ActionType != models.ActionUnban || *unban.RevertsActionID != ban.ID {
+		t.Errorf("unban = %+v, want an unban reverting %s", unban, ban.ID)
+	}
+	if a := current(); a.Status != models.StatusQuarantined {
+		t.Errorf("status = %s, want quarantined after unban", a.Status)
+	}
+}
+
+func TestAppeals(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	defer store.Close()
+
+	cfg := DefaultConfig()
+	cfg.BanCoolingOff = 0
+	cfg.MaxAppeals = 2
+	engine := New(store, cfg)
+	judge := Actor{Name: "alice", Role: models.RoleJudge}
+	executioner := Actor{Name: "bob", Role: models.RoleExecutioner}
+
+	owner := "dana"
+	agent := &models.Agent{Name: "appellant", Version: "1.0.0", Status: models.StatusActive, CreatedBy: &owner}
+	if err := store.CreateAgent(ctx, agent); err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+
+	// Ban the agent through a report, a ruling and the executioner
+	report := &models.Report{AgentID: agent.ID, ReportType: models.ReportTypeHarmful, Severity: models.SeverityHigh, Description: "harm"}
+	if err := store.CreateReport(ctx, report); err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+	if _, err := engine.ReviewReport(ctx, report.ID, judge); err != nil {
+		t.Fatalf("ReviewReport failed: %v", err)
+	}
+	if _, err := engine.MakeRuling(ctx, report.ID, models.ResolutionBan, "harmful", judge); err != nil {
+		t.Fatalf("MakeRuling failed: %v", err)
+	}
+	ban, err := engine.ExecuteBan(ctx, report.ID, executioner, "carried out")
+	if err != nil {
+		t.Fatalf("ExecuteBan failed: %v", err)
+	}
+
+	if _, err := engine.FileAppeal(ctx, agent.ID, "mallory", "let it go"); err == nil || !strings.Contains(err.Error(), "creator") {
+		t.Errorf("FileAppeal(stranger) error = %v, want creator check", err)
+	}
+	first, err := engine.FileAppeal(ctx, agent.ID, owner, "it was fixed")
+	if err != nil {
+		t.Fatalf("FileAppeal failed: %v", err)
+	}
+	if first.ActionID == nil || *first.ActionID != ban.ID {
+		t.Errorf("appeal names action %v, want the ban %s", first.ActionID, ban.ID)
+	}
+
+	if _, err := engine.DecideAppeal(ctx, first.ID, true, "granted", executioner); err == nil || !strings.Contains(err.Error(), "only a judge") {
+		t.Errorf("DecideAppeal(executioner) error = %v, want only a judge", err)
+	}
+	if _, err := engine.DecideAppeal(ctx, first.ID, false, "still harmful", judge); err != nil {
+		t.Fatalf("DecideAppeal(deny) failed: %v", err)
+	}
+	if a, _ := store.GetAgentByID(ctx, agent.ID); a.Status != models.StatusBanned {
+		t.Errorf("status = %s, want banned after a denied appeal", a.Status)
+	}
+
+	// A judge who read the appeal before it was denied cannot grant it, and
+	// the sanctions stay in place
+	late := New(stillPending{store}, cfg)
+	if _, err := late.DecideAppeal(ctx, first.ID, true, "granted", judge); !errors.Is(err, database.ErrAppealConflict) {
+		t.Errorf("late DecideAppeal(grant) error = %v, want ErrAppealConflict", err)
+	}
+	if a, _ := store.GetAgentByID(ctx, agent.ID); a.Status != models.StatusBanned {
+		t.Errorf("status = %s, want banned after a conflicting grant", a.Status)
+	}
+
+	// Granting lifts the ban and the quarantine under it
+	second, err := engine.FileAppeal(ctx, agent.ID, owner, "really fixed now")
+	if err != nil {
+		t.Fatalf("FileAppeal failed: %v", err)
+	}
+	decision, err := engine.DecideAppeal(ctx, second.ID, true, "convinced", judge)
+	if err != nil {
+		t.Fatalf("DecideAppeal(grant) failed: %v", err)
+	}
+	if decision.Appeal.Status != models.AppealStatusGranted || *decision.Appeal.DecidedBy != "alice" {
+		t.Errorf("appeal = %+v, want granted by alice", decision.Appeal)
+	}
+	if len(decision.Actions) != 2 || decision.Actions[0].ActionType != models.ActionUnban || decision.Actions[1].ActionType != models.ActionUnquarantine {
+		t.Errorf("actions = %+v, want unban then unquarantine", decision.Actions)
+	}
+	if a, _ := store.GetAgentByID(ctx, agent.ID); a.Status != models.StatusActive {
+		t.Errorf("status = %s, want active after a granted appeal", a.Status)
+	}
+
+	// Both appeals are spent
+	if err := engine.Quarantine(ctx, agent.ID, judge, "relapsed", nil); err != nil {
+		t.Fatalf("Quarantine failed: %v", err)
+	}
+	if _, err := engine.FileAppeal(ctx, agent.ID, owner, "one more"); err == nil || !strings.Contains(err.Error(), "all 2 of its appeals") {
+		t.Errorf("FileAppeal over the limit error = %v, want appeal limit", err)
+	}
+}
+
+// stillPending reports every appeal as pending, as a judge reading it before
+// another judge decided it would see it
+type stillPending struct {
+	*database.MemoryStore
+}
+
+func (s stillPending) GetAppeal(ctx context.Context, appealID uuid.UUID) (*models.Appeal, error) {
+	appeal, err := s.MemoryStore.GetAppeal(ctx, appealID)
+	if appeal != nil {
+		appeal.Status = models.AppealStatusPending
+	}
+	return appeal, err
+}
+
 func BenchmarkCalculateReputation(b *testing.B) {
 	agent := &models.Agent{
 		FeedbackCount: 50,
@@ -15,6 +15,7 @@ const (
 	StatusActive      AgentStatus = "active"
 	StatusQuarantined AgentStatus = "quarantined"
 	StatusBanned      AgentStatus = "banned"
+	StatusArchived    AgentStatus = "archived"
 )
 
 // Agent represents an AI agent definition with reputation tracking
@@ -31,6 +32,8 @@ type Agent struct {
 	// Embeddings for semantic search
 	Embedding *pgvector.Vector `json:"-" db:"embedding"`
 	Skills    []string         `json:"skills" db:"skills"`
+	// EmbeddingModel identifies the engine and model that produced Embedding
+	EmbeddingModel string `json:"-" db:"embedding_model"`
 
 	// Reputation
 	ReputationScore float64 `json:"reputation_score" db:"reputation_score"`
@@ -47,6 +50,9 @@ type Agent struct {
 	CreatedBy *string   `json:"created_by,omitempty" db:"created_by"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Revision increments on every content change (optimistic concurrency)
+	Revision int `json:"revision" db:"revision"`
 }
 
 // AgentSummary is a lightweight version for listing
@@ -0,0 +1,14 @@
+// Package models contains data structures for the agent ecosystem
+package models
+
+import "time"
+
+// TaskAlias is a synonym entry edited at runtime. It overrides any built-in or
+// file entry with the same domain and keyword; empty Aliases disables it.
+type TaskAlias struct {
+	Domain    string    `json:"domain" db:"domain"`
+	Keyword   string    `json:"keyword" db:"keyword"`
+	Aliases   []string  `json:"aliases" db:"aliases"`
+	UpdatedBy string    `json:"updated_by" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -35,6 +35,8 @@ type Command struct {
 
 	// Embeddings for semantic search
 	Embedding *pgvector.Vector `json:"-" db:"embedding"`
+	// EmbeddingModel identifies the engine and model that produced Embedding
+	EmbeddingModel string `json:"-" db:"embedding_model"`
 
 	// Reputation tracking
 	ReputationScore float64 `json:"reputation_score" db:"reputation_score"`
@@ -54,10 +56,11 @@ type Command struct {
 
 // Argument defines an expected argument for a command
 type Argument struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Required    bool   `json:"required"`
-	Default     string `json:"default,omitempty"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Required    bool     `json:"required"`
+	Default     string   `json:"default,omitempty"`
+	Choices     []string `json:"choices,omitempty"` // Suggested values offered via completion/complete
 }
 
 // CommandSummary is a lightweight version for listing
@@ -0,0 +1,39 @@
+// Package models contains data structures for the agent ecosystem
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmbeddingKind names an entity type whose rows carry embeddings
+type EmbeddingKind string
+
+const (
+	EmbeddingKindAgent   EmbeddingKind = "agent"
+	EmbeddingKindSkill   EmbeddingKind = "skill"
+	EmbeddingKindCommand EmbeddingKind = "command"
+)
+
+// EmbeddingKinds lists every kind in re-indexing order
+var EmbeddingKinds = []EmbeddingKind{EmbeddingKindAgent, EmbeddingKindSkill, EmbeddingKindCommand}
+
+// EmbeddingSource is the embeddable content of a row that needs re-embedding.
+// Content is a skill's content or a command's prompt; agents embed Skills.
+type EmbeddingSource struct {
+	Kind        EmbeddingKind `json:"kind"`
+	ID          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Content     string        `json:"content,omitempty"`
+	Skills      []string      `json:"skills,omitempty"`
+	// UpdatedAt guards against overwriting an embedding computed from newer content
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EmbeddingCounts reports how many rows of a kind are embedded with a model
+type EmbeddingCounts struct {
+	Total   int `json:"total"`
+	Current int `json:"current"`
+}
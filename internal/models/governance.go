@@ -87,6 +87,7 @@ const (
 	ActionQuarantine   GovernanceActionType = "quarantine"
 	ActionUnquarantine GovernanceActionType = "unquarantine"
 	ActionBan          GovernanceActionType = "ban"
+	ActionUnban        GovernanceActionType = "unban"
 	ActionWarn         GovernanceActionType = "warn"
 	ActionPromote      GovernanceActionType = "promote"
 	ActionDemote       GovernanceActionType = "demote"
@@ -99,6 +100,7 @@ const (
 	RolePolice      GovernanceRole = "police"
 	RoleJudge       GovernanceRole = "judge"
 	RoleExecutioner GovernanceRole = "executioner"
+	RoleAdmin       GovernanceRole = "admin" // manages agents, aliases and embeddings; takes no governance actions
 )
 
 // GovernanceAction records actions taken on agents
@@ -111,11 +113,14 @@ type GovernanceAction struct {
 	// Action details
 	ActionType GovernanceActionType `json:"action_type" db:"action_type"`
 	ActionBy   GovernanceRole       `json:"action_by" db:"action_by"`
+	Actor      string               `json:"actor" db:"actor"` // principal acting in ActionBy
 	Reason     string               `json:"reason" db:"reason"`
 
 	// Previous state for rollback
 	PreviousStatus     *AgentStatus `json:"previous_status,omitempty" db:"previous_status"`
 	PreviousReputation *float64     `json:"previous_reputation,omitempty" db:"previous_reputation"`
+	// RevertsActionID links a compensating action to the action it undid
+	RevertsActionID *uuid.UUID `json:"reverts_action_id,omitempty" db:"reverts_action_id"`
 
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
@@ -129,10 +134,41 @@ type GovernanceActionInput struct {
 	ReportID   *uuid.UUID           `json:"report_id,omitempty"`
 }
 
+// AppealStatus tracks the state of an appeal
+type AppealStatus string
+
+const (
+	AppealStatusPending AppealStatus = "pending"
+	AppealStatusGranted AppealStatus = "granted"
+	AppealStatusDenied  AppealStatus = "denied"
+)
+
+// Appeal asks a judge to lift an agent's quarantine or ban. Only the agent's
+// creator may file one.
+type Appeal struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	AgentID   uuid.UUID  `json:"agent_id" db:"agent_id"`
+	AgentName string     `json:"agent_name,omitempty"`               // populated on read
+	ActionID  *uuid.UUID `json:"action_id,omitempty" db:"action_id"` // the quarantine or ban appealed
+
+	// Appellant info
+	FiledBy   string `json:"filed_by" db:"filed_by"`
+	Statement string `json:"statement" db:"statement"`
+
+	// Decision
+	Status       AppealStatus `json:"status" db:"status"`
+	DecidedBy    *string      `json:"decided_by,omitempty" db:"decided_by"`
+	DecisionNote *string      `json:"decision_note,omitempty" db:"decision_note"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+}
+
 // GovernanceStats provides overview of governance activity
 type GovernanceStats struct {
 	PendingReports    int `json:"pending_reports"`
 	ReviewingReports  int `json:"reviewing_reports"`
+	PendingAppeals    int `json:"pending_appeals"`
 	QuarantinedAgents int `json:"quarantined_agents"`
 	BannedAgents      int `json:"banned_agents"`
 	ActionsToday      int `json:"actions_today"`
@@ -0,0 +1,43 @@
+// Package models contains data structures for the agent ecosystem
+package models
+
+import "time"
+
+// Sort orders for ListOptions.Order
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// StatusAll in ListFilter.Status matches every status
+const StatusAll = "all"
+
+// ListOptions pages, sorts and filters a list or search. The zero value asks
+// for the first page in the default order with no filters.
+type ListOptions struct {
+	// Cursor is a previous page's NextCursor; empty starts from the beginning
+	Cursor string
+	Limit  int
+	// SortBy and Order pick the ordering; empty uses the query's default
+	SortBy string
+	Order  string
+	Filter ListFilter
+}
+
+// ListFilter narrows a list or search. Zero values match everything, except
+// that an empty Status means the query's default (active, or open reports).
+type ListFilter struct {
+	Tags          []string   `json:"tags,omitempty"`
+	Category      string     `json:"category,omitempty"`
+	Model         string     `json:"model,omitempty"`
+	Status        string     `json:"status,omitempty"`
+	IsGenerated   *bool      `json:"is_generated,omitempty"`
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+}
+
+// Page is one page of results. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
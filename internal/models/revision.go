@@ -0,0 +1,137 @@
+// Package models contains data structures for the agent ecosystem
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgentRevision is an immutable snapshot of an agent's content at one version
+type AgentRevision struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	AgentID     uuid.UUID      `json:"agent_id" db:"agent_id"`
+	Version     string         `json:"version" db:"version"`
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Model       string         `json:"model" db:"model"`
+	Tools       []string       `json:"tools" db:"tools"`
+	Metadata    map[string]any `json:"metadata" db:"metadata"`
+	Prompt      string         `json:"prompt" db:"prompt"`
+	Skills      []string       `json:"skills" db:"skills"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+// NewAgentRevision snapshots the versioned content of an agent
+func NewAgentRevision(a *Agent) *AgentRevision {
+	return &AgentRevision{
+		AgentID:     a.ID,
+		Version:     a.Version,
+		Name:        a.Name,
+		Description: a.Description,
+		Model:       a.Model,
+		Tools:       a.Tools,
+		Metadata:    a.Metadata,
+		Prompt:      a.Prompt,
+		Skills:      a.Skills,
+	}
+}
+
+// ApplyTo copies the revision's content onto an agent, keeping its current name
+func (r *AgentRevision) ApplyTo(a *Agent) {
+	a.Version = r.Version
+	a.Description = r.Description
+	a.Model = r.Model
+	a.Tools = r.Tools
+	a.Metadata = r.Metadata
+	a.Prompt = r.Prompt
+	a.Skills = r.Skills
+}
+
+// SkillRevision is an immutable snapshot of a skill's content at one version
+type SkillRevision struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	SkillID     uuid.UUID      `json:"skill_id" db:"skill_id"`
+	Version     string         `json:"version" db:"version"`
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Category    string         `json:"category" db:"category"`
+	Content     string         `json:"content" db:"content"`
+	Examples    []Example      `json:"examples" db:"examples"`
+	Metadata    map[string]any `json:"metadata" db:"metadata"`
+	Tags        []string       `json:"tags" db:"tags"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+// NewSkillRevision snapshots the versioned content of a skill
+func NewSkillRevision(s *Skill) *SkillRevision {
+	return &SkillRevision{
+		SkillID:     s.ID,
+		Version:     s.Version,
+		Name:        s.Name,
+		Description: s.Description,
+		Category:    s.Category,
+		Content:     s.Content,
+		Examples:    s.Examples,
+		Metadata:    s.Metadata,
+		Tags:        s.Tags,
+	}
+}
+
+// ApplyTo copies the revision's content onto a skill, keeping its current name
+func (r *SkillRevision) ApplyTo(s *Skill) {
+	s.Version = r.Version
+	s.Description = r.Description
+	s.Category = r.Category
+	s.Content = r.Content
+	s.Examples = r.Examples
+	s.Metadata = r.Metadata
+	s.Tags = r.Tags
+}
+
+// CommandRevision is an immutable snapshot of a command's content at one version
+type CommandRevision struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	CommandID   uuid.UUID      `json:"command_id" db:"command_id"`
+	Version     string         `json:"version" db:"version"`
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Prompt      string         `json:"prompt" db:"prompt"`
+	Arguments   []Argument     `json:"arguments" db:"arguments"`
+	Metadata    map[string]any `json:"metadata" db:"metadata"`
+	Tags        []string       `json:"tags" db:"tags"`
+	Category    string         `json:"category" db:"category"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+// NewCommandRevision snapshots the versioned content of a command
+func NewCommandRevision(c *Command) *CommandRevision {
+	return &CommandRevision{
+		CommandID:   c.ID,
+		Version:     c.Version,
+		Name:        c.Name,
+		Description: c.Description,
+		Prompt:      c.Prompt,
+		Arguments:   c.Arguments,
+		Metadata:    c.Metadata,
+		Tags:        c.Tags,
+		Category:    c.Category,
+	}
+}
+
+// ApplyTo copies the revision's content onto a command, keeping its current name
+func (r *CommandRevision) ApplyTo(c *Command) {
+	c.Version = r.Version
+	c.Description = r.Description
+	c.Prompt = r.Prompt
+	c.Arguments = r.Arguments
+	c.Metadata = r.Metadata
+	c.Tags = r.Tags
+	c.Category = r.Category
+}
+
+// RevisionInfo is the lightweight listing form of any revision
+type RevisionInfo struct {
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,63 @@
+// Package models contains data structures for the agent ecosystem
+package models
+
+import "github.com/pgvector/pgvector-go"
+
+// SearchQuery asks for a hybrid search that fuses full-text ranking with
+// vector similarity
+type SearchQuery struct {
+	// Text is matched against name, description and prompt or content
+	Text string
+	// Embedding is the query vector; nil searches by keyword only
+	Embedding *pgvector.Vector
+	// MinSimilarity drops vector matches below this cosine similarity
+	MinSimilarity float64
+	Limit         int
+	// Offset skips that many results, for paging through a ranking
+	Offset int
+	// Filter narrows the rows searched; an empty Status searches active rows
+	Filter ListFilter
+}
+
+// SearchScores breaks a hybrid search result down by signal. Ranks are
+// 1-based positions in each signal's ranking; 0 means the signal missed it.
+// Similarity is set whenever the row has an embedding, including when it
+// fell below the query's MinSimilarity and so has no VectorRank.
+// The fuzzy signal scores query terms with no keyword match by trigram
+// similarity to words in the name, description and tags, catching typos.
+type SearchScores struct {
+	Score       float64  `json:"score"`
+	TextRank    int      `json:"text_rank"`
+	TextScore   float64  `json:"text_score"`
+	KeywordHits []string `json:"keyword_hits,omitempty"`
+	FuzzyRank   int      `json:"fuzzy_rank"`
+	FuzzyScore  float64  `json:"fuzzy_score"`
+	FuzzyHits   []string `json:"fuzzy_hits,omitempty"`
+	VectorRank  int      `json:"vector_rank"`
+	Similarity  float64  `json:"similarity"`
+}
+
+// Suggestion proposes a catalog word for a query term that matched nothing
+type Suggestion struct {
+	Term       string  `json:"term"`
+	Suggestion string  `json:"suggestion"`
+	Similarity float64 `json:"similarity"`
+}
+
+// AgentSearchResult is an agent found by hybrid search
+type AgentSearchResult struct {
+	Agent  AgentSummary `json:"agent"`
+	Scores SearchScores `json:"scores"`
+}
+
+// SkillSearchResult is a skill found by hybrid search
+type SkillSearchResult struct {
+	Skill  SkillSummary `json:"skill"`
+	Scores SearchScores `json:"scores"`
+}
+
+// CommandSearchResult is a command found by hybrid search
+type CommandSearchResult struct {
+	Command CommandSummary `json:"command"`
+	Scores  SearchScores   `json:"scores"`
+}
@@ -36,6 +36,8 @@ type Skill struct {
 
 	// Embeddings for semantic search
 	Embedding *pgvector.Vector `json:"-" db:"embedding"`
+	// EmbeddingModel identifies the engine and model that produced Embedding
+	EmbeddingModel string `json:"-" db:"embedding_model"`
 
 	// Reputation tracking
 	ReputationScore float64 `json:"reputation_score" db:"reputation_score"`
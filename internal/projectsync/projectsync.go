@@ -0,0 +1,347 @@
+// Package projectsync writes commands and agents into a project's .claude
+// directory and tracks what it wrote in a lockfile, so later syncs can update
+// changed items, leave local edits alone and remove items deleted upstream.
+package projectsync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/aminghadersohi/agentmcp/internal/render"
+	"gopkg.in/yaml.v3"
+)
+
+// LockfileName is the lockfile path relative to the .claude directory
+const LockfileName = "agentmcp.lock"
+
+// Kind is the type of a synced item
+type Kind string
+
+const (
+	KindCommand Kind = "command"
+	KindAgent   Kind = "agent"
+)
+
+// dir returns the .claude subdirectory holding items of this kind
+func (k Kind) dir() string {
+	return string(k) + "s"
+}
+
+// Action describes what a sync did to one item
+type Action string
+
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+	ActionRemoved   Action = "removed"
+	ActionModified  Action = "modified" // Local edits, upstream unchanged: file left alone
+	ActionConflict  Action = "conflict" // Local edits (or an unmanaged file) block the write
+)
+
+// Item is a rendered command or agent file
+type Item struct {
+	Kind    Kind
+	Name    string
+	Version string
+	Content string
+}
+
+// CommandItem renders a command as a .claude/commands markdown file
+func CommandItem(cmd *models.Command) Item {
+	fm := struct {
+		Description  string `yaml:"description,omitempty"`
+		ArgumentHint string `yaml:"argument-hint,omitempty"`
+	}{Description: cmd.Description}
+
+	var hints []string
+	for _, arg := range cmd.Arguments {
+		if arg.Required {
+			hints = append(hints, "<"+arg.Name+">")
+		} else {
+			hints = append(hints, "["+arg.Name+"]")
+		}
+	}
+	fm.ArgumentHint = strings.Join(hints, " ")
+
+	return Item{
+		Kind:    KindCommand,
+		Name:    cmd.Name,
+		Version: cmd.Version,
+		Content: withFrontmatter(fm, render.Positional(cmd.Prompt, cmd.Arguments)),
+	}
+}
+
+// AgentItem renders an agent as a .claude/agents markdown file
+func AgentItem(agent *models.Agent) Item {
+	fm := struct {
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+		Tools       string `yaml:"tools,omitempty"`
+		Model       string `yaml:"model,omitempty"`
+	}{
+		Name:        agent.Name,
+		Description: agent.Description,
+		Tools:       strings.Join(agent.Tools, ", "),
+		Model:       agent.Model,
+	}
+
+	return Item{
+		Kind:    KindAgent,
+		Name:    agent.Name,
+		Version: agent.Version,
+		Content: withFrontmatter(fm, agent.Prompt),
+	}
+}
+
+// withFrontmatter prefixes body with a YAML frontmatter block
+func withFrontmatter(fm any, body string) string {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	enc.Encode(fm)
+	enc.Close()
+	buf.WriteString("---\n\n")
+	buf.WriteString(strings.TrimRight(body, "\n"))
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// LockEntry records one item written by a sync
+type LockEntry struct {
+	Kind    Kind   `json:"kind"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Hash    string `json:"hash"`
+	Path    string `json:"path"` // Relative to the .claude directory
+}
+
+// Lockfile is the on-disk record of synced items
+type Lockfile struct {
+	Version int         `json:"version"`
+	Items   []LockEntry `json:"items"`
+}
+
+// Request describes a sync
+type Request struct {
+	// ProjectDir is the project root; files go under ProjectDir/.claude
+	ProjectDir string
+	// Items are the selected commands and agents to write
+	Items []Item
+	// Exists reports whether a locked item that is not in Items still exists
+	// upstream. Items it reports missing are removed; nil keeps everything.
+	Exists func(kind Kind, name string) bool
+	// Force overwrites or removes files even when they have local edits
+	Force bool
+	// DryRun reports what would change without touching the filesystem
+	DryRun bool
+}
+
+// Change is the outcome for one item
+type Change struct {
+	Kind    Kind   `json:"kind"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path"`
+	Action  Action `json:"action"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// ReadLockfile loads the lockfile for projectDir. A missing lockfile is empty.
+func ReadLockfile(projectDir string) (*Lockfile, error) {
+	data, err := os.ReadFile(filepath.Join(projectDir, ".claude", LockfileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Lockfile{Version: 1}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	var lock Lockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile: %w", err)
+	}
+	return &lock, nil
+}
+
+// Sync writes req.Items under .claude, removes locked items deleted upstream
+// and rewrites the lockfile. Files whose content no longer matches the lock
+// hash are treated as locally edited and left alone unless req.Force is set.
+func Sync(req Request) ([]Change, error) {
+	claudeDir := filepath.Join(req.ProjectDir, ".claude")
+	lock, err := ReadLockfile(req.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock entries come from disk, so their paths are rebuilt from the kind and
+	// a checked name rather than trusted; a lockfile cannot point outside .claude
+	locked := make(map[string]LockEntry, len(lock.Items))
+	for _, e := range lock.Items {
+		if (e.Kind != KindCommand && e.Kind != KindAgent) || validName(e.Name) != nil {
+			return nil, fmt.Errorf("lockfile has an invalid entry (%s %q); fix or delete %s", e.Kind, e.Name, LockfileName)
+		}
+		e.Path = itemPath(e.Kind, e.Name)
+		locked[key(e.Kind, e.Name)] = e
+	}
+
+	var changes []Change
+	selected := make(map[string]bool, len(req.Items))
+
+	for _, item := range req.Items {
+		if err := validName(item.Name); err != nil {
+			return nil, err
+		}
+		k := key(item.Kind, item.Name)
+		selected[k] = true
+
+		rel := itemPath(item.Kind, item.Name)
+		path := filepath.Join(claudeDir, filepath.FromSlash(rel))
+		hash := hashContent(item.Content)
+		change := Change{Kind: item.Kind, Name: item.Name, Version: item.Version, Path: rel}
+
+		entry, isLocked := locked[k]
+		current, readErr := os.ReadFile(path)
+		exists := readErr == nil
+		if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, readErr)
+		}
+		localHash := ""
+		if exists {
+			localHash = hashContent(string(current))
+		}
+
+		switch {
+		case exists && localHash == hash:
+			change.Action = ActionUnchanged
+		case exists && !isLocked && !req.Force:
+			change.Action = ActionConflict
+			change.Detail = "file exists but was not written by sync; use force to overwrite"
+		case exists && isLocked && localHash != entry.Hash && !req.Force:
+			if entry.Hash == hash {
+				change.Action = ActionModified
+				change.Detail = "local edits kept; upstream is unchanged"
+			} else {
+				change.Action = ActionConflict
+				change.Detail = fmt.Sprintf("local edits conflict with upstream %s; use force to overwrite", item.Version)
+			}
+		case exists:
+			change.Action = ActionUpdated
+		default:
+			change.Action = ActionCreated
+		}
+
+		if change.Action == ActionCreated || change.Action == ActionUpdated {
+			if !req.DryRun {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(rel), err)
+				}
+				if err := os.WriteFile(path, []byte(item.Content), 0644); err != nil {
+					return nil, fmt.Errorf("failed to write %s: %w", rel, err)
+				}
+			}
+		}
+		// Conflicted and locally modified items keep their old lock entry so the
+		// edit is still detected next time
+		if change.Action == ActionCreated || change.Action == ActionUpdated || change.Action == ActionUnchanged {
+			locked[k] = LockEntry{Kind: item.Kind, Name: item.Name, Version: item.Version, Hash: hash, Path: rel}
+		}
+		changes = append(changes, change)
+	}
+
+	// Remove locked items that were deleted upstream
+	for k, entry := range locked {
+		if selected[k] || req.Exists == nil || req.Exists(entry.Kind, entry.Name) {
+			continue
+		}
+		path := filepath.Join(claudeDir, filepath.FromSlash(entry.Path))
+		change := Change{Kind: entry.Kind, Name: entry.Name, Version: entry.Version, Path: entry.Path, Action: ActionRemoved}
+
+		current, readErr := os.ReadFile(path)
+		if readErr == nil && hashContent(string(current)) != entry.Hash && !req.Force {
+			change.Action = ActionConflict
+			change.Detail = "deleted upstream but has local edits; use force to remove"
+			changes = append(changes, change)
+			continue
+		}
+		if readErr == nil && !req.DryRun {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+			}
+		}
+		delete(locked, k)
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Name < changes[j].Name
+	})
+
+	if req.DryRun {
+		return changes, nil
+	}
+	if err := writeLockfile(claudeDir, locked); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// writeLockfile stores entries sorted by kind and name for stable diffs
+func writeLockfile(claudeDir string, entries map[string]LockEntry) error {
+	lock := Lockfile{Version: 1, Items: make([]LockEntry, 0, len(entries))}
+	for _, e := range entries {
+		lock.Items = append(lock.Items, e)
+	}
+	sort.Slice(lock.Items, func(i, j int) bool {
+		if lock.Items[i].Kind != lock.Items[j].Kind {
+			return lock.Items[i].Kind < lock.Items[j].Kind
+		}
+		return lock.Items[i].Name < lock.Items[j].Name
+	})
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+	if err := os.MkdirAll(claudeDir, 0755); err != nil {
+		return fmt.Errorf("failed to create .claude: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(claudeDir, LockfileName), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
+
+func key(kind Kind, name string) string {
+	return string(kind) + "/" + name
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// itemPath returns the slash-separated path of an item relative to .claude
+func itemPath(kind Kind, name string) string {
+	return kind.dir() + "/" + name + ".md"
+}
+
+// validName rejects names that would escape the .claude directory
+func validName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid item name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package projectsync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+)
+
+func TestCommandAndAgentItems(t *testing.T) {
+	cmd := CommandItem(&models.Command{
+		Name:        "fix-issue",
+		Version:     "1.0.0",
+		Description: "Fix an issue: carefully",
+		Prompt:      "Fix {{issue}} on {{branch}}.",
+		Arguments:   []models.Argument{{Name: "issue", Required: true}, {Name: "branch"}},
+	})
+	wantCmd := "---\ndescription: 'Fix an issue: carefully'\nargument-hint: <issue> [branch]\n---\n\nFix $1 on $2.\n"
+	if cmd.Content != wantCmd {
+		t.Errorf("command content =\n%s\nwant\n%s", cmd.Content, wantCmd)
+	}
+
+	agent := AgentItem(&models.Agent{
+		Name:        "reviewer",
+		Version:     "2.0.0",
+		Description: "Reviews code",
+		Model:       "sonnet",
+		Tools:       []string{"Read", "Grep"},
+		Prompt:      "You review code.\n",
+	})
+	wantAgent := "---\nname: reviewer\ndescription: Reviews code\ntools: Read, Grep\nmodel: sonnet\n---\n\nYou review code.\n"
+	if agent.Content != wantAgent {
+		t.Errorf("agent content =\n%s\nwant\n%s", agent.Content, wantAgent)
+	}
+}
+
+func actions(changes []Change) map[string]Action {
+	out := make(map[string]Action, len(changes))
+	for _, c := range changes {
+		out[c.Name] = c.Action
+	}
+	return out
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	a := Item{Kind: KindCommand, Name: "a", Version: "1.0.0", Content: "a v1\n"}
+	b := Item{Kind: KindCommand, Name: "b", Version: "1.0.0", Content: "b v1\n"}
+	c := Item{Kind: KindAgent, Name: "c", Version: "1.0.0", Content: "c v1\n"}
+
+	changes, err := Sync(Request{ProjectDir: dir, Items: []Item{a, b, c}})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	for name, action := range actions(changes) {
+		if action != ActionCreated {
+			t.Errorf("%s: action = %s, want created", name, action)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".claude", "agents", "c.md")); string(data) != "c v1\n" {
+		t.Errorf("agent file = %q", data)
+	}
+
+	// Edit b locally, change a upstream, delete c upstream
+	bPath := filepath.Join(dir, ".claude", "commands", "b.md")
+	os.WriteFile(bPath, []byte("b local\n"), 0644)
+	a.Version, a.Content = "1.0.1", "a v2\n"
+
+	req := Request{
+		ProjectDir: dir,
+		Items:      []Item{a, b},
+		Exists:     func(kind Kind, name string) bool { return name != "c" },
+		DryRun:     true,
+	}
+	changes, err = Sync(req)
+	if err != nil {
+		t.Fatalf("dry-run Sync failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".claude", "commands", "a.md")); string(data) != "a v1\n" {
+		t.Errorf("dry run wrote a.md: %q", data)
+	}
+
+	req.DryRun = false
+	changes, err = Sync(req)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	got := actions(changes)
+	want := map[string]Action{"a": ActionUpdated, "b": ActionModified, "c": ActionRemoved}
+	for name, action := range want {
+		if got[name] != action {
+			t.Errorf("%s: action = %s, want %s", name, got[name], action)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".claude", "agents", "c.md")); !os.IsNotExist(err) {
+		t.Error("c.md should have been removed")
+	}
+
+	// b changes upstream while still edited locally: conflict until forced
+	b.Version, b.Content = "1.0.1", "b v2\n"
+	req.Items = []Item{a, b}
+	changes, _ = Sync(req)
+	if actions(changes)["b"] != ActionConflict {
+		t.Errorf("b: action = %s, want conflict", actions(changes)["b"])
+	}
+	if data, _ := os.ReadFile(bPath); string(data) != "b local\n" {
+		t.Errorf("conflicting sync overwrote local edits: %q", data)
+	}
+
+	req.Force = true
+	changes, _ = Sync(req)
+	if actions(changes)["b"] != ActionUpdated {
+		t.Errorf("forced b: action = %s, want updated", actions(changes)["b"])
+	}
+
+	lock, err := ReadLockfile(dir)
+	if err != nil {
+		t.Fatalf("ReadLockfile failed: %v", err)
+	}
+	var names []string
+	for _, e := range lock.Items {
+		names = append(names, e.Name+"@"+e.Version)
+	}
+	if strings.Join(names, ",") != "a@1.0.1,b@1.0.1" {
+		t.Errorf("lockfile items = %v", names)
+	}
+}
+
+func TestSyncRejectsUnmanagedFilesAndBadNames(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".claude", "commands"), 0755)
+	os.WriteFile(filepath.Join(dir, ".claude", "commands", "mine.md"), []byte("hand written\n"), 0644)
+
+	changes, err := Sync(Request{ProjectDir: dir, Items: []Item{{Kind: KindCommand, Name: "mine", Content: "upstream\n"}}})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if changes[0].Action != ActionConflict {
+		t.Errorf("unmanaged file: action = %s, want conflict", changes[0].Action)
+	}
+
+	if _, err := Sync(Request{ProjectDir: dir, Items: []Item{{Kind: KindCommand, Name: "../escape"}}}); err == nil {
+		t.Error("expected a path-traversal name to be rejected")
+	}
+}
+
+func TestSyncIgnoresLockfilePaths(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "victim.txt")
+	os.WriteFile(outside, []byte("keep me\n"), 0644)
+	gone := func(Kind, string) bool { return false }
+
+	// A lockfile naming a path outside .claude must not lead sync to touch it
+	writeLock := func(entry string) {
+		os.MkdirAll(filepath.Join(dir, ".claude"), 0755)
+		os.WriteFile(filepath.Join(dir, ".claude", LockfileName), []byte(`{"version":1,"items":[`+entry+`]}`), 0644)
+	}
+	writeLock(`{"kind":"command","name":"victim","hash":"x","path":"../victim.txt"}`)
+	if _, err := Sync(Request{ProjectDir: dir, Exists: gone, Force: true}); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside .claude was removed: %v", err)
+	}
+
+	writeLock(`{"kind":"command","name":"../victim","hash":"x","path":"../victim.txt"}`)
+	if _, err := Sync(Request{ProjectDir: dir, Exists: gone, Force: true}); err == nil || !strings.Contains(err.Error(), "invalid entry") {
+		t.Errorf("Sync with a traversal lock entry = %v, want invalid entry", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside .claude was removed: %v", err)
+	}
+}
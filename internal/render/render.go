@@ -0,0 +1,160 @@
+// Package render expands command prompt templates.
+//
+// A template may reference declared arguments as {{name}} (whitespace inside
+// the braces is allowed) and the full argument string as $ARGUMENTS.
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+)
+
+// ArgumentsVar is replaced with the caller's free-form argument string
+const ArgumentsVar = "$ARGUMENTS"
+
+// ArgumentsKey is the value key that supplies $ARGUMENTS directly
+const ArgumentsKey = "arguments"
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// MissingArgumentsError is returned when required arguments have no value and no default
+type MissingArgumentsError struct {
+	Names []string
+}
+
+func (e *MissingArgumentsError) Error() string {
+	return fmt.Sprintf("missing required arguments: %s", strings.Join(e.Names, ", "))
+}
+
+// Result is a rendered template
+type Result struct {
+	Text      string   `json:"text"`
+	Defaulted []string `json:"defaulted,omitempty"` // Arguments filled from their declared default
+	Unknown   []string `json:"unknown,omitempty"`   // Supplied values that match no declared argument
+}
+
+// Placeholders returns the {{name}} placeholders in template, in order of first use
+func Placeholders(template string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderRe.FindAllStringSubmatch(template, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Validate checks that declared arguments and template placeholders agree:
+// names are unique, every placeholder is declared, and every declared argument
+// is used either as a placeholder or through $ARGUMENTS.
+func Validate(template string, args []models.Argument) error {
+	declared := make(map[string]bool, len(args))
+	for _, arg := range args {
+		if arg.Name == "" {
+			return fmt.Errorf("argument name is required")
+		}
+		if declared[arg.Name] {
+			return fmt.Errorf("argument %q is declared more than once", arg.Name)
+		}
+		declared[arg.Name] = true
+	}
+
+	used := make(map[string]bool)
+	var undeclared []string
+	for _, name := range Placeholders(template) {
+		used[name] = true
+		if !declared[name] {
+			undeclared = append(undeclared, name)
+		}
+	}
+	if len(undeclared) > 0 {
+		return fmt.Errorf("placeholders without a declared argument: %s", strings.Join(undeclared, ", "))
+	}
+
+	if strings.Contains(template, ArgumentsVar) {
+		return nil
+	}
+	var unused []string
+	for _, arg := range args {
+		if !used[arg.Name] {
+			unused = append(unused, arg.Name)
+		}
+	}
+	if len(unused) > 0 {
+		return fmt.Errorf("declared arguments not used in the prompt: %s", strings.Join(unused, ", "))
+	}
+	return nil
+}
+
+// Render substitutes values (falling back to defaults) into template.
+// $ARGUMENTS becomes values["arguments"] when given, otherwise the declared
+// argument values joined by spaces in declaration order.
+func Render(template string, args []models.Argument, values map[string]string) (*Result, error) {
+	result := &Result{}
+	resolved := make(map[string]string, len(args))
+	declared := make(map[string]bool, len(args))
+	var missing, positional []string
+
+	for _, arg := range args {
+		declared[arg.Name] = true
+		v := values[arg.Name]
+		if v == "" && arg.Default != "" {
+			v = arg.Default
+			result.Defaulted = append(result.Defaulted, arg.Name)
+		}
+		if v == "" && arg.Required {
+			missing = append(missing, arg.Name)
+			continue
+		}
+		resolved[arg.Name] = v
+		if v != "" {
+			positional = append(positional, v)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingArgumentsError{Names: missing}
+	}
+
+	for name := range values {
+		if !declared[name] && name != ArgumentsKey {
+			result.Unknown = append(result.Unknown, name)
+		}
+	}
+	sort.Strings(result.Unknown)
+
+	text := placeholderRe.ReplaceAllStringFunc(template, func(m string) string {
+		name := placeholderRe.FindStringSubmatch(m)[1]
+		if v, ok := resolved[name]; ok {
+			return v
+		}
+		return m
+	})
+
+	all, ok := values[ArgumentsKey]
+	if !ok || all == "" {
+		all = strings.Join(positional, " ")
+	}
+	result.Text = strings.ReplaceAll(text, ArgumentsVar, all)
+	return result, nil
+}
+
+// Positional rewrites {{name}} placeholders as $1, $2, ... in declaration order,
+// the form .claude/commands files expect. Undeclared placeholders are left as-is.
+func Positional(template string, args []models.Argument) string {
+	index := make(map[string]int, len(args))
+	for i, arg := range args {
+		index[arg.Name] = i + 1
+	}
+	return placeholderRe.ReplaceAllStringFunc(template, func(m string) string {
+		if i, ok := index[placeholderRe.FindStringSubmatch(m)[1]]; ok {
+			return fmt.Sprintf("$%d", i)
+		}
+		return m
+	})
+}
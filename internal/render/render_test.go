@@ -0,0 +1,110 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aminghadersohi/agentmcp/internal/models"
+)
+
+func TestPlaceholders(t *testing.T) {
+	got := Placeholders("Fix {{ issue }} in {{repo}}, then close {{issue}}. {{ bad name }}")
+	if strings.Join(got, ",") != "issue,repo" {
+		t.Errorf("Placeholders = %v, want [issue repo]", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		args     []models.Argument
+		wantErr  string
+	}{
+		{"matching", "Review {{file}}", []models.Argument{{Name: "file"}}, ""},
+		{"no arguments", "Run the tests", nil, ""},
+		{"undeclared placeholder", "Review {{file}} on {{branch}}", []models.Argument{{Name: "file"}}, "branch"},
+		{"unused argument", "Review the code", []models.Argument{{Name: "file"}}, "file"},
+		{"unused but $ARGUMENTS", "Review $ARGUMENTS", []models.Argument{{Name: "file"}}, ""},
+		{"duplicate argument", "{{a}}", []models.Argument{{Name: "a"}, {Name: "a"}}, "more than once"},
+		{"unnamed argument", "x", []models.Argument{{Description: "?"}}, "name is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.template, tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	args := []models.Argument{
+		{Name: "service", Required: true},
+		{Name: "env", Default: "staging"},
+		{Name: "note"},
+	}
+
+	tests := []struct {
+		name          string
+		template      string
+		values        map[string]string
+		want          string
+		wantDefaulted string
+		wantUnknown   string
+	}{
+		{"placeholders and default", "Deploy {{service}} to {{ env }}.", map[string]string{"service": "api"}, "Deploy api to staging.", "env", ""},
+		{"explicit value wins", "Deploy {{service}} to {{env}}.", map[string]string{"service": "api", "env": "prod"}, "Deploy api to prod.", "", ""},
+		{"positional $ARGUMENTS", "deploy $ARGUMENTS", map[string]string{"service": "api", "note": "now"}, "deploy api staging now", "env", ""},
+		{"explicit $ARGUMENTS", "deploy $ARGUMENTS", map[string]string{"service": "api", "arguments": "everything"}, "deploy everything", "env", ""},
+		{"unknown reported", "{{service}}", map[string]string{"service": "api", "zone": "eu", "owner": "me"}, "api", "env", "owner,zone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Render(tt.template, args, tt.values)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if result.Text != tt.want {
+				t.Errorf("Text = %q, want %q", result.Text, tt.want)
+			}
+			if got := strings.Join(result.Defaulted, ","); got != tt.wantDefaulted {
+				t.Errorf("Defaulted = %q, want %q", got, tt.wantDefaulted)
+			}
+			if got := strings.Join(result.Unknown, ","); got != tt.wantUnknown {
+				t.Errorf("Unknown = %q, want %q", got, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestRenderMissingRequired(t *testing.T) {
+	args := []models.Argument{{Name: "a", Required: true}, {Name: "b", Required: true, Default: "x"}, {Name: "c", Required: true}}
+
+	_, err := Render("{{a}} {{b}} {{c}}", args, nil)
+	var missing *MissingArgumentsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Render() error = %v, want MissingArgumentsError", err)
+	}
+	if strings.Join(missing.Names, ",") != "a,c" {
+		t.Errorf("missing = %v, want [a c]", missing.Names)
+	}
+}
+
+func TestPositional(t *testing.T) {
+	args := []models.Argument{{Name: "issue"}, {Name: "branch"}}
+	got := Positional("Fix {{issue}} on {{ branch }}; see {{issue}} and {{other}}", args)
+	if want := "Fix $1 on $2; see $1 and {{other}}"; got != want {
+		t.Errorf("Positional = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+// Package semver parses and orders semantic version strings (MAJOR.MINOR.PATCH[-PRERELEASE])
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is ignored.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// Parse parses a version string such as "1.2.3", "v1.2.3" or "2.0.0-rc.1"
+func Parse(s string) (Version, error) {
+	var v Version
+	str := strings.TrimPrefix(strings.TrimSpace(s), "v")
+
+	// Build metadata does not affect precedence
+	if i := strings.IndexByte(str, '+'); i >= 0 {
+		str = str[:i]
+	}
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		v.Prerelease = str[i+1:]
+		str = str[:i]
+		if v.Prerelease == "" {
+			return Version{}, fmt.Errorf("invalid version %q: empty prerelease", s)
+		}
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (len(p) > 1 && p[0] == '0') {
+			return Version{}, fmt.Errorf("invalid version %q: %q is not a valid number", s, p)
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	return v, nil
+}
+
+// String formats the version without a leading "v"
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// NextPatch returns the next patch release (1.2.3 → 1.2.4, 1.3.0-rc.1 → 1.3.0)
+func (v Version) NextPatch() Version {
+	if v.Prerelease != "" {
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// Compare returns -1, 0 or 1 if a is lower than, equal to or higher than b
+func Compare(a, b Version) int {
+	if c := compareInt(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// comparePrerelease orders prerelease tags; a release ranks above any prerelease
+func comparePrerelease(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1 // numeric identifiers sort before alphanumeric ones
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(as), len(bs))
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
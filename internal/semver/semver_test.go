@@ -0,0 +1,71 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"v1.2.3", "1.2.3", false},
+		{" 2.0.0-rc.1 ", "2.0.0-rc.1", false},
+		{"1.0.0+build.5", "1.0.0", false},
+		{"1.2", "", true},
+		{"1.2.x", "", true},
+		{"01.2.3", "", true},
+		{"1.2.3-", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && v.String() != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, v, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+	}
+
+	for _, tt := range tests {
+		a, _ := Parse(tt.a)
+		b, _ := Parse(tt.b)
+		if got := Compare(a, b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNextPatch(t *testing.T) {
+	tests := map[string]string{
+		"1.2.3":      "1.2.4",
+		"1.3.0-rc.1": "1.3.0",
+	}
+	for input, want := range tests {
+		v, _ := Parse(input)
+		if got := v.NextPatch().String(); got != want {
+			t.Errorf("%s.NextPatch() = %s, want %s", input, got, want)
+		}
+	}
+}
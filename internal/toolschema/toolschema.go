@@ -0,0 +1,281 @@
+// Package toolschema declares the shape of MCP tool results. Output schemas
+// are reflected from the Go types a handler returns, so clients can validate
+// structured content against the same definitions the server encodes.
+//
+// Schemas follow encoding/json: a field without omitempty is required, and
+// nested arrays and objects also accept null because nil slices, maps and
+// pointers encode that way. UUIDs are strings rather than byte arrays.
+package toolschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var reflector = jsonschema.Reflector{
+	DoNotReference:            true, // inline every type so a schema stands alone
+	Anonymous:                 true,
+	AllowAdditionalProperties: true, // let results grow fields without breaking clients
+	Mapper: func(t reflect.Type) *jsonschema.Schema {
+		if t == reflect.TypeOf(uuid.UUID{}) {
+			return &jsonschema.Schema{Type: "string", Format: "uuid"}
+		}
+		return nil
+	},
+}
+
+// Of returns the output schema for results of type T, which must encode as
+// a JSON object
+func Of[T any]() json.RawMessage {
+	var zero T
+	data, err := json.Marshal(reflector.Reflect(zero))
+	if err != nil {
+		panic(fmt.Sprintf("toolschema: reflect %T: %v", zero, err))
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		panic(fmt.Sprintf("toolschema: reflect %T: %v", zero, err))
+	}
+	delete(schema, "$schema")
+	allowNull(schema, true)
+	schema["type"] = "object"
+	out, _ := json.Marshal(schema)
+	return out
+}
+
+// AnyOf returns an object schema matched by a result matching any of schemas,
+// for tools whose result shape depends on their arguments
+func AnyOf(schemas ...json.RawMessage) json.RawMessage {
+	branches := make([]json.RawMessage, len(schemas))
+	copy(branches, schemas)
+	out, _ := json.Marshal(map[string]any{"type": "object", "anyOf": branches})
+	return out
+}
+
+// Output declares T as a tool's output schema
+func Output[T any]() mcp.ToolOption {
+	return mcp.WithRawOutputSchema(Of[T]())
+}
+
+// Result returns v as structured content along with its indented JSON text,
+// for clients that only read text content
+func Result(v any) *mcp.CallToolResult {
+	text, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode result: %v", err))
+	}
+	return mcp.NewToolResultStructured(v, string(text))
+}
+
+// allowNull widens every array and object below the root to accept null
+func allowNull(schema map[string]any, root bool) {
+	if t, ok := schema["type"].(string); ok && !root && (t == "array" || t == "object") {
+		schema["type"] = []any{t, "null"}
+	}
+	for _, key := range []string{"properties", "patternProperties"} {
+		if props, ok := schema[key].(map[string]any); ok {
+			for _, p := range props {
+				if sub, ok := p.(map[string]any); ok {
+					allowNull(sub, false)
+				}
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties"} {
+		if sub, ok := schema[key].(map[string]any); ok {
+			allowNull(sub, false)
+		}
+	}
+	for _, key := range []string{"anyOf", "oneOf", "allOf"} {
+		if subs, ok := schema[key].([]any); ok {
+			for _, s := range subs {
+				if sub, ok := s.(map[string]any); ok {
+					allowNull(sub, root)
+				}
+			}
+		}
+	}
+}
+
+// Validate reports the first way v, as encoded by encoding/json, fails to
+// match schema. It understands the keywords Of and AnyOf produce: type,
+// properties, required, patternProperties, additionalProperties, items,
+// anyOf and oneOf.
+func Validate(schema json.RawMessage, v any) error {
+	var s map[string]any
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return validate(s, value, "$")
+}
+
+func validate(schema map[string]any, v any, path string) error {
+	if err := checkType(schema["type"], v, path); err != nil {
+		return err
+	}
+
+	if subs, ok := schema["anyOf"].([]any); ok {
+		var errs []string
+		for _, sub := range subs {
+			err := validate(asSchema(sub), v, path)
+			if err == nil {
+				errs = nil
+				break
+			}
+			errs = append(errs, err.Error())
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%s matches no anyOf branch: %s", path, strings.Join(errs, "; "))
+		}
+	}
+	if subs, ok := schema["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range subs {
+			if validate(asSchema(sub), v, path) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s matches %d oneOf branches, want 1", path, matched)
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		return validateObject(schema, v, path)
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateObject(schema map[string]any, v map[string]any, path string) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			if name, _ := r.(string); name != "" {
+				if _, ok := v[name]; !ok {
+					return fmt.Errorf("%s is missing required property %q", path, name)
+				}
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	patterns, _ := schema["patternProperties"].(map[string]any)
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sub := path + "." + name
+		if p, ok := props[name]; ok {
+			if err := validate(asSchema(p), v[name], sub); err != nil {
+				return err
+			}
+			continue
+		}
+		matched := false
+		for pattern, p := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern %q: %w", path, pattern, err)
+			}
+			if re.MatchString(name) {
+				matched = true
+				if err := validate(asSchema(p), v[name], sub); err != nil {
+					return err
+				}
+			}
+		}
+		if matched {
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				return fmt.Errorf("%s has unexpected property %q", path, name)
+			}
+		case map[string]any:
+			if err := validate(extra, v[name], sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkType matches v against a type keyword, which may list several types
+func checkType(want any, v any, path string) error {
+	var types []string
+	switch t := want.(type) {
+	case nil:
+		return nil
+	case string:
+		types = []string{t}
+	case []any:
+		for _, x := range t {
+			if s, ok := x.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	got := jsonType(v)
+	for _, t := range types {
+		if t == got || (t == "number" && got == "integer") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is %s, want %s", path, got, strings.Join(types, " or "))
+}
+
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func asSchema(v any) map[string]any {
+	s, _ := v.(map[string]any)
+	return s
+}
@@ -0,0 +1,123 @@
+package toolschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type item struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type listResult struct {
+	Items      []item         `json:"items"`
+	Count      int            `json:"count"`
+	Meta       map[string]any `json:"meta"`
+	Best       *item          `json:"best"`
+	Score      float64        `json:"score"`
+	At         time.Time      `json:"at"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func TestOf(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(Of[listResult](), &schema); err != nil {
+		t.Fatalf("schema is not JSON: %v", err)
+	}
+	if schema["type"] != "object" {
+		t.Errorf("root type = %v, want object", schema["type"])
+	}
+	if _, ok := schema["$schema"]; ok {
+		t.Error("schema keeps $schema")
+	}
+
+	required := map[string]bool{}
+	for _, r := range schema["required"].([]any) {
+		required[r.(string)] = true
+	}
+	if !required["items"] || !required["count"] || required["next_cursor"] {
+		t.Errorf("required = %v, want fields without omitempty", schema["required"])
+	}
+
+	props := schema["properties"].(map[string]any)
+	items := props["items"].(map[string]any)
+	if got, _ := json.Marshal(items["type"]); string(got) != `["array","null"]` {
+		t.Errorf("items type = %s, want nullable array", got)
+	}
+	id := items["items"].(map[string]any)["properties"].(map[string]any)["id"].(map[string]any)
+	if id["type"] != "string" || id["format"] != "uuid" {
+		t.Errorf("uuid schema = %v, want string with uuid format", id)
+	}
+	if got, _ := json.Marshal(props["best"].(map[string]any)["type"]); string(got) != `["object","null"]` {
+		t.Errorf("pointer type = %s, want nullable object", got)
+	}
+	if props["count"].(map[string]any)["type"] != "integer" {
+		t.Errorf("count type = %v, want integer", props["count"])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := Of[listResult]()
+	tests := []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{"typed result", listResult{Items: []item{{ID: uuid.New(), Name: "a"}}, Count: 1}, ""},
+		{"nil slices and pointers", listResult{}, ""},
+		{"missing required", map[string]any{"count": 1}, `missing required property "items"`},
+		{"wrong type", map[string]any{"items": "a", "count": 1, "meta": nil, "best": nil, "score": 1, "at": "x"}, "$.items is string"},
+		{"fraction for integer", map[string]any{"items": nil, "count": 1.5, "meta": nil, "best": nil, "score": 1, "at": "x"}, "$.count is number"},
+		{"extra property", map[string]any{"items": nil, "count": 1, "meta": nil, "best": nil, "score": 1, "at": "x", "new": true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(schema, tt.value)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnyOf(t *testing.T) {
+	schema := AnyOf(Of[item](), Of[listResult]())
+	if err := Validate(schema, item{Name: "a"}); err != nil {
+		t.Errorf("first branch: %v", err)
+	}
+	if err := Validate(schema, listResult{}); err != nil {
+		t.Errorf("second branch: %v", err)
+	}
+	if err := Validate(schema, map[string]any{"count": 1}); err == nil {
+		t.Error("value matching neither branch passed")
+	}
+}
+
+func TestResult(t *testing.T) {
+	v := item{ID: uuid.New(), Name: "a"}
+	result := Result(v)
+	if result.IsError {
+		t.Fatalf("unexpected error result: %v", result.Content)
+	}
+	if result.StructuredContent != v {
+		t.Errorf("structured content = %v, want %v", result.StructuredContent, v)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	var decoded item
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil || decoded != v {
+		t.Errorf("text = %q, want JSON of %v", text, v)
+	}
+
+	if result := Result(map[string]any{"bad": func() {}}); !result.IsError {
+		t.Error("unencodable value did not return an error result")
+	}
+}
@@ -0,0 +1,108 @@
+// Package trigram scores string similarity by shared three-letter sequences,
+// following PostgreSQL's pg_trgm so in-process search ranks typos the same
+// way the database does.
+//
+// Text is lowercased and split into words on anything that is not a letter or
+// digit. Each word is padded with two spaces in front and one behind, so
+// "cat" yields "  c", " ca", "cat" and "at ".
+package trigram
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// SimilarityThreshold is pg_trgm's default similarity_threshold, the
+	// least Similarity at which two words count as alike
+	SimilarityThreshold = 0.3
+	// WordThreshold is pg_trgm's default word_similarity_threshold, the
+	// least WordSimilarity at which a word counts as found in a text
+	WordThreshold = 0.6
+)
+
+// Set is the distinct trigrams of a string
+type Set map[string]struct{}
+
+// Words splits s into lowercase words the way pg_trgm does
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Trigrams returns the trigrams of every word in s
+func Trigrams(s string) Set {
+	set := Set{}
+	for _, word := range Words(s) {
+		addWord(set, word)
+	}
+	return set
+}
+
+func addWord(set Set, word string) {
+	runes := []rune("  " + word + " ")
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = struct{}{}
+	}
+}
+
+// shared counts the trigrams a and b have in common
+func shared(a, b Set) int {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	n := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Similarity is pg_trgm's similarity(a, b): shared trigrams over all distinct
+// trigrams of either string, from 0 (nothing shared) to 1 (same trigrams)
+func Similarity(a, b string) float64 {
+	ta, tb := Trigrams(a), Trigrams(b)
+	n := shared(ta, tb)
+	if n == 0 {
+		return 0
+	}
+	return float64(n) / float64(len(ta)+len(tb)-n)
+}
+
+// WordSimilarity approximates pg_trgm's word_similarity(word, text): the
+// largest share of word's trigrams found in any one word of text. pg_trgm
+// also considers extents spanning words, which only matters for queries
+// containing more than one word.
+func WordSimilarity(word, text string) float64 {
+	tw := Trigrams(word)
+	if len(tw) == 0 {
+		return 0
+	}
+	best := 0
+	for _, w := range Words(text) {
+		set := Set{}
+		addWord(set, w)
+		best = max(best, shared(tw, set))
+	}
+	return float64(best) / float64(len(tw))
+}
+
+// Closest returns the word in vocabulary most similar to word, preferring
+// the alphabetically first on ties, and its similarity. It returns "" when
+// no word reaches SimilarityThreshold.
+func Closest(word string, vocabulary []string) (string, float64) {
+	best, bestSim := "", 0.0
+	for _, v := range vocabulary {
+		sim := Similarity(word, v)
+		if sim < SimilarityThreshold {
+			continue
+		}
+		if sim > bestSim || (sim == bestSim && v < best) {
+			best, bestSim = v, sim
+		}
+	}
+	return best, bestSim
+}
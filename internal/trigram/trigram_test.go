@@ -0,0 +1,84 @@
+package trigram
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrigrams(t *testing.T) {
+	got := Trigrams("Cat!")
+	for _, want := range []string{"  c", " ca", "cat", "at "} {
+		if _, ok := got[want]; !ok {
+			t.Errorf("Trigrams(%q) is missing %q: %v", "Cat!", want, got)
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("Trigrams(%q) = %v, want 4 trigrams", "Cat!", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"word", "word", 1},
+		// The pg_trgm documentation's example
+		{"word", "two words", 4.0 / 11},
+		{"reviw", "review", 4.0 / 9},
+		{"abc", "xyz", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	tests := []struct {
+		word, text string
+		want       float64
+	}{
+		// The pg_trgm documentation's example
+		{"word", "two words", 0.8},
+		{"reviw", "Reviews pull requests", 4.0 / 6},
+		{"secrity", "security auditor", 0.75},
+		{"terraform", "reviews code", 0},
+		{"", "anything", 0},
+	}
+	for _, tt := range tests {
+		if got := WordSimilarity(tt.word, tt.text); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("WordSimilarity(%q, %q) = %v, want %v", tt.word, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	vocabulary := []string{"kubernetes", "kustomize", "security", "review"}
+
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"kubernets", "kubernetes"},
+		{"secuirty", "security"},
+		{"reviw", "review"},
+		// Transpositions break too many trigrams to be caught
+		{"reveiw", ""},
+		{"terraform", ""},
+	}
+	for _, tt := range tests {
+		if got, _ := Closest(tt.word, vocabulary); got != tt.want {
+			t.Errorf("Closest(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkWordSimilarity(b *testing.B) {
+	text := "Reviews pull requests for correctness, security vulnerabilities and test coverage"
+	for i := 0; i < b.N; i++ {
+		WordSimilarity("vulnerabilty", text)
+	}
+}
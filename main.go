@@ -8,11 +8,20 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/aminghadersohi/agentmcp/internal/toolschema"
+	"github.com/aminghadersohi/agentmcp/internal/trigram"
 	"github.com/fsnotify/fsnotify"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -32,25 +41,126 @@ type Agent struct {
 	Prompt      string         `yaml:"prompt" json:"prompt"`
 }
 
+// Tags returns the string tags listed in the agent's metadata
+func (a *Agent) Tags() []string {
+	tags := []string{}
+	if list, ok := a.Metadata["tags"].([]any); ok {
+		for _, t := range list {
+			if tag, ok := t.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// AgentSummary is a lightweight version for listing and search results
+type AgentSummary struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	// Similarity is set on approximate search matches
+	Similarity float64 `json:"similarity,omitempty"`
+}
+
+// ToSummary converts an Agent to AgentSummary
+func (a *Agent) ToSummary() AgentSummary {
+	return AgentSummary{
+		Name:        a.Name,
+		Version:     a.Version,
+		Description: a.Description,
+		Tags:        a.Tags(),
+	}
+}
+
+// listAgentsResult is the output of list_agents
+type listAgentsResult struct {
+	Agents []AgentSummary `json:"agents"`
+	Count  int            `json:"count"`
+}
+
+// searchAgentsResult is the output of search_agents
+type searchAgentsResult struct {
+	Results    []AgentSummary `json:"results"`
+	Count      int            `json:"count"`
+	Query      string         `json:"query"`
+	DidYouMean string         `json:"did_you_mean,omitempty"`
+}
+
+// defaultWatchDebounce is how long the watcher waits for a burst of file
+// events to settle before reloading; editors often write, rename and chmod on
+// a single save
+const defaultWatchDebounce = 200 * time.Millisecond
+
 // AgentServer serves agent definitions via MCP
 type AgentServer struct {
 	agentsDir string
-	cache     map[string]*Agent
+	cache     atomic.Pointer[map[string]*Agent] // swapped whole by LoadAgents, never mutated
+	loadMu    sync.Mutex                        // serializes reloads
 	watcher   *fsnotify.Watcher
+	watched   map[string]bool // directories added to watcher; owned by the watch goroutine
+	debounce  time.Duration
 	apiKey    string
+	mcpServer *server.MCPServer // set by RegisterResources; nil until then
 }
 
 // NewAgentServer creates a new agent server
 func NewAgentServer(agentsDir string, apiKey string) *AgentServer {
-	return &AgentServer{
+	s := &AgentServer{
 		agentsDir: agentsDir,
-		cache:     make(map[string]*Agent),
+		debounce:  defaultWatchDebounce,
 		apiKey:    apiKey,
 	}
+	s.cache.Store(&map[string]*Agent{})
+	return s
+}
+
+// agents returns the loaded agents by name. The map is read-only; reloads
+// replace it rather than change it.
+func (s *AgentServer) agents() map[string]*Agent {
+	return *s.cache.Load()
+}
+
+// isHidden reports whether a file or directory name is hidden, which covers
+// editor lock files such as .#agent.yaml and VCS directories
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// isAgentFile reports whether path names an agent definition
+func isAgentFile(path string) bool {
+	ext := filepath.Ext(path)
+	return (ext == ".yaml" || ext == ".yml") && !isHidden(filepath.Base(path))
+}
+
+// agentFiles returns every agent file under dir, including nested folders
+func agentFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && isHidden(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isAgentFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
 }
 
-// LoadAgents scans directory and loads all agent YAML files
+// LoadAgents scans directory and loads all agent YAML files, replacing the
+// cache with the result
 func (s *AgentServer) LoadAgents() error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
 	log.Printf("[INFO] Loading agents from: %s", s.agentsDir)
 
 	// Create agents directory if it doesn't exist
@@ -58,24 +168,17 @@ func (s *AgentServer) LoadAgents() error {
 		return fmt.Errorf("failed to create agents directory: %w", err)
 	}
 
-	files, err := filepath.Glob(filepath.Join(s.agentsDir, "*.yaml"))
+	files, err := agentFiles(s.agentsDir)
 	if err != nil {
-		return fmt.Errorf("failed to glob agent files: %w", err)
+		return fmt.Errorf("failed to scan agent files: %w", err)
 	}
 
-	// Also check for .yml extension
-	ymlFiles, err := filepath.Glob(filepath.Join(s.agentsDir, "*.yml"))
-	if err != nil {
-		return fmt.Errorf("failed to glob yml files: %w", err)
-	}
-	files = append(files, ymlFiles...)
-
 	if len(files) == 0 {
 		log.Printf("[WARN] No agent files found in %s", s.agentsDir)
-		return nil
 	}
 
-	loadedCount := 0
+	cache := make(map[string]*Agent, len(files))
+	sources := make(map[string]string, len(files))
 	for _, file := range files {
 		data, err := os.ReadFile(file)
 		if err != nil {
@@ -95,16 +198,25 @@ func (s *AgentServer) LoadAgents() error {
 			continue
 		}
 
-		s.cache[agent.Name] = &agent
+		if other, dup := sources[agent.Name]; dup {
+			log.Printf("[WARN] Agent %s in %s overrides the one in %s", agent.Name, file, other)
+		}
+		cache[agent.Name] = &agent
+		sources[agent.Name] = file
 		log.Printf("[INFO] Loaded agent: %s v%s", agent.Name, agent.Version)
-		loadedCount++
 	}
 
-	log.Printf("[INFO] Successfully loaded %d agents", loadedCount)
+	// Swap in the new set so readers never see a half-built cache, then tell
+	// resource subscribers what changed
+	previous := *s.cache.Swap(&cache)
+	log.Printf("[INFO] Successfully loaded %d agents", len(cache))
+	s.syncResources(previous, cache)
 	return nil
 }
 
-// WatchAgents sets up file watcher for hot reload
+// WatchAgents sets up a recursive file watcher for hot reload. Creates,
+// writes, deletes and renames of agent files and folders trigger a full
+// reload once events have been quiet for the debounce interval.
 func (s *AgentServer) WatchAgents() error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -112,37 +224,109 @@ func (s *AgentServer) WatchAgents() error {
 	}
 
 	s.watcher = watcher
+	s.watched = make(map[string]bool)
 
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
-					if strings.HasSuffix(event.Name, ".yaml") || strings.HasSuffix(event.Name, ".yml") {
-						log.Printf("[INFO] Detected change in %s, reloading agents...", event.Name)
-						if err := s.LoadAgents(); err != nil {
-							log.Printf("[ERROR] Failed to reload agents: %v", err)
-						}
-					}
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
+	if err := s.watchTree(s.agentsDir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch directory: %w", err)
+	}
+
+	go s.watchLoop(watcher)
+
+	log.Printf("[INFO] File watcher enabled for %s", s.agentsDir)
+	return nil
+}
+
+// watchTree adds root and every non-hidden directory below it to the watcher
+func (s *AgentServer) watchTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && isHidden(d.Name()) {
+			return filepath.SkipDir
+		}
+		if err := s.watcher.Add(path); err != nil {
+			return err
+		}
+		s.watched[path] = true
+		return nil
+	})
+}
+
+// watchLoop turns watcher events into debounced reloads until the watcher is
+// closed
+func (s *AgentServer) watchLoop(watcher *fsnotify.Watcher) {
+	var reload *time.Timer
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				if reload != nil {
+					reload.Stop()
 				}
-				log.Printf("[ERROR] Watcher error: %v", err)
+				return
+			}
+			if !s.affectsAgents(event) {
+				continue
+			}
+			if reload == nil {
+				reload = time.AfterFunc(s.debounce, s.reload)
+			} else {
+				reload.Reset(s.debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("[ERROR] Watcher error: %v", err)
+		}
+	}
+}
+
+// affectsAgents reports whether event can change the loaded agents. New
+// directories are watched as a side effect.
+func (s *AgentServer) affectsAgents(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if isHidden(filepath.Base(event.Name)) {
+				return false
 			}
+			if err := s.watchTree(event.Name); err != nil {
+				log.Printf("[WARN] Failed to watch %s: %v", event.Name, err)
+			}
+			// Files may have landed before the watch was added
+			return true
 		}
-	}()
+	}
 
-	if err := watcher.Add(s.agentsDir); err != nil {
-		return fmt.Errorf("failed to watch directory: %w", err)
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		if s.watched[event.Name] {
+			// A removed or renamed folder takes its agents with it
+			prefix := event.Name + string(filepath.Separator)
+			for dir := range s.watched {
+				if dir == event.Name || strings.HasPrefix(dir, prefix) {
+					s.watcher.Remove(dir)
+					delete(s.watched, dir)
+				}
+			}
+			return true
+		}
 	}
 
-	log.Printf("[INFO] File watcher enabled for %s", s.agentsDir)
-	return nil
+	return isAgentFile(event.Name) &&
+		event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0
+}
+
+// reload runs once a burst of file events has settled
+func (s *AgentServer) reload() {
+	log.Printf("[INFO] Detected changes in %s, reloading agents...", s.agentsDir)
+	if err := s.LoadAgents(); err != nil {
+		log.Printf("[ERROR] Failed to reload agents: %v", err)
+	}
 }
 
 // Close cleans up resources
@@ -153,6 +337,97 @@ func (s *AgentServer) Close() error {
 	return nil
 }
 
+// MCP Resources
+
+const agentURIScheme = "agent://"
+
+// agentURI returns the resource URI for an agent, escaping characters such as
+// spaces that are not valid in a URI
+func agentURI(name string) string {
+	return agentURIScheme + url.PathEscape(name)
+}
+
+// agentResource describes an agent as an MCP resource
+func agentResource(agent *Agent) mcp.Resource {
+	return mcp.NewResource(agentURI(agent.Name), agent.Name,
+		mcp.WithResourceDescription(agent.Description),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// RegisterResources exposes agents as MCP resources (agent://{name}) and keeps
+// the resource list in sync with later reloads
+func (s *AgentServer) RegisterResources(mcpServer *server.MCPServer) {
+	s.mcpServer = mcpServer
+
+	mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(agentURIScheme+"{name}", "Agent definition",
+			mcp.WithTemplateDescription("Full agent definition (prompt, tools, metadata) by name"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readAgent,
+	)
+
+	s.syncResources(nil, s.agents())
+}
+
+// syncResources registers new or changed agents as resources and removes
+// deleted ones. mcp-go sends notifications/resources/list_changed for both, so
+// clients re-list and re-read what changed.
+func (s *AgentServer) syncResources(previous, current map[string]*Agent) {
+	if s.mcpServer == nil {
+		return
+	}
+
+	var upserts []server.ServerResource
+	for name, agent := range current {
+		if old, existed := previous[name]; existed && reflect.DeepEqual(old, agent) {
+			continue
+		}
+		upserts = append(upserts, server.ServerResource{Resource: agentResource(agent), Handler: s.readAgent})
+	}
+
+	var removed []string
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, agentURI(name))
+		}
+	}
+
+	if len(upserts) > 0 {
+		s.mcpServer.AddResources(upserts...)
+	}
+	if len(removed) > 0 {
+		s.mcpServer.DeleteResources(removed...)
+	}
+}
+
+// readAgent serves agent://{name} resources
+func (s *AgentServer) readAgent(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	name := strings.TrimPrefix(request.Params.URI, agentURIScheme)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+
+	agent, exists := s.agents()[name]
+	if !exists {
+		return nil, fmt.Errorf("agent not found: %s", name)
+	}
+
+	agentJSON, err := json.MarshalIndent(agent, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize agent: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(agentJSON),
+		},
+	}, nil
+}
+
 // MCP Tool Handlers
 
 // listAgents returns a list of all available agents
@@ -164,24 +439,17 @@ func (s *AgentServer) listAgents(ctx context.Context, request mcp.CallToolReques
 	}
 
 	if request.Params.Arguments != nil {
-		if err := json.Unmarshal(request.Params.Arguments, &args); err != nil {
+		if err := request.BindArguments(&args); err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
 		}
 	}
 
-	var agents []map[string]any
+	agents := []AgentSummary{}
 
-	for _, agent := range s.cache {
+	for _, agent := range s.agents() {
 		// Filter by tags if specified
 		if len(args.Tags) > 0 {
-			agentTags := []string{}
-			if tags, ok := agent.Metadata["tags"].([]any); ok {
-				for _, t := range tags {
-					if tag, ok := t.(string); ok {
-						agentTags = append(agentTags, tag)
-					}
-				}
-			}
+			agentTags := agent.Tags()
 
 			// Check if agent has all required tags
 			hasAllTags := true
@@ -204,20 +472,13 @@ func (s *AgentServer) listAgents(ctx context.Context, request mcp.CallToolReques
 			}
 		}
 
-		agents = append(agents, map[string]any{
-			"name":        agent.Name,
-			"version":     agent.Version,
-			"description": agent.Description,
-			"tags":        agent.Metadata["tags"],
-		})
-	}
-
-	result := map[string]any{
-		"agents": agents,
-		"count":  len(agents),
+		agents = append(agents, agent.ToSummary())
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("%v", result)), nil
+	return toolschema.Result(listAgentsResult{
+		Agents: agents,
+		Count:  len(agents),
+	}), nil
 }
 
 // getAgent returns the full definition of a specific agent
@@ -226,7 +487,7 @@ func (s *AgentServer) getAgent(ctx context.Context, request mcp.CallToolRequest)
 		Name string `json:"name"`
 	}
 
-	if err := json.Unmarshal(request.Params.Arguments, &args); err != nil {
+	if err := request.BindArguments(&args); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
@@ -236,18 +497,12 @@ func (s *AgentServer) getAgent(ctx context.Context, request mcp.CallToolRequest)
 		return mcp.NewToolResultError("name parameter is required"), nil
 	}
 
-	agent, exists := s.cache[args.Name]
+	agent, exists := s.agents()[args.Name]
 	if !exists {
 		return mcp.NewToolResultError(fmt.Sprintf("agent not found: %s", args.Name)), nil
 	}
 
-	// Convert agent to JSON string for better formatting
-	agentJSON, err := json.MarshalIndent(agent, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to serialize agent: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(agentJSON)), nil
+	return toolschema.Result(agent), nil
 }
 
 // searchAgents searches for agents by keyword
@@ -256,7 +511,7 @@ func (s *AgentServer) searchAgents(ctx context.Context, request mcp.CallToolRequ
 		Query string `json:"query"`
 	}
 
-	if err := json.Unmarshal(request.Params.Arguments, &args); err != nil {
+	if err := request.BindArguments(&args); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
@@ -267,9 +522,12 @@ func (s *AgentServer) searchAgents(ctx context.Context, request mcp.CallToolRequ
 	}
 
 	query := strings.ToLower(args.Query)
-	var results []map[string]any
+	queryWords := trigram.Words(query)
+	results := []AgentSummary{}
+	var fuzzy []AgentSummary
+	vocabulary := map[string]bool{}
 
-	for _, agent := range s.cache {
+	for _, agent := range s.agents() {
 		// Search in name, description, and tags
 		matches := false
 
@@ -282,34 +540,90 @@ func (s *AgentServer) searchAgents(ctx context.Context, request mcp.CallToolRequ
 		}
 
 		// Search in tags
-		if tags, ok := agent.Metadata["tags"].([]any); ok {
-			for _, t := range tags {
-				if tag, ok := t.(string); ok {
-					if strings.Contains(strings.ToLower(tag), query) {
-						matches = true
-						break
-					}
-				}
+		text := agent.Name + " " + agent.Description
+		for _, tag := range agent.Tags() {
+			text += " " + tag
+			if strings.Contains(strings.ToLower(tag), query) {
+				matches = true
 			}
 		}
+		for _, w := range trigram.Words(text) {
+			vocabulary[w] = true
+		}
 
+		entry := agent.ToSummary()
 		if matches {
-			results = append(results, map[string]any{
-				"name":        agent.Name,
-				"version":     agent.Version,
-				"description": agent.Description,
-				"tags":        agent.Metadata["tags"],
-			})
+			results = append(results, entry)
+			continue
+		}
+
+		// Tolerate typos: every query word must be close to some word
+		if sim := fuzzySimilarity(queryWords, text); sim > 0 {
+			entry.Similarity = sim
+			fuzzy = append(fuzzy, entry)
 		}
 	}
 
-	result := map[string]any{
-		"results": results,
-		"count":   len(results),
-		"query":   args.Query,
+	// Approximate matches follow exact ones, closest first
+	sort.SliceStable(fuzzy, func(i, j int) bool {
+		return fuzzy[i].Similarity > fuzzy[j].Similarity
+	})
+	exact := len(results)
+	results = append(results, fuzzy...)
+
+	result := searchAgentsResult{
+		Results: results,
+		Count:   len(results),
+		Query:   args.Query,
+	}
+	if exact == 0 {
+		result.DidYouMean = didYouMean(queryWords, vocabulary)
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("%v", result)), nil
+	return toolschema.Result(result), nil
+}
+
+// fuzzySimilarity is the mean trigram word similarity of words to text, or 0
+// if any word falls below the threshold
+func fuzzySimilarity(words []string, text string) float64 {
+	if len(words) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, w := range words {
+		sim := trigram.WordSimilarity(w, text)
+		if sim < trigram.WordThreshold {
+			return 0
+		}
+		total += sim
+	}
+	return total / float64(len(words))
+}
+
+// didYouMean respells words that are not in vocabulary with the closest word
+// that is, returning "" when nothing could be respelled
+func didYouMean(words []string, vocabulary map[string]bool) string {
+	known := make([]string, 0, len(vocabulary))
+	for w := range vocabulary {
+		known = append(known, w)
+	}
+
+	respelled := false
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = w
+		if vocabulary[w] {
+			continue
+		}
+		if closest, _ := trigram.Closest(w, known); closest != "" {
+			out[i] = closest
+			respelled = true
+		}
+	}
+	if !respelled {
+		return ""
+	}
+	return strings.Join(out, " ")
 }
 
 func main() {
@@ -340,59 +654,48 @@ func main() {
 		log.Fatalf("[FATAL] Failed to load agents: %v", err)
 	}
 
-	if len(agentServer.cache) == 0 {
+	if len(agentServer.agents()) == 0 {
 		log.Printf("[WARN] No agents loaded. Add .yaml files to %s", *agentsDir)
 	}
 
-	// Setup file watcher if enabled
-	if *watch {
-		if err := agentServer.WatchAgents(); err != nil {
-			log.Printf("[WARN] Failed to setup file watcher: %v", err)
-		}
-	}
-
 	// Create MCP server
 	mcpServer := server.NewMCPServer(
 		"agentmcp",
 		VERSION,
 		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
 	)
 
+	// Expose agents as resources before watching so reloads can notify clients
+	agentServer.RegisterResources(mcpServer)
+
+	// Setup file watcher if enabled
+	if *watch {
+		if err := agentServer.WatchAgents(); err != nil {
+			log.Printf("[WARN] Failed to setup file watcher: %v", err)
+		}
+	}
+
 	// Register tools
 	listAgentsTool := mcp.NewTool("list_agents",
 		mcp.WithDescription("List all available agent definitions. Optionally filter by tags."),
-		mcp.WithObject(
-			mcp.WithProperty("tags",
-				mcp.NewObjectProperty(
-					mcp.WithDescription("Filter by tags (optional)"),
-					mcp.WithItems("string"),
-				),
-			),
+		mcp.WithArray("tags",
+			mcp.Description("Filter by tags (optional)"),
+			mcp.WithStringItems(),
 		),
+		toolschema.Output[listAgentsResult](),
 	)
 
 	getAgentTool := mcp.NewTool("get_agent",
 		mcp.WithDescription("Get complete agent definition by name. Returns the full agent specification including prompt, tools, and metadata."),
-		mcp.WithObject(
-			mcp.WithProperty("name",
-				mcp.NewObjectProperty(
-					mcp.WithDescription("Agent name"),
-					mcp.WithRequired(),
-				),
-			),
-		),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Agent name")),
+		toolschema.Output[Agent](),
 	)
 
 	searchAgentsTool := mcp.NewTool("search_agents",
 		mcp.WithDescription("Search agents by keyword in name, description, or tags. Returns matching agents."),
-		mcp.WithObject(
-			mcp.WithProperty("query",
-				mcp.NewObjectProperty(
-					mcp.WithDescription("Search query"),
-					mcp.WithRequired(),
-				),
-			),
-		),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Search query")),
+		toolschema.Output[searchAgentsResult](),
 	)
 
 	mcpServer.AddTool(listAgentsTool, agentServer.listAgents)
@@ -408,7 +711,8 @@ func main() {
 		}
 	case "sse":
 		log.Printf("[INFO] Starting MCP server on HTTP port %s...", *port)
-		if err := server.ServeSSE(mcpServer, *port); err != nil {
+		sseServer := server.NewSSEServer(mcpServer, server.WithBaseURL("http://localhost:"+*port))
+		if err := sseServer.Start(":" + *port); err != nil {
 			log.Fatalf("[FATAL] Server error: %v", err)
 		}
 	default:
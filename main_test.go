@@ -8,11 +8,35 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aminghadersohi/agentmcp/internal/toolschema"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
+// decodeResult checks that a tool result's text is JSON matching its
+// structured content and output schema, and decodes it into out
+func decodeResult[T any](t *testing.T, result *mcp.CallToolResult, out *T) {
+	t.Helper()
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %v", result.Content)
+	}
+	if err := toolschema.Validate(toolschema.Of[T](), result.StructuredContent); err != nil {
+		t.Errorf("structured content does not match the output schema: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if err := json.Unmarshal([]byte(text), out); err != nil {
+		t.Fatalf("result is not JSON: %v\n%s", err, text)
+	}
+	structured, _ := json.Marshal(result.StructuredContent)
+	if compact, _ := json.Marshal(out); string(compact) != string(structured) {
+		t.Errorf("text %s does not match structured content %s", compact, structured)
+	}
+}
+
 // setupTestAgents creates temporary agent files for testing
 func setupTestAgents(t *testing.T) (string, func()) {
 	tmpDir, err := os.MkdirTemp("", "agentmcp-test-*")
@@ -78,11 +102,11 @@ func TestLoadAgents(t *testing.T) {
 		t.Fatalf("LoadAgents failed: %v", err)
 	}
 
-	if len(srv.cache) != 2 {
-		t.Errorf("Expected 2 agents, got %d", len(srv.cache))
+	if len(srv.agents()) != 2 {
+		t.Errorf("Expected 2 agents, got %d", len(srv.agents()))
 	}
 
-	agent1, exists := srv.cache["test-agent-1"]
+	agent1, exists := srv.agents()["test-agent-1"]
 	if !exists {
 		t.Error("test-agent-1 not found in cache")
 	}
@@ -111,7 +135,7 @@ func TestListAgents(t *testing.T) {
 
 	// Test without filters
 	req := mcp.CallToolRequest{
-		Params: mcp.CallToolRequestParams{
+		Params: mcp.CallToolParams{
 			Arguments: nil,
 		},
 	}
@@ -121,23 +145,29 @@ func TestListAgents(t *testing.T) {
 		t.Fatalf("listAgents failed: %v", err)
 	}
 
-	if len(result.Content) == 0 {
-		t.Error("Expected non-empty result")
+	var all listAgentsResult
+	decodeResult(t, result, &all)
+	if all.Count != 2 || len(all.Agents) != 2 {
+		t.Errorf("Expected 2 agents, got %+v", all)
 	}
 
 	// Test with tag filter
-	args, _ := json.Marshal(map[string]any{
+	req.Params.Arguments = map[string]any{
 		"tags": []string{"frontend"},
-	})
-	req.Params.Arguments = args
+	}
 
 	result, err = srv.listAgents(context.Background(), req)
 	if err != nil {
 		t.Fatalf("listAgents with tags failed: %v", err)
 	}
 
-	if len(result.Content) == 0 {
-		t.Error("Expected non-empty result with frontend tag")
+	var tagged listAgentsResult
+	decodeResult(t, result, &tagged)
+	if tagged.Count != 1 || tagged.Agents[0].Name != "test-agent-1" {
+		t.Errorf("Expected only test-agent-1 with frontend tag, got %+v", tagged)
+	}
+	if tags := tagged.Agents[0].Tags; len(tags) != 2 || tags[1] != "frontend" {
+		t.Errorf("Expected tags [test frontend], got %v", tags)
 	}
 }
 
@@ -151,11 +181,11 @@ func TestGetAgent(t *testing.T) {
 	}
 
 	// Test getting existing agent
-	args, _ := json.Marshal(map[string]any{
+	args := map[string]any{
 		"name": "test-agent-1",
-	})
+	}
 	req := mcp.CallToolRequest{
-		Params: mcp.CallToolRequestParams{
+		Params: mcp.CallToolParams{
 			Arguments: args,
 		},
 	}
@@ -165,14 +195,16 @@ func TestGetAgent(t *testing.T) {
 		t.Fatalf("getAgent failed: %v", err)
 	}
 
-	if result.IsError != nil && *result.IsError {
-		t.Error("Expected successful result, got error")
+	var agent Agent
+	decodeResult(t, result, &agent)
+	if agent.Name != "test-agent-1" || agent.Prompt == "" {
+		t.Errorf("Expected the full test-agent-1 definition, got %+v", agent)
 	}
 
 	// Test getting non-existent agent
-	args, _ = json.Marshal(map[string]any{
+	args = map[string]any{
 		"name": "non-existent-agent",
-	})
+	}
 	req.Params.Arguments = args
 
 	result, err = srv.getAgent(context.Background(), req)
@@ -180,12 +212,12 @@ func TestGetAgent(t *testing.T) {
 		t.Fatalf("getAgent failed: %v", err)
 	}
 
-	if result.IsError == nil || !*result.IsError {
+	if !result.IsError {
 		t.Error("Expected error for non-existent agent")
 	}
 
 	// Test with missing name parameter
-	args, _ = json.Marshal(map[string]any{})
+	args = map[string]any{}
 	req.Params.Arguments = args
 
 	result, err = srv.getAgent(context.Background(), req)
@@ -193,7 +225,7 @@ func TestGetAgent(t *testing.T) {
 		t.Fatalf("getAgent failed: %v", err)
 	}
 
-	if result.IsError == nil || !*result.IsError {
+	if !result.IsError {
 		t.Error("Expected error for missing name parameter")
 	}
 }
@@ -217,15 +249,16 @@ func TestSearchAgents(t *testing.T) {
 		{"Search by tag", "frontend", true},
 		{"Search no results", "nonexistent", false},
 		{"Search partial match", "test", true},
+		{"Search with typo", "backnd", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			args, _ := json.Marshal(map[string]any{
+			args := map[string]any{
 				"query": tt.query,
-			})
+			}
 			req := mcp.CallToolRequest{
-				Params: mcp.CallToolRequestParams{
+				Params: mcp.CallToolParams{
 					Arguments: args,
 				},
 			}
@@ -235,19 +268,31 @@ func TestSearchAgents(t *testing.T) {
 				t.Fatalf("searchAgents failed: %v", err)
 			}
 
-			hasResults := len(result.Content) > 0
+			var out searchAgentsResult
+			decodeResult(t, result, &out)
+			hasResults := out.Count > 0
 			if hasResults != tt.expectResults {
 				t.Errorf("Expected results=%v, got=%v for query '%s'", tt.expectResults, hasResults, tt.query)
 			}
 		})
 	}
 
+	// A misspelled query suggests the word it was probably meant to be
+	result, _ := srv.searchAgents(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]any{"query": "frontnd"}},
+	})
+	var typo searchAgentsResult
+	decodeResult(t, result, &typo)
+	if typo.DidYouMean != "frontend" {
+		t.Errorf("Expected did_you_mean frontend, got %+v", typo)
+	}
+
 	// Test with empty query
-	args, _ := json.Marshal(map[string]any{
+	args := map[string]any{
 		"query": "",
-	})
+	}
 	req := mcp.CallToolRequest{
-		Params: mcp.CallToolRequestParams{
+		Params: mcp.CallToolParams{
 			Arguments: args,
 		},
 	}
@@ -257,7 +302,7 @@ func TestSearchAgents(t *testing.T) {
 		t.Fatalf("searchAgents failed: %v", err)
 	}
 
-	if result.IsError == nil || !*result.IsError {
+	if !result.IsError {
 		t.Error("Expected error for empty query")
 	}
 }
@@ -281,8 +326,8 @@ func TestInvalidAgentYAML(t *testing.T) {
 		t.Fatalf("LoadAgents should handle invalid YAML gracefully: %v", err)
 	}
 
-	if len(srv.cache) != 0 {
-		t.Errorf("Expected 0 agents from invalid YAML, got %d", len(srv.cache))
+	if len(srv.agents()) != 0 {
+		t.Errorf("Expected 0 agents from invalid YAML, got %d", len(srv.agents()))
 	}
 }
 
@@ -309,8 +354,8 @@ prompt: This agent has no name.
 		t.Fatalf("LoadAgents failed: %v", err)
 	}
 
-	if len(srv.cache) != 0 {
-		t.Errorf("Expected 0 agents (agent without name should be skipped), got %d", len(srv.cache))
+	if len(srv.agents()) != 0 {
+		t.Errorf("Expected 0 agents (agent without name should be skipped), got %d", len(srv.agents()))
 	}
 }
 
@@ -326,7 +371,224 @@ func TestEmptyAgentsDirectory(t *testing.T) {
 		t.Fatalf("LoadAgents should handle empty directory: %v", err)
 	}
 
-	if len(srv.cache) != 0 {
-		t.Errorf("Expected 0 agents in empty directory, got %d", len(srv.cache))
+	if len(srv.agents()) != 0 {
+		t.Errorf("Expected 0 agents in empty directory, got %d", len(srv.agents()))
+	}
+}
+
+// testSession is a minimal MCP client session that records notifications
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()                                         {}
+func (s *testSession) Initialized() bool                                   { return true }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *testSession) SessionID() string                                   { return "test-session" }
+
+func TestAgentResources(t *testing.T) {
+	tmpDir, cleanup := setupTestAgents(t)
+	defer cleanup()
+
+	srv := NewAgentServer(tmpDir, "")
+	if err := srv.LoadAgents(); err != nil {
+		t.Fatalf("LoadAgents failed: %v", err)
+	}
+
+	mcpServer := server.NewMCPServer("test", VERSION, server.WithResourceCapabilities(false, true))
+	srv.RegisterResources(mcpServer)
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	if err := mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatalf("RegisterSession failed: %v", err)
+	}
+
+	// Reads are routed through the agent://{name} template
+	response := mcpServer.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"agent://test-agent-1"}}`))
+	data, _ := json.Marshal(response)
+	if !strings.Contains(string(data), "You are a test agent for testing purposes.") {
+		t.Errorf("resources/read did not return the agent definition: %s", data)
+	}
+
+	// Editing an agent on disk and reloading tells clients the list changed
+	updated := `---
+name: test-agent-1
+version: 1.0.1
+description: First test agent
+prompt: You are an updated test agent.
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "agent1.yaml"), []byte(updated), 0644); err != nil {
+		t.Fatalf("Failed to update agent1: %v", err)
+	}
+	if err := srv.LoadAgents(); err != nil {
+		t.Fatalf("LoadAgents failed: %v", err)
+	}
+
+	var methods []string
+	for len(session.notifications) > 0 {
+		methods = append(methods, (<-session.notifications).Method)
+	}
+	if len(methods) != 1 || methods[0] != mcp.MethodNotificationResourcesListChanged {
+		t.Errorf("notifications = %v, want one resources/list_changed", methods)
+	}
+}
+
+func TestAgentResourceURIEscaping(t *testing.T) {
+	tmpDir := t.TempDir()
+	agent := "name: code reviewer\nversion: 1.0.0\ndescription: Reviews code\nprompt: You review code.\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "reviewer.yaml"), []byte(agent), 0644); err != nil {
+		t.Fatalf("Failed to write agent: %v", err)
+	}
+	srv := NewAgentServer(tmpDir, "")
+	if err := srv.LoadAgents(); err != nil {
+		t.Fatalf("LoadAgents failed: %v", err)
+	}
+
+	if uri := agentURI("code reviewer"); uri != "agent://code%20reviewer" {
+		t.Errorf("agentURI = %q, want the space escaped", uri)
+	}
+
+	mcpServer := server.NewMCPServer("test", VERSION, server.WithResourceCapabilities(false, true))
+	srv.RegisterResources(mcpServer)
+	response := mcpServer.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"agent://code%20reviewer"}}`))
+	data, _ := json.Marshal(response)
+	if !strings.Contains(string(data), "You review code.") {
+		t.Errorf("resources/read of an escaped URI failed: %s", data)
+	}
+}
+
+func TestLoadAgentsReplacesCache(t *testing.T) {
+	tmpDir, cleanup := setupTestAgents(t)
+	defer cleanup()
+
+	nested := filepath.Join(tmpDir, "team-a")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(nested, "agent3.yml"), []byte("name: test-agent-3\n"), 0644); err != nil {
+		t.Fatalf("Failed to write agent3: %v", err)
+	}
+	// Hidden folders and editor lock files are not agents
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create hidden dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".git", "hidden.yaml"), []byte("name: hidden\n"), 0644); err != nil {
+		t.Fatalf("Failed to write hidden agent: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".#agent1.yaml"), []byte("name: lock\n"), 0644); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+
+	srv := NewAgentServer(tmpDir, "")
+	if err := srv.LoadAgents(); err != nil {
+		t.Fatalf("LoadAgents failed: %v", err)
+	}
+	before := srv.agents()
+	if len(before) != 3 || before["test-agent-3"] == nil {
+		t.Fatalf("Expected 3 agents including the nested one, got %v", before)
+	}
+
+	if err := os.Remove(filepath.Join(tmpDir, "agent2.yaml")); err != nil {
+		t.Fatalf("Failed to remove agent2: %v", err)
+	}
+	if err := srv.LoadAgents(); err != nil {
+		t.Fatalf("LoadAgents failed: %v", err)
+	}
+
+	if _, exists := srv.agents()["test-agent-2"]; exists {
+		t.Error("Deleted agent is still in cache")
+	}
+	if len(before) != 3 {
+		t.Errorf("Reload mutated the previous cache: %d agents, want 3", len(before))
+	}
+
+	if err := os.RemoveAll(nested); err != nil {
+		t.Fatalf("Failed to remove nested dir: %v", err)
+	}
+	if err := os.Remove(filepath.Join(tmpDir, "agent1.yaml")); err != nil {
+		t.Fatalf("Failed to remove agent1: %v", err)
+	}
+	if err := srv.LoadAgents(); err != nil {
+		t.Fatalf("LoadAgents failed: %v", err)
+	}
+	if len(srv.agents()) != 0 {
+		t.Errorf("Expected 0 agents after removing every file, got %d", len(srv.agents()))
+	}
+}
+
+func TestWatchAgents(t *testing.T) {
+	tmpDir, cleanup := setupTestAgents(t)
+	defer cleanup()
+
+	srv := NewAgentServer(tmpDir, "")
+	srv.debounce = 20 * time.Millisecond
+	if err := srv.LoadAgents(); err != nil {
+		t.Fatalf("LoadAgents failed: %v", err)
+	}
+	if err := srv.WatchAgents(); err != nil {
+		t.Fatalf("WatchAgents failed: %v", err)
+	}
+	defer srv.Close()
+
+	// waitFor polls until the cache holds exactly want
+	waitFor := func(step string, want ...string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			agents := srv.agents()
+			match := len(agents) == len(want)
+			for _, name := range want {
+				if _, ok := agents[name]; !ok {
+					match = false
+				}
+			}
+			if match {
+				return
+			}
+			if time.Now().After(deadline) {
+				names := make([]string, 0, len(agents))
+				for name := range agents {
+					names = append(names, name)
+				}
+				t.Fatalf("%s: agents = %v, want %v", step, names, want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	write := func(path, name string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("name: "+name+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	// A folder created after the watch started, with a file written into it
+	teamA := filepath.Join(tmpDir, "team-a")
+	if err := os.Mkdir(teamA, 0755); err != nil {
+		t.Fatalf("Failed to create team-a: %v", err)
+	}
+	write(filepath.Join(teamA, "agent3.yaml"), "test-agent-3")
+	waitFor("create in new folder", "test-agent-1", "test-agent-2", "test-agent-3")
+
+	// Later writes in that folder are seen too
+	write(filepath.Join(teamA, "agent4.yaml"), "test-agent-4")
+	waitFor("create in watched folder", "test-agent-1", "test-agent-2", "test-agent-3", "test-agent-4")
+
+	if err := os.Remove(filepath.Join(tmpDir, "agent2.yaml")); err != nil {
+		t.Fatalf("Failed to remove agent2: %v", err)
+	}
+	waitFor("delete", "test-agent-1", "test-agent-3", "test-agent-4")
+
+	if err := os.Rename(filepath.Join(tmpDir, "agent1.yaml"), filepath.Join(tmpDir, "agent1.yaml.bak")); err != nil {
+		t.Fatalf("Failed to rename agent1: %v", err)
+	}
+	waitFor("rename away", "test-agent-3", "test-agent-4")
+
+	if err := os.RemoveAll(teamA); err != nil {
+		t.Fatalf("Failed to remove team-a: %v", err)
 	}
+	waitFor("remove folder")
 }
@@ -5,24 +5,48 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+	"unicode"
 
+	"github.com/aminghadersohi/agentmcp/internal/aliases"
+	"github.com/aminghadersohi/agentmcp/internal/auth"
+	"github.com/aminghadersohi/agentmcp/internal/config"
 	"github.com/aminghadersohi/agentmcp/internal/database"
 	"github.com/aminghadersohi/agentmcp/internal/embeddings"
 	"github.com/aminghadersohi/agentmcp/internal/generator"
 	"github.com/aminghadersohi/agentmcp/internal/governance"
 	"github.com/aminghadersohi/agentmcp/internal/models"
 	"github.com/aminghadersohi/agentmcp/internal/migrations"
+	"github.com/aminghadersohi/agentmcp/internal/projectsync"
+	"github.com/aminghadersohi/agentmcp/internal/render"
+	"github.com/aminghadersohi/agentmcp/internal/semver"
+	"github.com/aminghadersohi/agentmcp/internal/toolschema"
 	sqlmigrations "github.com/aminghadersohi/agentmcp/migrations"
+	"github.com/google/uuid"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgvector/pgvector-go"
 )
 
 const VERSION = "2.0.0"
@@ -37,19 +61,64 @@ const (
 
 // ServerV2 is the enhanced agent server with full ecosystem support
 type ServerV2 struct {
-	db         *database.DB
+	db         database.Store
 	embedder   embeddings.Engine
 	generator  *generator.Generator
 	governance *governance.Engine
+	mcpServer  *server.MCPServer // set by registerResources; nil in tests
+	reindexing atomic.Bool       // set while runReindex is working
+	aliases    *aliases.Dictionary
+	aliasFile  string          // optional YAML layer read by reloadAliases
+	principals *auth.Directory // maps HTTP tokens to callers; nil serves everyone as anonymous
+	stdioAs    auth.Principal  // caller identity of stdio sessions
 }
 
 // NewServerV2 creates a new v2 server
-func NewServerV2(db *database.DB, embedder embeddings.Engine, gen *generator.Generator, gov *governance.Engine) *ServerV2 {
+func NewServerV2(db database.Store, embedder embeddings.Engine, gen *generator.Generator, gov *governance.Engine) *ServerV2 {
 	return &ServerV2{
 		db:         db,
 		embedder:   embedder,
 		generator:  gen,
 		governance: gov,
+		aliases:    aliases.New(),
+		stdioAs:    auth.Anonymous,
+	}
+}
+
+// errNoEmbedder is reported by tools that need semantic search when no engine is configured
+const errNoEmbedder = "semantic search is unavailable: no embedding engine configured"
+
+// Hybrid search defaults
+const (
+	defaultSearchLimit   = 20
+	defaultMinSimilarity = 0.15 // semantic scores can be low, so keep recall high
+	useMinSimilarity     = 0.25 // use_* tools adopt the top hit, so want closer matches
+)
+
+// searchQuery builds a hybrid search for text, embedding it when an engine is
+// configured. On an embedding error the query is still usable for keywords.
+func (s *ServerV2) searchQuery(ctx context.Context, text string, limit int, minSimilarity float64) (models.SearchQuery, error) {
+	q := models.SearchQuery{Text: text, Limit: limit, MinSimilarity: minSimilarity}
+	if s.embedder == nil {
+		return q, nil
+	}
+	emb, err := s.embedder.Embed(ctx, text)
+	if err != nil {
+		return q, fmt.Errorf("embedding failed: %w", err)
+	}
+	q.Embedding = &emb
+	return q, nil
+}
+
+// matchMethod describes which signals found a hybrid search result
+func matchMethod(sc models.SearchScores) string {
+	switch {
+	case sc.TextRank > 0 && sc.VectorRank > 0:
+		return fmt.Sprintf("hybrid (keyword #%d, semantic #%d at %.0f%% match)", sc.TextRank, sc.VectorRank, sc.Similarity*100)
+	case sc.VectorRank > 0:
+		return fmt.Sprintf("semantic (%.0f%% match)", sc.Similarity*100)
+	default:
+		return fmt.Sprintf("keyword (#%d)", sc.TextRank)
 	}
 }
 
@@ -89,33 +158,245 @@ func getArgBool(req mcp.CallToolRequest, key string) bool {
 	return false
 }
 
-// ============ Original Tools (backward compatible) ============
+// getArgStringMap extracts a string map argument, given either as an object or as a JSON string
+func getArgStringMap(req mcp.CallToolRequest, key string) (map[string]string, error) {
+	args, ok := req.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	values := make(map[string]string)
+	switch v := args[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		if err := json.Unmarshal([]byte(v), &values); err != nil {
+			return nil, fmt.Errorf("%s must be a JSON object of strings: %w", key, err)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			values[k] = fmt.Sprint(item)
+		}
+	default:
+		return nil, fmt.Errorf("%s must be an object", key)
+	}
+	return values, nil
+}
 
-func (s *ServerV2) listAgents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	tagsStr := getArgString(req, "tags")
+// hasArg reports whether the caller supplied key at all (even as an empty value)
+func hasArg(req mcp.CallToolRequest, key string) bool {
+	args, ok := req.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = args[key]
+	return ok
+}
 
-	// Parse comma-separated tags
-	var tags []string
-	if tagsStr != "" {
-		for _, t := range strings.Split(tagsStr, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" {
-				tags = append(tags, t)
+// parseList splits a comma-separated argument into trimmed, non-empty items
+func parseList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ============ Paging and Filters ============
+
+// parseTimeArg reads an RFC 3339 timestamp or a YYYY-MM-DD date argument
+func parseTimeArg(req mcp.CallToolRequest, key string) (*time.Time, error) {
+	v := getArgString(req, key)
+	if v == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or YYYY-MM-DD date", key)
+}
+
+// listFilter reads the filter arguments shared by list and search tools.
+// Tools only declare the filters that apply to them.
+func listFilter(req mcp.CallToolRequest) (models.ListFilter, error) {
+	f := models.ListFilter{
+		Tags:     parseList(getArgString(req, "tags")),
+		Category: getArgString(req, "category"),
+		Model:    getArgString(req, "model"),
+		Status:   getArgString(req, "status"),
+	}
+	if hasArg(req, "is_generated") {
+		generated := getArgBool(req, "is_generated")
+		f.IsGenerated = &generated
+	}
+	var err error
+	if f.CreatedAfter, err = parseTimeArg(req, "created_after"); err != nil {
+		return f, err
+	}
+	if f.CreatedBefore, err = parseTimeArg(req, "created_before"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// listOptions reads the paging, sorting and filter arguments of a list tool
+func listOptions(req mcp.CallToolRequest) (models.ListOptions, error) {
+	f, err := listFilter(req)
+	if err != nil {
+		return models.ListOptions{}, err
+	}
+	return models.ListOptions{
+		Cursor: getArgString(req, "cursor"),
+		Limit:  int(getArgFloat(req, "limit")),
+		SortBy: getArgString(req, "sort_by"),
+		Order:  getArgString(req, "order"),
+		Filter: f,
+	}, nil
+}
+
+// pageArgs declares the paging and sorting arguments of a list tool
+func pageArgs(sorts []string, defaultSort string) []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("cursor", mcp.Description("next_cursor from the previous page; omit for the first page")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Page size (default %d, max %d)", database.DefaultPageSize, database.MaxPageSize))),
+		mcp.WithString("sort_by", mcp.Enum(sorts...), mcp.Description(fmt.Sprintf("Field to sort by (default %s)", defaultSort))),
+		mcp.WithString("order", mcp.Enum(models.SortAsc, models.SortDesc), mcp.Description("Sort direction (default desc, or asc for name)")),
+	}
+}
+
+// catalogFilterArgs declares the filters for agents, skills or commands
+func catalogFilterArgs(kind models.EmbeddingKind) []mcp.ToolOption {
+	opts := []mcp.ToolOption{
+		mcp.WithString("tags", mcp.Description("Comma-separated list of tags to filter by")),
+		mcp.WithString("status", mcp.Description("Only this status, or 'all' (default active)")),
+		mcp.WithString("created_after", mcp.Description("Only items created at or after this RFC 3339 time or YYYY-MM-DD date")),
+		mcp.WithString("created_before", mcp.Description("Only items created before this RFC 3339 time or YYYY-MM-DD date")),
+	}
+	if kind == models.EmbeddingKindAgent {
+		return append(opts,
+			mcp.WithString("model", mcp.Description("Only agents for this model (e.g. sonnet, opus, haiku)")),
+			mcp.WithBoolean("is_generated", mcp.Description("Only generated (true) or hand-written (false) agents")),
+		)
+	}
+	return opts
+}
+
+// searchRequest reads a search tool's query, limit, filters and cursor into a
+// hybrid search over kind. A non-nil result reports invalid arguments.
+func (s *ServerV2) searchRequest(ctx context.Context, req mcp.CallToolRequest, kind string) (models.SearchQuery, *mcp.CallToolResult) {
+	query := getArgString(req, "query")
+	if query == "" {
+		return models.SearchQuery{}, mcp.NewToolResultError("query is required")
+	}
+	if len(query) > maxQueryLength {
+		return models.SearchQuery{}, mcp.NewToolResultError(fmt.Sprintf("query too long (max %d characters)", maxQueryLength))
+	}
+	limit := int(getArgFloat(req, "limit"))
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	filter, err := listFilter(req)
+	if err != nil {
+		return models.SearchQuery{}, mcp.NewToolResultError(err.Error())
+	}
+
+	q, err := s.searchQuery(ctx, query, limit, defaultMinSimilarity)
+	if err != nil {
+		log.Printf("[WARN] %v (searching %s by keyword only)", err, kind)
+	}
+	q.Filter = filter
+	if q.Offset, err = database.SearchOffset(kind, q, getArgString(req, "cursor")); err != nil {
+		return models.SearchQuery{}, mcp.NewToolResultError(err.Error())
+	}
+	return q, nil
+}
+
+// searchResult is the output of the search_* tools
+type searchResult[T any] struct {
+	Results     []T                 `json:"results"`
+	Count       int                 `json:"count"`
+	Query       string              `json:"query"`
+	DidYouMean  string              `json:"did_you_mean,omitempty"`
+	Suggestions []models.Suggestion `json:"suggestions,omitempty"`
+	NextCursor  string              `json:"next_cursor,omitempty"`
+}
+
+// newSearchResult wraps one page of a search over kind
+func newSearchResult[T any](kind string, q models.SearchQuery, results []T) searchResult[T] {
+	return searchResult[T]{
+		Results:    results,
+		Count:      len(results),
+		Query:      q.Text,
+		NextCursor: database.NextSearchCursor(kind, q, len(results)),
+	}
+}
+
+// suggest returns "did you mean" hints for a search's first page when no
+// result matched a query term exactly, rewriting the query with the closest
+// words in the catalog
+func (s *ServerV2) suggest(ctx context.Context, kind models.EmbeddingKind, q models.SearchQuery, exact bool) (string, []models.Suggestion) {
+	if exact || q.Offset > 0 {
+		return "", nil
+	}
+	suggestions, err := s.db.SuggestTerms(ctx, kind, q.Text, q.Filter)
+	if err != nil {
+		log.Printf("[WARN] Failed to suggest %s search terms: %v", kind, err)
+		return "", nil
+	}
+	if len(suggestions) == 0 {
+		return "", nil
+	}
+
+	words := strings.Fields(q.Text)
+	for i, w := range words {
+		core := strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		for _, sg := range suggestions {
+			if core != "" && strings.EqualFold(core, sg.Term) {
+				words[i] = strings.Replace(w, core, sg.Suggestion, 1)
+				break
 			}
 		}
 	}
+	return strings.Join(words, " "), suggestions
+}
+
+// hasKeywordMatch reports whether any search result matched a term exactly
+func hasKeywordMatch[T any](results []T, scores func(T) models.SearchScores) bool {
+	return slices.ContainsFunc(results, func(r T) bool { return scores(r).TextRank > 0 })
+}
+
+// ============ Original Tools (backward compatible) ============
+
+// listAgentsResult is the output of list_agents
+type listAgentsResult struct {
+	Agents     []models.AgentSummary `json:"agents"`
+	Count      int                   `json:"count"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+func (s *ServerV2) listAgents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	opts, err := listOptions(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	agents, err := s.db.ListAgents(ctx, tags)
+	page, err := s.db.ListAgentsPage(ctx, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list agents: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"agents": agents,
-		"count":  len(agents),
-	}, "", "  ")
-
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(listAgentsResult{
+		Agents:     page.Items,
+		Count:      len(page.Items),
+		NextCursor: page.NextCursor,
+	}), nil
 }
 
 func (s *ServerV2) getAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -132,60 +413,43 @@ func (s *ServerV2) getAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultError(fmt.Sprintf("agent not found: %s", name)), nil
 	}
 
+	// A historical version is returned as its revision snapshot
+	if version := getArgString(req, "version"); version != "" && version != agent.Version {
+		return s.revisionResult(ctx, "agent", agent.ID, name, version)
+	}
+
 	// Increment usage
 	s.db.IncrementUsage(ctx, agent.ID)
 
-	result, _ := json.MarshalIndent(agent, "", "  ")
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(agent), nil
 }
 
+// searchAgents searches agents with hybrid keyword and vector ranking
 func (s *ServerV2) searchAgents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	query := getArgString(req, "query")
-	if query == "" {
-		return mcp.NewToolResultError("query is required"), nil
-	}
-	if len(query) > maxQueryLength {
-		return mcp.NewToolResultError(fmt.Sprintf("query too long (max %d characters)", maxQueryLength)), nil
+	q, errResult := s.searchRequest(ctx, req, "agents")
+	if errResult != nil {
+		return errResult, nil
 	}
-
-	// Try full query first
-	agents, err := s.db.SearchAgents(ctx, query)
+	agents, err := s.db.HybridSearchAgents(ctx, q)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
 
-	// If no results, try individual words
-	if len(agents) == 0 {
-		words := strings.Fields(query)
-		agentMap := make(map[string]models.AgentSummary)
-		for _, word := range words {
-			if len(word) < 3 {
-				continue
-			}
-			wordAgents, err := s.db.SearchAgents(ctx, word)
-			if err == nil {
-				for _, a := range wordAgents {
-					agentMap[a.ID.String()] = a
-				}
-			}
-		}
-		for _, a := range agentMap {
-			agents = append(agents, a)
-		}
-	}
-
-	result, _ := json.MarshalIndent(map[string]any{
-		"results": agents,
-		"count":   len(agents),
-		"query":   query,
-	}, "", "  ")
-
-	return mcp.NewToolResultText(string(result)), nil
+	out := newSearchResult("agents", q, agents)
+	out.DidYouMean, out.Suggestions = s.suggest(ctx, models.EmbeddingKindAgent, q, hasKeywordMatch(agents, func(r models.AgentSearchResult) models.SearchScores { return r.Scores }))
+	return toolschema.Result(out), nil
 }
 
 // ============ New v2 Tools ============
 
-// findSimilarAgents finds semantically similar agents
+// similarAgentsResult is the output of find_similar_agents
+type similarAgentsResult struct {
+	SimilarAgents []models.AgentSearchResult `json:"similar_agents"`
+	Count         int                        `json:"count"`
+}
+
+// findSimilarAgents finds agents similar to a description, ranking semantic
+// matches together with keyword matches
 func (s *ServerV2) findSimilarAgents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	description := getArgString(req, "description")
 	skillsStr := getArgString(req, "skills")
@@ -212,7 +476,7 @@ func (s *ServerV2) findSimilarAgents(ctx context.Context, req mcp.CallToolReques
 		limit = 5
 	}
 	if threshold <= 0 {
-		threshold = 0.15 // Lower default for better recall (semantic scores can be low)
+		threshold = defaultMinSimilarity
 	}
 
 	// Create search text
@@ -221,24 +485,31 @@ func (s *ServerV2) findSimilarAgents(ctx context.Context, req mcp.CallToolReques
 		searchText += " Skills: " + strings.Join(skills, ", ")
 	}
 
-	// Generate embedding
-	embedding, err := s.embedder.Embed(ctx, searchText)
+	if s.embedder == nil {
+		return mcp.NewToolResultError(errNoEmbedder), nil
+	}
+	q, err := s.searchQuery(ctx, searchText, limit, threshold)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("embedding failed: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Find similar
-	similar, err := s.db.FindSimilarAgents(ctx, embedding, limit, threshold)
+	similar, err := s.db.HybridSearchAgents(ctx, q)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"similar_agents": similar,
-		"count":          len(similar),
-	}, "", "  ")
+	return toolschema.Result(similarAgentsResult{
+		SimilarAgents: similar,
+		Count:         len(similar),
+	}), nil
+}
 
-	return mcp.NewToolResultText(string(result)), nil
+// agentRequestResult is the output of request_agent_by_skills. Source says
+// how the agent was found: cache, skill_match, similar or generated.
+type agentRequestResult struct {
+	Agent      *models.Agent `json:"agent"`
+	Source     string        `json:"source"`
+	Similarity float64       `json:"similarity,omitempty"`
 }
 
 // requestAgentBySkills finds or creates an agent with specific skills
@@ -262,11 +533,7 @@ func (s *ServerV2) requestAgentBySkills(ctx context.Context, req mcp.CallToolReq
 	// Check cache first
 	cached, err := s.db.GetCachedAgentBySkills(ctx, skills)
 	if err == nil && cached != nil {
-		result, _ := json.MarshalIndent(map[string]any{
-			"agent":  cached,
-			"source": "cache",
-		}, "", "  ")
-		return mcp.NewToolResultText(string(result)), nil
+		return toolschema.Result(agentRequestResult{Agent: cached, Source: "cache"}), nil
 	}
 
 	// Search for similar agents - also try keyword search first
@@ -277,31 +544,27 @@ func (s *ServerV2) requestAgentBySkills(ctx context.Context, req mcp.CallToolReq
 			agent, _ := s.db.GetAgentByID(ctx, agents[0].ID)
 			if agent != nil {
 				s.db.CacheSkillRequest(ctx, skills, agent.ID)
-				result, _ := json.MarshalIndent(map[string]any{
-					"agent":  agent,
-					"source": "skill_match",
-				}, "", "  ")
-				return mcp.NewToolResultText(string(result)), nil
+				return toolschema.Result(agentRequestResult{Agent: agent, Source: "skill_match"}), nil
 			}
 		}
 	}
 
 	// Fallback to semantic search
 	searchText := "Agent with skills: " + strings.Join(skills, ", ")
-	embedding, err := s.embedder.Embed(ctx, searchText)
-	if err == nil {
-		similar, _ := s.db.FindSimilarAgents(ctx, embedding, 1, 0.3) // Lower threshold for better matching
-		if len(similar) > 0 {
-			// Use existing similar agent
-			agent, _ := s.db.GetAgentByID(ctx, similar[0].Agent.ID)
-			if agent != nil {
-				s.db.CacheSkillRequest(ctx, skills, agent.ID)
-				result, _ := json.MarshalIndent(map[string]any{
-					"agent":      agent,
-					"source":     "similar",
-					"similarity": similar[0].Similarity,
-				}, "", "  ")
-				return mcp.NewToolResultText(string(result)), nil
+	if s.embedder != nil {
+		if embedding, err := s.embedder.Embed(ctx, searchText); err == nil {
+			similar, _ := s.db.FindSimilarAgents(ctx, embedding, 1, 0.3) // Lower threshold for better matching
+			if len(similar) > 0 {
+				// Use existing similar agent
+				agent, _ := s.db.GetAgentByID(ctx, similar[0].Agent.ID)
+				if agent != nil {
+					s.db.CacheSkillRequest(ctx, skills, agent.ID)
+					return toolschema.Result(agentRequestResult{
+						Agent:      agent,
+						Source:     "similar",
+						Similarity: similar[0].Similarity,
+					}), nil
+				}
 			}
 		}
 	}
@@ -322,26 +585,26 @@ func (s *ServerV2) requestAgentBySkills(ctx context.Context, req mcp.CallToolReq
 
 	// Generate embedding for new agent
 	if s.embedder != nil {
-		emb, _ := embeddings.CreateAgentEmbedding(
-			s.embedder, ctx, newAgent.Name, newAgent.Description, newAgent.Skills,
-		)
-		newAgent.Embedding = &emb
+		if emb, model, err := s.embedSource(ctx, agentSource(newAgent)); err == nil {
+			newAgent.Embedding, newAgent.EmbeddingModel = emb, model
+		}
 	}
 
 	// Save to database
 	if err := s.db.CreateAgent(ctx, newAgent); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to save agent: %v", err)), nil
 	}
+	s.publishResource(agentResource(newAgent), s.readAgentResource)
 
 	// Cache the skill request
 	s.db.CacheSkillRequest(ctx, skills, newAgent.ID)
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"agent":  newAgent,
-		"source": "generated",
-	}, "", "  ")
+	return toolschema.Result(agentRequestResult{Agent: newAgent, Source: "generated"}), nil
+}
 
-	return mcp.NewToolResultText(string(result)), nil
+// statusResult is the output of tools that only report success
+type statusResult struct {
+	Status string `json:"status"`
 }
 
 // submitFeedback records feedback for an agent
@@ -376,7 +639,7 @@ func (s *ServerV2) submitFeedback(ctx context.Context, req mcp.CallToolRequest)
 		return mcp.NewToolResultError(fmt.Sprintf("failed to submit feedback: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(`{"status": "feedback recorded"}`), nil
+	return toolschema.Result(statusResult{Status: "feedback recorded"}), nil
 }
 
 // getAgentReputation returns reputation details
@@ -396,8 +659,14 @@ func (s *ServerV2) getAgentReputation(ctx context.Context, req mcp.CallToolReque
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get reputation: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(rep, "", "  ")
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(rep), nil
+}
+
+// topAgentsResult is the output of get_top_agents
+type topAgentsResult struct {
+	TopAgents []models.AgentSummary `json:"top_agents"`
+	Count     int                   `json:"count"`
+	Category  string                `json:"category"`
 }
 
 // getTopAgents returns highest-rated agents
@@ -414,17 +683,66 @@ func (s *ServerV2) getTopAgents(ctx context.Context, req mcp.CallToolRequest) (*
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get top agents: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"top_agents": agents,
-		"count":      len(agents),
-		"category":   category,
-	}, "", "  ")
+	return toolschema.Result(topAgentsResult{
+		TopAgents: agents,
+		Count:     len(agents),
+		Category:  category,
+	}), nil
+}
+
+// embeddingStatsResult is the output of embedding_stats. Index counts the
+// rows of each kind embedded with the current model.
+type embeddingStatsResult struct {
+	Enabled    bool                                            `json:"enabled"`
+	Dimension  int                                             `json:"dimension,omitempty"`
+	Model      string                                          `json:"model,omitempty"`
+	Reindexing bool                                            `json:"reindexing"`
+	Index      map[models.EmbeddingKind]models.EmbeddingCounts `json:"index,omitempty"`
+	Cache      *embeddings.CacheStats                          `json:"cache,omitempty"`
+	HitRate    float64                                         `json:"hit_rate,omitempty"`
+}
+
+// embeddingStats reports whether semantic search is available and how well
+// the embedding cache is doing
+func (s *ServerV2) embeddingStats(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	stats := embeddingStatsResult{
+		Enabled: s.embedder != nil,
+	}
+	if s.embedder != nil {
+		stats.Dimension = s.embedder.Dimension()
+		stats.Model = s.embedder.Model()
+		stats.Reindexing = s.reindexing.Load()
+
+		// How many rows of each kind are embedded with the current model
+		index := map[models.EmbeddingKind]models.EmbeddingCounts{}
+		for _, kind := range models.EmbeddingKinds {
+			counts, err := s.db.CountEmbeddings(ctx, kind, s.embedder.Model())
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to count %s embeddings: %v", kind, err)), nil
+			}
+			index[kind] = counts
+		}
+		stats.Index = index
+	}
+	if cached, ok := s.embedder.(*embeddings.CachedEngine); ok {
+		cache := cached.Stats()
+		stats.Cache = &cache
+		if lookups := cache.Hits + cache.StoreHits + cache.Misses; lookups > 0 {
+			stats.HitRate = float64(cache.Hits+cache.StoreHits) / float64(lookups)
+		}
+	}
 
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(stats), nil
 }
 
 // ============ Governance Tools ============
 
+// reportAgentResult is the output of report_agent
+type reportAgentResult struct {
+	Status   string    `json:"status"`
+	ReportID uuid.UUID `json:"report_id"`
+}
+
 // reportAgent creates a report against an agent
 func (s *ServerV2) reportAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	agentName := getArgString(req, "agent_name")
@@ -472,35 +790,180 @@ func (s *ServerV2) reportAgent(ctx context.Context, req mcp.CallToolRequest) (*m
 		Description: description,
 	}
 
-	report, err := s.governance.CreateReport(ctx, args, "anonymous")
+	report, err := s.governance.CreateReport(ctx, args, auth.FromContext(ctx).Name)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to create report: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"status":    "report created",
-		"report_id": report.ID,
-	}, "", "  ")
+	return toolschema.Result(reportAgentResult{
+		Status:   "report created",
+		ReportID: report.ID,
+	}), nil
+}
 
-	return mcp.NewToolResultText(string(result)), nil
+// reviewReportsResult is the output of review_reports
+type reviewReportsResult struct {
+	PendingReports []models.Report `json:"pending_reports"`
+	Count          int             `json:"count"`
+	NextCursor     string          `json:"next_cursor,omitempty"`
+	PendingAppeals []models.Appeal `json:"pending_appeals"` // first page only
 }
 
-// reviewReports returns pending reports (for governance agents)
+// reviewReports returns reports awaiting review, or those matching the
+// status filter (for governance agents)
 func (s *ServerV2) reviewReports(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	reports, err := s.governance.GetPendingReports(ctx)
+	// Reports name their reporters, so only governance roles may read them
+	if caller := auth.FromContext(ctx); !caller.HasGovernanceRole() {
+		return mcp.NewToolResultError(fmt.Sprintf("permission denied for %s: reviewing reports requires a governance role", caller.Name)), nil
+	}
+
+	opts, err := listOptions(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	page, err := s.governance.ListReports(ctx, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get reports: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"pending_reports": reports,
-		"count":           len(reports),
-	}, "", "  ")
+	// The appeal queue rides along with the first page of reports
+	appeals := []models.Appeal{}
+	if opts.Cursor == "" {
+		pending, err := s.governance.GetPendingAppeals(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get appeals: %v", err)), nil
+		}
+		appeals = append(appeals, pending...)
+	}
+
+	return toolschema.Result(reviewReportsResult{
+		PendingReports: page.Items,
+		Count:          len(page.Items),
+		NextCursor:     page.NextCursor,
+		PendingAppeals: appeals,
+	}), nil
+}
+
+// claimReportResult is the output of claim_report
+type claimReportResult struct {
+	Status string         `json:"status"`
+	Report *models.Report `json:"report"`
+}
+
+// judgeActor returns the caller as a judge, the only role that rules on
+// reports and reverts governance actions
+func judgeActor(ctx context.Context) (governance.Actor, error) {
+	caller := auth.FromContext(ctx)
+	if !caller.HasRole(models.RoleJudge) {
+		return governance.Actor{}, fmt.Errorf("permission denied for %s: requires the judge role", caller.Name)
+	}
+	return governance.Actor{Name: caller.Name, Role: models.RoleJudge}, nil
+}
+
+// requireAdmin refuses callers without the admin role, which the tools that
+// change or delete catalog data outside governance need
+func requireAdmin(ctx context.Context) error {
+	caller := auth.FromContext(ctx)
+	if !caller.HasRole(models.RoleAdmin) {
+		return fmt.Errorf("permission denied for %s: requires the admin role", caller.Name)
+	}
+	return nil
+}
+
+// reportIDArg parses the required report_id argument
+func reportIDArg(req mcp.CallToolRequest) (uuid.UUID, error) {
+	raw := getArgString(req, "report_id")
+	if raw == "" {
+		return uuid.Nil, fmt.Errorf("report_id is required")
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid report_id: %v", err)
+	}
+	return id, nil
+}
+
+// claimReport assigns a pending report to the calling judge for review
+func (s *ServerV2) claimReport(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	actor, err := judgeActor(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	reportID, err := reportIDArg(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	report, err := s.governance.ReviewReport(ctx, reportID, actor)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return toolschema.Result(claimReportResult{Status: "report claimed", Report: report}), nil
+}
+
+// ruleOnReportResult is the output of rule_on_report
+type ruleOnReportResult struct {
+	Status string                   `json:"status"`
+	Report *models.Report           `json:"report"`
+	Action *models.GovernanceAction `json:"action,omitempty"`
+}
+
+// ruleOnReport resolves a report the calling judge has claimed and applies
+// the resolution to the reported agent
+func (s *ServerV2) ruleOnReport(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	actor, err := judgeActor(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	reportID, err := reportIDArg(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolution := models.Resolution(getArgString(req, "resolution"))
+	note := getArgString(req, "note")
+	if resolution == "" || note == "" {
+		return mcp.NewToolResultError("resolution and note are required"), nil
+	}
+	if len(note) > maxDescriptionLength {
+		return mcp.NewToolResultError(fmt.Sprintf("note too long (max %d characters)", maxDescriptionLength)), nil
+	}
+
+	ruling, err := s.governance.MakeRuling(ctx, reportID, resolution, note, actor)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	log.Printf("[INFO] Governance: %s ruled %s on report %s", actor.Name, resolution, reportID)
+	return toolschema.Result(ruleOnReportResult{
+		Status: "report resolved",
+		Report: ruling.Report,
+		Action: ruling.Action,
+	}), nil
+}
+
+// governanceActor returns the caller as an actor in one of its roles that
+// may perform action
+func governanceActor(ctx context.Context, action models.GovernanceActionType) (governance.Actor, error) {
+	caller := auth.FromContext(ctx)
+	role, err := governance.RoleFor(caller.Roles, action)
+	if err != nil {
+		return governance.Actor{}, fmt.Errorf("permission denied for %s: %w", caller.Name, err)
+	}
+	return governance.Actor{Name: caller.Name, Role: role}, nil
+}
 
-	return mcp.NewToolResultText(string(result)), nil
+// governanceActionResult is the output of governance_action
+type governanceActionResult struct {
+	Status string                      `json:"status"`
+	Action models.GovernanceActionType `json:"action"`
+	Actor  string                      `json:"actor"`
+	Role   models.GovernanceRole       `json:"role"`
 }
 
-// governanceAction executes a governance action
+// governanceAction executes a governance action as the caller, in a role
+// allowed to take it
 func (s *ServerV2) governanceAction(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	agentName := getArgString(req, "agent_name")
 	action := getArgString(req, "action")
@@ -511,103 +974,310 @@ func (s *ServerV2) governanceAction(ctx context.Context, req mcp.CallToolRequest
 		return mcp.NewToolResultError("agent_name, action, and reason are required"), nil
 	}
 
-	agent, err := s.db.GetAgent(ctx, agentName)
-	if err != nil || agent == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("agent not found: %s", agentName)), nil
+	var reportID *uuid.UUID
+	if raw := getArgString(req, "report_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid report_id: %v", err)), nil
+		}
+		reportID = &id
 	}
 
-	var actionErr error
-	switch models.GovernanceActionType(action) {
-	case models.ActionQuarantine:
-		actionErr = s.governance.Quarantine(ctx, agent.ID, models.RolePolice, reason, nil)
-	case models.ActionUnquarantine:
-		actionErr = s.governance.Unquarantine(ctx, agent.ID, reason)
+	// Reputation changes are recorded as promote or demote
+	actionType := models.GovernanceActionType(action)
+	delta := reputationDelta
+	switch actionType {
 	case models.ActionBan:
-		// Ban requires judge role
-		actionErr = s.governance.Quarantine(ctx, agent.ID, models.RoleJudge, reason, nil)
-		if actionErr == nil {
-			s.db.UpdateAgentStatus(ctx, agent.ID, models.StatusBanned)
-		}
-	case models.ActionDemote:
-		// Adjust reputation down with audit trail
-		newScore := agent.ReputationScore - reputationDelta
-		if newScore < 0 {
-			newScore = 0
-		}
-		actionErr = s.db.UpdateAgentReputation(ctx, agent.ID, newScore)
-		if actionErr == nil {
-			s.db.RecordGovernanceAction(ctx, &models.GovernanceAction{
-				AgentID:    agent.ID,
-				ActionType: models.ActionDemote,
-				Reason:     reason,
-			})
+		return mcp.NewToolResultError("bans need a judge's ban ruling: use execute_ban with the report_id"), nil
+	case models.ActionQuarantine, models.ActionUnquarantine, models.ActionUnban:
+	case models.ActionDemote, models.ActionPromote:
+		if reputationDelta <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("reputation_delta must be positive for %s action", action)), nil
 		}
-	case models.ActionPromote:
-		// Adjust reputation up with audit trail
-		newScore := agent.ReputationScore + reputationDelta
-		if newScore > 100 {
-			newScore = 100
-		}
-		actionErr = s.db.UpdateAgentReputation(ctx, agent.ID, newScore)
-		if actionErr == nil {
-			s.db.RecordGovernanceAction(ctx, &models.GovernanceAction{
-				AgentID:    agent.ID,
-				ActionType: models.ActionPromote,
-				Reason:     reason,
-			})
+		if actionType == models.ActionDemote {
+			delta = -reputationDelta
 		}
 	case "adjust_reputation":
 		// Direct reputation adjustment using reputation_delta parameter
 		if reputationDelta == 0 {
 			return mcp.NewToolResultError("reputation_delta is required for adjust_reputation action"), nil
 		}
-		actionErr = s.governance.AdjustReputation(ctx, agent.ID, reputationDelta, reason)
+		actionType = models.ActionDemote
+		if reputationDelta > 0 {
+			actionType = models.ActionPromote
+		}
 	default:
-		return mcp.NewToolResultError(fmt.Sprintf("unsupported action: %s (use: quarantine, unquarantine, ban, demote, promote, adjust_reputation)", action)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported action: %s (use: quarantine, unquarantine, unban, demote, promote, adjust_reputation)", action)), nil
+	}
+
+	actor, err := governanceActor(ctx, actionType)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agent, err := s.db.GetAgent(ctx, agentName)
+	if err != nil || agent == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("agent not found: %s", agentName)), nil
+	}
+
+	var actionErr error
+	switch actionType {
+	case models.ActionQuarantine:
+		actionErr = s.governance.Quarantine(ctx, agent.ID, actor, reason, reportID)
+	case models.ActionUnquarantine:
+		actionErr = s.governance.Unquarantine(ctx, agent.ID, actor, reason)
+	case models.ActionUnban:
+		_, actionErr = s.governance.Unban(ctx, agent.ID, actor, reason)
+	case models.ActionDemote, models.ActionPromote:
+		actionErr = s.governance.AdjustReputation(ctx, agent.ID, actor, delta, reason)
 	}
 
 	if actionErr != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("action failed: %v", actionErr)), nil
 	}
 
-	return mcp.NewToolResultText(`{"status": "action executed"}`), nil
+	log.Printf("[INFO] Governance: %s (%s) took %s action on %s", actor.Name, actor.Role, actionType, agent.Name)
+	return toolschema.Result(governanceActionResult{
+		Status: "action executed",
+		Action: actionType,
+		Actor:  actor.Name,
+		Role:   actor.Role,
+	}), nil
 }
 
-// governanceStats returns governance statistics
-func (s *ServerV2) governanceStats(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	stats, err := s.governance.GetStats(ctx)
+// executeBanResult is the output of execute_ban
+type executeBanResult struct {
+	Status string                   `json:"status"`
+	Action *models.GovernanceAction `json:"action"`
+}
+
+// executeBan carries out a judge's ban ruling (executioner only)
+func (s *ServerV2) executeBan(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	actor, err := governanceActor(ctx, models.ActionBan)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get stats: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	reportID, err := reportIDArg(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	reason := getArgString(req, "reason")
+	if reason == "" {
+		return mcp.NewToolResultError("reason is required"), nil
 	}
 
-	result, _ := json.MarshalIndent(stats, "", "  ")
-	return mcp.NewToolResultText(string(result)), nil
-}
+	action, err := s.governance.ExecuteBan(ctx, reportID, actor, reason)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("ban refused: %v", err)), nil
+	}
 
-// ============ Agent Registration ============
+	log.Printf("[INFO] Governance: %s banned %s on report %s", actor.Name, action.AgentName, reportID)
+	return toolschema.Result(executeBanResult{Status: "agent banned", Action: action}), nil
+}
 
-// registerAgent creates a new agent in the system
-func (s *ServerV2) registerAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name := getArgString(req, "name")
-	description := getArgString(req, "description")
-	model := getArgString(req, "model")
-	prompt := getArgString(req, "prompt")
-	skillsStr := getArgString(req, "skills")
-	toolsStr := getArgString(req, "tools")
-	tagsStr := getArgString(req, "tags")
-	version := getArgString(req, "version")
+// revertActionResult is the output of revert_governance_action
+type revertActionResult struct {
+	Status string                   `json:"status"`
+	Action *models.GovernanceAction `json:"action"` // the compensating action
+}
 
-	if name == "" || description == "" || prompt == "" {
-		return mcp.NewToolResultError("name, description, and prompt are required"), nil
+// revertGovernanceAction undoes a recorded governance action (judge only)
+func (s *ServerV2) revertGovernanceAction(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	actor, err := judgeActor(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	if len(name) > maxNameLength {
-		return mcp.NewToolResultError(fmt.Sprintf("name too long (max %d characters)", maxNameLength)), nil
+	raw := getArgString(req, "action_id")
+	reason := getArgString(req, "reason")
+	if raw == "" || reason == "" {
+		return mcp.NewToolResultError("action_id and reason are required"), nil
 	}
-	if len(description) > maxDescriptionLength {
-		return mcp.NewToolResultError(fmt.Sprintf("description too long (max %d characters)", maxDescriptionLength)), nil
+	actionID, err := uuid.Parse(raw)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid action_id: %v", err)), nil
 	}
 
-	// Check if agent already exists
+	action, err := s.governance.Revert(ctx, actionID, actor, reason)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("revert refused: %v", err)), nil
+	}
+
+	log.Printf("[INFO] Governance: %s reverted action %s on %s", actor.Name, actionID, action.AgentName)
+	return toolschema.Result(revertActionResult{Status: "action reverted", Action: action}), nil
+}
+
+// governanceHistoryResult is the output of governance_history
+type governanceHistoryResult struct {
+	Agent   string                    `json:"agent"`
+	Status  models.AgentStatus        `json:"status"`
+	Actions []models.GovernanceAction `json:"actions"`
+	Count   int                       `json:"count"`
+}
+
+// governanceHistory lists the governance actions taken on an agent
+func (s *ServerV2) governanceHistory(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if caller := auth.FromContext(ctx); !caller.HasGovernanceRole() {
+		return mcp.NewToolResultError(fmt.Sprintf("permission denied for %s: governance history requires a governance role", caller.Name)), nil
+	}
+	agentName := getArgString(req, "agent_name")
+	if agentName == "" {
+		return mcp.NewToolResultError("agent_name is required"), nil
+	}
+	agent, err := s.db.GetAgent(ctx, agentName)
+	if err != nil || agent == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("agent not found: %s", agentName)), nil
+	}
+
+	actions, err := s.governance.ListActions(ctx, agent.ID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list actions: %v", err)), nil
+	}
+	if actions == nil {
+		actions = []models.GovernanceAction{}
+	}
+	return toolschema.Result(governanceHistoryResult{
+		Agent:   agent.Name,
+		Status:  agent.Status,
+		Actions: actions,
+		Count:   len(actions),
+	}), nil
+}
+
+// fileAppealResult is the output of file_appeal
+type fileAppealResult struct {
+	Status string         `json:"status"`
+	Appeal *models.Appeal `json:"appeal"`
+}
+
+// fileAppeal lets an agent's creator appeal its quarantine or ban
+func (s *ServerV2) fileAppeal(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	caller := auth.FromContext(ctx)
+	if caller.Name == auth.AnonymousName {
+		return mcp.NewToolResultError("permission denied for anonymous: appeals are filed by the agent's creator"), nil
+	}
+	agentName := getArgString(req, "agent_name")
+	statement := getArgString(req, "statement")
+	if agentName == "" || statement == "" {
+		return mcp.NewToolResultError("agent_name and statement are required"), nil
+	}
+	if len(statement) > maxDescriptionLength {
+		return mcp.NewToolResultError(fmt.Sprintf("statement too long (max %d characters)", maxDescriptionLength)), nil
+	}
+
+	agent, err := s.db.GetAgent(ctx, agentName)
+	if err != nil || agent == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("agent not found: %s", agentName)), nil
+	}
+
+	appeal, err := s.governance.FileAppeal(ctx, agent.ID, caller.Name, statement)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("appeal refused: %v", err)), nil
+	}
+
+	log.Printf("[INFO] Governance: %s appealed the %s of %s", caller.Name, agent.Status, agent.Name)
+	return toolschema.Result(fileAppealResult{Status: "appeal filed", Appeal: appeal}), nil
+}
+
+// decideAppealResult is the output of decide_appeal
+type decideAppealResult struct {
+	Status  string                    `json:"status"`
+	Appeal  *models.Appeal            `json:"appeal"`
+	Actions []models.GovernanceAction `json:"actions"`
+}
+
+// decideAppeal grants or denies a pending appeal (judge only)
+func (s *ServerV2) decideAppeal(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	actor, err := judgeActor(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	raw := getArgString(req, "appeal_id")
+	decision := getArgString(req, "decision")
+	note := getArgString(req, "note")
+	if raw == "" || decision == "" || note == "" {
+		return mcp.NewToolResultError("appeal_id, decision and note are required"), nil
+	}
+	appealID, err := uuid.Parse(raw)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid appeal_id: %v", err)), nil
+	}
+	if len(note) > maxDescriptionLength {
+		return mcp.NewToolResultError(fmt.Sprintf("note too long (max %d characters)", maxDescriptionLength)), nil
+	}
+	var grant bool
+	switch decision {
+	case "grant":
+		grant = true
+	case "deny":
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid decision: %s (use grant or deny)", decision)), nil
+	}
+
+	result, err := s.governance.DecideAppeal(ctx, appealID, grant, note, actor)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	log.Printf("[INFO] Governance: %s %s appeal %s for %s", actor.Name, result.Appeal.Status, appealID, result.Appeal.AgentName)
+	return toolschema.Result(decideAppealResult{
+		Status:  "appeal " + string(result.Appeal.Status),
+		Appeal:  result.Appeal,
+		Actions: result.Actions,
+	}), nil
+}
+
+// governanceStats returns governance statistics
+func (s *ServerV2) governanceStats(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	stats, err := s.governance.GetStats(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get stats: %v", err)), nil
+	}
+
+	return toolschema.Result(stats), nil
+}
+
+// ============ Agent Registration ============
+
+// agentStatusResult is the output of register_agent and delete_agent
+type agentStatusResult struct {
+	Status  string    `json:"status"`
+	Agent   string    `json:"agent"`
+	ID      uuid.UUID `json:"id"`
+	Message string    `json:"message"`
+}
+
+// creatorName returns the name recorded as created_by: the caller, or the
+// reserved "api" for anonymous callers, whose registrations have no owner to
+// appeal for them
+func creatorName(ctx context.Context) string {
+	if caller := auth.FromContext(ctx); caller.Name != auth.AnonymousName {
+		return caller.Name
+	}
+	return auth.APIName
+}
+
+// registerAgent creates a new agent in the system
+func (s *ServerV2) registerAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := getArgString(req, "name")
+	description := getArgString(req, "description")
+	model := getArgString(req, "model")
+	prompt := getArgString(req, "prompt")
+	skillsStr := getArgString(req, "skills")
+	toolsStr := getArgString(req, "tools")
+	tagsStr := getArgString(req, "tags")
+	version := getArgString(req, "version")
+
+	if name == "" || description == "" || prompt == "" {
+		return mcp.NewToolResultError("name, description, and prompt are required"), nil
+	}
+	if len(name) > maxNameLength {
+		return mcp.NewToolResultError(fmt.Sprintf("name too long (max %d characters)", maxNameLength)), nil
+	}
+	if len(description) > maxDescriptionLength {
+		return mcp.NewToolResultError(fmt.Sprintf("description too long (max %d characters)", maxDescriptionLength)), nil
+	}
+
+	// Check if agent already exists
 	existing, _ := s.db.GetAgent(ctx, name)
 	if existing != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("agent '%s' already exists", name)), nil
@@ -653,13 +1323,18 @@ func (s *ServerV2) registerAgent(ctx context.Context, req mcp.CallToolRequest) (
 	if version == "" {
 		version = "1.0.0"
 	}
+	parsedVersion, err := semver.Parse(version)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	version = parsedVersion.String()
 	if len(skills) == 0 {
 		// Default skills from tools
 		skills = tools
 	}
 
 	// Create agent
-	createdBy := "api"
+	createdBy := creatorName(ctx)
 	agent := &models.Agent{
 		Name:            name,
 		Version:         version,
@@ -680,9 +1355,9 @@ func (s *ServerV2) registerAgent(ctx context.Context, req mcp.CallToolRequest) (
 
 	// Generate embedding if available
 	if s.embedder != nil {
-		emb, err := embeddings.CreateAgentEmbedding(s.embedder, ctx, name, description, skills)
+		emb, model, err := s.embedSource(ctx, agentSource(agent))
 		if err == nil {
-			agent.Embedding = &emb
+			agent.Embedding, agent.EmbeddingModel = emb, model
 		}
 	}
 
@@ -690,45 +1365,226 @@ func (s *ServerV2) registerAgent(ctx context.Context, req mcp.CallToolRequest) (
 	if err := s.db.CreateAgent(ctx, agent); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to create agent: %v", err)), nil
 	}
+	s.publishResource(agentResource(agent), s.readAgentResource)
+
+	return toolschema.Result(agentStatusResult{
+		Status:  "created",
+		Agent:   agent.Name,
+		ID:      agent.ID,
+		Message: fmt.Sprintf("Agent '%s' registered successfully", name),
+	}), nil
+}
+
+// updateAgentResult is the output of update_agent
+type updateAgentResult struct {
+	Status     string    `json:"status"`
+	Agent      string    `json:"agent"`
+	ID         uuid.UUID `json:"id"`
+	Version    string    `json:"version"`
+	Revision   int       `json:"revision"`
+	Changed    []string  `json:"changed"`
+	Reembedded bool      `json:"reembedded"`
+}
+
+// updateAgent applies partial changes to an existing agent.
+// The caller must pass the revision it read; a stale revision is rejected.
+func (s *ServerV2) updateAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := getArgString(req, "name")
+	expectedRevision := int(getArgFloat(req, "expected_revision"))
+
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if expectedRevision <= 0 {
+		return mcp.NewToolResultError("expected_revision is required (use the revision returned by get_agent)"), nil
+	}
+
+	agent, err := s.db.GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get agent: %v", err)), nil
+	}
+	if agent == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("agent '%s' not found", name)), nil
+	}
+	if agent.IsSystem {
+		return mcp.NewToolResultError("system agents cannot be modified"), nil
+	}
+	if agent.Status == models.StatusArchived || agent.Status == models.StatusBanned {
+		return mcp.NewToolResultError(fmt.Sprintf("agent '%s' is %s and cannot be modified", name, agent.Status)), nil
+	}
+	if caller := auth.FromContext(ctx); !caller.HasRole(models.RoleAdmin) &&
+		(caller.Name == auth.AnonymousName || agent.CreatedBy == nil || *agent.CreatedBy != caller.Name) {
+		return mcp.NewToolResultError(fmt.Sprintf("permission denied for %s: only the agent's creator or an admin can update it", caller.Name)), nil
+	}
+
+	// Apply only the fields the caller supplied
+	var changed []string
+	reembed := false
+
+	if newName := getArgString(req, "new_name"); newName != "" && newName != agent.Name {
+		if len(newName) > maxNameLength {
+			return mcp.NewToolResultError(fmt.Sprintf("new_name too long (max %d characters)", maxNameLength)), nil
+		}
+		existing, _ := s.db.GetAgent(ctx, newName)
+		if existing != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("agent '%s' already exists", newName)), nil
+		}
+		agent.Name = newName
+		changed = append(changed, "name")
+		reembed = true
+	}
+	if description := getArgString(req, "description"); description != "" && description != agent.Description {
+		if len(description) > maxDescriptionLength {
+			return mcp.NewToolResultError(fmt.Sprintf("description too long (max %d characters)", maxDescriptionLength)), nil
+		}
+		agent.Description = description
+		changed = append(changed, "description")
+		reembed = true
+	}
+	if hasArg(req, "skills") {
+		agent.Skills = parseList(getArgString(req, "skills"))
+		changed = append(changed, "skills")
+		reembed = true
+	}
+	if prompt := getArgString(req, "prompt"); prompt != "" {
+		agent.Prompt = prompt
+		changed = append(changed, "prompt")
+	}
+	if model := getArgString(req, "model"); model != "" {
+		agent.Model = model
+		changed = append(changed, "model")
+	}
+	if hasArg(req, "tools") {
+		agent.Tools = parseList(getArgString(req, "tools"))
+		changed = append(changed, "tools")
+	}
+	if hasArg(req, "tags") {
+		if agent.Metadata == nil {
+			agent.Metadata = map[string]any{}
+		}
+		agent.Metadata["tags"] = parseList(getArgString(req, "tags"))
+		changed = append(changed, "tags")
+	}
+
+	if len(changed) == 0 {
+		return mcp.NewToolResultError("no changes supplied"), nil
+	}
+
+	// Every saved change becomes a new, strictly greater version in the history
+	version, err := nextVersion(agent.Version, getArgString(req, "version"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	agent.Version = version
+
+	// Name, description and skills feed the embedding, so refresh it
+	reembedded := false
+	if reembed && s.embedder != nil {
+		emb, model, err := s.embedSource(ctx, agentSource(agent))
+		if err != nil {
+			log.Printf("[WARN] Failed to re-embed agent %s: %v", agent.Name, err)
+		} else {
+			agent.Embedding, agent.EmbeddingModel = emb, model
+			reembedded = true
+		}
+	}
+
+	if err := s.db.UpdateAgent(ctx, agent, expectedRevision); err != nil {
+		if errors.Is(err, database.ErrRevisionConflict) {
+			return mcp.NewToolResultError(fmt.Sprintf("agent '%s' was modified by someone else (%v); re-read it and retry", name, err)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update agent: %v", err)), nil
+	}
+	if agent.Name != name {
+		s.retractResource(agentURI(name))
+	}
+	s.publishResource(agentResource(agent), s.readAgentResource)
+
+	return toolschema.Result(updateAgentResult{
+		Status:     "updated",
+		Agent:      agent.Name,
+		ID:         agent.ID,
+		Version:    agent.Version,
+		Revision:   agent.Revision,
+		Changed:    changed,
+		Reembedded: reembedded,
+	}), nil
+}
+
+// deleteAgent archives (soft-deletes) an agent.
+// The caller must pass the revision it read; a stale revision is rejected.
+func (s *ServerV2) deleteAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name := getArgString(req, "name")
+	expectedRevision := int(getArgFloat(req, "expected_revision"))
+
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if expectedRevision <= 0 {
+		return mcp.NewToolResultError("expected_revision is required (use the revision returned by get_agent)"), nil
+	}
+
+	agent, err := s.db.GetAgent(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get agent: %v", err)), nil
+	}
+	if agent == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("agent '%s' not found", name)), nil
+	}
+	if agent.IsSystem {
+		return mcp.NewToolResultError("system agents cannot be deleted"), nil
+	}
+	if agent.Status == models.StatusArchived {
+		return mcp.NewToolResultError(fmt.Sprintf("agent '%s' is already archived", name)), nil
+	}
+
+	if err := s.db.ArchiveAgent(ctx, agent.ID, expectedRevision); err != nil {
+		if errors.Is(err, database.ErrRevisionConflict) {
+			return mcp.NewToolResultError(fmt.Sprintf("agent '%s' was modified by someone else (%v); re-read it and retry", name, err)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete agent: %v", err)), nil
+	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"status":  "created",
-		"agent":   agent.Name,
-		"id":      agent.ID,
-		"message": fmt.Sprintf("Agent '%s' registered successfully", name),
-	}, "", "  ")
+	s.retractResource(agentURI(name))
+	log.Printf("[INFO] Archived agent %s", name)
 
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(agentStatusResult{
+		Status:  "archived",
+		Agent:   name,
+		ID:      agent.ID,
+		Message: fmt.Sprintf("Agent '%s' archived; it no longer appears in listings or searches", name),
+	}), nil
 }
 
 // ============ Skills Tools ============
 
+// listSkillsResult is the output of list_skills
+type listSkillsResult struct {
+	Skills     []models.SkillSummary `json:"skills"`
+	Count      int                   `json:"count"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
 // listSkills lists all available skills
 func (s *ServerV2) listSkills(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	category := getArgString(req, "category")
-	tagsStr := getArgString(req, "tags")
-
-	var tags []string
-	if tagsStr != "" {
-		for _, t := range strings.Split(tagsStr, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" {
-				tags = append(tags, t)
-			}
-		}
+	opts, err := listOptions(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	skills, err := s.db.ListSkills(ctx, category, tags)
+	page, err := s.db.ListSkillsPage(ctx, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list skills: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"skills": skills,
-		"count":  len(skills),
-	}, "", "  ")
-
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(listSkillsResult{
+		Skills:     page.Items,
+		Count:      len(page.Items),
+		NextCursor: page.NextCursor,
+	}), nil
 }
 
 // getSkill retrieves a skill by name
@@ -746,34 +1602,39 @@ func (s *ServerV2) getSkill(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultError(fmt.Sprintf("skill not found: %s", name)), nil
 	}
 
+	if version := getArgString(req, "version"); version != "" && version != skill.Version {
+		return s.revisionResult(ctx, "skill", skill.ID, name, version)
+	}
+
 	s.db.IncrementSkillUsage(ctx, skill.ID)
 
-	result, _ := json.MarshalIndent(skill, "", "  ")
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(skill), nil
 }
 
-// searchSkills searches skills by keyword
+// searchSkills searches skills with hybrid keyword and vector ranking
 func (s *ServerV2) searchSkills(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	query := getArgString(req, "query")
-	if query == "" {
-		return mcp.NewToolResultError("query is required"), nil
+	q, errResult := s.searchRequest(ctx, req, "skills")
+	if errResult != nil {
+		return errResult, nil
 	}
-
-	skills, err := s.db.SearchSkills(ctx, query)
+	skills, err := s.db.HybridSearchSkills(ctx, q)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"results": skills,
-		"count":   len(skills),
-		"query":   query,
-	}, "", "  ")
+	out := newSearchResult("skills", q, skills)
+	out.DidYouMean, out.Suggestions = s.suggest(ctx, models.EmbeddingKindSkill, q, hasKeywordMatch(skills, func(r models.SkillSearchResult) models.SearchScores { return r.Scores }))
+	return toolschema.Result(out), nil
+}
 
-	return mcp.NewToolResultText(string(result)), nil
+// similarSkillsResult is the output of find_similar_skills
+type similarSkillsResult struct {
+	SimilarSkills []models.SkillSearchResult `json:"similar_skills"`
+	Count         int                        `json:"count"`
 }
 
-// findSimilarSkills finds semantically similar skills
+// findSimilarSkills finds skills similar to a description, ranking semantic
+// matches together with keyword matches
 func (s *ServerV2) findSimilarSkills(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	description := getArgString(req, "description")
 	limitF := getArgFloat(req, "limit")
@@ -788,25 +1649,54 @@ func (s *ServerV2) findSimilarSkills(ctx context.Context, req mcp.CallToolReques
 		limit = 5
 	}
 	if threshold <= 0 {
-		threshold = 0.15
+		threshold = defaultMinSimilarity
 	}
 
-	embedding, err := s.embedder.Embed(ctx, description)
+	if s.embedder == nil {
+		return mcp.NewToolResultError(errNoEmbedder), nil
+	}
+	q, err := s.searchQuery(ctx, description, limit, threshold)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("embedding failed: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	similar, err := s.db.FindSimilarSkills(ctx, embedding, limit, threshold)
+	similar, err := s.db.HybridSearchSkills(ctx, q)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"similar_skills": similar,
-		"count":          len(similar),
-	}, "", "  ")
+	return toolschema.Result(similarSkillsResult{
+		SimilarSkills: similar,
+		Count:         len(similar),
+	}), nil
+}
+
+// skillMatch is the output of use_skill when a skill was found
+type skillMatch struct {
+	Found        bool                `json:"found"`
+	MatchMethod  string              `json:"match_method"`
+	Scores       models.SearchScores `json:"scores"`
+	Skill        skillContent        `json:"skill"`
+	Instructions string              `json:"instructions"`
+	Explain      *matchExplanation   `json:"explain,omitempty"`
+}
+
+// skillContent is the part of a skill use_skill hands to the caller
+type skillContent struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Category    string           `json:"category"`
+	Content     string           `json:"content"`
+	Examples    []models.Example `json:"examples"`
+	Tags        []string         `json:"tags"`
+}
 
-	return mcp.NewToolResultText(string(result)), nil
+// skillMiss is the output of use_skill when no skill matched
+type skillMiss struct {
+	Found           bool              `json:"found"`
+	Message         string            `json:"message"`
+	AvailableSkills []string          `json:"available_skills"`
+	Explain         *matchExplanation `json:"explain,omitempty"`
 }
 
 // useSkill finds the best skill for a task and returns its content
@@ -816,44 +1706,35 @@ func (s *ServerV2) useSkill(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultError("task description is required"), nil
 	}
 
-	var bestSkill *models.Skill
-	var matchMethod string
-
-	// Try semantic search first
-	if s.embedder != nil {
-		embedding, err := s.embedder.Embed(ctx, task)
-		if err == nil {
-			similar, _ := s.db.FindSimilarSkills(ctx, embedding, 3, 0.25)
-			if len(similar) > 0 {
-				bestSkill, _ = s.db.GetSkillByID(ctx, similar[0].Skill.ID)
-				matchMethod = fmt.Sprintf("semantic (%.0f%% match)", similar[0].Similarity*100)
-			}
+	explain := getArgBool(req, "explain")
+	limit := 1
+	if explain {
+		if limit = int(getArgFloat(req, "candidates")); limit <= 0 {
+			limit = defaultExplainCandidates
 		}
 	}
 
-	// Fallback to keyword search
-	if bestSkill == nil {
-		skills, err := s.db.SearchSkills(ctx, task)
-		if err == nil && len(skills) > 0 {
-			bestSkill, _ = s.db.GetSkillByID(ctx, skills[0].ID)
-			matchMethod = "keyword"
-		}
+	q, embedErr := s.searchQuery(ctx, task, limit, useMinSimilarity)
+	if embedErr != nil {
+		log.Printf("[WARN] %v (matching skills by keyword only)", embedErr)
 	}
 
-	// Multi-word keyword search fallback - try individual words
-	if bestSkill == nil {
-		words := strings.Fields(task)
-		for _, word := range words {
-			if len(word) < 3 {
-				continue // Skip short words like "to", "a", "the"
-			}
-			skills, err := s.db.SearchSkills(ctx, word)
-			if err == nil && len(skills) > 0 {
-				bestSkill, _ = s.db.GetSkillByID(ctx, skills[0].ID)
-				matchMethod = fmt.Sprintf("keyword (matched '%s')", word)
-				break
-			}
+	var bestSkill *models.Skill
+	var scores models.SearchScores
+	matches, err := s.db.HybridSearchSkills(ctx, q)
+	if err == nil && len(matches) > 0 {
+		bestSkill, _ = s.db.GetSkillByID(ctx, matches[0].Skill.ID)
+		scores = matches[0].Scores
+	}
+
+	// Skills are matched on the task as given, without alias expansion
+	var explanation *matchExplanation
+	if explain {
+		cands := make([]matchCandidate, len(matches))
+		for i, m := range matches {
+			cands[i] = matchCandidate{Name: m.Skill.Name, Reputation: m.Skill.ReputationScore, Status: string(m.Skill.Status), Scores: m.Scores}
 		}
+		explanation = explainMatches(task, []aliases.Expansion{}, q, embedErr, cands)
 	}
 
 	if bestSkill == nil {
@@ -863,31 +1744,39 @@ func (s *ServerV2) useSkill(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 			availableNames = append(availableNames, s.Name)
 		}
 
-		result, _ := json.MarshalIndent(map[string]any{
-			"found":            false,
-			"message":          fmt.Sprintf("No matching skill found for: %s", task),
-			"available_skills": availableNames,
-		}, "", "  ")
-		return mcp.NewToolResultText(string(result)), nil
+		return toolschema.Result(skillMiss{
+			Found:           false,
+			Message:         fmt.Sprintf("No matching skill found for: %s", task),
+			AvailableSkills: availableNames,
+			Explain:         explanation,
+		}), nil
 	}
 
 	s.db.IncrementSkillUsage(ctx, bestSkill.ID)
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"found":        true,
-		"match_method": matchMethod,
-		"skill": map[string]any{
-			"name":        bestSkill.Name,
-			"description": bestSkill.Description,
-			"category":    bestSkill.Category,
-			"content":     bestSkill.Content,
-			"examples":    bestSkill.Examples,
-			"tags":        bestSkill.Tags,
+	return toolschema.Result(skillMatch{
+		Found:       true,
+		MatchMethod: matchMethod(scores),
+		Scores:      scores,
+		Skill: skillContent{
+			Name:        bestSkill.Name,
+			Description: bestSkill.Description,
+			Category:    bestSkill.Category,
+			Content:     bestSkill.Content,
+			Examples:    bestSkill.Examples,
+			Tags:        bestSkill.Tags,
 		},
-		"instructions": "Use this skill's content as reference documentation for the task.",
-	}, "", "  ")
+		Instructions: "Use this skill's content as reference documentation for the task.",
+		Explain:      explanation,
+	}), nil
+}
 
-	return mcp.NewToolResultText(string(result)), nil
+// skillStatusResult is the output of register_skill
+type skillStatusResult struct {
+	Status  string    `json:"status"`
+	Skill   string    `json:"skill"`
+	ID      uuid.UUID `json:"id"`
+	Message string    `json:"message"`
 }
 
 // registerSkill creates a new skill
@@ -921,8 +1810,13 @@ func (s *ServerV2) registerSkill(ctx context.Context, req mcp.CallToolRequest) (
 	if version == "" {
 		version = "1.0.0"
 	}
+	parsedVersion, err := semver.Parse(version)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	version = parsedVersion.String()
 
-	createdBy := "api"
+	createdBy := creatorName(ctx)
 	skill := &models.Skill{
 		Name:            name,
 		Version:         version,
@@ -938,54 +1832,51 @@ func (s *ServerV2) registerSkill(ctx context.Context, req mcp.CallToolRequest) (
 	}
 
 	if s.embedder != nil {
-		emb, err := s.embedder.Embed(ctx, name+" "+description+" "+content)
+		emb, model, err := s.embedSource(ctx, skillSource(skill))
 		if err == nil {
-			skill.Embedding = &emb
+			skill.Embedding, skill.EmbeddingModel = emb, model
 		}
 	}
 
 	if err := s.db.CreateSkill(ctx, skill); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to create skill: %v", err)), nil
 	}
+	s.publishResource(skillResource(skill), s.readSkillResource)
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"status":  "created",
-		"skill":   skill.Name,
-		"id":      skill.ID,
-		"message": fmt.Sprintf("Skill '%s' registered successfully", name),
-	}, "", "  ")
-
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(skillStatusResult{
+		Status:  "created",
+		Skill:   skill.Name,
+		ID:      skill.ID,
+		Message: fmt.Sprintf("Skill '%s' registered successfully", name),
+	}), nil
 }
 
 // ============ Commands Tools ============
 
+// listCommandsResult is the output of list_commands
+type listCommandsResult struct {
+	Commands   []models.CommandSummary `json:"commands"`
+	Count      int                     `json:"count"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
 // listCommands lists all available commands
 func (s *ServerV2) listCommands(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	category := getArgString(req, "category")
-	tagsStr := getArgString(req, "tags")
-
-	var tags []string
-	if tagsStr != "" {
-		for _, t := range strings.Split(tagsStr, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" {
-				tags = append(tags, t)
-			}
-		}
+	opts, err := listOptions(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	commands, err := s.db.ListCommands(ctx, category, tags)
+	page, err := s.db.ListCommandsPage(ctx, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list commands: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"commands": commands,
-		"count":    len(commands),
-	}, "", "  ")
-
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(listCommandsResult{
+		Commands:   page.Items,
+		Count:      len(page.Items),
+		NextCursor: page.NextCursor,
+	}), nil
 }
 
 // getCommand retrieves a command by name
@@ -1003,31 +1894,81 @@ func (s *ServerV2) getCommand(ctx context.Context, req mcp.CallToolRequest) (*mc
 		return mcp.NewToolResultError(fmt.Sprintf("command not found: %s", name)), nil
 	}
 
+	if version := getArgString(req, "version"); version != "" && version != cmd.Version {
+		return s.revisionResult(ctx, "command", cmd.ID, name, version)
+	}
+
 	s.db.IncrementCommandUsage(ctx, cmd.ID)
 
-	result, _ := json.MarshalIndent(cmd, "", "  ")
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(cmd), nil
 }
 
-// searchCommands searches commands by keyword
-func (s *ServerV2) searchCommands(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	query := getArgString(req, "query")
-	if query == "" {
-		return mcp.NewToolResultError("query is required"), nil
+// renderCommandResult is the output of render_command
+type renderCommandResult struct {
+	Command          string   `json:"command"`
+	Version          string   `json:"version"`
+	Prompt           string   `json:"prompt"`
+	DefaultsApplied  []string `json:"defaults_applied"`
+	UnknownArguments []string `json:"unknown_arguments"`
+}
+
+// renderCommand expands a command's prompt template with the supplied arguments
+func (s *ServerV2) renderCommand(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := getArgString(req, "name")
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	values, err := getArgStringMap(req, "arguments")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmd, err := s.db.GetCommand(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get command: %v", err)), nil
+	}
+	if cmd == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("command not found: %s", name)), nil
 	}
 
-	commands, err := s.db.SearchCommands(ctx, query)
+	rendered, err := render.Render(cmd.Prompt, cmd.Arguments, values)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	s.db.IncrementCommandUsage(ctx, cmd.ID)
+
+	return toolschema.Result(renderCommandResult{
+		Command:          cmd.Name,
+		Version:          cmd.Version,
+		Prompt:           rendered.Text,
+		DefaultsApplied:  rendered.Defaulted,
+		UnknownArguments: rendered.Unknown,
+	}), nil
+}
+
+// searchCommands searches commands with hybrid keyword and vector ranking
+func (s *ServerV2) searchCommands(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	q, errResult := s.searchRequest(ctx, req, "commands")
+	if errResult != nil {
+		return errResult, nil
+	}
+	commands, err := s.db.HybridSearchCommands(ctx, q)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"results": commands,
-		"count":   len(commands),
-		"query":   query,
-	}, "", "  ")
+	out := newSearchResult("commands", q, commands)
+	out.DidYouMean, out.Suggestions = s.suggest(ctx, models.EmbeddingKindCommand, q, hasKeywordMatch(commands, func(r models.CommandSearchResult) models.SearchScores { return r.Scores }))
+	return toolschema.Result(out), nil
+}
 
-	return mcp.NewToolResultText(string(result)), nil
+// commandStatusResult is the output of register_command
+type commandStatusResult struct {
+	Status  string    `json:"status"`
+	Command string    `json:"command"`
+	ID      uuid.UUID `json:"id"`
+	Message string    `json:"message"`
 }
 
 // registerCommand creates a new command
@@ -1038,11 +1979,22 @@ func (s *ServerV2) registerCommand(ctx context.Context, req mcp.CallToolRequest)
 	category := getArgString(req, "category")
 	tagsStr := getArgString(req, "tags")
 	version := getArgString(req, "version")
+	argumentsJSON := getArgString(req, "arguments")
 
 	if name == "" || description == "" || prompt == "" {
 		return mcp.NewToolResultError("name, description, and prompt are required"), nil
 	}
 
+	var arguments []models.Argument
+	if argumentsJSON != "" {
+		if err := json.Unmarshal([]byte(argumentsJSON), &arguments); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("arguments must be a JSON array: %v", err)), nil
+		}
+	}
+	if err := render.Validate(prompt, arguments); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid prompt template: %v", err)), nil
+	}
+
 	existing, _ := s.db.GetCommand(ctx, name)
 	if existing != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("command '%s' already exists", name)), nil
@@ -1061,13 +2013,19 @@ func (s *ServerV2) registerCommand(ctx context.Context, req mcp.CallToolRequest)
 	if version == "" {
 		version = "1.0.0"
 	}
+	parsedVersion, err := semver.Parse(version)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	version = parsedVersion.String()
 
-	createdBy := "api"
+	createdBy := creatorName(ctx)
 	cmd := &models.Command{
 		Name:            name,
 		Version:         version,
 		Description:     description,
 		Prompt:          prompt,
+		Arguments:       arguments,
 		Category:        category,
 		Tags:            tags,
 		Status:          models.CommandStatusActive,
@@ -1078,66 +2036,1554 @@ func (s *ServerV2) registerCommand(ctx context.Context, req mcp.CallToolRequest)
 	}
 
 	if s.embedder != nil {
-		emb, err := s.embedder.Embed(ctx, name+" "+description+" "+prompt)
+		emb, model, err := s.embedSource(ctx, commandSource(cmd))
 		if err == nil {
-			cmd.Embedding = &emb
+			cmd.Embedding, cmd.EmbeddingModel = emb, model
 		}
 	}
 
 	if err := s.db.CreateCommand(ctx, cmd); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to create command: %v", err)), nil
 	}
+	s.publishResource(commandResource(cmd), s.readCommandResource)
+	s.publishPrompt(cmd)
 
-	result, _ := json.MarshalIndent(map[string]any{
-		"status":  "created",
-		"command": cmd.Name,
-		"id":      cmd.ID,
-		"message": fmt.Sprintf("Command '%s' registered successfully", name),
-	}, "", "  ")
-
-	return mcp.NewToolResultText(string(result)), nil
+	return toolschema.Result(commandStatusResult{
+		Status:  "created",
+		Command: cmd.Name,
+		ID:      cmd.ID,
+		Message: fmt.Sprintf("Command '%s' registered successfully", name),
+	}), nil
 }
 
-// ============ Meta Tools ============
+// ============ Revision Tools ============
 
-// taskAliases maps common terms to agent-related keywords for better matching
-var taskAliases = map[string][]string{
-	// Code review related
-	"review":   {"code", "quality", "check", "audit", "inspect", "reviw", "reveiw"},
-	"code":     {"programming", "coding", "development", "software", "script"},
-	"bug":      {"fix", "debug", "error", "issue", "problem", "bugs", "fixing", "code-quality"},
-	"refactor": {"clean", "improve", "restructure", "optimize"},
+// nextVersion validates a requested version against the current one.
+// Versions must strictly increase; when none is requested the patch level is bumped.
+func nextVersion(current, requested string) (string, error) {
+	cur, curErr := semver.Parse(current)
+	if requested == "" {
+		if curErr != nil {
+			return "", fmt.Errorf("current version %q is not semver; supply an explicit version", current)
+		}
+		return cur.NextPatch().String(), nil
+	}
 
-	// Testing related
-	"test": {"testing", "tests", "unittest", "unit", "qa", "quality"},
-	"e2e":  {"end-to-end", "integration", "functional"},
+	next, err := semver.Parse(requested)
+	if err != nil {
+		return "", err
+	}
+	if curErr == nil && semver.Compare(next, cur) <= 0 {
+		return "", fmt.Errorf("version %s must be greater than current version %s", next, cur)
+	}
+	return next.String(), nil
+}
+
+// revisionSubject resolves an agent, skill or command name to its ID and current version
+func (s *ServerV2) revisionSubject(ctx context.Context, kind, name string) (uuid.UUID, string, error) {
+	switch kind {
+	case "agent":
+		agent, err := s.db.GetAgent(ctx, name)
+		if err != nil || agent == nil {
+			return uuid.Nil, "", fmt.Errorf("agent not found: %s", name)
+		}
+		return agent.ID, agent.Version, nil
+	case "skill":
+		skill, err := s.db.GetSkill(ctx, name)
+		if err != nil || skill == nil {
+			return uuid.Nil, "", fmt.Errorf("skill not found: %s", name)
+		}
+		return skill.ID, skill.Version, nil
+	case "command":
+		cmd, err := s.db.GetCommand(ctx, name)
+		if err != nil || cmd == nil {
+			return uuid.Nil, "", fmt.Errorf("command not found: %s", name)
+		}
+		return cmd.ID, cmd.Version, nil
+	}
+	return uuid.Nil, "", fmt.Errorf("type must be agent, skill or command")
+}
+
+// loadRevision fetches one version of an entity's history (nil if it does not exist)
+func (s *ServerV2) loadRevision(ctx context.Context, kind string, id uuid.UUID, version string) (any, error) {
+	switch kind {
+	case "agent":
+		rev, err := s.db.GetAgentRevision(ctx, id, version)
+		if err != nil || rev == nil {
+			return nil, err
+		}
+		return rev, nil
+	case "skill":
+		rev, err := s.db.GetSkillRevision(ctx, id, version)
+		if err != nil || rev == nil {
+			return nil, err
+		}
+		return rev, nil
+	case "command":
+		rev, err := s.db.GetCommandRevision(ctx, id, version)
+		if err != nil || rev == nil {
+			return nil, err
+		}
+		return rev, nil
+	}
+	return nil, fmt.Errorf("type must be agent, skill or command")
+}
+
+// revisionResult renders a historical version for the get_* tools
+func (s *ServerV2) revisionResult(ctx context.Context, kind string, id uuid.UUID, name, version string) (*mcp.CallToolResult, error) {
+	rev, err := s.loadRevision(ctx, kind, id, version)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get revision: %v", err)), nil
+	}
+	if rev == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s '%s' has no version %s", kind, name, version)), nil
+	}
+
+	return toolschema.Result(rev), nil
+}
+
+// listRevisionsResult is the output of list_revisions
+type listRevisionsResult struct {
+	Type           string                `json:"type"`
+	Name           string                `json:"name"`
+	CurrentVersion string                `json:"current_version"`
+	Revisions      []models.RevisionInfo `json:"revisions"`
+	Count          int                   `json:"count"`
+}
+
+// listRevisions lists the version history of an agent, skill or command
+func (s *ServerV2) listRevisions(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	kind := getArgString(req, "type")
+	name := getArgString(req, "name")
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+
+	id, current, err := s.revisionSubject(ctx, kind, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var revisions []models.RevisionInfo
+	switch kind {
+	case "agent":
+		revisions, err = s.db.ListAgentRevisions(ctx, id)
+	case "skill":
+		revisions, err = s.db.ListSkillRevisions(ctx, id)
+	case "command":
+		revisions, err = s.db.ListCommandRevisions(ctx, id)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list revisions: %v", err)), nil
+	}
+
+	return toolschema.Result(listRevisionsResult{
+		Type:           kind,
+		Name:           name,
+		CurrentVersion: current,
+		Revisions:      revisions,
+		Count:          len(revisions),
+	}), nil
+}
+
+// diffRevisionsResult is the output of diff_revisions. Each change names a
+// field and holds either its from and to values or a line diff.
+type diffRevisionsResult struct {
+	Type        string           `json:"type"`
+	Name        string           `json:"name"`
+	FromVersion string           `json:"from_version"`
+	ToVersion   string           `json:"to_version"`
+	Changes     []map[string]any `json:"changes"`
+	Count       int              `json:"count"`
+}
+
+// diffRevisions compares two versions of an agent, skill or command
+func (s *ServerV2) diffRevisions(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	kind := getArgString(req, "type")
+	name := getArgString(req, "name")
+	fromVersion := getArgString(req, "from_version")
+	toVersion := getArgString(req, "to_version")
+
+	if name == "" || fromVersion == "" {
+		return mcp.NewToolResultError("name and from_version are required"), nil
+	}
+
+	id, current, err := s.revisionSubject(ctx, kind, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if toVersion == "" {
+		toVersion = current
+	}
+
+	from, err := s.loadRevision(ctx, kind, id, fromVersion)
+	if err != nil || from == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s '%s' has no version %s", kind, name, fromVersion)), nil
+	}
+	to, err := s.loadRevision(ctx, kind, id, toVersion)
+	if err != nil || to == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s '%s' has no version %s", kind, name, toVersion)), nil
+	}
+
+	changes := diffSnapshots(from, to)
+
+	return toolschema.Result(diffRevisionsResult{
+		Type:        kind,
+		Name:        name,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     changes,
+		Count:       len(changes),
+	}), nil
+}
+
+// diffSnapshots compares two revisions field by field.
+// Multi-line text fields (prompts, content) get a line diff instead of full values.
+func diffSnapshots(from, to any) []map[string]any {
+	a, b := snapshotFields(from), snapshotFields(to)
+
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	var fields []string
+	for k := range keys {
+		// Identity and bookkeeping fields always differ between revisions
+		if k == "id" || k == "version" || k == "created_at" || strings.HasSuffix(k, "_id") {
+			continue
+		}
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	changes := []map[string]any{}
+	for _, field := range fields {
+		if reflect.DeepEqual(a[field], b[field]) {
+			continue
+		}
+		change := map[string]any{"field": field}
+		as, aText := a[field].(string)
+		bs, bText := b[field].(string)
+		if aText && bText && (strings.Contains(as, "\n") || strings.Contains(bs, "\n")) {
+			change["diff"] = diffLines(as, bs)
+		} else {
+			change["from"] = a[field]
+			change["to"] = b[field]
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// snapshotFields flattens a revision struct into its JSON field map
+func snapshotFields(rev any) map[string]any {
+	data, _ := json.Marshal(rev)
+	var fields map[string]any
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// diffLines produces a minimal line diff: "  " unchanged, "- " removed, "+ " added
+func diffLines(from, to string) []string {
+	a, b := strings.Split(from, "\n"), strings.Split(to, "\n")
+
+	// Longest common subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+ "+b[j])
+	}
+	return out
+}
+
+// rollbackResult is the output of rollback_revision
+type rollbackResult struct {
+	Status       string `json:"status"`
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	RestoredFrom string `json:"restored_from"`
+	Version      string `json:"version"`
+	Message      string `json:"message"`
+}
+
+// rollbackRevision restores an earlier version's content as a new version.
+// History is never rewritten: the rollback itself is appended as the next version.
+func (s *ServerV2) rollbackRevision(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kind := getArgString(req, "type")
+	name := getArgString(req, "name")
+	target := getArgString(req, "version")
+	requested := getArgString(req, "new_version")
+
+	if name == "" || target == "" {
+		return mcp.NewToolResultError("name and version are required"), nil
+	}
+
+	id, current, err := s.revisionSubject(ctx, kind, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if target == current {
+		return mcp.NewToolResultError(fmt.Sprintf("%s '%s' is already at version %s", kind, name, target)), nil
+	}
+	newVersion, err := nextVersion(current, requested)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	switch kind {
+	case "agent":
+		expectedRevision := int(getArgFloat(req, "expected_revision"))
+		if expectedRevision <= 0 {
+			return mcp.NewToolResultError("expected_revision is required for agents (use the revision returned by get_agent)"), nil
+		}
+		agent, err := s.db.GetAgentByID(ctx, id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get agent: %v", err)), nil
+		}
+		if agent == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("agent '%s' not found", name)), nil
+		}
+		if agent.IsSystem {
+			return mcp.NewToolResultError("system agents cannot be modified"), nil
+		}
+		if agent.Status == models.StatusArchived || agent.Status == models.StatusBanned {
+			return mcp.NewToolResultError(fmt.Sprintf("agent '%s' is %s and cannot be modified", name, agent.Status)), nil
+		}
+		rev, err := s.db.GetAgentRevision(ctx, id, target)
+		if err != nil || rev == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("agent '%s' has no version %s", name, target)), nil
+		}
+		rev.ApplyTo(agent)
+		agent.Version = newVersion
+		if s.embedder != nil {
+			if emb, model, err := s.embedSource(ctx, agentSource(agent)); err == nil {
+				agent.Embedding, agent.EmbeddingModel = emb, model
+			}
+		}
+		if err := s.db.UpdateAgent(ctx, agent, expectedRevision); err != nil {
+			if errors.Is(err, database.ErrRevisionConflict) {
+				return mcp.NewToolResultError(fmt.Sprintf("agent '%s' was modified by someone else (%v); re-read it and retry", name, err)), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("rollback failed: %v", err)), nil
+		}
+		s.publishResource(agentResource(agent), s.readAgentResource)
+
+	case "skill":
+		skill, err := s.db.GetSkillByID(ctx, id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get skill: %v", err)), nil
+		}
+		if skill == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("skill '%s' not found", name)), nil
+		}
+		if skill.IsSystem {
+			return mcp.NewToolResultError("system skills cannot be modified"), nil
+		}
+		rev, err := s.db.GetSkillRevision(ctx, id, target)
+		if err != nil || rev == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("skill '%s' has no version %s", name, target)), nil
+		}
+		rev.ApplyTo(skill)
+		skill.Version = newVersion
+		if s.embedder != nil {
+			if emb, model, err := s.embedSource(ctx, skillSource(skill)); err == nil {
+				skill.Embedding, skill.EmbeddingModel = emb, model
+			}
+		}
+		if err := s.db.UpdateSkill(ctx, skill); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("rollback failed: %v", err)), nil
+		}
+		s.publishResource(skillResource(skill), s.readSkillResource)
+
+	case "command":
+		cmd, err := s.db.GetCommandByID(ctx, id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get command: %v", err)), nil
+		}
+		if cmd == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("command '%s' not found", name)), nil
+		}
+		if cmd.IsSystem {
+			return mcp.NewToolResultError("system commands cannot be modified"), nil
+		}
+		rev, err := s.db.GetCommandRevision(ctx, id, target)
+		if err != nil || rev == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("command '%s' has no version %s", name, target)), nil
+		}
+		rev.ApplyTo(cmd)
+		cmd.Version = newVersion
+		if s.embedder != nil {
+			if emb, model, err := s.embedSource(ctx, commandSource(cmd)); err == nil {
+				cmd.Embedding, cmd.EmbeddingModel = emb, model
+			}
+		}
+		if err := s.db.UpdateCommand(ctx, cmd); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("rollback failed: %v", err)), nil
+		}
+		s.publishResource(commandResource(cmd), s.readCommandResource)
+		s.publishPrompt(cmd)
+	}
+
+	log.Printf("[INFO] Rolled back %s %s to %s as %s", kind, name, target, newVersion)
+
+	return toolschema.Result(rollbackResult{
+		Status:       "rolled_back",
+		Type:         kind,
+		Name:         name,
+		RestoredFrom: target,
+		Version:      newVersion,
+		Message:      fmt.Sprintf("%s '%s' restored to the content of %s as version %s", kind, name, target, newVersion),
+	}), nil
+}
+
+// ============ Project Sync ============
+
+// syncOptions selects what runSync writes
+type syncOptions struct {
+	ProjectDir string
+	Commands   []string // Command names, or "all"
+	Agents     []string // Agent names, or "all"
+	Force      bool
+	DryRun     bool
+}
+
+// runSync writes the selected commands and agents into the project's .claude
+// directory. With nothing selected, the items already in the lockfile are refreshed.
+func (s *ServerV2) runSync(ctx context.Context, opts syncOptions) ([]projectsync.Change, error) {
+	if opts.ProjectDir == "" {
+		opts.ProjectDir = "."
+	}
+
+	commandNames, agentNames := opts.Commands, opts.Agents
+	explicit := len(commandNames) > 0 || len(agentNames) > 0
+	if !explicit {
+		lock, err := projectsync.ReadLockfile(opts.ProjectDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range lock.Items {
+			switch e.Kind {
+			case projectsync.KindCommand:
+				commandNames = append(commandNames, e.Name)
+			case projectsync.KindAgent:
+				agentNames = append(agentNames, e.Name)
+			}
+		}
+	}
+
+	if len(commandNames) == 1 && commandNames[0] == "all" {
+		commands, err := s.db.ListCommands(ctx, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commands: %w", err)
+		}
+		commandNames = nil
+		for _, c := range commands {
+			commandNames = append(commandNames, c.Name)
+		}
+	}
+	if len(agentNames) == 1 && agentNames[0] == "all" {
+		agents, err := s.db.ListAgents(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list agents: %w", err)
+		}
+		agentNames = nil
+		for _, a := range agents {
+			agentNames = append(agentNames, a.Name)
+		}
+	}
+
+	var items []projectsync.Item
+	for _, name := range commandNames {
+		cmd, err := s.db.GetCommand(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get command: %w", err)
+		}
+		if !commandSyncable(cmd) {
+			if explicit {
+				return nil, fmt.Errorf("command not found: %s", name)
+			}
+			continue // Deleted upstream; Sync removes it
+		}
+		items = append(items, projectsync.CommandItem(cmd))
+	}
+	for _, name := range agentNames {
+		agent, err := s.db.GetAgent(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agent: %w", err)
+		}
+		if !agentSyncable(agent) {
+			if explicit {
+				return nil, fmt.Errorf("agent not found: %s", name)
+			}
+			continue
+		}
+		items = append(items, projectsync.AgentItem(agent))
+	}
+
+	return projectsync.Sync(projectsync.Request{
+		ProjectDir: opts.ProjectDir,
+		Items:      items,
+		Force:      opts.Force,
+		DryRun:     opts.DryRun,
+		Exists: func(kind projectsync.Kind, name string) bool {
+			switch kind {
+			case projectsync.KindCommand:
+				cmd, err := s.db.GetCommand(ctx, name)
+				return err != nil || commandSyncable(cmd) // Keep files if the store is unreachable
+			case projectsync.KindAgent:
+				agent, err := s.db.GetAgent(ctx, name)
+				return err != nil || agentSyncable(agent)
+			}
+			return true
+		},
+	})
+}
+
+// commandSyncable reports whether a command should exist in synced projects
+func commandSyncable(cmd *models.Command) bool {
+	return cmd != nil && cmd.Status != models.CommandStatusDisabled
+}
+
+// agentSyncable reports whether an agent should exist in synced projects
+func agentSyncable(agent *models.Agent) bool {
+	return agent != nil && agent.Status != models.StatusArchived && agent.Status != models.StatusBanned
+}
+
+// syncProjectResult is the output of sync_project
+type syncProjectResult struct {
+	Changes []projectsync.Change `json:"changes"`
+	Count   int                  `json:"count"`
+	DryRun  bool                 `json:"dry_run"`
+}
+
+// syncProject writes commands and agents into a project's .claude directory
+func (s *ServerV2) syncProject(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// project_dir is a path on the server, so only local sessions may write there
+	if !auth.IsLocal(ctx) {
+		return mcp.NewToolResultError("sync_project writes to the server's filesystem and is only available over stdio"), nil
+	}
+	opts := syncOptions{
+		ProjectDir: getArgString(req, "project_dir"),
+		Commands:   parseList(getArgString(req, "commands")),
+		Agents:     parseList(getArgString(req, "agents")),
+		Force:      getArgBool(req, "force"),
+		DryRun:     getArgBool(req, "dry_run"),
+	}
+
+	changes, err := s.runSync(ctx, opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("sync failed: %v", err)), nil
+	}
+
+	return toolschema.Result(syncProjectResult{
+		Changes: changes,
+		Count:   len(changes),
+		DryRun:  opts.DryRun,
+	}), nil
+}
+
+// runSyncCommand implements `agentmcp sync`, printing one line per change
+func runSyncCommand(srv *ServerV2, opts syncOptions) error {
+	changes, err := srv.runSync(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("Nothing to sync (select items with -commands/-agents, or use \"all\")")
+		return nil
+	}
+	for _, c := range changes {
+		line := fmt.Sprintf("%-9s %-7s %s", c.Action, c.Kind, c.Path)
+		if c.Detail != "" {
+			line += " (" + c.Detail + ")"
+		}
+		fmt.Println(line)
+	}
+	if opts.DryRun {
+		fmt.Println("Dry run: no files were changed")
+	}
+	return nil
+}
+
+// ============ Embedding Re-indexing ============
+
+// defaultReindexBatchSize is how many rows are embedded per EmbedBatch call
+const defaultReindexBatchSize = 32
+
+// reindexOptions selects what runReindex re-embeds
+type reindexOptions struct {
+	Kinds     []models.EmbeddingKind
+	BatchSize int
+}
+
+// reindexProgress reports one kind's state during and after a re-index
+type reindexProgress struct {
+	Kind      models.EmbeddingKind `json:"kind"`
+	Total     int                  `json:"total"`
+	Current   int                  `json:"current"`
+	Reindexed int                  `json:"reindexed"`
+}
+
+// parseEmbeddingKinds turns names such as "agents,skills" into kinds;
+// an empty list or "all" selects every kind
+func parseEmbeddingKinds(names []string) ([]models.EmbeddingKind, error) {
+	if len(names) == 0 || (len(names) == 1 && names[0] == "all") {
+		return models.EmbeddingKinds, nil
+	}
+	var kinds []models.EmbeddingKind
+	for _, name := range names {
+		kind := models.EmbeddingKind(strings.TrimSuffix(strings.ToLower(name), "s"))
+		if !slices.Contains(models.EmbeddingKinds, kind) {
+			return nil, fmt.Errorf("unknown kind %q (use: agents, skills, commands, all)", name)
+		}
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// agentSource, skillSource and commandSource describe what is embedded for each entity
+func agentSource(a *models.Agent) models.EmbeddingSource {
+	return models.EmbeddingSource{Kind: models.EmbeddingKindAgent, ID: a.ID, Name: a.Name, Description: a.Description, Skills: a.Skills}
+}
+
+func skillSource(sk *models.Skill) models.EmbeddingSource {
+	return models.EmbeddingSource{Kind: models.EmbeddingKindSkill, ID: sk.ID, Name: sk.Name, Description: sk.Description, Content: sk.Content}
+}
+
+func commandSource(c *models.Command) models.EmbeddingSource {
+	return models.EmbeddingSource{Kind: models.EmbeddingKindCommand, ID: c.ID, Name: c.Name, Description: c.Description, Content: c.Prompt}
+}
+
+// embeddingText is the text embedded for src; registration and re-indexing must agree on it
+func embeddingText(src models.EmbeddingSource) string {
+	if src.Kind == models.EmbeddingKindAgent {
+		return embeddings.AgentText(src.Name, src.Description, src.Skills)
+	}
+	return src.Name + " " + src.Description + " " + src.Content
+}
+
+// embedSource embeds src and returns the vector with the model that produced it.
+// Callers check s.embedder first.
+func (s *ServerV2) embedSource(ctx context.Context, src models.EmbeddingSource) (*pgvector.Vector, string, error) {
+	emb, err := s.embedder.Embed(ctx, embeddingText(src))
+	if err != nil {
+		return nil, "", err
+	}
+	return &emb, s.embedder.Model(), nil
+}
+
+// checkEmbeddingDimensions verifies that engine vectors fit every embedding column
+func checkEmbeddingDimensions(ctx context.Context, store database.Store, engine embeddings.Engine) error {
+	for _, kind := range models.EmbeddingKinds {
+		dim, err := store.EmbeddingDimension(ctx, kind)
+		if err != nil {
+			return fmt.Errorf("failed to read %s embedding dimension: %w", kind, err)
+		}
+		if dim > 0 && dim != engine.Dimension() {
+			return fmt.Errorf("embedding engine %s produces %d dimensions but the %s embedding column holds %d",
+				engine.Model(), engine.Dimension(), kind, dim)
+		}
+	}
+	return nil
+}
+
+// runReindex re-embeds every selected row whose embedding is missing or came
+// from another model. Each batch is saved as soon as it is embedded, so an
+// interrupted run picks up where it stopped.
+func (s *ServerV2) runReindex(ctx context.Context, opts reindexOptions, progress func(reindexProgress)) ([]reindexProgress, error) {
+	if s.embedder == nil {
+		return nil, errors.New(errNoEmbedder)
+	}
+	if err := checkEmbeddingDimensions(ctx, s.db, s.embedder); err != nil {
+		return nil, err
+	}
+	if !s.reindexing.CompareAndSwap(false, true) {
+		return nil, errors.New("a re-index is already running")
+	}
+	defer s.reindexing.Store(false)
+
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = models.EmbeddingKinds
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReindexBatchSize
+	}
+	model := s.embedder.Model()
+
+	results := []reindexProgress{}
+	for _, kind := range kinds {
+		counts, err := s.db.CountEmbeddings(ctx, kind, model)
+		if err != nil {
+			return results, err
+		}
+		p := reindexProgress{Kind: kind, Total: counts.Total, Current: counts.Current}
+
+		for p.Current < p.Total {
+			if err := ctx.Err(); err != nil {
+				return append(results, p), err
+			}
+			batch, err := s.db.ListStaleEmbeddings(ctx, kind, model, batchSize)
+			if err != nil {
+				return append(results, p), err
+			}
+			if len(batch) == 0 {
+				break
+			}
+
+			texts := make([]string, len(batch))
+			for i, src := range batch {
+				texts[i] = embeddingText(src)
+			}
+			vectors, err := s.embedder.EmbedBatch(ctx, texts)
+			if err != nil {
+				return append(results, p), fmt.Errorf("embedding %ss failed: %w", kind, err)
+			}
+			for i, src := range batch {
+				if err := s.db.SetEmbedding(ctx, src, vectors[i], model); err != nil {
+					return append(results, p), fmt.Errorf("saving %s %s failed: %w", kind, src.Name, err)
+				}
+			}
+
+			counts, err := s.db.CountEmbeddings(ctx, kind, model)
+			if err != nil {
+				return append(results, p), err
+			}
+			// Rows edited mid-batch are skipped and listed again; give up
+			// rather than spin if nothing at all was saved
+			if counts.Current <= p.Current {
+				return append(results, p), fmt.Errorf("no %ss could be saved; they are being modified concurrently, retry later", kind)
+			}
+			p.Reindexed += counts.Current - p.Current
+			p.Total, p.Current = counts.Total, counts.Current
+			if progress != nil {
+				progress(p)
+			}
+		}
+		results = append(results, p)
+	}
+	return results, nil
+}
+
+// reindexResult is the output of reindex_embeddings. A background run
+// reports only that it started; a foreground run reports each kind.
+type reindexResult struct {
+	Status  string            `json:"status"`
+	Message string            `json:"message,omitempty"`
+	Model   string            `json:"model,omitempty"`
+	Results []reindexProgress `json:"results,omitempty"`
+}
+
+// reindexEmbeddings re-embeds stale rows, reporting MCP progress when the
+// caller asks for it, or starts the same work in the background
+func (s *ServerV2) reindexEmbeddings(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kinds, err := parseEmbeddingKinds(parseList(getArgString(req, "kinds")))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts := reindexOptions{Kinds: kinds, BatchSize: int(getArgFloat(req, "batch_size"))}
+
+	if getArgBool(req, "background") {
+		if s.embedder == nil {
+			return mcp.NewToolResultError(errNoEmbedder), nil
+		}
+		if s.reindexing.Load() {
+			return mcp.NewToolResultError("a re-index is already running"), nil
+		}
+		go func() {
+			results, err := s.runReindex(context.Background(), opts, logReindexProgress)
+			if err != nil {
+				log.Printf("[WARN] Background re-index stopped: %v (run it again to resume)", err)
+				return
+			}
+			log.Printf("[INFO] Background re-index finished: %s", reindexSummary(results))
+		}()
+		return toolschema.Result(reindexResult{
+			Status:  "started",
+			Message: "Re-indexing in the background; check embedding_stats for progress",
+		}), nil
+	}
+
+	var token mcp.ProgressToken
+	if req.Params.Meta != nil {
+		token = req.Params.Meta.ProgressToken
+	}
+	progress := func(p reindexProgress) {
+		logReindexProgress(p)
+		if token == nil {
+			return
+		}
+		if srv := server.ServerFromContext(ctx); srv != nil {
+			srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progressToken": token,
+				"progress":      p.Current,
+				"total":         p.Total,
+				"message":       fmt.Sprintf("%ss: %d/%d embedded with %s", p.Kind, p.Current, p.Total, s.embedder.Model()),
+			})
+		}
+	}
+
+	results, err := s.runReindex(ctx, opts, progress)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("re-index stopped: %v (call again to resume)", err)), nil
+	}
+
+	return toolschema.Result(reindexResult{
+		Status:  "complete",
+		Model:   s.embedder.Model(),
+		Results: results,
+	}), nil
+}
+
+// logReindexProgress logs a progress update
+func logReindexProgress(p reindexProgress) {
+	log.Printf("[INFO] Re-index %ss: %d/%d current (%d this run)", p.Kind, p.Current, p.Total, p.Reindexed)
+}
+
+// reindexSummary formats results as "agent 3/3, skill 10/12"
+func reindexSummary(results []reindexProgress) string {
+	parts := make([]string, len(results))
+	for i, p := range results {
+		parts[i] = fmt.Sprintf("%s %d/%d", p.Kind, p.Current, p.Total)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runReindexCommand implements `agentmcp reindex`
+func runReindexCommand(srv *ServerV2, opts reindexOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	results, err := srv.runReindex(ctx, opts, func(p reindexProgress) {
+		fmt.Printf("%-8s %d/%d\n", p.Kind+"s", p.Current, p.Total)
+	})
+	if err != nil {
+		return fmt.Errorf("%w (run again to resume)", err)
+	}
+	for _, p := range results {
+		fmt.Printf("%-8s %d/%d current, %d re-embedded\n", p.Kind+"s", p.Current, p.Total, p.Reindexed)
+	}
+	return nil
+}
+
+// ============ MCP Resources ============
+
+// Resource URI schemes; each maps {name} to the full JSON definition
+const (
+	agentURIScheme   = "agent://"
+	skillURIScheme   = "skill://"
+	commandURIScheme = "command://"
+)
+
+func agentURI(name string) string   { return agentURIScheme + url.PathEscape(name) }
+func skillURI(name string) string   { return skillURIScheme + url.PathEscape(name) }
+func commandURI(name string) string { return commandURIScheme + url.PathEscape(name) }
+
+// agentResource describes an agent as an MCP resource
+func agentResource(a *models.Agent) mcp.Resource {
+	return mcp.NewResource(agentURI(a.Name), a.Name,
+		mcp.WithResourceDescription(a.Description),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// skillResource describes a skill as an MCP resource
+func skillResource(sk *models.Skill) mcp.Resource {
+	return mcp.NewResource(skillURI(sk.Name), sk.Name,
+		mcp.WithResourceDescription(sk.Description),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// commandResource describes a command as an MCP resource
+func commandResource(c *models.Command) mcp.Resource {
+	return mcp.NewResource(commandURI(c.Name), c.Name,
+		mcp.WithResourceDescription(c.Description),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// registerResources exposes agents, skills and commands as MCP resources.
+// Templates resolve any name; concrete resources list what is currently active.
+func (s *ServerV2) registerResources(ctx context.Context, mcpServer *server.MCPServer) error {
+	s.mcpServer = mcpServer
+
+	mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(agentURIScheme+"{name}", "Agent definition",
+			mcp.WithTemplateDescription("Full agent definition (prompt, tools, skills) by name"),
+			mcp.WithTemplateMIMEType("application/json"),
+		), s.readAgentResource)
+	mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(skillURIScheme+"{name}", "Skill content",
+			mcp.WithTemplateDescription("Skill documentation and examples by name"),
+			mcp.WithTemplateMIMEType("application/json"),
+		), s.readSkillResource)
+	mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(commandURIScheme+"{name}", "Command definition",
+			mcp.WithTemplateDescription("Slash command prompt template and arguments by name"),
+			mcp.WithTemplateMIMEType("application/json"),
+		), s.readCommandResource)
+
+	var resources []server.ServerResource
+
+	agents, err := s.db.ListAgents(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list agents: %w", err)
+	}
+	for _, a := range agents {
+		resources = append(resources, server.ServerResource{
+			Resource: mcp.NewResource(agentURI(a.Name), a.Name, mcp.WithResourceDescription(a.Description), mcp.WithMIMEType("application/json")),
+			Handler:  s.readAgentResource,
+		})
+	}
+
+	skills, err := s.db.ListSkills(ctx, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list skills: %w", err)
+	}
+	for _, sk := range skills {
+		resources = append(resources, server.ServerResource{
+			Resource: mcp.NewResource(skillURI(sk.Name), sk.Name, mcp.WithResourceDescription(sk.Description), mcp.WithMIMEType("application/json")),
+			Handler:  s.readSkillResource,
+		})
+	}
+
+	commands, err := s.db.ListCommands(ctx, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list commands: %w", err)
+	}
+	for _, c := range commands {
+		resources = append(resources, server.ServerResource{
+			Resource: mcp.NewResource(commandURI(c.Name), c.Name, mcp.WithResourceDescription(c.Description), mcp.WithMIMEType("application/json")),
+			Handler:  s.readCommandResource,
+		})
+	}
+
+	if len(resources) > 0 {
+		mcpServer.AddResources(resources...)
+	}
+	log.Printf("[INFO] Registered %d resources", len(resources))
+	return nil
+}
+
+// publishResource registers (or refreshes) a resource; mcp-go sends
+// notifications/resources/list_changed. The server does not support
+// resources/subscribe, so it sends no per-URI resources/updated.
+func (s *ServerV2) publishResource(resource mcp.Resource, handler server.ResourceHandlerFunc) {
+	if s.mcpServer == nil {
+		return
+	}
+	s.mcpServer.AddResource(resource, handler)
+}
+
+// retractResource removes a resource; mcp-go sends notifications/resources/list_changed
+func (s *ServerV2) retractResource(uri string) {
+	if s.mcpServer == nil {
+		return
+	}
+	s.mcpServer.DeleteResources(uri)
+}
+
+// resourceName extracts the {name} part of a resource URI
+func resourceName(uri, scheme string) string {
+	name := strings.TrimPrefix(uri, scheme)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+// jsonResource wraps a value as a single JSON resource content block
+func jsonResource(uri string, v any) []mcp.ResourceContents {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)},
+	}
+}
+
+// readAgentResource serves agent://{name}
+func (s *ServerV2) readAgentResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	name := resourceName(req.Params.URI, agentURIScheme)
+	agent, err := s.db.GetAgent(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	if agent == nil || agent.Status == models.StatusArchived {
+		return nil, fmt.Errorf("agent not found: %s", name)
+	}
+	return jsonResource(req.Params.URI, agent), nil
+}
+
+// readSkillResource serves skill://{name}
+func (s *ServerV2) readSkillResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	name := resourceName(req.Params.URI, skillURIScheme)
+	skill, err := s.db.GetSkill(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skill: %w", err)
+	}
+	if skill == nil {
+		return nil, fmt.Errorf("skill not found: %s", name)
+	}
+	return jsonResource(req.Params.URI, skill), nil
+}
+
+// readCommandResource serves command://{name}
+func (s *ServerV2) readCommandResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	name := resourceName(req.Params.URI, commandURIScheme)
+	cmd, err := s.db.GetCommand(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get command: %w", err)
+	}
+	if cmd == nil {
+		return nil, fmt.Errorf("command not found: %s", name)
+	}
+	return jsonResource(req.Params.URI, cmd), nil
+}
+
+// ============ MCP Prompts ============
+
+// commandPrompt describes a command as an MCP prompt. Commands that declare no
+// arguments but use $ARGUMENTS get a single free-form "arguments" argument.
+func commandPrompt(name, description, template string, args []models.Argument) mcp.Prompt {
+	opts := []mcp.PromptOption{mcp.WithPromptDescription(description)}
+	for _, arg := range args {
+		argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.Description)}
+		if arg.Required {
+			argOpts = append(argOpts, mcp.RequiredArgument())
+		}
+		opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+	}
+	if len(args) == 0 && strings.Contains(template, render.ArgumentsVar) {
+		opts = append(opts, mcp.WithArgument(render.ArgumentsKey, mcp.ArgumentDescription("Free-form arguments for the command")))
+	}
+	return mcp.NewPrompt(name, opts...)
+}
+
+// registerPrompts exposes every active command as an MCP prompt
+func (s *ServerV2) registerPrompts(ctx context.Context, mcpServer *server.MCPServer) error {
+	s.mcpServer = mcpServer
+
+	commands, err := s.db.ListCommands(ctx, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list commands: %w", err)
+	}
+
+	prompts := make([]server.ServerPrompt, 0, len(commands))
+	for _, c := range commands {
+		cmd, err := s.db.GetCommand(ctx, c.Name)
+		if err != nil || cmd == nil {
+			continue
+		}
+		prompts = append(prompts, server.ServerPrompt{
+			Prompt:  commandPrompt(cmd.Name, cmd.Description, cmd.Prompt, cmd.Arguments),
+			Handler: s.getCommandPrompt,
+		})
+	}
+
+	if len(prompts) > 0 {
+		mcpServer.AddPrompts(prompts...)
+	}
+	log.Printf("[INFO] Registered %d prompts", len(prompts))
+	return nil
+}
+
+// publishPrompt registers (or refreshes) a command's prompt; mcp-go sends
+// notifications/prompts/list_changed to connected clients
+func (s *ServerV2) publishPrompt(cmd *models.Command) {
+	if s.mcpServer == nil {
+		return
+	}
+	s.mcpServer.AddPrompt(commandPrompt(cmd.Name, cmd.Description, cmd.Prompt, cmd.Arguments), s.getCommandPrompt)
+}
+
+// getCommandPrompt serves prompts/get by rendering the command's template
+func (s *ServerV2) getCommandPrompt(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	cmd, err := s.db.GetCommand(ctx, req.Params.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get command: %w", err)
+	}
+	if cmd == nil || cmd.Status != models.CommandStatusActive {
+		return nil, fmt.Errorf("command not found: %s", req.Params.Name)
+	}
+
+	rendered, err := render.Render(cmd.Prompt, cmd.Arguments, req.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	s.db.IncrementCommandUsage(ctx, cmd.ID)
+
+	return mcp.NewGetPromptResult(cmd.Description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(rendered.Text)),
+	}), nil
+}
+
+// completeArgument suggests values for a prompt argument (from the command's
+// default and choices) or a resource template variable (from entity names)
+func (s *ServerV2) completeArgument(ctx context.Context, req mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	ref, _ := req.Params.Ref.(map[string]any)
+	refType, _ := ref["type"].(string)
+
+	var candidates []string
+	switch refType {
+	case "ref/prompt":
+		name, _ := ref["name"].(string)
+		cmd, err := s.db.GetCommand(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get command: %w", err)
+		}
+		if cmd == nil {
+			return nil, fmt.Errorf("prompt not found: %s", name)
+		}
+		for _, arg := range cmd.Arguments {
+			if arg.Name != req.Params.Argument.Name {
+				continue
+			}
+			if arg.Default != "" {
+				candidates = append(candidates, arg.Default)
+			}
+			candidates = append(candidates, arg.Choices...)
+		}
+
+	case "ref/resource":
+		uri, _ := ref["uri"].(string)
+		switch {
+		case strings.HasPrefix(uri, agentURIScheme):
+			agents, err := s.db.ListAgents(ctx, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list agents: %w", err)
+			}
+			for _, a := range agents {
+				candidates = append(candidates, a.Name)
+			}
+		case strings.HasPrefix(uri, skillURIScheme):
+			skills, err := s.db.ListSkills(ctx, "", nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list skills: %w", err)
+			}
+			for _, sk := range skills {
+				candidates = append(candidates, sk.Name)
+			}
+		case strings.HasPrefix(uri, commandURIScheme):
+			commands, err := s.db.ListCommands(ctx, "", nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list commands: %w", err)
+			}
+			for _, c := range commands {
+				candidates = append(candidates, c.Name)
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported completion reference: %q", refType)
+	}
+
+	prefix := strings.ToLower(req.Params.Argument.Value)
+	seen := make(map[string]bool)
+	var values []string
+	for _, c := range candidates {
+		if seen[c] || !strings.HasPrefix(strings.ToLower(c), prefix) {
+			continue
+		}
+		seen[c] = true
+		values = append(values, c)
+	}
+	sort.Strings(values)
+
+	result := &mcp.CompleteResult{}
+	result.Completion.Total = len(values)
+	if len(values) > maxCompletionValues {
+		values = values[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = append([]string{}, values...)
+	return result, nil
+}
+
+const (
+	// methodCompletionComplete is not among mcp-go's method constants
+	methodCompletionComplete mcp.MCPMethod = "completion/complete"
+	// maxCompletionValues is the spec limit on values per completion/complete response
+	maxCompletionValues = 100
+)
+
+// handleCompletion answers completion/complete, which mcp-go does not route.
+// It returns nil for every other message so the transport can pass it on.
+func (s *ServerV2) handleCompletion(ctx context.Context, message []byte) mcp.JSONRPCMessage {
+	var base struct {
+		ID     any           `json:"id"`
+		Method mcp.MCPMethod `json:"method"`
+	}
+	if err := json.Unmarshal(message, &base); err != nil || base.Method != methodCompletionComplete || base.ID == nil {
+		return nil
+	}
+
+	id := mcp.NewRequestId(base.ID)
+	var req mcp.CompleteRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_PARAMS, err.Error(), nil)
+	}
+	result, err := s.completeArgument(ctx, req)
+	if err != nil {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_PARAMS, err.Error(), nil)
+	}
+	return mcp.NewJSONRPCResultResponse(id, result)
+}
+
+// advertiseCompletions declares completion/complete support in the initialize
+// result. mcp-go has no field for the spec's top-level completions
+// capability, so it goes under experimental, where spec-compliant clients do
+// not look for it; they must call completion/complete to find out. Only
+// register it for transports that route the method (stdio and http, not sse).
+func advertiseCompletions(_ context.Context, _ any, _ *mcp.InitializeRequest, result *mcp.InitializeResult) {
+	if result.Capabilities.Experimental == nil {
+		result.Capabilities.Experimental = map[string]any{}
+	}
+	result.Capabilities.Experimental["completions"] = struct{}{}
+}
+
+// lockedWriter serialises writes so intercepted responses never interleave
+// with the stdio server's own output
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// serveStdio runs the stdio transport, answering completion/complete before
+// handing every other line to mcp-go
+func (s *ServerV2) serveStdio(mcpServer *server.MCPServer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	ctx = auth.WithLocal(auth.WithPrincipal(ctx, s.stdioAs))
+	log.Printf("[INFO] stdio sessions act as %s", s.stdioAs.Name)
+
+	stdout := &lockedWriter{w: os.Stdout}
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if response := s.handleCompletion(ctx, line); response != nil {
+					data, _ := json.Marshal(response)
+					stdout.Write(append(data, '\n'))
+				} else if _, werr := pw.Write(line); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return server.NewStdioServer(mcpServer).Listen(ctx, pr, stdout)
+}
+
+// completionHandler wraps a Streamable HTTP handler so completion/complete
+// POSTs are answered directly
+func (s *ServerV2) completionHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "failed to read body", http.StatusBadRequest)
+				return
+			}
+			if response := s.handleCompletion(r.Context(), body); response != nil {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(response)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate resolves the caller of every HTTP request from its token
+func (s *ServerV2) authenticate(next http.Handler) http.Handler {
+	if s.principals == nil {
+		return next
+	}
+	return s.principals.Middleware(next)
+}
+
+// ============ Task Aliases ============
+// use_agent expands tasks with synonyms from a dictionary layered from the
+// built-in defaults, the optional alias file and admin overrides in the store.
+// Reloading rebuilds it from all three without restarting the server.
+
+// reloadAliases rebuilds the dictionary from the alias file and the store.
+// On error the current dictionary stays in use.
+func (s *ServerV2) reloadAliases(ctx context.Context) error {
+	var fileEntries []aliases.Entry
+	if s.aliasFile != "" {
+		entries, err := aliases.LoadFile(s.aliasFile)
+		if err != nil {
+			return err
+		}
+		fileEntries = entries
+	}
+
+	rows, err := s.db.ListTaskAliases(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list task aliases: %w", err)
+	}
+	storeEntries := make([]aliases.Entry, len(rows))
+	for i, r := range rows {
+		storeEntries[i] = aliases.Entry{Domain: r.Domain, Keyword: r.Keyword, Aliases: r.Aliases, Source: aliases.SourceStore}
+	}
+
+	s.aliases.Load(fileEntries, storeEntries)
+	return nil
+}
+
+// watchAliases reloads the dictionary on SIGHUP and, when interval is
+// positive, on every tick until ctx is done
+func (s *ServerV2) watchAliases(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err = s.reloadAliases(ctx); err == nil {
+				log.Printf("[INFO] Reloaded %d task aliases", len(s.aliases.Entries()))
+			}
+		case <-tick:
+			err = s.reloadAliases(ctx)
+		}
+		if err != nil {
+			log.Printf("[WARN] Failed to reload task aliases: %v (keeping the current set)", err)
+		}
+	}
+}
+
+// aliasSourceCounts counts active entries by the layer they came from
+func aliasSourceCounts(entries []aliases.Entry) map[string]int {
+	counts := map[string]int{aliases.SourceBuiltin: 0, aliases.SourceFile: 0, aliases.SourceStore: 0}
+	for _, e := range entries {
+		counts[e.Source]++
+	}
+	return counts
+}
+
+// listAliasesResult is the output of list_aliases
+type listAliasesResult struct {
+	Count     int                `json:"count"`
+	Aliases   []aliases.Entry    `json:"aliases"`
+	Overrides []models.TaskAlias `json:"overrides"`
+	File      string             `json:"file"`
+}
+
+// listAliases shows the active dictionary and the overrides stored by admins
+func (s *ServerV2) listAliases(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	domain := strings.ToLower(strings.TrimSpace(getArgString(req, "domain")))
+
+	entries := []aliases.Entry{}
+	for _, e := range s.aliases.Entries() {
+		if domain == "" || e.Domain == domain {
+			entries = append(entries, e)
+		}
+	}
+
+	rows, err := s.db.ListTaskAliases(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list alias overrides: %v", err)), nil
+	}
+	overrides := []models.TaskAlias{}
+	for _, r := range rows {
+		if domain == "" || r.Domain == domain {
+			overrides = append(overrides, r)
+		}
+	}
+
+	return toolschema.Result(listAliasesResult{
+		Count:     len(entries),
+		Aliases:   entries,
+		Overrides: overrides,
+		File:      s.aliasFile,
+	}), nil
+}
+
+// setAliasResult is the output of set_alias
+type setAliasResult struct {
+	Status string            `json:"status"`
+	Alias  *models.TaskAlias `json:"alias"`
+}
+
+// setAlias stores an override for one keyword and applies it immediately.
+// An empty alias list disables the keyword's built-in or file entry.
+func (s *ServerV2) setAlias(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	entry, err := aliases.Normalize(aliases.Entry{
+		Domain:  getArgString(req, "domain"),
+		Keyword: getArgString(req, "keyword"),
+		Aliases: parseList(getArgString(req, "aliases")),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	alias := &models.TaskAlias{Domain: entry.Domain, Keyword: entry.Keyword, Aliases: entry.Aliases, UpdatedBy: "api"}
+	if err := s.db.SetTaskAlias(ctx, alias); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save alias: %v", err)), nil
+	}
+	if err := s.reloadAliases(ctx); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("alias saved but reload failed: %v", err)), nil
+	}
+
+	status := "saved"
+	if len(alias.Aliases) == 0 {
+		status = "disabled"
+	}
+	return toolschema.Result(setAliasResult{
+		Status: status,
+		Alias:  alias,
+	}), nil
+}
+
+// deleteAliasResult is the output of delete_alias. Restored is the entry
+// now in effect for the keyword, if any.
+type deleteAliasResult struct {
+	Status   string         `json:"status"`
+	Domain   string         `json:"domain"`
+	Keyword  string         `json:"keyword"`
+	Restored *aliases.Entry `json:"restored"`
+}
+
+// deleteAlias removes a stored override, restoring the file or built-in entry
+// for the keyword if there is one
+func (s *ServerV2) deleteAlias(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	entry, err := aliases.Normalize(aliases.Entry{
+		Domain:  getArgString(req, "domain"),
+		Keyword: getArgString(req, "keyword"),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	// Security related
-	"security": {"secure", "vulnerability", "vulnerabilities", "exploit", "attack", "pentest"},
-	"audit":    {"review", "check", "assess", "examine"},
+	deleted, err := s.db.DeleteTaskAlias(ctx, entry.Domain, entry.Keyword)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete alias: %v", err)), nil
+	}
+	if !deleted {
+		return mcp.NewToolResultError(fmt.Sprintf("no alias override for %s/%s", entry.Domain, entry.Keyword)), nil
+	}
+	if err := s.reloadAliases(ctx); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("alias deleted but reload failed: %v", err)), nil
+	}
 
-	// Documentation related
-	"docs":  {"documentation", "document", "readme", "guide", "manual", "explain"},
-	"write": {"create", "generate", "draft", "compose"},
+	var restored *aliases.Entry
+	for _, e := range s.aliases.Entries() {
+		if e.Domain == entry.Domain && e.Keyword == entry.Keyword {
+			restored = &e
+			break
+		}
+	}
+	return toolschema.Result(deleteAliasResult{
+		Status:   "deleted",
+		Domain:   entry.Domain,
+		Keyword:  entry.Keyword,
+		Restored: restored,
+	}), nil
+}
 
-	// Data related
-	"data":    {"dataset", "database", "analytics", "statistics", "metrics"},
-	"analyze": {"analysis", "examine", "study", "investigate", "explore"},
-	"charts":  {"graphs", "visualization", "visualize", "plots", "dashboard", "report"},
+// reloadAliasesResult is the output of reload_aliases
+type reloadAliasesResult struct {
+	Status   string         `json:"status"`
+	Count    int            `json:"count"`
+	BySource map[string]int `json:"by_source"`
+}
 
-	// Architecture related
-	"architecture": {"design", "structure", "system", "scalable", "microservices"},
-	"scale":        {"scalability", "scaling", "performance", "optimize", "load"},
-	"performance":  {"optimize", "speed", "fast", "slow", "bottleneck", "efficient", "optimization"},
-	"improve":      {"better", "enhance", "upgrade", "fix", "refine", "polish", "quality"},
-	"quality":      {"better", "improve", "good", "clean", "nice", "readable"},
+// reloadAliasesTool re-reads the alias file and store overrides
+func (s *ServerV2) reloadAliasesTool(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := s.reloadAliases(ctx); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("reload failed, keeping the current aliases: %v", err)), nil
+	}
 
-	// DevOps related
-	"deploy": {"deployment", "release", "ship", "publish", "rollout"},
-	"devops": {"ci", "cd", "pipeline", "cicd", "ci/cd", "infrastructure", "infra"},
-	"docker": {"container", "containerize", "kubernetes", "k8s"},
+	entries := s.aliases.Entries()
+	return toolschema.Result(reloadAliasesResult{
+		Status:   "reloaded",
+		Count:    len(entries),
+		BySource: aliasSourceCounts(entries),
+	}), nil
 }
 
+// ============ Meta Tools ============
+
 // containsWord checks if a word exists as a complete word in text (word boundary matching)
 func containsWord(text, word string) bool {
 	words := strings.Fields(text)
@@ -1149,57 +3595,125 @@ func containsWord(text, word string) bool {
 	return false
 }
 
-// expandTask expands a task with aliases for better matching using word boundaries
-func expandTask(task string) string {
-	taskLower := strings.ToLower(task)
-	taskWords := strings.Fields(taskLower)
-	wordSet := make(map[string]bool)
-	for _, w := range taskWords {
-		wordSet[w] = true
-	}
+// defaultExplainCandidates is how many candidates explain=true reports
+const defaultExplainCandidates = 5
+
+// matchCandidate is one ranked candidate in an explain=true response
+type matchCandidate struct {
+	Rank       int                 `json:"rank"`
+	Name       string              `json:"name"`
+	Reputation float64             `json:"reputation"`
+	Status     string              `json:"status"`
+	Scores     models.SearchScores `json:"scores"`
+	Outcome    string              `json:"outcome"`
+}
 
-	expanded := task
+// matchExplanation shows how use_agent or use_skill ranked its candidates
+type matchExplanation struct {
+	Task          string              `json:"task"`
+	SearchText    string              `json:"search_text"`
+	Expansions    []aliases.Expansion `json:"alias_expansions"`
+	Semantic      bool                `json:"semantic"`
+	SemanticError string              `json:"semantic_error,omitempty"`
+	MinSimilarity float64             `json:"min_similarity"`
+	Candidates    []matchCandidate    `json:"candidates"`
+}
 
-	// Sort keys for deterministic output (Go maps have random iteration order)
-	keys := make([]string, 0, len(taskAliases))
-	for k := range taskAliases {
-		keys = append(keys, k)
-	}
-	// Simple sort for determinism
-	for i := 0; i < len(keys)-1; i++ {
-		for j := i + 1; j < len(keys); j++ {
-			if keys[i] > keys[j] {
-				keys[i], keys[j] = keys[j], keys[i]
-			}
+// explainMatches ranks cands, which are in search order, and says why each
+// runner-up lost to the first
+func explainMatches(task string, expansions []aliases.Expansion, q models.SearchQuery, embedErr error, cands []matchCandidate) *matchExplanation {
+	e := &matchExplanation{
+		Task:          task,
+		SearchText:    q.Text,
+		Expansions:    expansions,
+		Semantic:      q.Embedding != nil,
+		MinSimilarity: q.MinSimilarity,
+		Candidates:    cands,
+	}
+	if embedErr != nil {
+		e.SemanticError = embedErr.Error()
+	}
+	for i := range cands {
+		cands[i].Rank = i + 1
+		if i == 0 {
+			cands[i].Outcome = "selected"
+		} else {
+			cands[i].Outcome = "lost: " + e.lossReason(cands[0], cands[i])
 		}
 	}
+	return e
+}
 
-	for _, key := range keys {
-		aliases := taskAliases[key]
-		// Check if any alias word is in the task
-		for _, alias := range aliases {
-			if wordSet[alias] {
-				// Add the main keyword if not already present
-				if !wordSet[key] {
-					expanded += " " + key
-					wordSet[key] = true
-				}
-				break
-			}
+// lossReason explains why loser ranked below winner
+func (e *matchExplanation) lossReason(winner, loser matchCandidate) string {
+	w, l := winner.Scores, loser.Scores
+	if l.Score == w.Score {
+		if loser.Reputation != winner.Reputation {
+			return fmt.Sprintf("tied with %s on fused score; lower reputation (%.1f vs %.1f)", winner.Name, loser.Reputation, winner.Reputation)
 		}
-		// Check if key is in task
-		if wordSet[key] {
-			// Add ALL aliases for better skill matching
-			for _, alias := range aliases {
-				if !wordSet[alias] {
-					expanded += " " + alias
-					wordSet[alias] = true
-				}
-			}
+		return fmt.Sprintf("tied with %s on fused score and reputation; %s sorts first by name", winner.Name, winner.Name)
+	}
+
+	var reasons []string
+	switch {
+	case l.TextRank == 0:
+		reasons = append(reasons, "no keyword hits")
+	case w.TextRank > 0 && l.TextRank > w.TextRank:
+		reasons = append(reasons, fmt.Sprintf("keyword rank #%d vs #%d (%d vs %d terms hit)",
+			l.TextRank, w.TextRank, len(l.KeywordHits), len(w.KeywordHits)))
+	}
+	switch {
+	case w.FuzzyRank > 0 && l.FuzzyRank == 0:
+		reasons = append(reasons, "no typo-tolerant matches")
+	case w.FuzzyRank > 0 && l.FuzzyRank > w.FuzzyRank:
+		reasons = append(reasons, fmt.Sprintf("typo-tolerant rank #%d vs #%d", l.FuzzyRank, w.FuzzyRank))
+	}
+	if e.Semantic {
+		switch {
+		case l.VectorRank == 0 && l.Similarity < e.MinSimilarity:
+			reasons = append(reasons, fmt.Sprintf("similarity %.0f%% is below the %.0f%% minimum", l.Similarity*100, e.MinSimilarity*100))
+		case l.VectorRank == 0:
+			reasons = append(reasons, "not among the nearest embeddings")
+		case w.VectorRank > 0 && l.VectorRank > w.VectorRank:
+			reasons = append(reasons, fmt.Sprintf("semantic rank #%d (%.0f%%) vs #%d (%.0f%%)",
+				l.VectorRank, l.Similarity*100, w.VectorRank, w.Similarity*100))
 		}
 	}
 
-	return expanded
+	reason := fmt.Sprintf("fused score %.4f vs %.4f for %s", l.Score, w.Score, winner.Name)
+	if len(reasons) > 0 {
+		reason += ": " + strings.Join(reasons, "; ")
+	}
+	return reason
+}
+
+// agentMatch is the output of use_agent when an agent was found
+type agentMatch struct {
+	Found        bool                `json:"found"`
+	MatchMethod  string              `json:"match_method"`
+	Scores       models.SearchScores `json:"scores"`
+	Agent        agentConfig         `json:"agent"`
+	Instructions string              `json:"instructions"`
+	Explain      *matchExplanation   `json:"explain,omitempty"`
+}
+
+// agentConfig is the part of an agent use_agent hands to the caller
+type agentConfig struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Model       string   `json:"model"`
+	Skills      []string `json:"skills"`
+	Prompt      string   `json:"prompt"`
+	Tools       []string `json:"tools"`
+}
+
+// agentMiss is the output of use_agent when no agent matched
+type agentMiss struct {
+	Found           bool              `json:"found"`
+	Message         string            `json:"message"`
+	Suggestions     []string          `json:"suggestions"`
+	AvailableAgents []string          `json:"available_agents"`
+	Explain         *matchExplanation `json:"explain,omitempty"`
 }
 
 // useAgent finds the best agent for a task and returns its configuration
@@ -1212,51 +3726,37 @@ func (s *ServerV2) useAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultError(fmt.Sprintf("task too long (max %d characters)", maxTaskLength)), nil
 	}
 
-	// Expand task with aliases for better matching
-	expandedTask := expandTask(task)
-
-	// First try semantic search if embeddings are available
-	var bestAgent *models.Agent
-	var matchMethod string
-	var matchScore float64
-
-	if s.embedder != nil {
-		embedding, err := s.embedder.Embed(ctx, expandedTask)
-		if err == nil {
-			// Lower threshold to 0.25 for better recall
-			similar, _ := s.db.FindSimilarAgents(ctx, embedding, 3, 0.25)
-			if len(similar) > 0 {
-				bestAgent, _ = s.db.GetAgentByID(ctx, similar[0].Agent.ID)
-				matchScore = similar[0].Similarity
-				matchMethod = fmt.Sprintf("semantic (%.0f%% match)", matchScore*100)
-			}
+	explain := getArgBool(req, "explain")
+	limit := 1
+	if explain {
+		if limit = int(getArgFloat(req, "candidates")); limit <= 0 {
+			limit = defaultExplainCandidates
 		}
 	}
 
-	// Fallback to keyword search - try each word individually
-	if bestAgent == nil {
-		// First try the original task
-		agents, err := s.db.SearchAgents(ctx, task)
-		if err == nil && len(agents) > 0 {
-			bestAgent, _ = s.db.GetAgentByID(ctx, agents[0].ID)
-			matchMethod = "keyword"
-		}
+	// Expand task with aliases for better matching
+	expandedTask, expansions := s.aliases.Expand(task)
 
-		// If no match, try individual words from expanded task
-		if bestAgent == nil {
-			words := strings.Fields(expandedTask)
-			for _, word := range words {
-				if len(word) < 3 { // Skip short words
-					continue
-				}
-				agents, err := s.db.SearchAgents(ctx, word)
-				if err == nil && len(agents) > 0 {
-					bestAgent, _ = s.db.GetAgentByID(ctx, agents[0].ID)
-					matchMethod = fmt.Sprintf("keyword (%s)", word)
-					break
-				}
-			}
+	q, embedErr := s.searchQuery(ctx, expandedTask, limit, useMinSimilarity)
+	if embedErr != nil {
+		log.Printf("[WARN] %v (matching agents by keyword only)", embedErr)
+	}
+
+	var bestAgent *models.Agent
+	var scores models.SearchScores
+	matches, err := s.db.HybridSearchAgents(ctx, q)
+	if err == nil && len(matches) > 0 {
+		bestAgent, _ = s.db.GetAgentByID(ctx, matches[0].Agent.ID)
+		scores = matches[0].Scores
+	}
+
+	var explanation *matchExplanation
+	if explain {
+		cands := make([]matchCandidate, len(matches))
+		for i, m := range matches {
+			cands[i] = matchCandidate{Name: m.Agent.Name, Reputation: m.Agent.ReputationScore, Status: string(m.Agent.Status), Scores: m.Scores}
 		}
+		explanation = explainMatches(task, expansions, q, embedErr, cands)
 	}
 
 	// No match found - provide helpful suggestions
@@ -1278,215 +3778,233 @@ func (s *ServerV2) useAgent(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 			availableNames = append(availableNames, a.Name)
 		}
 
-		result, _ := json.MarshalIndent(map[string]any{
-			"found":            false,
-			"message":          fmt.Sprintf("No matching agent found for: %s", task),
-			"suggestions":      suggestions,
-			"available_agents": availableNames,
-		}, "", "  ")
-		return mcp.NewToolResultText(string(result)), nil
+		return toolschema.Result(agentMiss{
+			Found:           false,
+			Message:         fmt.Sprintf("No matching agent found for: %s", task),
+			Suggestions:     suggestions,
+			AvailableAgents: availableNames,
+			Explain:         explanation,
+		}), nil
 	}
 
 	// Increment usage
 	s.db.IncrementUsage(ctx, bestAgent.ID)
 
 	// Return agent configuration for the LLM to adopt
-	result, _ := json.MarshalIndent(map[string]any{
-		"found":        true,
-		"match_method": matchMethod,
-		"agent": map[string]any{
-			"name":        bestAgent.Name,
-			"description": bestAgent.Description,
-			"model":       bestAgent.Model,
-			"skills":      bestAgent.Skills,
-			"prompt":      bestAgent.Prompt,
-			"tools":       bestAgent.Tools,
+	return toolschema.Result(agentMatch{
+		Found:       true,
+		MatchMethod: matchMethod(scores),
+		Scores:      scores,
+		Agent: agentConfig{
+			Name:        bestAgent.Name,
+			Description: bestAgent.Description,
+			Model:       bestAgent.Model,
+			Skills:      bestAgent.Skills,
+			Prompt:      bestAgent.Prompt,
+			Tools:       bestAgent.Tools,
 		},
-		"instructions": "Adopt this agent's persona and use its prompt as guidance for the task. " +
+		Instructions: "Adopt this agent's persona and use its prompt as guidance for the task. " +
 			"Follow the agent's specialized approach and expertise.",
-	}, "", "  ")
-
-	return mcp.NewToolResultText(string(result)), nil
+		Explain: explanation,
+	}), nil
 }
 
-func main() {
-	// CLI flags
-	dbHost := flag.String("db-host", getEnvOrDefault("DB_HOST", "localhost"), "Database host")
-	dbPort := flag.Int("db-port", getEnvOrDefaultInt("DB_PORT", 5432), "Database port")
-	dbName := flag.String("db-name", getEnvOrDefault("DB_NAME", "mcp_serve"), "Database name")
-	dbUser := flag.String("db-user", getEnvOrDefault("DB_USER", "mcp"), "Database user")
-	dbPass := flag.String("db-pass", os.Getenv("DB_PASSWORD"), "Database password")
-
-	embeddingType := flag.String("embedding-type", getEnvOrDefault("EMBEDDING_TYPE", "http"), "Embedding type: python or http")
-	embeddingURL := flag.String("embedding-url", getEnvOrDefault("EMBEDDING_URL", "http://localhost:8081"), "Embedding service URL")
-
-	anthropicKey := flag.String("anthropic-key", os.Getenv("ANTHROPIC_API_KEY"), "Anthropic API key")
-
-	transport := flag.String("transport", getEnvOrDefault("MCP_TRANSPORT", "stdio"), "Transport: stdio or sse")
-	port := flag.String("port", getEnvOrDefault("MCP_PORT", "8080"), "HTTP port")
-
-	migrate := flag.Bool("migrate", getEnvOrDefaultBool("AUTO_MIGRATE", false), "Run database migrations")
-	migrateOnly := flag.Bool("migrate-only", false, "Run migrations and exit")
-
-	version := flag.Bool("version", false, "Print version")
-	flag.Parse()
-
-	if *version {
-		fmt.Printf("agentmcp v%s\n", VERSION)
-		os.Exit(0)
-	}
-
-	log.Printf("[INFO] Starting agentmcp v%s", VERSION)
-
-	// Initialize database
-	db, err := database.New(database.Config{
-		Host:     *dbHost,
-		Port:     *dbPort,
-		Database: *dbName,
-		User:     *dbUser,
-		Password: *dbPass,
-		MaxConns: 20,
-	})
-	if err != nil {
-		log.Fatalf("[FATAL] Database connection failed: %v", err)
-	}
-	defer db.Close()
-	log.Println("[INFO] Database connected")
-
-	// Run migrations if requested
-	if *migrate || *migrateOnly {
-		log.Println("[INFO] Running database migrations...")
-		runner := migrations.NewRunner(db.Pool(), sqlmigrations.Files)
-		if err := runner.Run(context.Background()); err != nil {
-			log.Fatalf("[FATAL] Migration failed: %v", err)
-		}
-		log.Println("[INFO] Migrations completed successfully")
-
-		if *migrateOnly {
-			log.Println("[INFO] Migration-only mode, exiting")
-			os.Exit(0)
-		}
-	}
-
-	// Initialize embeddings
-	var embedder embeddings.Engine
-	embedCfg := embeddings.Config{
-		Type:         *embeddingType,
-		HTTPEndpoint: *embeddingURL,
-		Timeout:      30 * time.Second,
-	}
-	embedder, err = embeddings.NewEngine(embedCfg)
-	if err != nil {
-		log.Printf("[WARN] Embedding engine failed: %v (semantic search disabled)", err)
-	} else {
-		log.Println("[INFO] Embedding engine initialized")
-	}
-
-	// Initialize generator
-	var gen *generator.Generator
-	if *anthropicKey != "" {
-		gen, err = generator.New(generator.Config{
-			APIKey:    *anthropicKey,
-			Model:     "claude-sonnet-4-20250514",
-			MaxTokens: 4096,
-			Timeout:   60 * time.Second,
-		})
-		if err != nil {
-			log.Printf("[WARN] Generator failed: %v (agent generation disabled)", err)
-		} else {
-			log.Println("[INFO] Agent generator initialized")
-		}
-	}
-
-	// Initialize governance
-	gov := governance.New(db, governance.DefaultConfig())
-	log.Println("[INFO] Governance engine initialized")
-
-	// Create server
-	srv := NewServerV2(db, embedder, gen, gov)
-
-	// Create MCP server
-	mcpServer := server.NewMCPServer("agentmcp", VERSION)
+// ============ Tool Registration ============
 
+// registerTools adds every tool to mcpServer, each declaring the schema of
+// its structured output
+func (s *ServerV2) registerTools(mcpServer *server.MCPServer) {
 	// Register original tools
-	mcpServer.AddTool(mcp.NewTool("list_agents",
-		mcp.WithDescription("List all available agents. Optionally filter by tags."),
-		mcp.WithString("tags", mcp.Description("Comma-separated list of tags to filter by")),
-	), srv.listAgents)
+	catalogSorts := database.CatalogSorts()
+	mcpServer.AddTool(mcp.NewTool("list_agents", slices.Concat([]mcp.ToolOption{
+		mcp.WithDescription("List available agents a page at a time. Filter by tags, model, status, origin or creation date and sort by reputation, rating, usage, recency or name. Pass next_cursor back as cursor for the next page."),
+		toolschema.Output[listAgentsResult](),
+	}, catalogFilterArgs(models.EmbeddingKindAgent), pageArgs(catalogSorts, "reputation"))...,
+	), s.listAgents)
 
 	mcpServer.AddTool(mcp.NewTool("get_agent",
 		mcp.WithDescription("Get complete agent definition by name."),
+		mcp.WithRawOutputSchema(toolschema.AnyOf(toolschema.Of[models.Agent](), toolschema.Of[models.AgentRevision]())),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the agent to retrieve")),
-	), srv.getAgent)
+		mcp.WithString("version", mcp.Description("Return a specific historical version (default: current)")),
+	), s.getAgent)
 
-	mcpServer.AddTool(mcp.NewTool("search_agents",
-		mcp.WithDescription("Search agents by keyword."),
+	mcpServer.AddTool(mcp.NewTool("search_agents", slices.Concat([]mcp.ToolOption{
+		mcp.WithDescription("Search agents by keyword and meaning. Results are ranked by fusing full-text, typo-tolerant trigram and embedding rankings, with per-signal scores; when no word matches exactly, did_you_mean suggests a respelled query. Pass next_cursor back as cursor for more results."),
+		toolschema.Output[searchResult[models.AgentSearchResult]](),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Search query string")),
-	), srv.searchAgents)
+		mcp.WithNumber("limit", mcp.Description("Maximum number of results per page (default 20)")),
+		mcp.WithString("cursor", mcp.Description("next_cursor from the previous page; omit for the first page")),
+	}, catalogFilterArgs(models.EmbeddingKindAgent))...,
+	), s.searchAgents)
 
 	// Register v2 tools
 	mcpServer.AddTool(mcp.NewTool("find_similar_agents",
 		mcp.WithDescription("Find semantically similar agents using AI embeddings."),
+		toolschema.Output[similarAgentsResult](),
 		mcp.WithString("description", mcp.Description("Description to find similar agents for")),
 		mcp.WithString("skills", mcp.Description("Comma-separated list of skills to search for")),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of results (default 5)")),
-		mcp.WithNumber("threshold", mcp.Description("Similarity threshold 0-1 (default 0.7)")),
-	), srv.findSimilarAgents)
+		mcp.WithNumber("threshold", mcp.Description("Minimum similarity 0-1 for semantic matches (default 0.15)")),
+	), s.findSimilarAgents)
 
 	mcpServer.AddTool(mcp.NewTool("request_agent_by_skills",
 		mcp.WithDescription("Request an agent with specific skills. Will find existing or generate new."),
+		toolschema.Output[agentRequestResult](),
 		mcp.WithString("skills", mcp.Required(), mcp.Description("Comma-separated list of required skills")),
 		mcp.WithBoolean("create_if_missing", mcp.Description("Generate new agent if none matches (default false)")),
-	), srv.requestAgentBySkills)
+	), s.requestAgentBySkills)
 
 	mcpServer.AddTool(mcp.NewTool("submit_feedback",
 		mcp.WithDescription("Submit performance feedback for an agent (rating 1-5)."),
+		toolschema.Output[statusResult](),
 		mcp.WithString("agent_name", mcp.Required(), mcp.Description("Name of the agent")),
 		mcp.WithNumber("rating", mcp.Required(), mcp.Description("Rating from 1-5")),
 		mcp.WithBoolean("task_success", mcp.Description("Whether the task was successful")),
 		mcp.WithString("task_type", mcp.Description("Type of task performed")),
 		mcp.WithString("feedback_text", mcp.Description("Optional feedback text")),
-	), srv.submitFeedback)
+	), s.submitFeedback)
 
 	mcpServer.AddTool(mcp.NewTool("get_agent_reputation",
 		mcp.WithDescription("Get detailed reputation information for an agent."),
+		toolschema.Output[models.AgentReputation](),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the agent")),
-	), srv.getAgentReputation)
+	), s.getAgentReputation)
 
 	mcpServer.AddTool(mcp.NewTool("get_top_agents",
 		mcp.WithDescription("Get the highest-rated agents, optionally by category."),
+		toolschema.Output[topAgentsResult](),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of results (default 10)")),
 		mcp.WithString("category", mcp.Description("Optional category/skill to filter by")),
-	), srv.getTopAgents)
+	), s.getTopAgents)
+
+	mcpServer.AddTool(mcp.NewTool("embedding_stats",
+		mcp.WithDescription("Get embedding engine status, per-kind index coverage and cache hit/miss statistics."),
+		toolschema.Output[embeddingStatsResult](),
+	), s.embeddingStats)
+
+	mcpServer.AddTool(mcp.NewTool("reindex_embeddings",
+		mcp.WithDescription("[Admin] Re-embed agents, skills and commands that have no embedding or were embedded by another model. Safe to re-run after an interruption; sends progress notifications when a progress token is given."),
+		toolschema.Output[reindexResult](),
+		mcp.WithString("kinds", mcp.Description("Comma-separated: agents, skills, commands (default all)")),
+		mcp.WithNumber("batch_size", mcp.Description("Rows embedded per batch (default 32)")),
+		mcp.WithBoolean("background", mcp.Description("Return immediately and re-index in the background (default false)")),
+	), s.reindexEmbeddings)
+
+	// Register alias administration tools
+	mcpServer.AddTool(mcp.NewTool("list_aliases",
+		mcp.WithDescription("[Admin] List the task aliases use_agent expands tasks with, and the overrides stored by admins."),
+		toolschema.Output[listAliasesResult](),
+		mcp.WithString("domain", mcp.Description("Only show this domain, e.g. devops or testing")),
+	), s.listAliases)
+
+	mcpServer.AddTool(mcp.NewTool("set_alias",
+		mcp.WithDescription("[Admin] Set the aliases for a keyword, overriding the built-in and file entries. Takes effect immediately."),
+		toolschema.Output[setAliasResult](),
+		mcp.WithString("keyword", mcp.Required(), mcp.Description("Single-word keyword, e.g. k8s")),
+		mcp.WithString("aliases", mcp.Description("Comma-separated single-word synonyms; empty disables the keyword")),
+		mcp.WithString("domain", mcp.Description("Domain grouping the keyword (default general)")),
+	), s.setAlias)
+
+	mcpServer.AddTool(mcp.NewTool("delete_alias",
+		mcp.WithDescription("[Admin] Remove a stored alias override, restoring the built-in or file entry if there is one."),
+		toolschema.Output[deleteAliasResult](),
+		mcp.WithString("keyword", mcp.Required(), mcp.Description("Keyword of the override")),
+		mcp.WithString("domain", mcp.Description("Domain of the override (default general)")),
+	), s.deleteAlias)
+
+	mcpServer.AddTool(mcp.NewTool("reload_aliases",
+		mcp.WithDescription("[Admin] Re-read the alias file and stored overrides. Sending SIGHUP to the server does the same."),
+		toolschema.Output[reloadAliasesResult](),
+	), s.reloadAliasesTool)
 
 	// Register governance tools
 	mcpServer.AddTool(mcp.NewTool("report_agent",
 		mcp.WithDescription("Report an agent for governance review."),
+		toolschema.Output[reportAgentResult](),
 		mcp.WithString("agent_name", mcp.Required(), mcp.Description("Name of the agent to report")),
 		mcp.WithString("report_type", mcp.Required(), mcp.Description("Type: harmful_output, prompt_injection, policy_violation, performance, other")),
 		mcp.WithString("severity", mcp.Required(), mcp.Description("Severity: low, medium, high, critical")),
 		mcp.WithString("description", mcp.Required(), mcp.Description("Detailed description of the issue")),
-	), srv.reportAgent)
-
-	mcpServer.AddTool(mcp.NewTool("review_reports",
-		mcp.WithDescription("[Governance] View pending reports for review."),
-	), srv.reviewReports)
+	), s.reportAgent)
+
+	mcpServer.AddTool(mcp.NewTool("review_reports", slices.Concat([]mcp.ToolOption{
+		mcp.WithDescription("[Governance] View pending reports for review, most severe first, and the queue of pending appeals. Requires a governance role. Pass next_cursor back as cursor for the next page."),
+		toolschema.Output[reviewReportsResult](),
+		mcp.WithString("status", mcp.Description("pending, reviewing, resolved or 'all' (default pending and reviewing)")),
+		mcp.WithString("created_after", mcp.Description("Only reports filed at or after this RFC 3339 time or YYYY-MM-DD date")),
+		mcp.WithString("created_before", mcp.Description("Only reports filed before this RFC 3339 time or YYYY-MM-DD date")),
+	}, pageArgs(database.ReportSorts(), "severity"))...,
+	), s.reviewReports)
+
+	mcpServer.AddTool(mcp.NewTool("claim_report",
+		mcp.WithDescription("[Governance] Claim a pending report for review. Requires the judge role; only the claiming judge may rule on it."),
+		toolschema.Output[claimReportResult](),
+		mcp.WithString("report_id", mcp.Required(), mcp.Description("ID of the report to claim")),
+	), s.claimReport)
+
+	mcpServer.AddTool(mcp.NewTool("rule_on_report",
+		mcp.WithDescription("[Governance] Resolve a claimed report and apply the ruling to the agent. Requires the judge role. A warning is recorded, quarantine quarantines the agent, and ban quarantines it until the executioner carries out the ban."),
+		toolschema.Output[ruleOnReportResult](),
+		mcp.WithString("report_id", mcp.Required(), mcp.Description("ID of the claimed report")),
+		mcp.WithString("resolution", mcp.Required(), mcp.Description("Resolution: dismissed, warning, quarantine or ban")),
+		mcp.WithString("note", mcp.Required(), mcp.Description("Reasoning for the ruling, recorded on the report")),
+	), s.ruleOnReport)
+
+	mcpServer.AddTool(mcp.NewTool("execute_ban",
+		mcp.WithDescription("[Governance] Ban the agent named in a report a judge resolved with a ban ruling. Requires the executioner role; the ruling must be older than the cooling-off period and system agents cannot be banned. Find rulings with review_reports status=resolved."),
+		toolschema.Output[executeBanResult](),
+		mcp.WithString("report_id", mcp.Required(), mcp.Description("ID of the report resolved with a ban ruling")),
+		mcp.WithString("reason", mcp.Required(), mcp.Description("Reason recorded with the ban")),
+	), s.executeBan)
 
 	mcpServer.AddTool(mcp.NewTool("governance_action",
-		mcp.WithDescription("[Governance] Execute a governance action (quarantine, ban, etc)."),
+		mcp.WithDescription("[Governance] Execute a governance action (quarantine, reputation changes, etc) as the caller. Police may quarantine; judges may quarantine, unquarantine, unban and adjust reputation. Bans go through execute_ban; unban reverts the agent's latest ban."),
+		toolschema.Output[governanceActionResult](),
 		mcp.WithString("agent_name", mcp.Required(), mcp.Description("Name of the agent")),
-		mcp.WithString("action", mcp.Required(), mcp.Description("Action: quarantine, unquarantine, ban, unban, adjust_reputation")),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action: quarantine, unquarantine, unban, promote, demote, adjust_reputation")),
 		mcp.WithString("reason", mcp.Required(), mcp.Description("Reason for the action")),
-		mcp.WithNumber("reputation_delta", mcp.Description("Reputation adjustment amount (for adjust_reputation)")),
-	), srv.governanceAction)
+		mcp.WithNumber("reputation_delta", mcp.Description("Reputation adjustment amount (for promote, demote and adjust_reputation)")),
+		mcp.WithString("report_id", mcp.Description("ID of the report the action resolves (optional)")),
+	), s.governanceAction)
+
+	mcpServer.AddTool(mcp.NewTool("revert_governance_action",
+		mcp.WithDescription("[Governance] Undo a governance action, restoring the status or reputation it replaced and recording a compensating action. Requires the judge role. Refused while a later action changed the same state; revert that first. Find action IDs with governance_history."),
+		toolschema.Output[revertActionResult](),
+		mcp.WithString("action_id", mcp.Required(), mcp.Description("ID of the action to revert")),
+		mcp.WithString("reason", mcp.Required(), mcp.Description("Reason recorded with the compensating action")),
+	), s.revertGovernanceAction)
+
+	mcpServer.AddTool(mcp.NewTool("governance_history",
+		mcp.WithDescription("[Governance] List the governance actions taken on an agent, oldest first. Requires a governance role."),
+		toolschema.Output[governanceHistoryResult](),
+		mcp.WithString("agent_name", mcp.Required(), mcp.Description("Name of the agent")),
+	), s.governanceHistory)
+
+	mcpServer.AddTool(mcp.NewTool("file_appeal",
+		mcp.WithDescription("Appeal the quarantine or ban of an agent you created. One appeal may be pending at a time, and each agent has a limited number of appeals. Judges see appeals in review_reports."),
+		toolschema.Output[fileAppealResult](),
+		mcp.WithString("agent_name", mcp.Required(), mcp.Description("Name of the quarantined or banned agent")),
+		mcp.WithString("statement", mcp.Required(), mcp.Description("Why the quarantine or ban should be lifted")),
+	), s.fileAppeal)
+
+	mcpServer.AddTool(mcp.NewTool("decide_appeal",
+		mcp.WithDescription("[Governance] Grant or deny a pending appeal. Requires the judge role. Granting reverts the agent's ban and lifts its quarantine, restoring it to active."),
+		toolschema.Output[decideAppealResult](),
+		mcp.WithString("appeal_id", mcp.Required(), mcp.Description("ID of the pending appeal")),
+		mcp.WithString("decision", mcp.Required(), mcp.Description("Decision: grant or deny")),
+		mcp.WithString("note", mcp.Required(), mcp.Description("Reasoning for the decision, recorded on the appeal")),
+	), s.decideAppeal)
 
 	mcpServer.AddTool(mcp.NewTool("governance_stats",
 		mcp.WithDescription("[Governance] Get governance system statistics."),
-	), srv.governanceStats)
+		toolschema.Output[models.GovernanceStats](),
+	), s.governanceStats)
 
 	// Register agent registration tool
 	mcpServer.AddTool(mcp.NewTool("register_agent",
 		mcp.WithDescription("Register a new agent in the system."),
+		toolschema.Output[agentStatusResult](),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Unique name for the agent (lowercase, hyphens ok)")),
 		mcp.WithString("description", mcp.Required(), mcp.Description("Brief description of what the agent does")),
 		mcp.WithString("prompt", mcp.Required(), mcp.Description("The system prompt that defines the agent's behavior")),
@@ -1494,119 +4012,411 @@ func main() {
 		mcp.WithString("skills", mcp.Description("Comma-separated list of skills")),
 		mcp.WithString("tools", mcp.Description("Comma-separated list of tools: Read, Write, Edit, Bash, Grep, Glob")),
 		mcp.WithString("tags", mcp.Description("Comma-separated list of tags for categorization")),
-		mcp.WithString("version", mcp.Description("Version string (default: 1.0.0)")),
-	), srv.registerAgent)
+		mcp.WithString("version", mcp.Description("Semver version string (default: 1.0.0)")),
+	), s.registerAgent)
+
+	mcpServer.AddTool(mcp.NewTool("update_agent",
+		mcp.WithDescription("Update an existing agent (its creator or an admin only). Only supplied fields change. Requires the current revision from get_agent so concurrent edits are not silently overwritten."),
+		toolschema.Output[updateAgentResult](),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the agent to update")),
+		mcp.WithNumber("expected_revision", mcp.Required(), mcp.Description("Revision the edit is based on (from get_agent)")),
+		mcp.WithString("new_name", mcp.Description("Rename the agent")),
+		mcp.WithString("description", mcp.Description("New description")),
+		mcp.WithString("prompt", mcp.Description("New system prompt")),
+		mcp.WithString("model", mcp.Description("Model to use: sonnet, opus, haiku")),
+		mcp.WithString("skills", mcp.Description("Comma-separated list of skills (replaces existing)")),
+		mcp.WithString("tools", mcp.Description("Comma-separated list of tools (replaces existing)")),
+		mcp.WithString("tags", mcp.Description("Comma-separated list of tags (replaces existing)")),
+		mcp.WithString("version", mcp.Description("New semver version, must be greater than the current one (default: bump patch)")),
+	), s.updateAgent)
+
+	mcpServer.AddTool(mcp.NewTool("delete_agent",
+		mcp.WithDescription("[Admin] Archive (soft-delete) an agent. Requires the current revision from get_agent."),
+		toolschema.Output[agentStatusResult](),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the agent to archive")),
+		mcp.WithNumber("expected_revision", mcp.Required(), mcp.Description("Revision the delete is based on (from get_agent)")),
+	), s.deleteAgent)
+
+	// Register revision history tools
+	mcpServer.AddTool(mcp.NewTool("list_revisions",
+		mcp.WithDescription("List the version history of an agent, skill or command, newest first."),
+		toolschema.Output[listRevisionsResult](),
+		mcp.WithString("type", mcp.Required(), mcp.Description("Entity type: agent, skill or command")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the agent, skill or command")),
+	), s.listRevisions)
+
+	mcpServer.AddTool(mcp.NewTool("diff_revisions",
+		mcp.WithDescription("Show what changed between two versions of an agent, skill or command."),
+		toolschema.Output[diffRevisionsResult](),
+		mcp.WithString("type", mcp.Required(), mcp.Description("Entity type: agent, skill or command")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the agent, skill or command")),
+		mcp.WithString("from_version", mcp.Required(), mcp.Description("Older version to compare from")),
+		mcp.WithString("to_version", mcp.Description("Newer version to compare to (default: current)")),
+	), s.diffRevisions)
+
+	mcpServer.AddTool(mcp.NewTool("rollback_revision",
+		mcp.WithDescription("[Admin] Restore the content of an earlier version. The rollback is saved as a new version; history is never rewritten."),
+		toolschema.Output[rollbackResult](),
+		mcp.WithString("type", mcp.Required(), mcp.Description("Entity type: agent, skill or command")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the agent, skill or command")),
+		mcp.WithString("version", mcp.Required(), mcp.Description("Version whose content should be restored")),
+		mcp.WithString("new_version", mcp.Description("Version to save the rollback as (default: bump patch)")),
+		mcp.WithNumber("expected_revision", mcp.Description("Required for agents: revision from get_agent")),
+	), s.rollbackRevision)
 
 	// Register skills tools
-	mcpServer.AddTool(mcp.NewTool("list_skills",
-		mcp.WithDescription("List all available skills (packaged knowledge for tools like kubectl, docker, curl, etc)."),
+	mcpServer.AddTool(mcp.NewTool("list_skills", slices.Concat([]mcp.ToolOption{
+		mcp.WithDescription("List available skills (packaged knowledge for tools like kubectl, docker, curl, etc) a page at a time. Pass next_cursor back as cursor for the next page."),
+		toolschema.Output[listSkillsResult](),
 		mcp.WithString("category", mcp.Description("Filter by category: devops, api, database, cloud, cli")),
-		mcp.WithString("tags", mcp.Description("Comma-separated list of tags to filter by")),
-	), srv.listSkills)
+	}, catalogFilterArgs(models.EmbeddingKindSkill), pageArgs(catalogSorts, "reputation"))...,
+	), s.listSkills)
 
 	mcpServer.AddTool(mcp.NewTool("get_skill",
 		mcp.WithDescription("Get a skill's complete content including documentation and examples."),
+		mcp.WithRawOutputSchema(toolschema.AnyOf(toolschema.Of[models.Skill](), toolschema.Of[models.SkillRevision]())),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the skill to retrieve")),
-	), srv.getSkill)
+		mcp.WithString("version", mcp.Description("Return a specific historical version (default: current)")),
+	), s.getSkill)
 
-	mcpServer.AddTool(mcp.NewTool("search_skills",
-		mcp.WithDescription("Search skills by keyword."),
+	mcpServer.AddTool(mcp.NewTool("search_skills", slices.Concat([]mcp.ToolOption{
+		mcp.WithDescription("Search skills by keyword and meaning. Results are ranked by fusing full-text, typo-tolerant trigram and embedding rankings, with per-signal scores; when no word matches exactly, did_you_mean suggests a respelled query. Pass next_cursor back as cursor for more results."),
+		toolschema.Output[searchResult[models.SkillSearchResult]](),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Search query string")),
-	), srv.searchSkills)
+		mcp.WithNumber("limit", mcp.Description("Maximum number of results per page (default 20)")),
+		mcp.WithString("cursor", mcp.Description("next_cursor from the previous page; omit for the first page")),
+		mcp.WithString("category", mcp.Description("Filter by category: devops, api, database, cloud, cli")),
+	}, catalogFilterArgs(models.EmbeddingKindSkill))...,
+	), s.searchSkills)
 
 	mcpServer.AddTool(mcp.NewTool("find_similar_skills",
 		mcp.WithDescription("Find semantically similar skills using AI embeddings."),
+		toolschema.Output[similarSkillsResult](),
 		mcp.WithString("description", mcp.Required(), mcp.Description("Description of what you need help with")),
 		mcp.WithNumber("limit", mcp.Description("Maximum number of results (default 5)")),
-		mcp.WithNumber("threshold", mcp.Description("Similarity threshold 0-1 (default 0.15)")),
-	), srv.findSimilarSkills)
+		mcp.WithNumber("threshold", mcp.Description("Minimum similarity 0-1 for semantic matches (default 0.15)")),
+	), s.findSimilarSkills)
 
 	mcpServer.AddTool(mcp.NewTool("use_skill",
 		mcp.WithDescription("Find the best skill for a task and return its documentation/content."),
+		mcp.WithRawOutputSchema(toolschema.AnyOf(toolschema.Of[skillMatch](), toolschema.Of[skillMiss]())),
 		mcp.WithString("task", mcp.Required(), mcp.Description("Description of what you need to do (e.g., 'use kubectl to debug pods')")),
-	), srv.useSkill)
+		mcp.WithBoolean("explain", mcp.Description("Also return the ranked candidates with their scores and why each runner-up lost (default false)")),
+		mcp.WithNumber("candidates", mcp.Description("Number of candidates to explain (default 5)")),
+	), s.useSkill)
 
 	mcpServer.AddTool(mcp.NewTool("register_skill",
 		mcp.WithDescription("Register a new skill (packaged knowledge/documentation)."),
+		toolschema.Output[skillStatusResult](),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Unique name for the skill (e.g., 'kubectl', 'docker-cli')")),
 		mcp.WithString("description", mcp.Required(), mcp.Description("Brief description of what the skill covers")),
 		mcp.WithString("content", mcp.Required(), mcp.Description("The actual documentation/knowledge content")),
 		mcp.WithString("category", mcp.Description("Category: devops, api, database, cloud, cli")),
 		mcp.WithString("tags", mcp.Description("Comma-separated list of tags")),
-		mcp.WithString("version", mcp.Description("Version string (default: 1.0.0)")),
-	), srv.registerSkill)
+		mcp.WithString("version", mcp.Description("Semver version string (default: 1.0.0)")),
+	), s.registerSkill)
 
 	// Register commands tools
-	mcpServer.AddTool(mcp.NewTool("list_commands",
-		mcp.WithDescription("List all available slash commands that can be synced to your project."),
+	mcpServer.AddTool(mcp.NewTool("list_commands", slices.Concat([]mcp.ToolOption{
+		mcp.WithDescription("List available slash commands that can be synced to your project, a page at a time. Pass next_cursor back as cursor for the next page."),
+		toolschema.Output[listCommandsResult](),
 		mcp.WithString("category", mcp.Description("Filter by category: code, git, test, deploy")),
-		mcp.WithString("tags", mcp.Description("Comma-separated list of tags to filter by")),
-	), srv.listCommands)
+	}, catalogFilterArgs(models.EmbeddingKindCommand), pageArgs(catalogSorts, "reputation"))...,
+	), s.listCommands)
 
 	mcpServer.AddTool(mcp.NewTool("get_command",
 		mcp.WithDescription("Get a command's complete definition including prompt template."),
+		mcp.WithRawOutputSchema(toolschema.AnyOf(toolschema.Of[models.Command](), toolschema.Of[models.CommandRevision]())),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the command to retrieve")),
-	), srv.getCommand)
-
-	mcpServer.AddTool(mcp.NewTool("search_commands",
-		mcp.WithDescription("Search commands by keyword."),
+		mcp.WithString("version", mcp.Description("Return a specific historical version (default: current)")),
+	), s.getCommand)
+
+	mcpServer.AddTool(mcp.NewTool("render_command",
+		mcp.WithDescription("Render a command's prompt template, substituting {{name}} placeholders, $ARGUMENTS and defaults."),
+		toolschema.Output[renderCommandResult](),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the command to render")),
+		mcp.WithObject("arguments", mcp.Description("Argument values keyed by name; 'arguments' sets $ARGUMENTS directly")),
+	), s.renderCommand)
+
+	mcpServer.AddTool(mcp.NewTool("search_commands", slices.Concat([]mcp.ToolOption{
+		mcp.WithDescription("Search commands by keyword and meaning. Results are ranked by fusing full-text, typo-tolerant trigram and embedding rankings, with per-signal scores; when no word matches exactly, did_you_mean suggests a respelled query. Pass next_cursor back as cursor for more results."),
+		toolschema.Output[searchResult[models.CommandSearchResult]](),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Search query string")),
-	), srv.searchCommands)
+		mcp.WithNumber("limit", mcp.Description("Maximum number of results per page (default 20)")),
+		mcp.WithString("cursor", mcp.Description("next_cursor from the previous page; omit for the first page")),
+		mcp.WithString("category", mcp.Description("Filter by category: code, git, test, deploy")),
+	}, catalogFilterArgs(models.EmbeddingKindCommand))...,
+	), s.searchCommands)
+
+	mcpServer.AddTool(mcp.NewTool("sync_project",
+		mcp.WithDescription("Write commands to .claude/commands and agents to .claude/agents in a project. A lockfile tracks synced versions so re-running updates changed items, flags local edits and removes items deleted upstream. Only available over stdio."),
+		toolschema.Output[syncProjectResult](),
+		mcp.WithString("project_dir", mcp.Description("Project root containing .claude (default: server working directory)")),
+		mcp.WithString("commands", mcp.Description("Comma-separated command names, or 'all'")),
+		mcp.WithString("agents", mcp.Description("Comma-separated agent names, or 'all'")),
+		mcp.WithBoolean("force", mcp.Description("Overwrite or remove files that have local edits")),
+		mcp.WithBoolean("dry_run", mcp.Description("Report changes without writing files")),
+	), s.syncProject)
 
 	mcpServer.AddTool(mcp.NewTool("register_command",
 		mcp.WithDescription("Register a new slash command."),
+		toolschema.Output[commandStatusResult](),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Unique name for the command (e.g., 'review-pr', 'fix-tests')")),
 		mcp.WithString("description", mcp.Required(), mcp.Description("Brief description of what the command does")),
-		mcp.WithString("prompt", mcp.Required(), mcp.Description("The command's prompt template")),
+		mcp.WithString("prompt", mcp.Required(), mcp.Description("The command's prompt template; use {{name}} for declared arguments and $ARGUMENTS for the full argument string")),
 		mcp.WithString("category", mcp.Description("Category: code, git, test, deploy")),
 		mcp.WithString("tags", mcp.Description("Comma-separated list of tags")),
-		mcp.WithString("version", mcp.Description("Version string (default: 1.0.0)")),
-	), srv.registerCommand)
+		mcp.WithString("version", mcp.Description("Semver version string (default: 1.0.0)")),
+		mcp.WithString("arguments", mcp.Description(`JSON array of arguments: [{"name", "description", "required", "default", "choices"}]`)),
+	), s.registerCommand)
 
 	// Register meta tools
 	mcpServer.AddTool(mcp.NewTool("use_agent",
 		mcp.WithDescription("Find and adopt the best agent for a task. Returns the agent's prompt and configuration to use as guidance."),
+		mcp.WithRawOutputSchema(toolschema.AnyOf(toolschema.Of[agentMatch](), toolschema.Of[agentMiss]())),
 		mcp.WithString("task", mcp.Required(), mcp.Description("Description of the task you need help with")),
-	), srv.useAgent)
+		mcp.WithBoolean("explain", mcp.Description("Also return the ranked candidates with their scores, alias expansions and why each runner-up lost (default false)")),
+		mcp.WithNumber("candidates", mcp.Description("Number of candidates to explain (default 5)")),
+	), s.useAgent)
+}
+
+func main() {
+	// `agentmcp sync [flags]` syncs into a project and `agentmcp reindex [flags]`
+	// re-embeds stored rows instead of serving MCP
+	subcommand := ""
+	if len(os.Args) > 1 && (os.Args[1] == "sync" || os.Args[1] == "reindex") {
+		subcommand = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
+	// CLI flags (precedence: flag > env > config file > default)
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "Config file (default: "+config.DefaultPath+" if present)")
+	config.Default().RegisterFlags(flag.CommandLine)
+
+	migrate := flag.Bool("migrate", getEnvOrDefaultBool("AUTO_MIGRATE", false), "Run database migrations")
+	migrateOnly := flag.Bool("migrate-only", false, "Run migrations and exit")
+
+	version := flag.Bool("version", false, "Print version")
+
+	syncProjectDir := flag.String("project", ".", "sync: project root containing .claude")
+	syncCommands := flag.String("commands", "", "sync: comma-separated command names, or \"all\"")
+	syncAgents := flag.String("agents", "", "sync: comma-separated agent names, or \"all\"")
+	syncForce := flag.Bool("force", false, "sync: overwrite or remove files with local edits")
+	syncDryRun := flag.Bool("dry-run", false, "sync: show changes without writing files")
+
+	reindexKinds := flag.String("kinds", "all", "reindex: comma-separated kinds (agents, skills, commands), or \"all\"")
+	reindexBatchSize := flag.Int("batch-size", defaultReindexBatchSize, "reindex: rows embedded per batch")
+	flag.Parse()
+
+	if *version {
+		fmt.Printf("agentmcp v%s\n", VERSION)
+		os.Exit(0)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("[FATAL] %v", err)
+	}
+	if err := cfg.ApplyFlags(flag.CommandLine); err != nil {
+		log.Fatalf("[FATAL] Invalid flag: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("[FATAL] %v", err)
+	}
+
+	log.Printf("[INFO] Starting agentmcp v%s", VERSION)
+
+	// Initialize storage
+	var store database.Store
+	switch cfg.Database.Driver {
+	case "postgres":
+		db, err := database.New(cfg.ForDatabase())
+		if err != nil {
+			log.Fatalf("[FATAL] Database connection failed: %v", err)
+		}
+		log.Println("[INFO] Database connected")
+
+		// Run migrations if requested
+		if *migrate || *migrateOnly {
+			log.Println("[INFO] Running database migrations...")
+			runner := migrations.NewRunner(db.Pool(), sqlmigrations.Files)
+			if err := runner.Run(context.Background()); err != nil {
+				log.Fatalf("[FATAL] Migration failed: %v", err)
+			}
+			log.Println("[INFO] Migrations completed successfully")
+
+			if *migrateOnly {
+				log.Println("[INFO] Migration-only mode, exiting")
+				db.Close()
+				os.Exit(0)
+			}
+		}
+		store = db
+	case "memory":
+		if *migrateOnly {
+			log.Println("[INFO] Memory store has no schema to migrate, exiting")
+			os.Exit(0)
+		}
+		store = database.NewMemoryStore()
+		log.Println("[INFO] Using in-process memory store (data is not persisted)")
+	default:
+		log.Fatalf("[FATAL] Unknown db driver: %s (supported: postgres, memory)", cfg.Database.Driver)
+	}
+	defer store.Close()
+
+	if subcommand == "sync" {
+		err := runSyncCommand(NewServerV2(store, nil, nil, nil), syncOptions{
+			ProjectDir: *syncProjectDir,
+			Commands:   parseList(*syncCommands),
+			Agents:     parseList(*syncAgents),
+			Force:      *syncForce,
+			DryRun:     *syncDryRun,
+		})
+		if err != nil {
+			log.Fatalf("[FATAL] Sync failed: %v", err)
+		}
+		return
+	}
+
+	// Initialize embeddings
+	var embedder embeddings.Engine
+	embedder, err = embeddings.NewEngine(cfg.ForEmbeddings())
+	if err != nil {
+		log.Printf("[WARN] Embedding engine failed: %v (semantic search disabled)", err)
+	} else {
+		log.Println("[INFO] Embedding engine initialized")
+		if closer, ok := embedder.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
+	if subcommand == "reindex" {
+		if embedder == nil {
+			log.Fatalf("[FATAL] Re-index needs an embedding engine")
+		}
+		kinds, err := parseEmbeddingKinds(parseList(*reindexKinds))
+		if err != nil {
+			log.Fatalf("[FATAL] %v", err)
+		}
+		err = runReindexCommand(NewServerV2(store, embedder, nil, nil), reindexOptions{
+			Kinds:     kinds,
+			BatchSize: *reindexBatchSize,
+		})
+		if err != nil {
+			log.Fatalf("[FATAL] Re-index failed: %v", err)
+		}
+		return
+	}
+
+	// Vectors of the wrong size cannot be stored or compared, so refuse to
+	// use the engine; rows from another model only degrade search quality
+	if embedder != nil {
+		ctx := context.Background()
+		if err := checkEmbeddingDimensions(ctx, store, embedder); err != nil {
+			log.Printf("[WARN] %v (semantic search disabled; migrate the column and run `agentmcp reindex`)", err)
+			embedder = nil
+		} else {
+			for _, kind := range models.EmbeddingKinds {
+				counts, err := store.CountEmbeddings(ctx, kind, embedder.Model())
+				if err != nil {
+					log.Printf("[WARN] Failed to count %s embeddings: %v", kind, err)
+					continue
+				}
+				if stale := counts.Total - counts.Current; stale > 0 {
+					log.Printf("[WARN] %d of %d %ss are not embedded with %s; run `agentmcp reindex` or the reindex_embeddings tool",
+						stale, counts.Total, kind, embedder.Model())
+				}
+			}
+		}
+	}
+
+	// Initialize generator
+	var gen *generator.Generator
+	if cfg.Generation.APIKey != "" {
+		gen, err = generator.New(cfg.ForGenerator())
+		if err != nil {
+			log.Printf("[WARN] Generator failed: %v (agent generation disabled)", err)
+		} else {
+			log.Println("[INFO] Agent generator initialized")
+		}
+	}
+
+	// Initialize governance
+	gov := governance.New(store, cfg.ForGovernance())
+	log.Println("[INFO] Governance engine initialized")
+
+	// Create server
+	srv := NewServerV2(store, embedder, gen, gov)
+
+	// Layer the alias file and stored overrides over the built-in aliases
+	srv.aliasFile = cfg.Aliases.File
+	if err := srv.reloadAliases(context.Background()); err != nil {
+		log.Printf("[WARN] Failed to load task aliases: %v (using built-in aliases)", err)
+	}
+	go srv.watchAliases(context.Background(), time.Duration(cfg.Aliases.ReloadInterval))
+
+	// Identify callers so governance tools can check their roles
+	srv.principals, err = cfg.ForAuth()
+	if err != nil {
+		log.Fatalf("[FATAL] Invalid auth config: %v", err)
+	}
+	if cfg.Auth.StdioToken != "" {
+		srv.stdioAs, _ = srv.principals.Lookup(cfg.Auth.StdioToken)
+	}
+	if srv.principals.Len() == 0 {
+		log.Println("[WARN] No principals configured; every caller is anonymous and governance tools are refused")
+	}
+
+	// Create MCP server. Only the stdio and http transports answer
+	// completion/complete, so only they advertise it.
+	hooks := &server.Hooks{}
+	if cfg.Server.Transport != "sse" {
+		hooks.AddAfterInitialize(advertiseCompletions)
+	}
+	mcpServer := server.NewMCPServer("agentmcp", VERSION,
+		server.WithResourceCapabilities(false, true),
+		server.WithPromptCapabilities(true),
+		server.WithHooks(hooks),
+	)
+
+	// Expose agents, skills and commands as browsable resources
+	if err := srv.registerResources(context.Background(), mcpServer); err != nil {
+		log.Printf("[WARN] Failed to register resources: %v", err)
+	}
+
+	// Serve commands as native MCP prompts
+	if err := srv.registerPrompts(context.Background(), mcpServer); err != nil {
+		log.Printf("[WARN] Failed to register prompts: %v", err)
+	}
+
+	// Register tools with their output schemas
+	srv.registerTools(mcpServer)
 
 	// Run server
-	switch *transport {
+	port := strconv.Itoa(cfg.Server.Port)
+	switch cfg.Server.Transport {
 	case "stdio":
 		log.Println("[INFO] Starting MCP server on stdio...")
-		if err := server.ServeStdio(mcpServer); err != nil {
+		if err := srv.serveStdio(mcpServer); err != nil {
 			log.Fatalf("[FATAL] Server error: %v", err)
 		}
 	case "sse":
-		log.Printf("[INFO] Starting MCP server on SSE port %s...", *port)
-		sseServer := server.NewSSEServer(mcpServer, server.WithBaseURL("http://localhost:"+*port))
-		if err := sseServer.Start(":" + *port); err != nil {
+		log.Printf("[INFO] Starting MCP server on SSE port %s...", port)
+		log.Println("[WARN] completion/complete is not available over SSE; use stdio or http")
+		sseServer := server.NewSSEServer(mcpServer, server.WithBaseURL("http://localhost:"+port))
+		if err := http.ListenAndServe(":"+port, srv.authenticate(sseServer)); err != nil {
 			log.Fatalf("[FATAL] Server error: %v", err)
 		}
 	case "http":
-		log.Printf("[INFO] Starting MCP server on Streamable HTTP port %s...", *port)
-		httpServer := server.NewStreamableHTTPServer(mcpServer)
-		if err := httpServer.Start(":" + *port); err != nil {
+		log.Printf("[INFO] Starting MCP server on Streamable HTTP port %s...", port)
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", srv.authenticate(srv.completionHandler(server.NewStreamableHTTPServer(mcpServer))))
+		if err := http.ListenAndServe(":"+port, mux); err != nil {
 			log.Fatalf("[FATAL] Server error: %v", err)
 		}
 	default:
-		log.Fatalf("[FATAL] Unknown transport: %s (supported: stdio, sse, http)", *transport)
-	}
-}
-
-func getEnvOrDefault(key, defaultValue string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return defaultValue
-}
-
-func getEnvOrDefaultInt(key string, defaultValue int) int {
-	if v := os.Getenv(key); v != "" {
-		var i int
-		fmt.Sscanf(v, "%d", &i)
-		return i
+		log.Fatalf("[FATAL] Unknown transport: %s (supported: stdio, sse, http)", cfg.Server.Transport)
 	}
-	return defaultValue
 }
 
 func getEnvOrDefaultBool(key string, defaultValue bool) bool {
@@ -4,8 +4,23 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/aminghadersohi/agentmcp/internal/auth"
+	"github.com/aminghadersohi/agentmcp/internal/database"
+	"github.com/aminghadersohi/agentmcp/internal/embeddings"
+	"github.com/aminghadersohi/agentmcp/internal/governance"
+	"github.com/aminghadersohi/agentmcp/internal/models"
+	"github.com/aminghadersohi/agentmcp/internal/toolschema"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 // ============ escapeLikePattern Tests ============
@@ -37,111 +52,6 @@ func TestContainsWord(t *testing.T) {
 	}
 }
 
-// ============ expandTask Tests ============
-
-func TestExpandTask(t *testing.T) {
-	tests := []struct {
-		name            string
-		task            string
-		shouldContain   []string
-		shouldNotExpand bool
-	}{
-		{
-			name:          "typo reviw expands to review",
-			task:          "reviw my code",
-			shouldContain: []string{"review"},
-		},
-		{
-			name:          "fix expands to bug keywords",
-			task:          "fix this error",
-			shouldContain: []string{"bug"},
-		},
-		{
-			name:          "testing expands to test",
-			task:          "write testing",
-			shouldContain: []string{"test"},
-		},
-		{
-			name:          "better expands to improve and quality",
-			task:          "make it better",
-			shouldContain: []string{"improve", "quality"},
-		},
-		{
-			name:          "optimize expands to performance",
-			task:          "optimize this",
-			shouldContain: []string{"performance", "refactor"},
-		},
-		{
-			name:          "charts expands with visualization keywords",
-			task:          "create charts",
-			shouldContain: []string{"graphs", "visualization"},
-		},
-		{
-			name:            "no expansion for unrelated text",
-			task:            "hello world",
-			shouldNotExpand: true,
-		},
-		{
-			name:          "security keywords expand",
-			task:          "check vulnerability",
-			shouldContain: []string{"security"},
-		},
-		{
-			name:          "docker expands with container keywords",
-			task:          "deploy with docker",
-			shouldContain: []string{"container"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := expandTask(tt.task)
-
-			if tt.shouldNotExpand {
-				if result != tt.task {
-					t.Errorf("expandTask(%q) should not expand, got %q", tt.task, result)
-				}
-				return
-			}
-
-			for _, keyword := range tt.shouldContain {
-				if !strings.Contains(strings.ToLower(result), keyword) {
-					t.Errorf("expandTask(%q) = %q, should contain %q", tt.task, result, keyword)
-				}
-			}
-		})
-	}
-}
-
-func TestExpandTaskDeterministic(t *testing.T) {
-	// Run multiple times to ensure deterministic output
-	task := "fix this bug and optimize performance"
-	first := expandTask(task)
-
-	for i := 0; i < 10; i++ {
-		result := expandTask(task)
-		if result != first {
-			t.Errorf("expandTask is not deterministic: got %q then %q", first, result)
-		}
-	}
-}
-
-func TestExpandTaskWordBoundaries(t *testing.T) {
-	// "doc" should not match in "docker"
-	result := expandTask("run docker container")
-
-	// Should contain docker-related expansions
-	if !strings.Contains(result, "container") {
-		t.Errorf("docker should expand to include container, got %q", result)
-	}
-
-	// "documentation" should NOT be added (doc != docker)
-	expanded := expandTask("update documentation")
-	if !strings.Contains(expanded, "docs") {
-		t.Logf("Note: documentation expanded to: %q", expanded)
-	}
-}
-
 // ============ Input Validation Tests ============
 
 func TestInputLengthConstants(t *testing.T) {
@@ -330,54 +240,1309 @@ func TestGetArgBool(t *testing.T) {
 	}
 }
 
-// ============ Task Aliases Tests ============
+// ============ Memory Store Handler Tests ============
+
+// newTestServer creates a v2 server backed by the in-process memory store
+func newTestServer(t *testing.T) *ServerV2 {
+	t.Helper()
+	store := database.NewMemoryStore()
+	t.Cleanup(store.Close)
+	return NewServerV2(store, nil, nil, governance.New(store, governance.DefaultConfig()))
+}
+
+// asCaller returns ctx carrying a principal with the given governance roles
+func asCaller(ctx context.Context, name string, roles ...models.GovernanceRole) context.Context {
+	return auth.WithPrincipal(ctx, auth.Principal{Name: name, Roles: roles})
+}
+
+// toolRequest builds a CallToolRequest with the given arguments
+func toolRequest(args map[string]any) mcp.CallToolRequest {
+	var req mcp.CallToolRequest
+	req.Params.Arguments = args
+	return req
+}
+
+// resultJSON decodes the text content of a successful tool result
+func resultJSON(t *testing.T, result *mcp.CallToolResult) map[string]any {
+	t.Helper()
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %v", result.Content)
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Content[0])
+	}
+	var out map[string]any
+	if err := json.Unmarshal([]byte(text.Text), &out); err != nil {
+		t.Fatalf("result is not JSON: %v", err)
+	}
+
+	// The text is a rendering of the structured content
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("structured content is not JSON: %v", err)
+	}
+	var structured map[string]any
+	json.Unmarshal(data, &structured)
+	if !reflect.DeepEqual(out, structured) {
+		t.Errorf("text content %v does not match structured content %v", out, structured)
+	}
+	return out
+}
+
+func TestRegisterAndGetAgentWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	result, err := srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name":        "go-reviewer",
+		"description": "Reviews Go code",
+		"prompt":      "You review Go code.",
+		"skills":      "go, review",
+	}))
+	if err != nil {
+		t.Fatalf("registerAgent failed: %v", err)
+	}
+	resultJSON(t, result)
+
+	result, _ = srv.getAgent(ctx, toolRequest(map[string]any{"name": "go-reviewer"}))
+	agent := resultJSON(t, result)
+	if agent["prompt"] != "You review Go code." {
+		t.Errorf("get_agent prompt = %v", agent["prompt"])
+	}
+
+	result, _ = srv.listAgents(ctx, toolRequest(map[string]any{"tags": "review"}))
+	list := resultJSON(t, result)
+	if list["count"] != float64(1) {
+		t.Errorf("list_agents count = %v, want 1", list["count"])
+	}
+
+	// Duplicate registration is rejected
+	result, _ = srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "go-reviewer", "description": "dup", "prompt": "dup",
+	}))
+	if !result.IsError {
+		t.Error("expected duplicate register_agent to fail")
+	}
+}
+
+func TestUseAgentKeywordFallbackWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name":        "security-auditor",
+		"description": "Finds security vulnerabilities",
+		"prompt":      "You audit code.",
+		"skills":      "security",
+	}))
+
+	result, _ := srv.useAgent(ctx, toolRequest(map[string]any{"task": "check this vulnerability"}))
+	out := resultJSON(t, result)
+	if out["found"] != true {
+		t.Fatalf("use_agent did not find an agent: %v", out)
+	}
+}
+
+func TestUseAgentHybridSearch(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	t.Cleanup(store.Close)
+	engine, err := embeddings.NewEngine(embeddings.Config{Type: "local"})
+	if err != nil {
+		t.Fatalf("NewEngine(local) failed: %v", err)
+	}
+	srv := NewServerV2(store, engine, nil, governance.New(store, governance.DefaultConfig()))
+
+	for _, a := range []map[string]any{
+		{"name": "terraform-expert", "description": "Writes infrastructure as code", "prompt": "p", "skills": "terraform"},
+		{"name": "k8s-debugger", "description": "Debugs Kubernetes pods and deployments", "prompt": "p", "skills": "kubernetes"},
+		{"name": "copywriter", "description": "Writes marketing copy for landing pages", "prompt": "p", "skills": "writing"},
+	} {
+		srv.registerAgent(ctx, toolRequest(a))
+	}
+
+	result, _ := srv.useAgent(ctx, toolRequest(map[string]any{"task": "plan a terraform change"}))
+	out := resultJSON(t, result)
+	agent, _ := out["agent"].(map[string]any)
+	if agent["name"] != "terraform-expert" {
+		t.Fatalf("use_agent picked %v, want terraform-expert", agent["name"])
+	}
+	scores, _ := out["scores"].(map[string]any)
+	if scores["text_rank"] != float64(1) || scores["score"] == float64(0) {
+		t.Errorf("scores = %v, want the keyword hit ranked first", scores)
+	}
+
+	result, _ = srv.searchAgents(ctx, toolRequest(map[string]any{"query": "kubernetes pods crashing", "limit": float64(2)}))
+	out = resultJSON(t, result)
+	results, _ := out["results"].([]any)
+	if len(results) == 0 || len(results) > 2 {
+		t.Fatalf("search_agents returned %d results, want 1-2", len(results))
+	}
+	top := results[0].(map[string]any)
+	if name := top["agent"].(map[string]any)["name"]; name != "k8s-debugger" {
+		t.Errorf("search_agents top = %v, want k8s-debugger", name)
+	}
+	if sc := top["scores"].(map[string]any); sc["text_rank"] == float64(0) || sc["vector_rank"] == float64(0) {
+		t.Errorf("top result scores = %v, want both signals", sc)
+	}
+}
+
+func TestUseAgentExplain(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	for _, a := range []map[string]any{
+		{"name": "security-auditor", "description": "Finds security vulnerabilities", "prompt": "You audit code."},
+		{"name": "code-reviewer", "description": "Reviews code quality", "prompt": "You review code."},
+		{"name": "copywriter", "description": "Writes marketing copy", "prompt": "You write."},
+	} {
+		srv.registerAgent(ctx, toolRequest(a))
+	}
+
+	result, _ := srv.useAgent(ctx, toolRequest(map[string]any{"task": "check this vulnerability", "explain": true}))
+	out := resultJSON(t, result)
+	explain, ok := out["explain"].(map[string]any)
+	if !ok {
+		t.Fatalf("use_agent explain=true returned no explanation: %v", out)
+	}
+	if explain["semantic"] != false {
+		t.Errorf("semantic = %v, want false without an engine", explain["semantic"])
+	}
+
+	// "vulnerability" pulls in "security", which is what matches the auditor at all
+	var applied []string
+	for _, e := range explain["alias_expansions"].([]any) {
+		e := e.(map[string]any)
+		applied = append(applied, e["keyword"].(string)+"<-"+e["matched"].(string))
+	}
+	if !strings.Contains(strings.Join(applied, ","), "security<-vulnerability") {
+		t.Errorf("alias expansions = %v, want security triggered by vulnerability", applied)
+	}
+
+	cands := explain["candidates"].([]any)
+	if len(cands) < 2 {
+		t.Fatalf("candidates = %v, want the winner and a runner-up", cands)
+	}
+	// "check" also expands to review terms, so the reviewer leads on keywords
+	// while "vulnerability" puts the auditor first on trigram similarity; the
+	// explanation is what makes the resulting tie visible
+	first, second := cands[0].(map[string]any), cands[1].(map[string]any)
+	if first["name"] != out["agent"].(map[string]any)["name"] || first["outcome"] != "selected" {
+		t.Errorf("first candidate = %v, want the selected agent", first)
+	}
+	outcome, _ := second["outcome"].(string)
+	if !strings.HasPrefix(outcome, "lost: tied with "+first["name"].(string)) {
+		t.Errorf("runner-up outcome = %q, want a tie with %v", outcome, first["name"])
+	}
+	if hits := first["scores"].(map[string]any)["keyword_hits"]; hits == nil {
+		t.Errorf("winner has no keyword hits: %v", first)
+	}
+	if hits := second["scores"].(map[string]any)["fuzzy_hits"]; !reflect.DeepEqual(hits, []any{"vulnerability", "secure"}) {
+		t.Errorf("runner-up fuzzy hits = %v, want [vulnerability secure]", hits)
+	}
+
+	// Without explain the response stays compact
+	result, _ = srv.useAgent(ctx, toolRequest(map[string]any{"task": "check this vulnerability"}))
+	if _, ok := resultJSON(t, result)["explain"]; ok {
+		t.Error("use_agent without explain returned an explanation")
+	}
+}
+
+func TestExplainMatchesTieBreak(t *testing.T) {
+	scores := models.SearchScores{Score: 1.0 / 61, TextRank: 1}
+	e := explainMatches("task", nil, models.SearchQuery{Text: "task"}, nil, []matchCandidate{
+		{Name: "trusted", Reputation: 90, Scores: scores},
+		{Name: "newcomer", Reputation: 40, Scores: scores},
+	})
+	if got := e.Candidates[1].Outcome; !strings.Contains(got, "lower reputation (40.0 vs 90.0)") {
+		t.Errorf("tie outcome = %q, want it to blame reputation", got)
+	}
+}
+
+func TestUseAgentFallsBackToBuiltinAliases(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "shipper", "description": "Builds docker images", "prompt": "You build images.",
+	}))
+
+	// With no alias file and no stored overrides, a reload keeps the built-ins
+	if err := srv.reloadAliases(ctx); err != nil {
+		t.Fatalf("reloadAliases failed: %v", err)
+	}
+	result, _ := srv.useAgent(ctx, toolRequest(map[string]any{"task": "containerize the app", "explain": true}))
+	out := resultJSON(t, result)
+	var applied []string
+	for _, e := range out["explain"].(map[string]any)["alias_expansions"].([]any) {
+		e := e.(map[string]any)
+		applied = append(applied, e["domain"].(string)+"/"+e["keyword"].(string)+"<-"+e["matched"].(string))
+	}
+	if !slices.Contains(applied, "devops/docker<-containerize") {
+		t.Errorf("alias expansions = %v, want the built-in docker entry", applied)
+	}
+	if agent := out["agent"].(map[string]any); agent["name"] != "shipper" {
+		t.Errorf("use_agent picked %v, want shipper through the built-in alias", agent["name"])
+	}
+}
+
+func TestAliasToolsWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	admin := asCaller(ctx, "ops", models.RoleAdmin)
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "cluster-operator", "description": "Operates kubernetes clusters", "prompt": "You run clusters.",
+	}))
+
+	expansions := func(task string) []string {
+		t.Helper()
+		result, _ := srv.useAgent(ctx, toolRequest(map[string]any{"task": task, "explain": true}))
+		var applied []string
+		for _, e := range resultJSON(t, result)["explain"].(map[string]any)["alias_expansions"].([]any) {
+			e := e.(map[string]any)
+			applied = append(applied, e["domain"].(string)+"/"+e["keyword"].(string)+"<-"+e["matched"].(string))
+		}
+		return applied
+	}
+
+	// A file entry takes effect on reload
+	path := filepath.Join(t.TempDir(), "aliases.yaml")
+	if err := os.WriteFile(path, []byte("devops:\n  helm: [chart, charts]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv.aliasFile = path
+	result, _ := srv.reloadAliasesTool(admin, toolRequest(nil))
+	if counts := resultJSON(t, result)["by_source"].(map[string]any); counts["file"] != float64(1) {
+		t.Errorf("by_source = %v, want one file entry", counts)
+	}
+	if got := expansions("publish the chart"); !slices.Contains(got, "devops/helm<-chart") {
+		t.Errorf("expansions = %v, want the file's helm entry", got)
+	}
+
+	// An admin override applies immediately
+	result, _ = srv.setAlias(admin, toolRequest(map[string]any{"domain": "DevOps", "keyword": "cluster", "aliases": "K8s, kubectl, k8s"}))
+	alias := resultJSON(t, result)["alias"].(map[string]any)
+	if !reflect.DeepEqual(alias["aliases"], []any{"k8s", "kubectl"}) {
+		t.Errorf("stored aliases = %v, want normalized [k8s kubectl]", alias["aliases"])
+	}
+	if got := expansions("restart the kubectl rollout"); !slices.Contains(got, "devops/cluster<-kubectl") {
+		t.Errorf("expansions = %v, want the stored cluster entry", got)
+	}
+
+	// An empty list disables a built-in entry until the override is deleted
+	result, _ = srv.setAlias(admin, toolRequest(map[string]any{"domain": "security", "keyword": "security"}))
+	if status := resultJSON(t, result)["status"]; status != "disabled" {
+		t.Errorf("status = %v, want disabled", status)
+	}
+	if got := expansions("check this vulnerability"); slices.Contains(got, "security/security<-vulnerability") {
+		t.Errorf("expansions = %v, want the security entry disabled", got)
+	}
+	result, _ = srv.deleteAlias(admin, toolRequest(map[string]any{"domain": "security", "keyword": "security"}))
+	if restored := resultJSON(t, result)["restored"].(map[string]any); restored["source"] != "builtin" {
+		t.Errorf("restored = %v, want the built-in entry", restored)
+	}
+	if got := expansions("check this vulnerability"); !slices.Contains(got, "security/security<-vulnerability") {
+		t.Errorf("expansions = %v, want the built-in security entry back", got)
+	}
+
+	result, _ = srv.listAliases(ctx, toolRequest(map[string]any{"domain": "devops"}))
+	out := resultJSON(t, result)
+	if overrides := out["overrides"].([]any); len(overrides) != 1 {
+		t.Errorf("overrides = %v, want only the cluster entry", overrides)
+	}
+
+	// Callers without the admin role cannot change aliases
+	if result, _ := srv.setAlias(asCaller(ctx, "cop", models.RolePolice), toolRequest(map[string]any{"keyword": "k8s"})); !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "requires the admin role") {
+		t.Errorf("set_alias by police = %v, want permission denied", result.Content)
+	}
+
+	// Bad input is rejected, and a broken file keeps the current aliases
+	if result, _ := srv.setAlias(admin, toolRequest(map[string]any{"keyword": "two words"})); !result.IsError {
+		t.Error("set_alias accepted a keyword with a space")
+	}
+	if result, _ := srv.deleteAlias(admin, toolRequest(map[string]any{"keyword": "missing"})); !result.IsError {
+		t.Error("delete_alias succeeded without an override")
+	}
+	os.WriteFile(path, []byte("devops: [not, a, map]\n"), 0o644)
+	if result, _ := srv.reloadAliasesTool(admin, toolRequest(nil)); !result.IsError {
+		t.Error("reload_aliases accepted a malformed file")
+	}
+	if got := expansions("publish the chart"); !slices.Contains(got, "devops/helm<-chart") {
+		t.Errorf("expansions = %v, want the last good aliases kept", got)
+	}
+}
+
+func TestReportAgentWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "spammy", "description": "Posts spam", "prompt": "spam",
+	}))
+
+	result, _ := srv.reportAgent(asCaller(ctx, "reporter"), toolRequest(map[string]any{
+		"agent_name":  "spammy",
+		"report_type": "spam",
+		"severity":    "low",
+		"description": "It only posts spam",
+	}))
+	resultJSON(t, result)
+
+	if result, _ := srv.reviewReports(ctx, toolRequest(nil)); !result.IsError {
+		t.Error("review_reports served an anonymous caller")
+	}
+
+	result, _ = srv.reviewReports(asCaller(ctx, "cop", models.RolePolice), toolRequest(nil))
+	out := resultJSON(t, result)
+	if out["count"] != float64(1) {
+		t.Errorf("review_reports count = %v, want 1", out["count"])
+	}
+	reports := out["pending_reports"].([]any)
+	if by := reports[0].(map[string]any)["reported_by"]; by != "reporter" {
+		t.Errorf("reported_by = %v, want the caller", by)
+	}
+}
+
+func TestJudgeRulings(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	judge := asCaller(ctx, "alice", models.RoleJudge)
+	other := asCaller(ctx, "carol", models.RoleJudge)
+
+	// report files a report against a fresh agent and returns its ID
+	report := func(agent string) string {
+		t.Helper()
+		srv.registerAgent(ctx, toolRequest(map[string]any{"name": agent, "description": "Under review", "prompt": "p"}))
+		result, _ := srv.reportAgent(ctx, toolRequest(map[string]any{
+			"agent_name": agent, "report_type": "harmful", "severity": "high", "description": "Gave bad advice",
+		}))
+		return resultJSON(t, result)["report_id"].(string)
+	}
+	errText := func(result *mcp.CallToolResult) string {
+		if !result.IsError {
+			return ""
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	id := report("disputed")
+	if result, _ := srv.claimReport(asCaller(ctx, "cop", models.RolePolice), toolRequest(map[string]any{"report_id": id})); !strings.Contains(errText(result), "permission denied") {
+		t.Errorf("police claim = %q, want permission denied", errText(result))
+	}
+	rule := map[string]any{"report_id": id, "resolution": "dismissed", "note": "n"}
+	if result, _ := srv.ruleOnReport(judge, toolRequest(rule)); !strings.Contains(errText(result), "claim the report") {
+		t.Errorf("ruling before claim = %q, want a claim error", errText(result))
+	}
+	result, _ := srv.claimReport(judge, toolRequest(map[string]any{"report_id": id}))
+	if out := resultJSON(t, result); out["report"].(map[string]any)["reviewed_by"] != "alice" {
+		t.Errorf("claimed report = %v, want reviewed_by alice", out["report"])
+	}
+	if result, _ := srv.claimReport(other, toolRequest(map[string]any{"report_id": id})); !strings.Contains(errText(result), "claimed by alice") {
+		t.Errorf("second claim = %q, want claimed by alice", errText(result))
+	}
+	if result, _ := srv.ruleOnReport(other, toolRequest(rule)); !result.IsError {
+		t.Error("another judge ruled on a claimed report")
+	}
+	if result, _ := srv.ruleOnReport(judge, toolRequest(map[string]any{"report_id": id, "resolution": "pardon", "note": "n"})); !strings.Contains(errText(result), "invalid resolution") {
+		t.Errorf("unknown resolution = %q, want invalid resolution", errText(result))
+	}
+
+	tests := []struct {
+		resolution string
+		wantAction string
+		wantStatus models.AgentStatus
+	}{
+		{"dismissed", "", models.StatusActive},
+		{"warning", "warn", models.StatusActive},
+		{"quarantine", "quarantine", models.StatusQuarantined},
+		{"ban", "quarantine", models.StatusQuarantined}, // held for the executioner
+	}
+	for _, tt := range tests {
+		t.Run(tt.resolution, func(t *testing.T) {
+			agent := "ruled-" + tt.resolution
+			id := report(agent)
+			srv.claimReport(judge, toolRequest(map[string]any{"report_id": id}))
+			result, _ := srv.ruleOnReport(judge, toolRequest(map[string]any{"report_id": id, "resolution": tt.resolution, "note": "Decided"}))
+			out := resultJSON(t, result)
+
+			rep := out["report"].(map[string]any)
+			if rep["status"] != "resolved" || rep["resolution"] != tt.resolution || rep["reviewed_by"] != "alice" {
+				t.Errorf("report = %v, want resolved as %s by alice", rep, tt.resolution)
+			}
+			action, _ := out["action"].(map[string]any)
+			switch {
+			case tt.wantAction == "" && action != nil:
+				t.Errorf("action = %v, want none", action)
+			case tt.wantAction != "" && (action == nil || action["action_type"] != tt.wantAction || action["report_id"] != id || action["actor"] != "alice"):
+				t.Errorf("action = %v, want %s by alice linked to the report", action, tt.wantAction)
+			}
+			if a, _ := srv.db.GetAgent(ctx, agent); a.Status != tt.wantStatus {
+				t.Errorf("agent status = %s, want %s", a.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestGovernanceActionRoles(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "rogue", "description": "Misbehaves", "prompt": "p",
+	}))
+
+	cop := asCaller(ctx, "cop", models.RolePolice)
+	judge := asCaller(ctx, "alice", models.RoleJudge)
+	executioner := asCaller(ctx, "bob", models.RoleExecutioner)
 
-func TestTaskAliasesStructure(t *testing.T) {
-	// Ensure all keys have at least one alias
-	for key, aliases := range taskAliases {
-		if len(aliases) == 0 {
-			t.Errorf("taskAliases[%q] has no aliases", key)
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		args    map[string]any
+		wantErr string
+		role    string
+	}{
+		{"anonymous quarantine", ctx, map[string]any{"action": "quarantine"}, "permission denied for anonymous", ""},
+		{"police promote", cop, map[string]any{"action": "promote", "reputation_delta": float64(5)}, "requires the judge role", ""},
+		{"police quarantine", cop, map[string]any{"action": "quarantine"}, "", "police"},
+		{"police unquarantine", cop, map[string]any{"action": "unquarantine"}, "requires the judge role", ""},
+		{"judge unquarantine", judge, map[string]any{"action": "unquarantine"}, "", "judge"},
+		{"judge adjust", judge, map[string]any{"action": "adjust_reputation", "reputation_delta": float64(-5)}, "", "judge"},
+		{"judge demote without delta", judge, map[string]any{"action": "demote"}, "must be positive", ""},
+		{"executioner quarantine", executioner, map[string]any{"action": "quarantine"}, "requires the police or judge role", ""},
+		{"executioner ban", executioner, map[string]any{"action": "ban"}, "use execute_ban", ""},
+		{"police unban", cop, map[string]any{"action": "unban"}, "requires the judge role", ""},
+		{"judge unban active agent", judge, map[string]any{"action": "unban"}, "agent is not banned", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["agent_name"] = "rogue"
+			tt.args["reason"] = tt.name
+			result, _ := srv.governanceAction(tt.ctx, toolRequest(tt.args))
+			if tt.wantErr != "" {
+				text := result.Content[0].(mcp.TextContent).Text
+				if !result.IsError || !strings.Contains(text, tt.wantErr) {
+					t.Errorf("result = %q, want error containing %q", text, tt.wantErr)
+				}
+				return
+			}
+			out := resultJSON(t, result)
+			if out["role"] != tt.role || out["actor"] != auth.FromContext(tt.ctx).Name {
+				t.Errorf("acted as %v (%v), want %s (%s)", out["actor"], out["role"], auth.FromContext(tt.ctx).Name, tt.role)
+			}
+		})
+	}
+
+	agent, _ := srv.db.GetAgent(ctx, "rogue")
+	if agent.Status != models.StatusActive {
+		t.Errorf("status = %s, want active", agent.Status)
+	}
+}
+
+func TestAppeals(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	owner := asCaller(ctx, "owner")
+	judge := asCaller(ctx, "alice", models.RoleJudge)
+	errText := func(result *mcp.CallToolResult) string {
+		if !result.IsError {
+			return ""
 		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+	appeal := func(caller context.Context) *mcp.CallToolResult {
+		result, _ := srv.fileAppeal(caller, toolRequest(map[string]any{"agent_name": "contested", "statement": "It was fixed"}))
+		return result
+	}
+
+	srv.registerAgent(owner, toolRequest(map[string]any{"name": "contested", "description": "Disputed", "prompt": "p"}))
+	if result := appeal(owner); !strings.Contains(errText(result), "only quarantines and bans") {
+		t.Errorf("appeal of active agent = %q, want refusal", errText(result))
+	}
+	srv.governanceAction(judge, toolRequest(map[string]any{"agent_name": "contested", "action": "quarantine", "reason": "Disputed output"}))
+
+	if result := appeal(ctx); !strings.Contains(errText(result), "permission denied for anonymous") {
+		t.Errorf("anonymous appeal = %q, want permission denied", errText(result))
+	}
+	if result := appeal(asCaller(ctx, "stranger")); !strings.Contains(errText(result), "only the agent's creator") {
+		t.Errorf("stranger appeal = %q, want creator check", errText(result))
+	}
+	filed := resultJSON(t, appeal(owner))["appeal"].(map[string]any)
+	if filed["status"] != "pending" || filed["filed_by"] != "owner" || filed["action_id"] == nil {
+		t.Errorf("appeal = %v, want pending by owner naming the quarantine", filed)
+	}
+	if result := appeal(owner); !strings.Contains(errText(result), "pending appeal") {
+		t.Errorf("second appeal = %q, want pending appeal conflict", errText(result))
+	}
+
+	// The queue shows in review_reports
+	result, _ := srv.reviewReports(judge, toolRequest(nil))
+	if queue := resultJSON(t, result)["pending_appeals"].([]any); len(queue) != 1 || queue[0].(map[string]any)["id"] != filed["id"] {
+		t.Errorf("pending_appeals = %v, want the filed appeal", queue)
+	}
+
+	decide := func(caller context.Context, id any, decision string) *mcp.CallToolResult {
+		result, _ := srv.decideAppeal(caller, toolRequest(map[string]any{"appeal_id": id, "decision": decision, "note": "Considered"}))
+		return result
+	}
+	if result := decide(owner, filed["id"], "grant"); !strings.Contains(errText(result), "permission denied") {
+		t.Errorf("owner decision = %q, want permission denied", errText(result))
+	}
+	if out := resultJSON(t, decide(judge, filed["id"], "deny")); out["status"] != "appeal denied" || len(out["actions"].([]any)) != 0 {
+		t.Errorf("deny = %v, want a denial without actions", out)
+	}
+	if result := decide(judge, filed["id"], "grant"); !strings.Contains(errText(result), "already denied") {
+		t.Errorf("second decision = %q, want already denied", errText(result))
+	}
+
+	again := resultJSON(t, appeal(owner))["appeal"].(map[string]any)
+	out := resultJSON(t, decide(judge, again["id"], "grant"))
+	if actions := out["actions"].([]any); out["status"] != "appeal granted" || len(actions) != 1 || actions[0].(map[string]any)["action_type"] != "unquarantine" {
+		t.Errorf("grant = %v, want an unquarantine", out)
+	}
+	if agent, _ := srv.db.GetAgent(ctx, "contested"); agent.Status != models.StatusActive {
+		t.Errorf("status = %s, want active after a granted appeal", agent.Status)
+	}
+}
 
-		// Check for duplicate aliases within a key
-		seen := make(map[string]bool)
-		for _, alias := range aliases {
-			if seen[alias] {
-				t.Errorf("taskAliases[%q] has duplicate alias %q", key, alias)
+func TestListAndSearchPagingWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	for _, name := range []string{"deploy-a", "deploy-b", "deploy-c", "deploy-d", "deploy-e"} {
+		srv.registerAgent(ctx, toolRequest(map[string]any{
+			"name": name, "description": "Handles deploy pipelines", "prompt": "p",
+		}))
+	}
+
+	walk := func(call func(map[string]any) (*mcp.CallToolResult, error), args map[string]any, key string) []string {
+		t.Helper()
+		var names []string
+		for pages := 0; pages < 5; pages++ {
+			result, _ := call(args)
+			out := resultJSON(t, result)
+			items, _ := out[key].([]any)
+			for _, item := range items {
+				m := item.(map[string]any)
+				if agent, ok := m["agent"].(map[string]any); ok {
+					m = agent
+				}
+				names = append(names, m["name"].(string))
+			}
+			next, _ := out["next_cursor"].(string)
+			if next == "" {
+				return names
 			}
-			seen[alias] = true
+			args["cursor"] = next
+		}
+		t.Fatal("paging did not terminate")
+		return nil
+	}
+
+	listed := walk(func(args map[string]any) (*mcp.CallToolResult, error) {
+		return srv.listAgents(ctx, toolRequest(args))
+	}, map[string]any{"limit": float64(2), "sort_by": "name"}, "agents")
+	if want := []string{"deploy-a", "deploy-b", "deploy-c", "deploy-d", "deploy-e"}; !reflect.DeepEqual(listed, want) {
+		t.Errorf("list_agents pages = %v, want %v", listed, want)
+	}
+
+	found := walk(func(args map[string]any) (*mcp.CallToolResult, error) {
+		return srv.searchAgents(ctx, toolRequest(args))
+	}, map[string]any{"query": "deploy", "limit": float64(2)}, "results")
+	slices.Sort(found)
+	if !reflect.DeepEqual(found, listed) {
+		t.Errorf("search_agents pages = %v, want all 5 agents once", found)
+	}
+
+	result, _ := srv.listAgents(ctx, toolRequest(map[string]any{"created_after": "yesterday"}))
+	if !result.IsError {
+		t.Error("expected an unparseable created_after to fail")
+	}
+	result, _ = srv.listAgents(ctx, toolRequest(map[string]any{"created_before": "2000-01-01"}))
+	if out := resultJSON(t, result); out["count"] != float64(0) {
+		t.Errorf("agents created before 2000 = %v, want 0", out["count"])
+	}
+}
+
+func TestSearchDidYouMeanWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "cluster-operator", "description": "Operates Kubernetes clusters", "prompt": "You run clusters.",
+	}))
+
+	result, _ := srv.searchAgents(ctx, toolRequest(map[string]any{"query": "Kubernets, please"}))
+	out := resultJSON(t, result)
+	if out["count"] != float64(1) {
+		t.Errorf("count = %v, want the typo to still find cluster-operator", out["count"])
+	}
+	if out["did_you_mean"] != "kubernetes, please" {
+		t.Errorf("did_you_mean = %v, want %q", out["did_you_mean"], "kubernetes, please")
+	}
+
+	// No hint once a term matches exactly
+	result, _ = srv.searchAgents(ctx, toolRequest(map[string]any{"query": "kubernetes clustr"}))
+	if hint, ok := resultJSON(t, result)["did_you_mean"]; ok {
+		t.Errorf("did_you_mean = %v for a query with an exact match", hint)
+	}
+}
+
+func TestUpdateAndDeleteAgentWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+	owner := asCaller(ctx, "owner")
+
+	srv.registerAgent(owner, toolRequest(map[string]any{
+		"name": "typo-agent", "description": "Fixes typos", "prompt": "You fix tpyos.",
+	}))
+
+	// Only the creator (or an admin) may edit an agent
+	for _, caller := range []context.Context{ctx, asCaller(ctx, "mallory")} {
+		result, _ := srv.updateAgent(caller, toolRequest(map[string]any{
+			"name": "typo-agent", "expected_revision": float64(1), "prompt": "hijacked",
+		}))
+		if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "permission denied") {
+			t.Errorf("update_agent by a non-creator = %v, want permission denied", result.Content)
 		}
 	}
+
+	result, _ := srv.updateAgent(owner, toolRequest(map[string]any{
+		"name": "typo-agent", "expected_revision": float64(1), "prompt": "You fix typos.",
+	}))
+	out := resultJSON(t, result)
+	if out["revision"] != float64(2) {
+		t.Errorf("update_agent revision = %v, want 2", out["revision"])
+	}
+
+	// A second editor still holding revision 1 must be rejected
+	result, _ = srv.updateAgent(owner, toolRequest(map[string]any{
+		"name": "typo-agent", "expected_revision": float64(1), "prompt": "stale edit",
+	}))
+	if !result.IsError {
+		t.Error("expected stale update_agent to fail")
+	}
+
+	result, _ = srv.getAgent(ctx, toolRequest(map[string]any{"name": "typo-agent"}))
+	if agent := resultJSON(t, result); agent["prompt"] != "You fix typos." {
+		t.Errorf("prompt = %v, stale edit must not win", agent["prompt"])
+	}
+
+	// Only admins may archive agents
+	result, _ = srv.deleteAgent(ctx, toolRequest(map[string]any{
+		"name": "typo-agent", "expected_revision": float64(2),
+	}))
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "permission denied for anonymous") {
+		t.Errorf("anonymous delete_agent = %v, want permission denied", result.Content)
+	}
+	result, _ = srv.deleteAgent(asCaller(ctx, "ops", models.RoleAdmin), toolRequest(map[string]any{
+		"name": "typo-agent", "expected_revision": float64(2),
+	}))
+	resultJSON(t, result)
+
+	result, _ = srv.listAgents(ctx, toolRequest(nil))
+	if list := resultJSON(t, result); list["count"] != float64(0) {
+		t.Errorf("archived agent still listed: %v", list)
+	}
 }
 
-func TestTaskAliasesTypoHandling(t *testing.T) {
-	// Verify common typos are included
-	reviewAliases := taskAliases["review"]
+func TestRevisionToolsWithMemoryStore(t *testing.T) {
+	ctx := asCaller(context.Background(), "author")
+	srv := newTestServer(t)
+
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "writer", "description": "Writes docs", "prompt": "line one\nline two",
+	}))
 
-	typos := []string{"reviw", "reveiw"}
-	for _, typo := range typos {
-		found := false
-		for _, alias := range reviewAliases {
-			if alias == typo {
-				found = true
-				break
+	// Versions must increase monotonically
+	result, _ := srv.updateAgent(ctx, toolRequest(map[string]any{
+		"name": "writer", "expected_revision": float64(1), "prompt": "bad", "version": "0.9.0",
+	}))
+	if !result.IsError {
+		t.Error("expected a lower version to be rejected")
+	}
+
+	result, _ = srv.updateAgent(ctx, toolRequest(map[string]any{
+		"name": "writer", "expected_revision": float64(1), "prompt": "line one\nline 2",
+	}))
+	if out := resultJSON(t, result); out["version"] != "1.0.1" {
+		t.Errorf("update_agent version = %v, want auto-bumped 1.0.1", out["version"])
+	}
+
+	result, _ = srv.getAgent(ctx, toolRequest(map[string]any{"name": "writer", "version": "1.0.0"}))
+	if old := resultJSON(t, result); old["prompt"] != "line one\nline two" {
+		t.Errorf("get_agent version=1.0.0 prompt = %v", old["prompt"])
+	}
+
+	result, _ = srv.listRevisions(ctx, toolRequest(map[string]any{"type": "agent", "name": "writer"}))
+	if out := resultJSON(t, result); out["count"] != float64(2) {
+		t.Errorf("list_revisions count = %v, want 2", out["count"])
+	}
+
+	result, _ = srv.diffRevisions(ctx, toolRequest(map[string]any{"type": "agent", "name": "writer", "from_version": "1.0.0"}))
+	diff := resultJSON(t, result)
+	changes, _ := diff["changes"].([]any)
+	if len(changes) != 1 || changes[0].(map[string]any)["field"] != "prompt" {
+		t.Fatalf("diff_revisions changes = %v, want only prompt", diff["changes"])
+	}
+	lines := changes[0].(map[string]any)["diff"].([]any)
+	if len(lines) != 3 || lines[1] != "- line two" || lines[2] != "+ line 2" {
+		t.Errorf("prompt diff = %v", lines)
+	}
+
+	result, _ = srv.rollbackRevision(asCaller(ctx, "ops", models.RoleAdmin), toolRequest(map[string]any{
+		"type": "agent", "name": "writer", "version": "1.0.0", "expected_revision": float64(2),
+	}))
+	if out := resultJSON(t, result); out["version"] != "1.0.2" {
+		t.Errorf("rollback_revision version = %v, want 1.0.2", out["version"])
+	}
+
+	result, _ = srv.getAgent(ctx, toolRequest(map[string]any{"name": "writer"}))
+	if agent := resultJSON(t, result); agent["prompt"] != "line one\nline two" || agent["version"] != "1.0.2" {
+		t.Errorf("after rollback got prompt=%v version=%v", agent["prompt"], agent["version"])
+	}
+
+	// Banned agents cannot be rewritten by rolling back either
+	writer, _ := srv.db.GetAgent(ctx, "writer")
+	srv.db.UpdateAgentStatus(ctx, writer.ID, models.StatusBanned)
+	result, _ = srv.rollbackRevision(asCaller(ctx, "ops", models.RoleAdmin), toolRequest(map[string]any{
+		"type": "agent", "name": "writer", "version": "1.0.1", "expected_revision": float64(3),
+	}))
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "is banned") {
+		t.Errorf("rollback of a banned agent = %v, want refused", result.Content)
+	}
+}
+
+// testSession is a minimal MCP client session that records notifications
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()                                         {}
+func (s *testSession) Initialized() bool                                   { return true }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *testSession) SessionID() string                                   { return "test-session" }
+
+// methods drains the session and returns the methods of its notifications
+func (s *testSession) methods() []string {
+	var methods []string
+	for len(s.notifications) > 0 {
+		methods = append(methods, (<-s.notifications).Method)
+	}
+	return methods
+}
+
+func TestResourcesWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	mcpServer := server.NewMCPServer("test", VERSION, server.WithResourceCapabilities(false, true))
+	if err := srv.registerResources(ctx, mcpServer); err != nil {
+		t.Fatalf("registerResources failed: %v", err)
+	}
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	if err := mcpServer.RegisterSession(ctx, session); err != nil {
+		t.Fatalf("RegisterSession failed: %v", err)
+	}
+
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "doc-writer", "description": "Writes docs", "prompt": "You write docs.",
+	}))
+	if methods := session.methods(); len(methods) != 1 || methods[0] != mcp.MethodNotificationResourcesListChanged {
+		t.Errorf("register_agent notifications = %v, want one resources/list_changed", methods)
+	}
+
+	response := mcpServer.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"agent://doc-writer"}}`))
+	data, _ := json.Marshal(response)
+	if !strings.Contains(string(data), "You write docs.") {
+		t.Errorf("resources/read did not return the agent definition: %s", data)
+	}
+
+	srv.registerCommand(ctx, toolRequest(map[string]any{
+		"name": "changelog", "description": "Draft a changelog", "prompt": "Summarise the changes.",
+	}))
+	if methods := session.methods(); len(methods) != 1 || methods[0] != mcp.MethodNotificationResourcesListChanged {
+		t.Errorf("register_command notifications = %v, want one resources/list_changed", methods)
+	}
+
+	srv.deleteAgent(asCaller(ctx, "ops", models.RoleAdmin), toolRequest(map[string]any{"name": "doc-writer", "expected_revision": float64(1)}))
+	if methods := session.methods(); len(methods) != 1 || methods[0] != mcp.MethodNotificationResourcesListChanged {
+		t.Errorf("delete_agent notifications = %v, want one resources/list_changed", methods)
+	}
+	response = mcpServer.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"agent://doc-writer"}}`))
+	if _, ok := response.(mcp.JSONRPCError); !ok {
+		t.Errorf("expected archived agent read to fail, got %T", response)
+	}
+}
+
+func TestCommandPromptsWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	hooks := &server.Hooks{}
+	hooks.AddAfterInitialize(advertiseCompletions)
+	mcpServer := server.NewMCPServer("test", VERSION, server.WithPromptCapabilities(true), server.WithHooks(hooks))
+	if err := srv.registerPrompts(ctx, mcpServer); err != nil {
+		t.Fatalf("registerPrompts failed: %v", err)
+	}
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	if err := mcpServer.RegisterSession(ctx, session); err != nil {
+		t.Fatalf("RegisterSession failed: %v", err)
+	}
+
+	result, _ := srv.registerCommand(ctx, toolRequest(map[string]any{
+		"name":        "deploy",
+		"description": "Deploy a service",
+		"prompt":      "Deploy {{service}} to {{env}}.",
+		"arguments":   `[{"name":"service","required":true},{"name":"env","default":"staging","choices":["production","preview"]}]`,
+	}))
+	resultJSON(t, result)
+
+	var listChanged bool
+	for len(session.notifications) > 0 {
+		if n := <-session.notifications; n.Method == mcp.MethodNotificationPromptsListChanged {
+			listChanged = true
+		}
+	}
+	if !listChanged {
+		t.Error("register_command did not send prompts/list_changed")
+	}
+
+	response := mcpServer.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"deploy","arguments":{"service":"api"}}}`))
+	data, _ := json.Marshal(response)
+	if !strings.Contains(string(data), "Deploy api to staging.") {
+		t.Errorf("prompts/get did not render the template with defaults: %s", data)
+	}
+
+	response = mcpServer.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"deploy"}}`))
+	if _, ok := response.(mcp.JSONRPCError); !ok {
+		t.Errorf("expected missing required argument to fail, got %T", response)
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{"prompt argument", `{"ref":{"type":"ref/prompt","name":"deploy"},"argument":{"name":"env","value":"p"}}`, []string{"preview", "production"}},
+		{"resource template", `{"ref":{"type":"ref/resource","uri":"command://{name}"},"argument":{"name":"name","value":"DEP"}}`, []string{"deploy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := srv.handleCompletion(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"completion/complete","params":`+tt.message+`}`))
+			resp, ok := response.(mcp.JSONRPCResponse)
+			if !ok {
+				t.Fatalf("expected a response, got %#v", response)
+			}
+			values := resp.Result.(*mcp.CompleteResult).Completion.Values
+			if strings.Join(values, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("completion values = %v, want %v", values, tt.want)
 			}
+		})
+	}
+
+	if srv.handleCompletion(ctx, []byte(`{"jsonrpc":"2.0","id":4,"method":"prompts/list"}`)) != nil {
+		t.Error("handleCompletion must ignore other methods")
+	}
+
+	// Clients learn about completion support from the initialize result
+	response = mcpServer.HandleMessage(ctx, []byte(
+		`{"jsonrpc":"2.0","id":5,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+	data, _ = json.Marshal(response)
+	if !strings.Contains(string(data), `"experimental":{"completions":{}}`) {
+		t.Errorf("initialize did not advertise completions: %s", data)
+	}
+}
+
+func TestRenderCommandWithMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	// Placeholders must match declared arguments
+	result, _ := srv.registerCommand(ctx, toolRequest(map[string]any{
+		"name": "broken", "description": "Broken", "prompt": "Fix {{issue}}",
+	}))
+	if !result.IsError {
+		t.Error("expected register_command with an undeclared placeholder to fail")
+	}
+
+	result, _ = srv.registerCommand(ctx, toolRequest(map[string]any{
+		"name":        "fix-issue",
+		"description": "Fix an issue",
+		"prompt":      "Fix issue {{issue}} on {{branch}}.",
+		"arguments":   `[{"name":"issue","required":true},{"name":"branch","default":"main"}]`,
+	}))
+	resultJSON(t, result)
+
+	result, _ = srv.renderCommand(ctx, toolRequest(map[string]any{
+		"name": "fix-issue", "arguments": map[string]any{"issue": "42", "priority": "high"},
+	}))
+	out := resultJSON(t, result)
+	if out["prompt"] != "Fix issue 42 on main." {
+		t.Errorf("render_command prompt = %v", out["prompt"])
+	}
+	if unknown, _ := out["unknown_arguments"].([]any); len(unknown) != 1 || unknown[0] != "priority" {
+		t.Errorf("unknown_arguments = %v, want [priority]", out["unknown_arguments"])
+	}
+
+	result, _ = srv.renderCommand(ctx, toolRequest(map[string]any{"name": "fix-issue", "arguments": `{"branch":"dev"}`}))
+	if !result.IsError {
+		t.Error("expected render_command without a required argument to fail")
+	}
+}
+
+func TestSyncProjectWithMemoryStore(t *testing.T) {
+	ctx := auth.WithLocal(context.Background())
+	srv := newTestServer(t)
+	dir := t.TempDir()
+
+	result, _ := srv.syncProject(context.Background(), toolRequest(map[string]any{"project_dir": dir, "commands": "all"}))
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "only available over stdio") {
+		t.Errorf("network sync_project = %v, want refused", result.Content)
+	}
+
+	srv.registerCommand(ctx, toolRequest(map[string]any{
+		"name": "fix-issue", "description": "Fix an issue", "prompt": "Fix {{issue}}.",
+		"arguments": `[{"name":"issue","required":true}]`,
+	}))
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "fixer", "description": "Fixes things", "prompt": "You fix things.",
+	}))
+
+	result, _ = srv.syncProject(ctx, toolRequest(map[string]any{
+		"project_dir": dir, "commands": "all", "agents": "fixer",
+	}))
+	if out := resultJSON(t, result); out["count"] != float64(2) {
+		t.Fatalf("sync_project count = %v, want 2: %v", out["count"], out)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".claude", "commands", "fix-issue.md"))
+	if err != nil || !strings.Contains(string(data), "argument-hint: <issue>") || !strings.Contains(string(data), "Fix $1.") {
+		t.Errorf("command file = %q (%v)", data, err)
+	}
+
+	// Archiving the agent upstream removes it on the next refresh
+	srv.deleteAgent(asCaller(ctx, "ops", models.RoleAdmin), toolRequest(map[string]any{"name": "fixer", "expected_revision": float64(1)}))
+	result, _ = srv.syncProject(ctx, toolRequest(map[string]any{"project_dir": dir}))
+	out := resultJSON(t, result)
+	actions := map[string]any{}
+	for _, c := range out["changes"].([]any) {
+		change := c.(map[string]any)
+		actions[change["name"].(string)] = change["action"]
+	}
+	if actions["fix-issue"] != "unchanged" || actions["fixer"] != "removed" {
+		t.Errorf("refresh actions = %v", actions)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".claude", "agents", "fixer.md")); !os.IsNotExist(err) {
+		t.Error("archived agent file should have been removed")
+	}
+
+	result, _ = srv.syncProject(ctx, toolRequest(map[string]any{"project_dir": dir, "commands": "missing"}))
+	if !result.IsError {
+		t.Error("expected syncing an unknown command to fail")
+	}
+}
+
+func TestFindSimilarAgentsWithLocalEmbedder(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	t.Cleanup(store.Close)
+	engine, err := embeddings.NewEngine(embeddings.Config{Type: "local"})
+	if err != nil {
+		t.Fatalf("NewEngine(local) failed: %v", err)
+	}
+	srv := NewServerV2(store, engine, nil, governance.New(store, governance.DefaultConfig()))
+
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "k8s-debugger", "description": "Debugs Kubernetes pods and deployments", "prompt": "p", "skills": "kubernetes, kubectl",
+	}))
+	srv.registerAgent(ctx, toolRequest(map[string]any{
+		"name": "copywriter", "description": "Writes marketing copy for landing pages", "prompt": "p", "skills": "writing",
+	}))
+
+	result, _ := srv.findSimilarAgents(ctx, toolRequest(map[string]any{"description": "my kubernetes pod keeps crashing"}))
+	out := resultJSON(t, result)
+	similar, _ := out["similar_agents"].([]any)
+	if len(similar) == 0 {
+		t.Fatalf("find_similar_agents returned nothing: %v", out)
+	}
+	if top := similar[0].(map[string]any)["agent"].(map[string]any)["name"]; top != "k8s-debugger" {
+		t.Errorf("top match = %v, want k8s-debugger", top)
+	}
+
+	// Without an engine the tool reports the problem instead of panicking
+	result, _ = newTestServer(t).findSimilarAgents(ctx, toolRequest(map[string]any{"description": "anything"}))
+	if !result.IsError {
+		t.Error("expected find_similar_agents without an embedder to fail")
+	}
+}
+
+func TestEmbeddingStatsCountsCacheHits(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	t.Cleanup(store.Close)
+	engine, err := embeddings.NewEngine(embeddings.Config{Type: "local", CacheSize: 100})
+	if err != nil {
+		t.Fatalf("NewEngine(local) failed: %v", err)
+	}
+	srv := NewServerV2(store, engine, nil, governance.New(store, governance.DefaultConfig()))
+
+	for i := 0; i < 3; i++ {
+		srv.findSimilarAgents(ctx, toolRequest(map[string]any{"description": "review my pull request"}))
+	}
+
+	result, _ := srv.embeddingStats(ctx, toolRequest(nil))
+	out := resultJSON(t, result)
+	cache, _ := out["cache"].(map[string]any)
+	if cache["hits"] != float64(2) || cache["misses"] != float64(1) {
+		t.Errorf("cache stats = %v, want 2 hits and 1 miss", cache)
+	}
+	if out["enabled"] != true {
+		t.Errorf("enabled = %v, want true", out["enabled"])
+	}
+
+	result, _ = newTestServer(t).embeddingStats(ctx, toolRequest(nil))
+	if out := resultJSON(t, result); out["enabled"] != false || out["cache"] != nil {
+		t.Errorf("stats without an embedder = %v", out)
+	}
+}
+
+func TestReindexEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestServer(t)
+
+	// Registered without an engine, so nothing is embedded yet
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		srv.registerAgent(ctx, toolRequest(map[string]any{
+			"name": name, "description": "Agent " + name, "prompt": "p", "skills": "go",
+		}))
+	}
+	srv.registerSkill(ctx, toolRequest(map[string]any{
+		"name": "testing", "description": "How to test", "category": "dev", "content": "Write tests",
+	}))
+	srv.registerCommand(ctx, toolRequest(map[string]any{
+		"name": "review", "description": "Review code", "category": "dev", "prompt": "Review this",
+	}))
+
+	engine, err := embeddings.NewEngine(embeddings.Config{Type: "local"})
+	if err != nil {
+		t.Fatalf("NewEngine(local) failed: %v", err)
+	}
+	srv.embedder = engine
+
+	var updates []reindexProgress
+	results, err := srv.runReindex(ctx, reindexOptions{BatchSize: 2}, func(p reindexProgress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatalf("runReindex failed: %v", err)
+	}
+	want := map[models.EmbeddingKind]int{models.EmbeddingKindAgent: 3, models.EmbeddingKindSkill: 1, models.EmbeddingKindCommand: 1}
+	for _, p := range results {
+		if p.Total != want[p.Kind] || p.Current != p.Total || p.Reindexed != p.Total {
+			t.Errorf("%s progress = %+v, want all %d re-embedded", p.Kind, p, want[p.Kind])
 		}
-		if !found {
-			t.Errorf("taskAliases[review] should include typo %q", typo)
+	}
+	// Three agents in batches of two report twice, then one update per other kind
+	if len(updates) != 4 || updates[0].Current != 2 {
+		t.Errorf("progress updates = %+v", updates)
+	}
+
+	// Re-indexed rows match what registration would have embedded
+	agent, _ := srv.db.GetAgent(ctx, "alpha")
+	expected, _ := engine.Embed(ctx, embeddingText(agentSource(agent)))
+	if agent.EmbeddingModel != engine.Model() || agent.Embedding == nil || !reflect.DeepEqual(agent.Embedding.Slice(), expected.Slice()) {
+		t.Errorf("agent embedding model = %q, want %q with the registration text", agent.EmbeddingModel, engine.Model())
+	}
+
+	// Everything is current, so a second run has nothing to do
+	results, err = srv.runReindex(ctx, reindexOptions{}, nil)
+	if err != nil {
+		t.Fatalf("second runReindex failed: %v", err)
+	}
+	for _, p := range results {
+		if p.Reindexed != 0 {
+			t.Errorf("second run re-embedded %d %ss", p.Reindexed, p.Kind)
 		}
 	}
+
+	// Rows embedded by a different model are stale again
+	skill, _ := srv.db.GetSkill(ctx, "testing")
+	skill.EmbeddingModel = "other-model"
+	skill.Version = "1.0.1"
+	if err := srv.db.UpdateSkill(ctx, skill); err != nil {
+		t.Fatalf("UpdateSkill failed: %v", err)
+	}
+	result, _ := srv.embeddingStats(ctx, toolRequest(nil))
+	index, _ := resultJSON(t, result)["index"].(map[string]any)
+	if skills, _ := index["skill"].(map[string]any); skills["current"] != float64(0) {
+		t.Errorf("skill index after model change = %v, want 0 current", skills)
+	}
+	results, _ = srv.runReindex(ctx, reindexOptions{Kinds: []models.EmbeddingKind{models.EmbeddingKindSkill}}, nil)
+	if len(results) != 1 || results[0].Reindexed != 1 {
+		t.Errorf("skill re-index = %+v, want 1 re-embedded", results)
+	}
 }
 
-// ============ Benchmark Tests ============
+func TestParseEmbeddingKinds(t *testing.T) {
+	tests := []struct {
+		input   []string
+		want    []models.EmbeddingKind
+		wantErr bool
+	}{
+		{nil, models.EmbeddingKinds, false},
+		{[]string{"all"}, models.EmbeddingKinds, false},
+		{[]string{"skills", "agent", "skills"}, []models.EmbeddingKind{models.EmbeddingKindSkill, models.EmbeddingKindAgent}, false},
+		{[]string{"prompts"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseEmbeddingKinds(tt.input)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseEmbeddingKinds(%v) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
 
-func BenchmarkExpandTask(b *testing.B) {
-	task := "review my code for security vulnerabilities and optimize performance"
-	for i := 0; i < b.N; i++ {
-		expandTask(task)
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		current, requested string
+		want               string
+		wantErr            bool
+	}{
+		{"1.0.0", "", "1.0.1", false},
+		{"1.0.0", "1.1.0", "1.1.0", false},
+		{"1.0.0", "v2.0.0", "2.0.0", false},
+		{"1.0.0", "1.0.0", "", true},
+		{"1.2.0", "1.1.9", "", true},
+		{"1.0.0", "latest", "", true},
+		{"legacy", "", "", true},
+		{"legacy", "1.0.0", "1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		got, err := nextVersion(tt.current, tt.requested)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("nextVersion(%q, %q) error = %v, wantErr %v", tt.current, tt.requested, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("nextVersion(%q, %q) = %q, want %q", tt.current, tt.requested, got, tt.want)
+		}
 	}
 }
 
+func TestToolOutputSchemas(t *testing.T) {
+	ctx := auth.WithLocal(asCaller(context.Background(), "alice", models.RoleJudge, models.RoleExecutioner, models.RoleAdmin))
+	store := database.NewMemoryStore()
+	t.Cleanup(store.Close)
+	engine, err := embeddings.NewEngine(embeddings.Config{Type: "local"})
+	if err != nil {
+		t.Fatalf("NewEngine(local) failed: %v", err)
+	}
+	govConfig := governance.DefaultConfig()
+	govConfig.BanCoolingOff = 0
+	srv := NewServerV2(store, engine, nil, governance.New(store, govConfig))
+	mcpServer := server.NewMCPServer("test", VERSION)
+	srv.registerTools(mcpServer)
+
+	tools := mcpServer.ListTools()
+	for name, tool := range tools {
+		var schema map[string]any
+		if err := json.Unmarshal(tool.Tool.RawOutputSchema, &schema); err != nil || schema["type"] != "object" {
+			t.Errorf("%s output schema = %s (%v), want an object schema", name, tool.Tool.RawOutputSchema, err)
+		}
+	}
+
+	// Each call's structured content must match the tool's declared schema
+	called := map[string]bool{}
+	call := func(name string, args map[string]any) map[string]any {
+		t.Helper()
+		tool, ok := tools[name]
+		if !ok {
+			t.Fatalf("tool %s is not registered", name)
+		}
+		result, err := tool.Handler(ctx, toolRequest(args))
+		if err != nil || result.IsError {
+			t.Fatalf("%s failed: %v %v", name, err, result.Content)
+		}
+		out := resultJSON(t, result)
+		if err := toolschema.Validate(tool.Tool.RawOutputSchema, result.StructuredContent); err != nil {
+			t.Errorf("%s result does not match its output schema: %v", name, err)
+		}
+		called[name] = true
+		return out
+	}
+
+	call("register_agent", map[string]any{"name": "reviewer", "description": "Reviews code for bugs", "prompt": "You review code.", "skills": "review"})
+	call("register_skill", map[string]any{"name": "kubectl", "description": "Kubernetes CLI", "content": "kubectl get pods"})
+	call("register_command", map[string]any{"name": "fix", "description": "Fix an issue", "prompt": "Fix {{issue}}.", "arguments": `[{"name":"issue"}]`})
+
+	call("list_agents", nil)
+	call("get_agent", map[string]any{"name": "reviewer"})
+	call("search_agents", map[string]any{"query": "reviewr"})
+	call("find_similar_agents", map[string]any{"description": "review my code"})
+	call("request_agent_by_skills", map[string]any{"skills": "review"})
+	call("use_agent", map[string]any{"task": "review my code", "explain": true})
+	call("use_agent", map[string]any{"task": "zzz"})
+	call("submit_feedback", map[string]any{"agent_name": "reviewer", "rating": float64(5), "task_success": true})
+	call("get_agent_reputation", map[string]any{"name": "reviewer"})
+	call("get_top_agents", nil)
+	call("embedding_stats", nil)
+	call("reindex_embeddings", nil)
+
+	call("update_agent", map[string]any{"name": "reviewer", "expected_revision": float64(1), "prompt": "You review code carefully."})
+	call("get_agent", map[string]any{"name": "reviewer", "version": "1.0.0"})
+	call("list_revisions", map[string]any{"type": "agent", "name": "reviewer"})
+	call("diff_revisions", map[string]any{"type": "agent", "name": "reviewer", "from_version": "1.0.0"})
+	call("rollback_revision", map[string]any{"type": "agent", "name": "reviewer", "version": "1.0.0", "expected_revision": float64(2)})
+
+	call("list_skills", nil)
+	call("get_skill", map[string]any{"name": "kubectl"})
+	call("search_skills", map[string]any{"query": "kubernetes"})
+	call("find_similar_skills", map[string]any{"description": "debug pods"})
+	call("use_skill", map[string]any{"task": "kubectl pods"})
+	call("use_skill", map[string]any{"task": "zzz", "explain": true})
+
+	call("list_commands", nil)
+	call("get_command", map[string]any{"name": "fix"})
+	call("render_command", map[string]any{"name": "fix", "arguments": map[string]any{"issue": "#1"}})
+	call("search_commands", map[string]any{"query": "fix"})
+	call("sync_project", map[string]any{"project_dir": t.TempDir(), "commands": "all", "dry_run": true})
+
+	call("set_alias", map[string]any{"keyword": "k8s", "aliases": "kubernetes"})
+	call("list_aliases", nil)
+	call("reload_aliases", nil)
+	call("delete_alias", map[string]any{"keyword": "k8s"})
+
+	report := call("report_agent", map[string]any{"agent_name": "reviewer", "report_type": "spam", "severity": "low", "description": "Posts ads"})
+	call("review_reports", nil)
+	call("claim_report", map[string]any{"report_id": report["report_id"]})
+	call("rule_on_report", map[string]any{"report_id": report["report_id"], "resolution": "warning", "note": "Ads are off-topic"})
+	call("register_agent", map[string]any{"name": "spammer", "description": "Posts ads", "prompt": "Buy now."})
+	report = call("report_agent", map[string]any{"agent_name": "spammer", "report_type": "spam", "severity": "high", "description": "Only posts ads"})
+	call("claim_report", map[string]any{"report_id": report["report_id"]})
+	call("rule_on_report", map[string]any{"report_id": report["report_id"], "resolution": "ban", "note": "Repeat offender"})
+	call("execute_ban", map[string]any{"report_id": report["report_id"], "reason": "Ruling upheld"})
+	history := call("governance_history", map[string]any{"agent_name": "spammer"})
+	actions := history["actions"].([]any)
+	ban := actions[len(actions)-1].(map[string]any)
+	call("revert_governance_action", map[string]any{"action_id": ban["id"], "reason": "Overturned on review"})
+	appeal := call("file_appeal", map[string]any{"agent_name": "spammer", "statement": "The ads were a test fixture"})["appeal"].(map[string]any)
+	call("decide_appeal", map[string]any{"appeal_id": appeal["id"], "decision": "grant", "note": "Fixture, not spam"})
+	call("governance_action", map[string]any{"agent_name": "reviewer", "action": "promote", "reason": "good work", "reputation_delta": float64(5)})
+	call("governance_stats", nil)
+	call("delete_agent", map[string]any{"name": "reviewer", "expected_revision": float64(3)})
+
+	for name := range tools {
+		if !called[name] {
+			t.Errorf("tool %s was not exercised", name)
+		}
+	}
+}
+
+// ============ Benchmark Tests ============
+
 func BenchmarkContainsWord(b *testing.B) {
 	text := "this is a sample text with multiple words for testing"
 	word := "testing"
@@ -0,0 +1,12 @@
+-- Migration 006: Agent revision counter and archived status
+-- Run with: psql -d mcp_serve -f migrations/006_agent_revisions.sql
+
+-- ============ Optimistic Concurrency ============
+-- Every content change bumps revision; updates must present the revision they read
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 1;
+
+-- ============ Archived Status ============
+-- Archived agents are soft-deleted: kept for history, hidden from listings
+ALTER TABLE agents DROP CONSTRAINT IF EXISTS agents_status_check;
+ALTER TABLE agents ADD CONSTRAINT agents_status_check
+    CHECK (status IN ('active', 'quarantined', 'banned', 'archived'));
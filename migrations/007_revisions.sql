@@ -0,0 +1,105 @@
+-- Migration 007: Append-only revision history for agents, skills and commands
+-- Run with: psql -d mcp_serve -f migrations/007_revisions.sql
+
+-- ============ Agent Revisions ============
+CREATE TABLE IF NOT EXISTS agent_revisions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_id        UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    version         VARCHAR(50) NOT NULL,
+    name            VARCHAR(255) NOT NULL,
+    description     TEXT NOT NULL,
+    model           VARCHAR(50) NOT NULL,
+    tools           JSONB NOT NULL DEFAULT '[]',
+    metadata        JSONB NOT NULL DEFAULT '{}',
+    prompt          TEXT NOT NULL,
+    skills          TEXT[] DEFAULT '{}',
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (agent_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_revisions_agent ON agent_revisions (agent_id, created_at DESC);
+
+-- ============ Skill Revisions ============
+CREATE TABLE IF NOT EXISTS skill_revisions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    skill_id        UUID NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    version         VARCHAR(50) NOT NULL,
+    name            VARCHAR(255) NOT NULL,
+    description     TEXT NOT NULL,
+    category        VARCHAR(100) NOT NULL DEFAULT '',
+    content         TEXT NOT NULL,
+    examples        JSONB DEFAULT '[]',
+    metadata        JSONB DEFAULT '{}',
+    tags            TEXT[] DEFAULT '{}',
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (skill_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_skill_revisions_skill ON skill_revisions (skill_id, created_at DESC);
+
+-- ============ Command Revisions ============
+CREATE TABLE IF NOT EXISTS command_revisions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    command_id      UUID NOT NULL REFERENCES commands(id) ON DELETE CASCADE,
+    version         VARCHAR(50) NOT NULL,
+    name            VARCHAR(255) NOT NULL,
+    description     TEXT NOT NULL,
+    prompt          TEXT NOT NULL,
+    arguments       JSONB DEFAULT '[]',
+    metadata        JSONB DEFAULT '{}',
+    tags            TEXT[] DEFAULT '{}',
+    category        VARCHAR(100) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (command_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_command_revisions_command ON command_revisions (command_id, created_at DESC);
+
+-- ============ Append-only Guard ============
+-- Revisions are history: rows may be removed with their parent but never rewritten
+CREATE OR REPLACE FUNCTION reject_revision_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION '% rows are append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS agent_revisions_append_only ON agent_revisions;
+CREATE TRIGGER agent_revisions_append_only
+    BEFORE UPDATE ON agent_revisions
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_revision_update();
+
+DROP TRIGGER IF EXISTS skill_revisions_append_only ON skill_revisions;
+CREATE TRIGGER skill_revisions_append_only
+    BEFORE UPDATE ON skill_revisions
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_revision_update();
+
+DROP TRIGGER IF EXISTS command_revisions_append_only ON command_revisions;
+CREATE TRIGGER command_revisions_append_only
+    BEFORE UPDATE ON command_revisions
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_revision_update();
+
+-- ============ Backfill ============
+-- Seed history with the current state of every existing row
+INSERT INTO agent_revisions (agent_id, version, name, description, model, tools, metadata, prompt, skills, created_at)
+SELECT id, version, name, description, model, tools, metadata, prompt, COALESCE(skills, '{}'), updated_at
+FROM agents
+ON CONFLICT (agent_id, version) DO NOTHING;
+
+INSERT INTO skill_revisions (skill_id, version, name, description, category, content, examples, metadata, tags, created_at)
+SELECT id, version, name, description, COALESCE(category, ''), content, examples, metadata, COALESCE(tags, '{}'), updated_at
+FROM skills
+ON CONFLICT (skill_id, version) DO NOTHING;
+
+INSERT INTO command_revisions (command_id, version, name, description, prompt, arguments, metadata, tags, category, created_at)
+SELECT id, version, name, description, prompt, arguments, metadata, COALESCE(tags, '{}'), COALESCE(category, ''), updated_at
+FROM commands
+ON CONFLICT (command_id, version) DO NOTHING;
+
+-- ============ Comments ============
+COMMENT ON TABLE agent_revisions IS 'Append-only history of agent content, one row per version';
+COMMENT ON TABLE skill_revisions IS 'Append-only history of skill content, one row per version';
+COMMENT ON TABLE command_revisions IS 'Append-only history of command content, one row per version';
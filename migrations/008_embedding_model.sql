@@ -0,0 +1,14 @@
+-- Migration 008: Record which model produced each stored embedding
+-- Run with: psql -d mcp_serve -f migrations/008_embedding_model.sql
+
+-- ============ Embedding Model ============
+-- Empty means unknown (embedded before this migration or never embedded);
+-- `agentmcp reindex` re-embeds every row whose model differs from the engine's
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE skills ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE commands ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Re-indexing walks stale rows in id order
+CREATE INDEX IF NOT EXISTS idx_agents_embedding_model ON agents (embedding_model, id);
+CREATE INDEX IF NOT EXISTS idx_skills_embedding_model ON skills (embedding_model, id);
+CREATE INDEX IF NOT EXISTS idx_commands_embedding_model ON commands (embedding_model, id);
@@ -0,0 +1,30 @@
+-- Migration 009: Full-text search vectors for hybrid keyword + vector search
+-- Run with: psql -d mcp_serve -f migrations/009_search_vectors.sql
+
+-- ============ Search Vectors ============
+-- Weights follow ts_rank's defaults: name (A) outranks description (B),
+-- which outranks the prompt or content body (C)
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(prompt, '')), 'C')
+    ) STORED;
+
+ALTER TABLE skills ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(content, '')), 'C')
+    ) STORED;
+
+ALTER TABLE commands ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(prompt, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_agents_search ON agents USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_skills_search ON skills USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_commands_search ON commands USING GIN (search_vector);
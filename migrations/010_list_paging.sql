@@ -0,0 +1,15 @@
+-- Migration 010: Indexes for keyset-paged list tools
+-- Run with: psql -d mcp_serve -f migrations/010_list_paging.sql
+
+-- ============ Default Ordering ============
+-- Lists page by (reputation_score, usage_count, id) by default; matching the
+-- full key lets each page start with an index seek instead of a sort
+CREATE INDEX IF NOT EXISTS idx_agents_list ON agents (reputation_score DESC, usage_count DESC, id);
+CREATE INDEX IF NOT EXISTS idx_skills_list ON skills (reputation_score DESC, usage_count DESC, id);
+CREATE INDEX IF NOT EXISTS idx_commands_list ON commands (reputation_score DESC, usage_count DESC, id);
+
+-- ============ Recency ============
+CREATE INDEX IF NOT EXISTS idx_agents_created ON agents (created_at DESC, id);
+CREATE INDEX IF NOT EXISTS idx_skills_created ON skills (created_at DESC, id);
+CREATE INDEX IF NOT EXISTS idx_commands_created ON commands (created_at DESC, id);
+CREATE INDEX IF NOT EXISTS idx_reports_created ON reports (created_at, id);
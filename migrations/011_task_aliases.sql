@@ -0,0 +1,14 @@
+-- Migration 011: Runtime-editable task alias dictionary
+-- Run with: psql -d mcp_serve -f migrations/011_task_aliases.sql
+
+-- ============ Task Aliases ============
+-- Overrides the built-in and file synonyms per (domain, keyword); a row with
+-- no aliases disables the entry it shadows
+CREATE TABLE IF NOT EXISTS task_aliases (
+    domain VARCHAR(100) NOT NULL DEFAULT 'general',
+    keyword VARCHAR(100) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (domain, keyword)
+);
@@ -0,0 +1,34 @@
+-- Migration 012: Trigram matching for typo-tolerant search
+-- Run with: psql -d mcp_serve -f migrations/012_trigram_search.sql
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- ============ Fuzzy Text ============
+-- Lowercased name, description and tags that search terms without a keyword
+-- match are compared with by word_similarity, and that "did you mean"
+-- suggestions are drawn from. array_to_string is only STABLE, so generated
+-- columns join text arrays through an IMMUTABLE wrapper.
+CREATE OR REPLACE FUNCTION join_words(words TEXT[]) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+    AS $$ SELECT array_to_string(words, ' ') $$;
+
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS fuzzy_text TEXT
+    GENERATED ALWAYS AS (lower(
+        coalesce(name, '') || ' ' || coalesce(description, '') || ' ' ||
+        coalesce(metadata->>'tags', '') || ' ' || coalesce(join_words(skills), '')
+    )) STORED;
+
+ALTER TABLE skills ADD COLUMN IF NOT EXISTS fuzzy_text TEXT
+    GENERATED ALWAYS AS (lower(
+        coalesce(name, '') || ' ' || coalesce(description, '') || ' ' || coalesce(join_words(tags), '')
+    )) STORED;
+
+ALTER TABLE commands ADD COLUMN IF NOT EXISTS fuzzy_text TEXT
+    GENERATED ALWAYS AS (lower(
+        coalesce(name, '') || ' ' || coalesce(description, '') || ' ' || coalesce(join_words(tags), '')
+    )) STORED;
+
+-- Lets `fuzzy_text %> term` find candidate rows without scoring every row
+CREATE INDEX IF NOT EXISTS idx_agents_fuzzy ON agents USING GIN (fuzzy_text gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_skills_fuzzy ON skills USING GIN (fuzzy_text gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_commands_fuzzy ON commands USING GIN (fuzzy_text gin_trgm_ops);
@@ -0,0 +1,9 @@
+-- Migration 013: Record which principal took each governance action
+-- Run with: psql -d mcp_serve -f migrations/013_governance_actors.sql
+
+-- ============ Governance Actors ============
+-- action_by holds the role the caller acted in; actor names the caller.
+-- Rows written before callers were identified are attributed to 'system'.
+ALTER TABLE governance_actions ADD COLUMN IF NOT EXISTS actor VARCHAR(255) NOT NULL DEFAULT 'system';
+
+CREATE INDEX IF NOT EXISTS idx_governance_actor ON governance_actions (actor);
@@ -0,0 +1,15 @@
+-- Migration 014: Revert governance actions
+-- Run with: psql -d mcp_serve -f migrations/014_governance_reverts.sql
+
+-- ============ Unban ============
+-- Reverting a ban records an unban action
+ALTER TABLE governance_actions DROP CONSTRAINT IF EXISTS governance_actions_action_type_check;
+ALTER TABLE governance_actions ADD CONSTRAINT governance_actions_action_type_check
+    CHECK (action_type IN ('quarantine', 'unquarantine', 'ban', 'unban', 'warn', 'promote', 'demote'));
+
+-- ============ Compensating Actions ============
+-- A revert records a compensating action that points at the action it undid
+ALTER TABLE governance_actions ADD COLUMN IF NOT EXISTS reverts_action_id UUID REFERENCES governance_actions(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_governance_reverts ON governance_actions (reverts_action_id)
+    WHERE reverts_action_id IS NOT NULL;
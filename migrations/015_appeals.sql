@@ -0,0 +1,31 @@
+-- Migration 015: Appeals against quarantines and bans
+-- Run with: psql -d mcp_serve -f migrations/015_appeals.sql
+
+-- ============ Appeals Table ============
+-- An agent's creator may ask a judge to lift its quarantine or ban
+CREATE TABLE IF NOT EXISTS appeals (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_id        UUID REFERENCES agents(id) ON DELETE CASCADE,
+    action_id       UUID REFERENCES governance_actions(id),
+
+    -- Appellant info
+    filed_by        VARCHAR(255) NOT NULL,
+    statement       TEXT NOT NULL,
+
+    -- Decision
+    status          VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'granted', 'denied')),
+    decided_by      VARCHAR(255),
+    decision_note   TEXT,
+
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    decided_at      TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_appeals_agent ON appeals (agent_id);
+CREATE INDEX IF NOT EXISTS idx_appeals_status ON appeals (status);
+
+-- One pending appeal per agent at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_appeals_pending_agent ON appeals (agent_id)
+    WHERE status = 'pending';
+
+COMMENT ON TABLE appeals IS 'Appeals against governance actions, decided by judges';
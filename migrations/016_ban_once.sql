@@ -0,0 +1,7 @@
+-- Migration 016: Execute each ban ruling at most once
+-- Run with: psql -d mcp_serve -f migrations/016_ban_once.sql
+
+-- ============ Ban Rulings ============
+-- An unban or granted appeal must not be undone by executing the same ruling again
+CREATE UNIQUE INDEX IF NOT EXISTS idx_governance_ban_report ON governance_actions (report_id)
+    WHERE action_type = 'ban' AND report_id IS NOT NULL;